@@ -0,0 +1,144 @@
+package indexers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"reel/internal/utils"
+)
+
+// Limits of the saved indexer responses: the oldest files are removed beyond
+// maxSavedResponses, and bodies are cut at maxSavedResponseBytes.
+const (
+	maxSavedResponses     = 200
+	maxSavedResponseBytes = 2 << 20
+)
+
+// secretQueryParams are removed from the request URLs written to saved responses.
+var secretQueryParams = []string{"apikey", "api_key", "passkey", "token"}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ResponseRecorder saves the raw response of every indexer search to a file, to
+// find out why a search returned nothing or something unexpected.
+type ResponseRecorder struct {
+	dir    string
+	source string // Names the files of one source, see For
+	logger *utils.Logger
+	mu     *sync.Mutex
+}
+
+// NewResponseRecorder creates a recorder writing to dir, which is created on the first save.
+func NewResponseRecorder(dir string, logger *utils.Logger) *ResponseRecorder {
+	return &ResponseRecorder{dir: dir, logger: logger, mu: &sync.Mutex{}}
+}
+
+// For returns a recorder for one source, whose name is part of the file names. It
+// shares the folder and its limits with r. A nil recorder gives nil.
+func (r *ResponseRecorder) For(source string) *ResponseRecorder {
+	if r == nil {
+		return nil
+	}
+	scoped := *r
+	scoped.source = source
+	return &scoped
+}
+
+// record saves a search response. The body is read in full and put back, so the caller
+// decodes it as usual. A nil recorder does nothing.
+func (r *ResponseRecorder) record(resp *http.Response) {
+	if r == nil {
+		return
+	}
+	indexer := r.source
+	if indexer == "" {
+		indexer = "indexer"
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		r.logger.Warn("Could not read the", indexer, "response to save it:", err)
+		return
+	}
+
+	var out bytes.Buffer
+	if resp.Request != nil {
+		fmt.Fprintf(&out, "# %s %s\n", resp.Request.Method, redactURL(resp.Request.URL))
+	}
+	fmt.Fprintf(&out, "# Status: %s\n# Content-Type: %s\n\n", resp.Status, resp.Header.Get("Content-Type"))
+	if len(body) > maxSavedResponseBytes {
+		out.Write(body[:maxSavedResponseBytes])
+		fmt.Fprintf(&out, "\n# Truncated: %d of %d bytes saved\n", maxSavedResponseBytes, len(body))
+	} else {
+		out.Write(body)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		r.logger.Warn("Could not create the indexer response folder:", err)
+		return
+	}
+	// The random suffix keeps searches saved within the same millisecond apart
+	pattern := fmt.Sprintf("%s-%s-*.txt", time.Now().Format("20060102-150405.000"), unsafeFilenameChars.ReplaceAllString(indexer, "_"))
+	file, err := os.CreateTemp(r.dir, pattern)
+	if err != nil {
+		r.logger.Warn("Could not save the", indexer, "response:", err)
+		return
+	}
+	_, err = file.Write(out.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		r.logger.Warn("Could not save the", indexer, "response:", err)
+	}
+	r.prune()
+}
+
+// prune removes the oldest saved responses beyond maxSavedResponses. File names start
+// with their timestamp, so they sort oldest first.
+func (r *ResponseRecorder) prune() {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".txt") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > maxSavedResponses {
+		os.Remove(filepath.Join(r.dir, names[0]))
+		names = names[1:]
+	}
+}
+
+// redactURL returns the URL with its API key and other secret parameters masked.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for param := range query {
+		for _, secret := range secretQueryParams {
+			if strings.EqualFold(param, secret) {
+				query.Set(param, "REDACTED")
+			}
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return redacted.String()
+}
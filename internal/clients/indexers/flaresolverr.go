@@ -0,0 +1,255 @@
+package indexers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"reel/internal/utils"
+)
+
+// defaultClearanceTTL is how long solved cookies are reused when FlareSolverr doesn't
+// report an expiry for them.
+const defaultClearanceTTL = 30 * time.Minute
+
+// flareSolverrTransport sends requests straight to the indexer and, when Cloudflare
+// answers with a challenge, has a FlareSolverr instance solve it. The clearance
+// cookies and the browser User-Agent they are bound to are cached per host until
+// they expire, so later requests go straight through again.
+type flareSolverrTransport struct {
+	base       http.RoundTripper
+	solverURL  string
+	timeout    time.Duration
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	clearances map[string]*clearance
+}
+
+type clearance struct {
+	cookies   []*http.Cookie
+	userAgent string
+	expires   time.Time
+}
+
+type flareSolverrRequest struct {
+	Cmd        string `json:"cmd"`
+	URL        string `json:"url"`
+	MaxTimeout int    `json:"maxTimeout"`
+}
+
+type flareSolverrResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Solution struct {
+		URL       string            `json:"url"`
+		Status    int               `json:"status"`
+		Headers   map[string]string `json:"headers"`
+		Response  string            `json:"response"`
+		UserAgent string            `json:"userAgent"`
+		Cookies   []struct {
+			Name    string  `json:"name"`
+			Value   string  `json:"value"`
+			Expires float64 `json:"expires"`
+		} `json:"cookies"`
+	} `json:"solution"`
+}
+
+// withFlareSolverr wraps the client's transport so Cloudflare challenges are solved
+// through the FlareSolverr instance at solverURL. An empty URL leaves it unchanged.
+func withFlareSolverr(client *http.Client, solverURL string, timeout time.Duration) *http.Client {
+	if solverURL == "" {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &flareSolverrTransport{
+		base:      base,
+		solverURL: strings.TrimSuffix(strings.TrimSuffix(solverURL, "/"), "/v1") + "/v1",
+		timeout:   timeout,
+		// Solving a challenge takes a while, give FlareSolverr some slack on top of maxTimeout.
+		httpClient: utils.NewLocalHTTPClient(timeout + 10*time.Second),
+		clearances: make(map[string]*clearance),
+	}
+	return client
+}
+
+func (t *flareSolverrTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	host := req.URL.Host
+	resp, err := t.base.RoundTrip(t.withClearance(req, t.cachedClearance(host)))
+	if err != nil || !isCloudflareChallenge(resp) {
+		return resp, err
+	}
+	resp.Body.Close()
+	t.forgetClearance(host)
+
+	solved, err := t.solve(req)
+	if err != nil {
+		return nil, err
+	}
+	c := t.storeClearance(host, solved)
+
+	// Replay the request with the clearance so the indexer's own response is returned;
+	// the page FlareSolverr fetched has been rendered by a browser, which can mangle XML.
+	resp, err = t.base.RoundTrip(t.withClearance(req, c))
+	if err != nil {
+		return nil, err
+	}
+	if isCloudflareChallenge(resp) {
+		resp.Body.Close()
+		return solutionResponse(req, solved), nil
+	}
+	return resp, nil
+}
+
+// isCloudflareChallenge reports whether the response is a Cloudflare challenge page
+// rather than the indexer's answer.
+func isCloudflareChallenge(resp *http.Response) bool {
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusServiceUnavailable) &&
+		strings.EqualFold(resp.Header.Get("Server"), "cloudflare")
+}
+
+// withClearance returns a copy of the request carrying the clearance cookies and
+// User-Agent, or the request itself when there is no clearance.
+func (t *flareSolverrTransport) withClearance(req *http.Request, c *clearance) *http.Request {
+	if c == nil {
+		return req
+	}
+	req = req.Clone(req.Context())
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return req
+}
+
+func (t *flareSolverrTransport) cachedClearance(host string) *clearance {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.clearances[host]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(c.expires) {
+		delete(t.clearances, host)
+		return nil
+	}
+	return c
+}
+
+func (t *flareSolverrTransport) forgetClearance(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.clearances, host)
+}
+
+// storeClearance caches the solved cookies until the earliest of them expires,
+// preferring cf_clearance's own expiry.
+func (t *flareSolverrTransport) storeClearance(host string, solved *flareSolverrResponse) *clearance {
+	c := &clearance{userAgent: solved.Solution.UserAgent}
+	var earliest, cfClearance time.Time
+	for _, cookie := range solved.Solution.Cookies {
+		c.cookies = append(c.cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		if cookie.Expires <= 0 {
+			continue // session cookie
+		}
+		expires := time.Unix(int64(cookie.Expires), 0)
+		if cookie.Name == "cf_clearance" {
+			cfClearance = expires
+		}
+		if earliest.IsZero() || expires.Before(earliest) {
+			earliest = expires
+		}
+	}
+	switch {
+	case !cfClearance.IsZero():
+		c.expires = cfClearance
+	case !earliest.IsZero():
+		c.expires = earliest
+	default:
+		c.expires = time.Now().Add(defaultClearanceTTL)
+	}
+
+	t.mu.Lock()
+	t.clearances[host] = c
+	t.mu.Unlock()
+	return c
+}
+
+// solve asks FlareSolverr to fetch the request's URL in its browser.
+func (t *flareSolverrTransport) solve(req *http.Request) (*flareSolverrResponse, error) {
+	body, err := json.Marshal(flareSolverrRequest{
+		Cmd:        "request.get",
+		URL:        req.URL.String(),
+		MaxTimeout: int(t.timeout / time.Millisecond),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FlareSolverr request: %w", err)
+	}
+
+	solverReq, err := http.NewRequestWithContext(req.Context(), http.MethodPost, t.solverURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FlareSolverr request: %w", err)
+	}
+	solverReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(solverReq)
+	if err != nil {
+		return nil, fmt.Errorf("FlareSolverr request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var solved flareSolverrResponse
+	if err := json.NewDecoder(resp.Body).Decode(&solved); err != nil {
+		return nil, fmt.Errorf("failed to decode FlareSolverr response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if solved.Status != "ok" {
+		return nil, fmt.Errorf("FlareSolverr could not solve the challenge for %s: %s", req.URL.Host, solved.Message)
+	}
+	return &solved, nil
+}
+
+// solutionResponse builds a response from the page FlareSolverr fetched, for hosts
+// that keep challenging plain requests even with the clearance cookies.
+func solutionResponse(req *http.Request, solved *flareSolverrResponse) *http.Response {
+	status := solved.Solution.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := make(http.Header)
+	for name, value := range solved.Solution.Headers {
+		switch http.CanonicalHeaderKey(name) {
+		case "Content-Length", "Content-Encoding":
+			// The body below is FlareSolverr's decoded copy.
+		default:
+			header.Set(name, value)
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(solved.Solution.Response)),
+		ContentLength: int64(len(solved.Solution.Response)),
+		Request:       req,
+	}
+}
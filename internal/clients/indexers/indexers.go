@@ -1,12 +1,19 @@
 package indexers
 
-import "time"
+import (
+	"time"
+
+	"reel/internal/utils"
+)
 
 // Client is the interface for all indexer providers.
 type Client interface {
 	SearchMovies(query string, tmdbID string, searchMode string) ([]IndexerResult, error)
 	SearchTVShows(query string, season int, episode int, searchMode string) ([]IndexerResult, error)
 	HealthCheck() (bool, error)
+	// Capabilities reports the search modes the indexer supports. Clients without a
+	// caps endpoint return nil, nil.
+	Capabilities() (*Capabilities, error)
 }
 
 // IndexerResult is a standardized struct for search results from any indexer.
@@ -18,5 +25,17 @@ type IndexerResult struct {
 	DownloadURL string
 	PublishDate time.Time
 	Indexer     string
+	Description string `json:",omitempty"` // The item's description, from Torznab and RSS sources
 	Score       int
+	SeasonPack  bool   // A whole-season release found by a "season" mode search
+	Priority    int    // Priority of the source the result came from
+	TorrentFile []byte `json:"-"`          // An uploaded .torrent file, added instead of DownloadURL
+	ID          string `json:",omitempty"` // Set on manual search results, which can be downloaded by ID alone
+	// The quality tags of the title, set on manual search results for display
+	Release *utils.ReleaseInfo `json:",omitempty"`
+	// A release without an episode number, found by the episode title fallback search
+	// and named after the episode's title
+	EpisodeTitleMatch bool `json:",omitempty"`
+	// Found on a source marked private, so no extra trackers are added to the torrent
+	Private bool `json:",omitempty"`
 }
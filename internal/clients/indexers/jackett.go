@@ -1,14 +1,13 @@
 package indexers
 
 import (
-	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 
-	"golang.org/x/net/html/charset"
+	"reel/internal/utils"
 )
 
 // JackettClient implements a real Jackett client.
@@ -16,13 +15,15 @@ type JackettClient struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	recorder   *ResponseRecorder // Saves search responses when set
 }
 
-func NewJackettClient(baseURL, apiKey string, timeout time.Duration) *JackettClient {
+func NewJackettClient(baseURL, apiKey string, httpConfig utils.HTTPClientConfig, flareSolverrURL string, recorder *ResponseRecorder) *JackettClient {
 	return &JackettClient{
 		baseURL:    baseURL,
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: timeout},
+		httpClient: withFlareSolverr(utils.NewHTTPClient(httpConfig), flareSolverrURL, httpConfig.Timeout),
+		recorder:   recorder,
 	}
 }
 
@@ -34,6 +35,7 @@ func (c *JackettClient) searchTorznab(params url.Values) ([]IndexerResult, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to search Jackett: %w", err)
 	}
+	c.recorder.record(resp)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
@@ -41,10 +43,8 @@ func (c *JackettClient) searchTorznab(params url.Values) ([]IndexerResult, error
 	}
 
 	var torznabResp TorznabFeed
-	decoder := xml.NewDecoder(resp.Body)
-	decoder.CharsetReader = charset.NewReaderLabel
-	if err := decoder.Decode(&torznabResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Jackett Torznab response: %w", err)
+	if err := decodeTorznab(resp, "Jackett", &torznabResp); err != nil {
+		return nil, err
 	}
 
 	results := make([]IndexerResult, len(torznabResp.Channel.Items))
@@ -55,38 +55,45 @@ func (c *JackettClient) searchTorznab(params url.Values) ([]IndexerResult, error
 			Size:        item.Size,
 			Seeders:     item.GetIntAttr("seeders"),
 			Leechers:    item.GetIntAttr("leechers"),
-			DownloadURL: item.Link,
+			DownloadURL: item.DownloadURL(c.baseURL),
 			PublishDate: pubDate,
 			Indexer:     "Jackett",
+			Description: item.Description,
 		}
 	}
 	return results, nil
 }
 
-// SearchMovies performs a movie search on Jackett.
-func (c *JackettClient) SearchMovies(query string, imdbID string, searchMode string) ([]IndexerResult, error) {
+// SearchMovies performs a movie search on Jackett. The TMDB id is only sent with a
+// movie search; a generic search relies on the query alone.
+func (c *JackettClient) SearchMovies(query string, tmdbID string, searchMode string) ([]IndexerResult, error) {
+	searchType := torznabSearchType(searchMode, "movie")
 	params := url.Values{}
-	params.Add("t", "movie")
+	params.Add("t", searchType)
 	params.Add("q", query)
 	params.Add("apikey", c.apiKey)
-	if imdbID != "" {
-		params.Add("imdbid", imdbID)
+	if searchType == "movie" && tmdbID != "" {
+		params.Add("tmdbid", tmdbID)
 	}
 
 	return c.searchTorznab(params)
 }
 
-// SearchTVShows performs a TV show search on Jackett.
+// SearchTVShows performs a TV show search on Jackett. Season and episode are only sent
+// with a tvsearch; a generic search already carries them in the query.
 func (c *JackettClient) SearchTVShows(query string, season int, episode int, searchMode string) ([]IndexerResult, error) {
+	searchType := torznabSearchType(searchMode, "tvsearch")
 	params := url.Values{}
-	params.Add("t", "tvsearch")
+	params.Add("t", searchType)
 	params.Add("q", query)
 	params.Add("apikey", c.apiKey)
-	if season > 0 {
-		params.Add("season", strconv.Itoa(season))
-	}
-	if episode > 0 {
-		params.Add("ep", strconv.Itoa(episode))
+	if searchType == "tvsearch" {
+		if season > 0 {
+			params.Add("season", strconv.Itoa(season))
+		}
+		if episode > 0 {
+			params.Add("ep", strconv.Itoa(episode))
+		}
 	}
 
 	return c.searchTorznab(params)
@@ -107,3 +114,8 @@ func (c *JackettClient) HealthCheck() (bool, error) {
 
 	return resp.StatusCode == http.StatusOK, nil
 }
+
+// Capabilities fetches the indexer's Torznab caps.
+func (c *JackettClient) Capabilities() (*Capabilities, error) {
+	return fetchTorznabCaps(c.httpClient, c.baseURL, c.apiKey)
+}
@@ -0,0 +1,40 @@
+package indexers
+
+import (
+	"testing"
+	"time"
+
+	"reel/internal/utils"
+)
+
+func TestJackettSearchParams(t *testing.T) {
+	const emptyFeed = `<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel></channel></rss>`
+	tests := []struct {
+		name   string
+		search searchRequest
+		want   map[string]string // "" for a parameter that must not be sent
+	}{
+		{"movie search sends the tmdb id", searchRequest{movie: true, query: "The Matrix", tmdbID: "603", mode: "movie"}, map[string]string{"t": "movie", "q": "The Matrix", "tmdbid": "603"}},
+		{"generic movie search", searchRequest{movie: true, query: "The Matrix 1999", tmdbID: "603", mode: "search"}, map[string]string{"t": "search", "q": "The Matrix 1999", "tmdbid": ""}},
+		{"tv search sends season and episode", searchRequest{query: "Severance", season: 1, episode: 3, mode: "tv-search"}, map[string]string{"t": "tvsearch", "q": "Severance", "season": "1", "ep": "3"}},
+		{"season pack search", searchRequest{query: "Severance", season: 2, mode: "tvsearch"}, map[string]string{"t": "tvsearch", "q": "Severance", "season": "2", "ep": ""}},
+		{"generic tv search", searchRequest{query: "Severance S01E03", season: 1, episode: 3, mode: "search"}, map[string]string{"t": "search", "q": "Severance S01E03", "season": "", "ep": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverURL, query := serveSearch(t, emptyFeed)
+			client := NewJackettClient(serverURL+"/api/v2.0/indexers/all/results/torznab", "key", utils.HTTPClientConfig{Timeout: time.Second}, "", nil)
+			if _, err := tt.search.send(client); err != nil {
+				t.Fatal(err)
+			}
+			for param, want := range tt.want {
+				if got, sent := query.Get(param), query.Has(param); got != want || sent != (want != "") {
+					t.Errorf("%s = %q (sent %t), want %q", param, got, sent, want)
+				}
+			}
+			if got := query.Get("apikey"); got != "key" {
+				t.Errorf("apikey = %q, want key", got)
+			}
+		})
+	}
+}
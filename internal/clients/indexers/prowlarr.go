@@ -6,14 +6,19 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
+
+	"reel/internal/utils"
 )
 
 // ProwlarrClient implements the indexer.Client interface for Prowlarr.
 type ProwlarrClient struct {
 	baseURL    string
 	apiKey     string
+	categories []int
 	httpClient *http.Client
+	recorder   *ResponseRecorder // Saves search responses when set
 }
 
 // prowlarrSearchResult defines the structure of a single search result from the Prowlarr API.
@@ -27,12 +32,35 @@ type prowlarrSearchResult struct {
 	Indexer     string    `json:"indexer"`
 }
 
-// NewProwlarrClient creates a new client for interacting with the Prowlarr API.
-func NewProwlarrClient(baseURL, apiKey string, timeout time.Duration) *ProwlarrClient {
+// Default Newznab categories searched on Prowlarr for each media type.
+var (
+	ProwlarrMovieCategories = []int{2000}
+	ProwlarrTVCategories    = []int{5000}
+	ProwlarrAnimeCategories = []int{5070}
+)
+
+// NewProwlarrClient creates a new client for interacting with the Prowlarr API. Searches
+// are limited to the given Newznab categories; without any, movie searches use
+// ProwlarrMovieCategories and TV searches ProwlarrTVCategories.
+func NewProwlarrClient(baseURL, apiKey string, categories []int, httpConfig utils.HTTPClientConfig, recorder *ResponseRecorder) *ProwlarrClient {
 	return &ProwlarrClient{
 		baseURL:    baseURL,
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: timeout},
+		categories: categories,
+		httpClient: utils.NewHTTPClient(httpConfig),
+		recorder:   recorder,
+	}
+}
+
+// addCategories adds the client's categories to the search params, or the fallback
+// when it has none.
+func (p *ProwlarrClient) addCategories(params url.Values, fallback []int) {
+	categories := p.categories
+	if len(categories) == 0 {
+		categories = fallback
+	}
+	for _, category := range categories {
+		params.Add("categories", strconv.Itoa(category))
 	}
 }
 
@@ -51,6 +79,7 @@ func (p *ProwlarrClient) search(params url.Values) ([]IndexerResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to search Prowlarr: %w", err)
 	}
+	p.recorder.record(resp)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
@@ -77,32 +106,58 @@ func (p *ProwlarrClient) search(params url.Values) ([]IndexerResult, error) {
 	return results, nil
 }
 
-// SearchMovies searches for movies using the Prowlarr API.
+// prowlarrQuery appends id and season/episode tokens to a search query. Prowlarr's search
+// API takes these as {Key:value} tokens inside the query rather than as separate
+// parameters, and passes them on to each indexer as proper Torznab/Newznab params.
+func prowlarrQuery(query string, tokens ...string) string {
+	for i := 0; i+1 < len(tokens); i += 2 {
+		if tokens[i+1] != "" {
+			query += fmt.Sprintf(" {%s:%s}", tokens[i], tokens[i+1])
+		}
+	}
+	return strings.TrimSpace(query)
+}
+
+// SearchMovies searches for movies using the Prowlarr API. With a movie search the TMDB
+// id is sent along; a generic search relies on the query alone.
 func (p *ProwlarrClient) SearchMovies(query string, tmdbID string, searchMode string) ([]IndexerResult, error) {
+	searchType := torznabSearchType(searchMode, "movie")
+	if searchType == "movie" {
+		query = prowlarrQuery(query, "TmdbId", tmdbID)
+	}
+
 	params := url.Values{}
 	params.Add("query", query)
-	params.Add("type", "search")
-	params.Add("categories", "2000") // Movie categories
+	params.Add("type", searchType)
+	p.addCategories(params, ProwlarrMovieCategories)
 
 	return p.search(params)
 }
 
-// SearchTVShows searches for TV shows using the Prowlarr API.
+// SearchTVShows searches for TV shows using the Prowlarr API. With a tvsearch the season
+// and episode are sent as tokens; a generic search already carries them in the query.
 func (p *ProwlarrClient) SearchTVShows(query string, season int, episode int, searchMode string) ([]IndexerResult, error) {
+	searchType := torznabSearchType(searchMode, "tvsearch")
+	if searchType == "tvsearch" {
+		query = prowlarrQuery(query, "Season", positiveItoa(season), "Episode", positiveItoa(episode))
+	}
+
 	params := url.Values{}
 	params.Add("query", query)
-	params.Add("type", "search")
-	params.Add("categories", "5000") // TV categories
-	if season > 0 {
-		params.Add("season", strconv.Itoa(season))
-	}
-	if episode > 0 {
-		params.Add("episode", strconv.Itoa(episode))
-	}
+	params.Add("type", searchType)
+	p.addCategories(params, ProwlarrTVCategories)
 
 	return p.search(params)
 }
 
+// positiveItoa formats n, or returns "" when it isn't set.
+func positiveItoa(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
 // HealthCheck verifies the connection to the Prowlarr API.
 func (p *ProwlarrClient) HealthCheck() (bool, error) {
 	healthURL := fmt.Sprintf("%s/api/v1/health", p.baseURL)
@@ -120,3 +175,9 @@ func (p *ProwlarrClient) HealthCheck() (bool, error) {
 
 	return resp.StatusCode == http.StatusOK, nil
 }
+
+// Capabilities returns nil: Prowlarr aggregates many indexers behind its own search API,
+// so there is no single caps document to check a search mode against.
+func (p *ProwlarrClient) Capabilities() (*Capabilities, error) {
+	return nil, nil
+}
@@ -0,0 +1,76 @@
+package indexers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"reel/internal/utils"
+)
+
+// serveSearch starts a server answering every request with body and returns its URL
+// and a pointer to the query of the last request.
+func serveSearch(t *testing.T, body string) (string, *url.Values) {
+	t.Helper()
+	query := new(url.Values)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*query = r.URL.Query()
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, query
+}
+
+// searchRequest is a search sent to an indexer client: a movie search when movie is
+// set, a TV search otherwise.
+type searchRequest struct {
+	movie           bool
+	query, tmdbID   string
+	season, episode int
+	mode            string
+}
+
+func (r searchRequest) send(client Client) ([]IndexerResult, error) {
+	if r.movie {
+		return client.SearchMovies(r.query, r.tmdbID, r.mode)
+	}
+	return client.SearchTVShows(r.query, r.season, r.episode, r.mode)
+}
+
+func TestProwlarrSearchParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		search    searchRequest
+		wantType  string
+		wantQuery string
+		wantCats  []string
+	}{
+		{"movie search sends the tmdb id", searchRequest{movie: true, query: "The Matrix", tmdbID: "603", mode: "movie"}, "movie", "The Matrix {TmdbId:603}", []string{"2000"}},
+		{"movie search without a tmdb id", searchRequest{movie: true, query: "The Matrix", mode: "movie-search"}, "movie", "The Matrix", []string{"2000"}},
+		{"generic movie search", searchRequest{movie: true, query: "The Matrix 1999", tmdbID: "603", mode: "search"}, "search", "The Matrix 1999", []string{"2000"}},
+		{"tv search sends season and episode", searchRequest{query: "Severance", season: 1, episode: 3, mode: "tvsearch"}, "tvsearch", "Severance {Season:1} {Episode:3}", []string{"5000"}},
+		{"season pack search", searchRequest{query: "Severance", season: 2}, "tvsearch", "Severance {Season:2}", []string{"5000"}},
+		{"generic tv search", searchRequest{query: "Severance S01E03", season: 1, episode: 3, mode: "search"}, "search", "Severance S01E03", []string{"5000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverURL, query := serveSearch(t, `[]`)
+			client := NewProwlarrClient(serverURL, "key", nil, utils.HTTPClientConfig{Timeout: time.Second}, nil)
+			if _, err := tt.search.send(client); err != nil {
+				t.Fatal(err)
+			}
+			if got := query.Get("type"); got != tt.wantType {
+				t.Errorf("type = %q, want %q", got, tt.wantType)
+			}
+			if got := query.Get("query"); got != tt.wantQuery {
+				t.Errorf("query = %q, want %q", got, tt.wantQuery)
+			}
+			if got := (*query)["categories"]; !slices.Equal(got, tt.wantCats) {
+				t.Errorf("categories = %q, want %q", got, tt.wantCats)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"reel/internal/utils"
+
 	"golang.org/x/net/html/charset"
 )
 
@@ -35,9 +37,9 @@ type RSSClient struct {
 	httpClient *http.Client
 }
 
-func NewRSSClient(timeout time.Duration) *RSSClient {
+func NewRSSClient(httpConfig utils.HTTPClientConfig) *RSSClient {
 	return &RSSClient{
-		httpClient: &http.Client{Timeout: timeout},
+		httpClient: utils.NewHTTPClient(httpConfig),
 	}
 }
 
@@ -65,9 +67,10 @@ func (r *RSSClient) fetchFeed(url string) ([]IndexerResult, error) {
 		pubDate, _ := time.Parse(time.RFC1123Z, item.PubDate)
 		results[i] = IndexerResult{
 			Title:       item.Title,
-			DownloadURL: item.Link,
+			DownloadURL: resolveDownloadURL(url, strings.TrimSpace(item.Link)),
 			PublishDate: pubDate,
 			Indexer:     "RSS",
+			Description: item.Description,
 			// Seeders/Leechers are typically not available in basic RSS feeds
 		}
 	}
@@ -102,3 +105,8 @@ func (r *RSSClient) HealthCheck() (bool, error) {
 	// For now, we'll assume it's always healthy if it's configured.
 	return true, nil
 }
+
+// Capabilities returns nil: RSS feeds have no caps, and their search mode holds the feed URL.
+func (r *RSSClient) Capabilities() (*Capabilities, error) {
+	return nil, nil
+}
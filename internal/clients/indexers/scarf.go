@@ -1,14 +1,13 @@
 package indexers
 
 import (
-	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
 
-	"golang.org/x/net/html/charset"
+	"reel/internal/utils"
 )
 
 // --- Structs for Torznab XML Parsing ---
@@ -16,13 +15,15 @@ type ScarfClient struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	recorder   *ResponseRecorder // Saves search responses when set
 }
 
-func NewScarfClient(baseURL, apiKey string, timeout time.Duration) *ScarfClient {
+func NewScarfClient(baseURL, apiKey string, httpConfig utils.HTTPClientConfig, flareSolverrURL string, recorder *ResponseRecorder) *ScarfClient {
 	return &ScarfClient{
 		baseURL:    baseURL,
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: timeout},
+		httpClient: withFlareSolverr(utils.NewHTTPClient(httpConfig), flareSolverrURL, httpConfig.Timeout),
+		recorder:   recorder,
 	}
 }
 
@@ -44,6 +45,7 @@ func (s *ScarfClient) SearchMovies(query string, tmdbID string, searchMode strin
 	if err != nil {
 		return nil, fmt.Errorf("failed to search Scarf: %w", err)
 	}
+	s.recorder.record(resp)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
@@ -51,10 +53,8 @@ func (s *ScarfClient) SearchMovies(query string, tmdbID string, searchMode strin
 	}
 
 	var torznabResp TorznabFeed
-	decoder := xml.NewDecoder(resp.Body)
-	decoder.CharsetReader = charset.NewReaderLabel
-	if err := decoder.Decode(&torznabResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Scarf Torznab response: %w", err)
+	if err := decodeTorznab(resp, "Scarf", &torznabResp); err != nil {
+		return nil, err
 	}
 
 	results := make([]IndexerResult, len(torznabResp.Channel.Items))
@@ -65,9 +65,10 @@ func (s *ScarfClient) SearchMovies(query string, tmdbID string, searchMode strin
 			Size:        item.Size,
 			Seeders:     item.GetIntAttr("seeders"),
 			Leechers:    item.GetIntAttr("leechers"),
-			DownloadURL: item.Link,
+			DownloadURL: item.DownloadURL(s.baseURL),
 			PublishDate: pubDate,
 			Indexer:     "Scarf",
+			Description: item.Description,
 		}
 	}
 	return results, nil
@@ -101,6 +102,7 @@ func (s *ScarfClient) SearchTVShows(query string, season int, episode int, searc
 	if err != nil {
 		return nil, fmt.Errorf("failed to search Scarf for TV shows: %w", err)
 	}
+	s.recorder.record(resp)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
@@ -108,10 +110,8 @@ func (s *ScarfClient) SearchTVShows(query string, season int, episode int, searc
 	}
 
 	var torznabResp TorznabFeed
-	decoder := xml.NewDecoder(resp.Body)
-	decoder.CharsetReader = charset.NewReaderLabel
-	if err := decoder.Decode(&torznabResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Scarf Torznab response: %w", err)
+	if err := decodeTorznab(resp, "Scarf", &torznabResp); err != nil {
+		return nil, err
 	}
 
 	results := make([]IndexerResult, len(torznabResp.Channel.Items))
@@ -122,9 +122,10 @@ func (s *ScarfClient) SearchTVShows(query string, season int, episode int, searc
 			Size:        item.Size,
 			Seeders:     item.GetIntAttr("seeders"),
 			Leechers:    item.GetIntAttr("leechers"),
-			DownloadURL: item.Link,
+			DownloadURL: item.DownloadURL(s.baseURL),
 			PublishDate: pubDate,
 			Indexer:     "Scarf",
+			Description: item.Description,
 		}
 	}
 	return results, nil
@@ -148,3 +149,8 @@ func (s *ScarfClient) HealthCheck() (bool, error) {
 
 	return resp.StatusCode == http.StatusOK, nil
 }
+
+// Capabilities fetches the indexer's Torznab caps.
+func (s *ScarfClient) Capabilities() (*Capabilities, error) {
+	return fetchTorznabCaps(s.httpClient, s.baseURL, s.apiKey)
+}
@@ -1,8 +1,16 @@
 package indexers
 
 import (
+	"bytes"
 	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
 )
 
 type TorznabChannel struct {
@@ -33,15 +41,205 @@ type TorznabItem struct {
 	Size        int64              `xml:"size"`
 	Description string             `xml:"description"`
 	GUID        string             `xml:"guid"`
+	Enclosure   TorznabEnclosure   `xml:"enclosure"`
 	Attributes  []TorznabAttribute `xml:"attr"`
 }
 
+type TorznabEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
 func (item *TorznabItem) GetIntAttr(name string) int {
+	val, _ := strconv.Atoi(item.GetAttr(name))
+	return val
+}
+
+// GetAttr returns the value of a torznab:attr, or "" if the item doesn't have it.
+func (item *TorznabItem) GetAttr(name string) string {
 	for _, attr := range item.Attributes {
 		if attr.Name == name {
-			val, _ := strconv.Atoi(attr.Value)
-			return val
+			return attr.Value
 		}
 	}
-	return 0
+	return ""
+}
+
+// DownloadURL picks the item's download link. Indexers put it in different places:
+// a magneturl attr, <link>, <enclosure url=...> or a torrent attr. A magnet is
+// preferred when there is one; otherwise the first non-empty link is used. A relative
+// link is resolved against baseURL, the indexer's URL.
+func (item *TorznabItem) DownloadURL(baseURL string) string {
+	candidates := []string{item.GetAttr("magneturl"), item.Link, item.Enclosure.URL, item.GetAttr("torrent")}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, "magnet:") {
+			return candidate
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			return resolveDownloadURL(baseURL, candidate)
+		}
+	}
+	return ""
+}
+
+// resolveDownloadURL makes a relative download link ("/download/123.torrent") absolute
+// against the indexer's URL. Absolute links and anything that doesn't parse are kept.
+func resolveDownloadURL(baseURL, link string) string {
+	ref, err := url.Parse(link)
+	if err != nil || ref.IsAbs() {
+		return link
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// Capabilities describes what an indexer supports, as reported by its Torznab caps endpoint.
+type Capabilities struct {
+	Title string `json:"title,omitempty"`
+	// SearchModes maps each available search type (e.g. "tv-search") to its supported params.
+	SearchModes map[string][]string `json:"search_modes"`
+}
+
+// searchModeAliases maps Torznab "t" values to the element names used in the caps document.
+var searchModeAliases = map[string]string{
+	"tvsearch": "tv-search",
+	"movie":    "movie-search",
+	"music":    "music-search",
+	"book":     "book-search",
+}
+
+// SupportsSearchMode reports whether the indexer offers the given search mode. Both the
+// "t" parameter value ("tvsearch") and the caps element name ("tv-search") are accepted.
+func (c *Capabilities) SupportsSearchMode(mode string) bool {
+	if alias, ok := searchModeAliases[mode]; ok {
+		mode = alias
+	}
+	_, ok := c.SearchModes[mode]
+	return ok
+}
+
+// torznabSearchType returns the Torznab "t" value for a configured search mode. Caps
+// element names ("tv-search") are accepted too; fallback is used when no mode is set.
+func torznabSearchType(mode, fallback string) string {
+	if mode == "" {
+		return fallback
+	}
+	for searchType, alias := range searchModeAliases {
+		if mode == alias {
+			return searchType
+		}
+	}
+	return mode
+}
+
+type torznabCaps struct {
+	XMLName xml.Name `xml:"caps"`
+	Server  struct {
+		Title string `xml:"title,attr"`
+	} `xml:"server"`
+	Searching struct {
+		Modes []struct {
+			XMLName         xml.Name
+			Available       string `xml:"available,attr"`
+			SupportedParams string `xml:"supportedParams,attr"`
+		} `xml:",any"`
+	} `xml:"searching"`
+}
+
+// fetchTorznabCaps requests t=caps from a Torznab endpoint and parses the response.
+func fetchTorznabCaps(httpClient *http.Client, baseURL, apiKey string) (*Capabilities, error) {
+	params := url.Values{}
+	params.Add("t", "caps")
+	params.Add("apikey", apiKey)
+
+	resp, err := httpClient.Get(fmt.Sprintf("%s?%s", baseURL, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch caps: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("caps request failed with status: %d", resp.StatusCode)
+	}
+
+	var caps torznabCaps
+	if err := decodeTorznab(resp, "caps endpoint", &caps); err != nil {
+		return nil, err
+	}
+
+	capabilities := &Capabilities{
+		Title:       caps.Server.Title,
+		SearchModes: make(map[string][]string),
+	}
+	for _, mode := range caps.Searching.Modes {
+		if mode.Available != "yes" {
+			continue
+		}
+		params := []string{}
+		for _, param := range strings.Split(mode.SupportedParams, ",") {
+			if param = strings.TrimSpace(param); param != "" {
+				params = append(params, param)
+			}
+		}
+		capabilities.SearchModes[mode.XMLName.Local] = params
+	}
+	return capabilities, nil
+}
+
+// torznabError is the reply of a Torznab endpoint that rejected the request,
+// e.g. <error code="100" description="Incorrect user credentials"/>.
+type torznabError struct {
+	XMLName     xml.Name `xml:"error"`
+	Code        string   `xml:"code,attr"`
+	Description string   `xml:"description,attr"`
+}
+
+// responseSnippetLength is how much of an unexpected response is quoted in errors.
+const responseSnippetLength = 200
+
+// decodeTorznab reads a Torznab response into v. HTML pages (usually a login or error
+// page served because of a wrong URL or API key), Torznab error replies and broken XML
+// are reported with a readable message that quotes the start of the response.
+func decodeTorznab(resp *http.Response, indexer string, v interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", indexer, err)
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return fmt.Errorf("%s returned an empty response", indexer)
+	}
+
+	start := strings.ToLower(string(body[:min(len(body), 100)]))
+	looksLikeXML := strings.HasPrefix(start, "<?xml") || strings.HasPrefix(start, "<rss") || strings.HasPrefix(start, "<caps") || strings.HasPrefix(start, "<error")
+	isHTML := strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html") ||
+		(strings.Contains(resp.Header.Get("Content-Type"), "text/html") && !looksLikeXML)
+	if isHTML {
+		return fmt.Errorf("%s returned HTML instead of Torznab XML, likely a wrong URL or API key (response starts with: %s)",
+			indexer, responseSnippet(body))
+	}
+
+	var torznabErr torznabError
+	if xml.Unmarshal(body, &torznabErr) == nil {
+		return fmt.Errorf("%s returned error %s: %s", indexer, torznabErr.Code, torznabErr.Description)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s Torznab response: %w (response starts with: %s)", indexer, err, responseSnippet(body))
+	}
+	return nil
+}
+
+// responseSnippet returns the start of a response body on one line, for error messages.
+func responseSnippet(body []byte) string {
+	snippet := strings.Join(strings.Fields(string(body[:min(len(body), responseSnippetLength)])), " ")
+	return strconv.Quote(snippet)
 }
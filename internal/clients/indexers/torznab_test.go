@@ -0,0 +1,94 @@
+package indexers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"reel/internal/utils"
+)
+
+const testMagnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&dn=Heat.1995.1080p"
+
+func TestTorznabDownloadURL(t *testing.T) {
+	attrs := func(pairs ...string) []TorznabAttribute {
+		var attributes []TorznabAttribute
+		for i := 0; i+1 < len(pairs); i += 2 {
+			attributes = append(attributes, TorznabAttribute{Name: pairs[i], Value: pairs[i+1]})
+		}
+		return attributes
+	}
+
+	tests := []struct {
+		name string
+		item TorznabItem
+		want string
+	}{
+		{"link", TorznabItem{Link: "http://indexer.test/dl/1.torrent"}, "http://indexer.test/dl/1.torrent"},
+		{"enclosure only", TorznabItem{Enclosure: TorznabEnclosure{URL: "http://indexer.test/dl/2.torrent"}}, "http://indexer.test/dl/2.torrent"},
+		{"magneturl attr only", TorznabItem{Attributes: attrs("magneturl", testMagnet)}, testMagnet},
+		{"torrent attr only", TorznabItem{Attributes: attrs("torrent", "http://indexer.test/dl/3.torrent")}, "http://indexer.test/dl/3.torrent"},
+		{"magnet preferred over the link", TorznabItem{Link: "http://indexer.test/dl/4.torrent", Attributes: attrs("magneturl", testMagnet)}, testMagnet},
+		{"magnet in the enclosure", TorznabItem{Link: "http://indexer.test/details/5", Enclosure: TorznabEnclosure{URL: testMagnet}}, testMagnet},
+		{"link before the enclosure", TorznabItem{Link: "http://indexer.test/dl/6.torrent", Enclosure: TorznabEnclosure{URL: "http://indexer.test/dl/7.torrent"}}, "http://indexer.test/dl/6.torrent"},
+		{"blank link falls through", TorznabItem{Link: "  ", Enclosure: TorznabEnclosure{URL: "http://indexer.test/dl/8.torrent"}}, "http://indexer.test/dl/8.torrent"},
+		{"relative link", TorznabItem{Link: "/dl/9.torrent"}, "http://indexer.test/dl/9.torrent"},
+		{"relative enclosure", TorznabItem{Enclosure: TorznabEnclosure{URL: "dl/10.torrent?key=abc"}}, "http://indexer.test/dl/10.torrent?key=abc"},
+		{"nothing", TorznabItem{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.DownloadURL("http://indexer.test/api"); got != tt.want {
+				t.Errorf("DownloadURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJackettSearchDownloadURLs(t *testing.T) {
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torznab="http://torznab.com/schemas/2015/feed">
+<channel>
+  <item>
+    <title>Heat.1995.1080p.BluRay-ENCLOSURE</title>
+    <enclosure url="http://indexer.test/dl/1.torrent" length="100" type="application/x-bittorrent"/>
+    <torznab:attr name="seeders" value="10"/>
+  </item>
+  <item>
+    <title>Heat.1995.1080p.BluRay-MAGNET</title>
+    <torznab:attr name="magneturl" value="` + "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567&amp;dn=Heat.1995.1080p" + `"/>
+    <torznab:attr name="seeders" value="20"/>
+  </item>
+  <item>
+    <title>Heat.1995.1080p.BluRay-RELATIVE</title>
+    <link>/dl/3.torrent?jackett_apikey=key</link>
+    <torznab:attr name="seeders" value="30"/>
+  </item>
+</channel>
+</rss>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	client := NewJackettClient(server.URL+"/api/v2.0/indexers/test/results/torznab", "key", utils.HTTPClientConfig{Timeout: time.Second}, "", nil)
+	results, err := client.SearchMovies("Heat", "", "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Heat.1995.1080p.BluRay-ENCLOSURE": "http://indexer.test/dl/1.torrent",
+		"Heat.1995.1080p.BluRay-MAGNET":    testMagnet,
+		"Heat.1995.1080p.BluRay-RELATIVE":  server.URL + "/dl/3.torrent?jackett_apikey=key",
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for _, result := range results {
+		if result.DownloadURL != want[result.Title] {
+			t.Errorf("%s: DownloadURL = %q, want %q", result.Title, result.DownloadURL, want[result.Title])
+		}
+	}
+}
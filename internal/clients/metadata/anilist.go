@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
+
+	"reel/internal/utils"
 )
 
 type AniListClient struct {
@@ -18,39 +19,38 @@ type aniListGraphQLQuery struct {
 	Variables map[string]interface{} `json:"variables"`
 }
 
+type aniListMedia struct {
+	ID    int `json:"id"`
+	Title struct {
+		English string `json:"english"`
+		Romaji  string `json:"romaji"`
+	} `json:"title"`
+	Description string   `json:"description"`
+	BannerImage string   `json:"bannerImage"`
+	Episodes    int      `json:"episodes"`
+	Genres      []string `json:"genres"`
+	StartDate   struct {
+		Year int `json:"year"`
+	} `json:"startDate"`
+	Status string `json:"status"` // e.g. RELEASING, FINISHED
+}
+
 type aniListSearchResponse struct {
 	Data struct {
 		Page struct {
-			Media []struct {
-				ID    int `json:"id"`
-				Title struct {
-					English string `json:"english"`
-					Romaji  string `json:"romaji"`
-				} `json:"title"`
-				Description string `json:"description"`
-				BannerImage string `json:"bannerImage"`
-				Episodes    int    `json:"episodes"`
-				StartDate   struct {
-					Year int `json:"year"`
-				} `json:"startDate"`
-			} `json:"media"`
+			Media []aniListMedia `json:"media"`
 		} `json:"page"`
 	} `json:"data"`
 }
 
-func NewAniListClient(timeout time.Duration) *AniListClient {
-	return &AniListClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-	}
+type aniListMediaResponse struct {
+	Data struct {
+		Media *aniListMedia `json:"Media"`
+	} `json:"data"`
 }
 
-func (a *AniListClient) SearchAnime(title string) ([]*TVShowResult, error) {
-	query := `
-query ($search: String) {
-  Page(perPage: 5) {
-    media(search: $search, type: ANIME, sort: POPULARITY_DESC) {
+// aniListMediaFields is the field selection shared by the search and ID queries.
+const aniListMediaFields = `
       id
       title {
         romaji
@@ -59,9 +59,28 @@ query ($search: String) {
       description(asHtml: false)
       bannerImage
       episodes
+      genres
       startDate {
         year
       }
+      status`
+
+func NewAniListClient(httpConfig utils.HTTPClientConfig) *AniListClient {
+	return &AniListClient{
+		httpClient: utils.NewHTTPClient(httpConfig),
+	}
+}
+
+// Name returns the provider name used in the config.
+func (a *AniListClient) Name() string {
+	return "anilist"
+}
+
+func (a *AniListClient) SearchAnime(title string) ([]*TVShowResult, error) {
+	query := `
+query ($search: String) {
+  Page(perPage: 5) {
+    media(search: $search, type: ANIME, sort: POPULARITY_DESC) {` + aniListMediaFields + `
     }
   }
 }
@@ -70,63 +89,104 @@ query ($search: String) {
 		"search": title,
 	}
 
+	var searchResp aniListSearchResponse
+	if err := a.query(query, variables, &searchResp); err != nil {
+		return nil, err
+	}
+
+	if len(searchResp.Data.Page.Media) == 0 {
+		return nil, fmt.Errorf("no anime results found for '%s'", title)
+	}
+
+	var results []*TVShowResult
+	for _, anime := range searchResp.Data.Page.Media {
+		results = append(results, anime.toTVShowResult())
+	}
+
+	return results, nil
+}
+
+// query posts a GraphQL query to AniList and decodes the response into target.
+func (a *AniListClient) query(query string, variables map[string]interface{}, target interface{}) error {
 	jsonData, err := json.Marshal(aniListGraphQLQuery{Query: query, Variables: variables})
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal graphQL query: %w", err)
+		return fmt.Errorf("failed to marshal graphQL query: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", "https://graphql.anilist.co", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create anilist request: %w", err)
+		return fmt.Errorf("failed to create anilist request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search anilist: %w", err)
+		return fmt.Errorf("failed to search anilist: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("anilist search failed with status: %d", resp.StatusCode)
+		return fmt.Errorf("anilist search failed with status: %d", resp.StatusCode)
 	}
 
-	var searchResp aniListSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
-		return nil, fmt.Errorf("failed to decode anilist response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode anilist response: %w", err)
 	}
+	return nil
+}
 
-	if len(searchResp.Data.Page.Media) == 0 {
-		return nil, fmt.Errorf("no anime results found for '%s'", title)
+func (anime aniListMedia) toTVShowResult() *TVShowResult {
+	animeTitle := anime.Title.English
+	if animeTitle == "" {
+		animeTitle = anime.Title.Romaji
 	}
 
-	var results []*TVShowResult
-	for _, anime := range searchResp.Data.Page.Media {
-		animeTitle := anime.Title.English
-		if animeTitle == "" {
-			animeTitle = anime.Title.Romaji
-		}
-
-		result := &TVShowResult{
-			ID:        strconv.Itoa(anime.ID),
-			Title:     animeTitle,
-			Year:      anime.StartDate.Year,
-			Overview:  anime.Description,
-			PosterURL: anime.BannerImage,
-			Seasons:   make(map[int][]Episode),
-		}
-
-		for i := 1; i <= anime.Episodes; i++ {
-			result.Seasons[1] = append(result.Seasons[1], Episode{
-				EpisodeNumber: i,
-				Title:         fmt.Sprintf("Episode %d", i),
-			})
-		}
-		results = append(results, result)
+	result := &TVShowResult{
+		ID:        strconv.Itoa(anime.ID),
+		Title:     animeTitle,
+		Year:      anime.StartDate.Year,
+		Overview:  anime.Description,
+		PosterURL: anime.BannerImage,
+		Status:    normalizeShowStatus(anime.Status),
+		Seasons:   make(map[int][]Episode),
+		Genres:    NormalizeGenres(anime.Genres),
 	}
 
-	return results, nil
+	for i := 1; i <= anime.Episodes; i++ {
+		result.Seasons[1] = append(result.Seasons[1], Episode{
+			EpisodeNumber: i,
+			Title:         fmt.Sprintf("Episode %d", i),
+		})
+	}
+	return result
+}
+
+// GetTVShowByID fetches an anime by its AniList ID.
+func (a *AniListClient) GetTVShowByID(id string) (*TVShowResult, error) {
+	anilistID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AniList ID '%s'", id)
+	}
+
+	query := `
+query ($id: Int) {
+  Media(id: $id, type: ANIME) {` + aniListMediaFields + `
+  }
+}
+`
+	var mediaResp aniListMediaResponse
+	if err := a.query(query, map[string]interface{}{"id": anilistID}, &mediaResp); err != nil {
+		return nil, err
+	}
+	if mediaResp.Data.Media == nil {
+		return nil, fmt.Errorf("no anime found on AniList with ID %d", anilistID)
+	}
+	return mediaResp.Data.Media.toTVShowResult(), nil
+}
+
+func (a *AniListClient) GetMovieByID(id string) (*MovieResult, error) {
+	return nil, fmt.Errorf("anilist client does not support movie lookups")
 }
 
 func (a *AniListClient) SearchMovie(title string, year int) ([]*MovieResult, error) {
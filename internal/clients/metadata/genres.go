@@ -0,0 +1,59 @@
+package metadata
+
+import "strings"
+
+// tmdbMovieGenres maps TMDB's movie genre IDs to their names, so genres don't depend
+// on the configured language and search results (which only carry IDs) have them too.
+var tmdbMovieGenres = map[int]string{
+	28: "Action", 12: "Adventure", 16: "Animation", 35: "Comedy", 80: "Crime",
+	99: "Documentary", 18: "Drama", 10751: "Family", 14: "Fantasy", 36: "History",
+	27: "Horror", 10402: "Music", 9648: "Mystery", 10749: "Romance",
+	878: "Science Fiction", 10770: "TV Movie", 53: "Thriller", 10752: "War", 37: "Western",
+}
+
+// tmdbTVGenres maps TMDB's TV genre IDs, a list of their own that merges some of the
+// movie genres, to their names.
+var tmdbTVGenres = map[int]string{
+	10759: "Action & Adventure", 16: "Animation", 35: "Comedy", 80: "Crime",
+	99: "Documentary", 18: "Drama", 10751: "Family", 10762: "Kids", 9648: "Mystery",
+	10763: "News", 10764: "Reality", 10765: "Sci-Fi & Fantasy", 10766: "Soap",
+	10767: "Talk", 10768: "War & Politics", 37: "Western",
+}
+
+// genreAliases maps the spellings of providers to a single name, by lowercased genre.
+var genreAliases = map[string]string{
+	"sci-fi":           "Science Fiction",
+	"science-fiction":  "Science Fiction",
+	"scifi":            "Science Fiction",
+	"slice of life":    "Slice of Life",
+	"slice-of-life":    "Slice of Life",
+	"mahou shoujo":     "Mahou Shoujo",
+	"tv movie":         "TV Movie",
+	"sci-fi & fantasy": "Sci-Fi & Fantasy",
+}
+
+// NormalizeGenres turns provider genres ("science-fiction", "Sci-Fi", "comedy") into
+// the same title-cased names ("Science Fiction", "Comedy"), dropping blanks and repeats.
+func NormalizeGenres(genres []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, genre := range genres {
+		key := strings.ToLower(strings.TrimSpace(genre))
+		if key == "" {
+			continue
+		}
+		name, ok := genreAliases[key]
+		if !ok {
+			words := strings.Fields(strings.ReplaceAll(key, "-", " "))
+			for i, word := range words {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+			name = strings.Join(words, " ")
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
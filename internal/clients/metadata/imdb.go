@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"net/http"
 	"reel/internal/utils"
-	"time"
 )
 
 // IMDBClient is a placeholder for a real IMDb client.
@@ -16,14 +15,19 @@ type IMDBClient struct {
 	logger     *utils.Logger
 }
 
-func NewIMDBClient(apiKey string, timeout time.Duration, logger *utils.Logger) *IMDBClient {
+func NewIMDBClient(apiKey string, httpConfig utils.HTTPClientConfig, logger *utils.Logger) *IMDBClient {
 	return &IMDBClient{
 		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: timeout},
+		httpClient: utils.NewHTTPClient(httpConfig),
 		logger:     logger,
 	}
 }
 
+// Name returns the provider name used in the config.
+func (c *IMDBClient) Name() string {
+	return "imdb"
+}
+
 func (c *IMDBClient) SearchMovie(title string, year int) ([]*MovieResult, error) {
 	// This is a mock implementation.
 	if c.apiKey == "" {
@@ -43,3 +47,11 @@ func (c *IMDBClient) SearchTVShow(title string) ([]*TVShowResult, error) {
 func (c *IMDBClient) GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error) {
 	return nil, fmt.Errorf("GetTVShowDetailsByID not implemented for this client")
 }
+
+func (c *IMDBClient) GetMovieByID(id string) (*MovieResult, error) {
+	return nil, fmt.Errorf("IMDb lookup by ID not implemented")
+}
+
+func (c *IMDBClient) GetTVShowByID(id string) (*TVShowResult, error) {
+	return nil, fmt.Errorf("IMDb lookup by ID not implemented")
+}
@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kinds of titles a link points to. IMDb links don't tell, so their Kind is empty.
+const (
+	LinkMovie = "movie"
+	LinkShow  = "show"
+)
+
+// Link is a title on a metadata site, parsed from its URL by ParseLink.
+type Link struct {
+	Provider string // Name of the provider that looks up ID; "imdb" for any provider accepting IMDb IDs
+	Kind     string // LinkMovie, LinkShow or empty
+	ID       string
+}
+
+var (
+	imdbIDPattern    = regexp.MustCompile(`^tt\d+$`)
+	leadingIDPattern = regexp.MustCompile(`^\d+`)
+)
+
+// ParseLink parses a TMDB, IMDb, Trakt or AniList URL, e.g.
+// https://www.themoviedb.org/movie/603-the-matrix or https://anilist.co/anime/21/one-piece.
+// The scheme may be left out. It returns nil without an error when raw is not a URL of
+// one of these sites, and an error when it is one but doesn't point to a title.
+func ParseLink(raw string) (*Link, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.ContainsAny(raw, " \t") {
+		return nil, nil
+	}
+	full := raw
+	if !strings.Contains(full, "://") {
+		full = "https://" + full
+	}
+	parsed, err := url.Parse(full)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, nil
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	var segments []string
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	// after returns the path segment following name, e.g. the ID in /movie/<id>
+	after := func(name string) string {
+		for i := 0; i+1 < len(segments); i++ {
+			if segments[i] == name {
+				return segments[i+1]
+			}
+		}
+		return ""
+	}
+
+	switch {
+	case onSite(host, "themoviedb.org"):
+		for _, kind := range []struct{ segment, kind string }{{"movie", LinkMovie}, {"tv", LinkShow}} {
+			if id := leadingIDPattern.FindString(after(kind.segment)); id != "" {
+				return &Link{Provider: "tmdb", Kind: kind.kind, ID: id}, nil
+			}
+		}
+	case onSite(host, "imdb.com"):
+		if id := after("title"); imdbIDPattern.MatchString(id) {
+			return &Link{Provider: "imdb", ID: id}, nil
+		}
+	case onSite(host, "trakt.tv"):
+		for _, kind := range []struct{ segment, kind string }{{"movies", LinkMovie}, {"shows", LinkShow}} {
+			if id := after(kind.segment); id != "" {
+				return &Link{Provider: "trakt", Kind: kind.kind, ID: id}, nil
+			}
+		}
+	case onSite(host, "anilist.co"):
+		if id := leadingIDPattern.FindString(after("anime")); id != "" {
+			return &Link{Provider: "anilist", Kind: LinkShow, ID: id}, nil
+		}
+	default:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("'%s' is not a link to a movie or show", raw)
+}
+
+// onSite reports whether host is domain or one of its subdomains (www., m., ...).
+func onSite(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,39 @@
+package metadata
+
+import "testing"
+
+func TestParseLink(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    *Link // nil when raw is not a link to one of the sites
+		wantErr bool
+	}{
+		{raw: "https://www.themoviedb.org/movie/603-the-matrix", want: &Link{Provider: "tmdb", Kind: LinkMovie, ID: "603"}},
+		{raw: "https://www.themoviedb.org/tv/95396-severance/season/1", want: &Link{Provider: "tmdb", Kind: LinkShow, ID: "95396"}},
+		{raw: "themoviedb.org/movie/603?language=es-ES", want: &Link{Provider: "tmdb", Kind: LinkMovie, ID: "603"}},
+		{raw: "https://www.imdb.com/title/tt0133093/", want: &Link{Provider: "imdb", ID: "tt0133093"}},
+		{raw: "https://m.imdb.com/title/tt11280740/?ref_=nv_sr_srsg_0", want: &Link{Provider: "imdb", ID: "tt11280740"}},
+		{raw: "https://trakt.tv/movies/the-matrix-1999", want: &Link{Provider: "trakt", Kind: LinkMovie, ID: "the-matrix-1999"}},
+		{raw: "https://trakt.tv/shows/severance/seasons/1", want: &Link{Provider: "trakt", Kind: LinkShow, ID: "severance"}},
+		{raw: "https://anilist.co/anime/154587/Sousou-no-Frieren/", want: &Link{Provider: "anilist", Kind: LinkShow, ID: "154587"}},
+		{raw: "  http://anilist.co/anime/21  ", want: &Link{Provider: "anilist", Kind: LinkShow, ID: "21"}},
+		{raw: "https://www.themoviedb.org/person/6384-keanu-reeves", wantErr: true},
+		{raw: "https://www.imdb.com/name/nm0000206/", wantErr: true},
+		{raw: "https://anilist.co/manga/30002", wantErr: true},
+		{raw: "The Matrix"},
+		{raw: "https://example.com/movie/603"},
+		{raw: "https://notimdb.com/title/tt0133093"},
+		{raw: "ftp://imdb.com/title/tt0133093"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseLink(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseLink() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
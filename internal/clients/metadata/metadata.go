@@ -1,20 +1,39 @@
 package metadata
 
+import (
+	"reel/internal/utils"
+	"sort"
+	"strings"
+)
+
+// maxSearchResults is how many results a provider search returns at most.
+const maxSearchResults = 5
+
 // Client is the interface for all metadata providers.
 type Client interface {
+	Name() string
 	SearchMovie(title string, year int) ([]*MovieResult, error)
 	SearchTVShow(title string) ([]*TVShowResult, error)
 	GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error)
+	// GetMovieByID and GetTVShowByID fetch a single title by the provider's own ID
+	// (the ID returned in search results), or by IMDb ID where the provider supports it.
+	GetMovieByID(id string) (*MovieResult, error)
+	GetTVShowByID(id string) (*TVShowResult, error)
 }
 
 // MovieResult is a standardized struct for movie metadata.
 type MovieResult struct {
-	ID        string  `json:"id"`
-	Title     string  `json:"title"`
-	Year      int     `json:"year"`
-	Overview  string  `json:"overview"`
-	PosterURL string  `json:"poster_url"`
-	Rating    float64 `json:"rating"`
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	Year          int      `json:"year"`
+	ReleaseDate   string   `json:"release_date,omitempty"` // YYYY-MM-DD, when the provider has it
+	Overview      string   `json:"overview"`
+	PosterURL     string   `json:"poster_url"`
+	Rating        float64  `json:"rating"`
+	Popularity    float64  `json:"popularity,omitempty"`
+	Genres        []string `json:"genres,omitempty"`
+	Provider      string   `json:"provider,omitempty"` // Set by merged searches across providers
 }
 
 type Episode struct {
@@ -33,4 +52,57 @@ type TVShowResult struct {
 	Rating    float64           `json:"rating"`
 	Status    string            `json:"status"`
 	Seasons   map[int][]Episode `json:"seasons"`
+	Genres    []string          `json:"genres,omitempty"`
+	Provider  string            `json:"provider,omitempty"` // Set by merged searches across providers
+}
+
+// showStatuses maps the show statuses of TMDB, Trakt and AniList, lowercased, to TVmaze's,
+// which the library stores: "Running" for shows still airing, "Ended" for finished or
+// canceled ones and "In Development" for those yet to air.
+var showStatuses = map[string]string{
+	"returning series": "Running",
+	"continuing":       "Running",
+	"releasing":        "Running",
+	"hiatus":           "Running",
+	"ended":            "Ended",
+	"canceled":         "Ended",
+	"cancelled":        "Ended",
+	"finished":         "Ended",
+	"in production":    "In Development",
+	"planned":          "In Development",
+	"upcoming":         "In Development",
+	"pilot":            "In Development",
+	"not_yet_released": "In Development",
+}
+
+// normalizeShowStatus returns a provider's show status in TVmaze's wording, or the
+// status unchanged when it is unknown.
+func normalizeShowStatus(status string) string {
+	if normalized, ok := showStatuses[strings.ToLower(status)]; ok {
+		return normalized
+	}
+	return status
+}
+
+// exactMatchesFirst stably moves the results named like the searched title ahead of the
+// others: first those of the searched year (any year when year is 0), then those of
+// other years. Providers rank by popularity or relevance, so cutting their results to
+// maxSearchResults could otherwise drop the exact match. titles returns a result's
+// title and alternative titles, year its year.
+func exactMatchesFirst[T any](results []T, title string, year int, titles func(T) []string, resultYear func(T) int) {
+	wanted := utils.NormalizeTitle(title)
+	rank := func(result T) int {
+		for _, candidate := range titles(result) {
+			if candidate != "" && utils.NormalizeTitle(candidate) == wanted {
+				if year == 0 || resultYear(result) == year {
+					return 0
+				}
+				return 1
+			}
+		}
+		return 2
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return rank(results[i]) < rank(results[j])
+	})
 }
@@ -0,0 +1,284 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reel/internal/utils"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// redirectTransport sends every request to a test server, keeping path and query.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// serveAPI starts a server answering requests with handler and returns an HTTP client
+// whose requests to any host reach it.
+func serveAPI(t *testing.T, handler http.HandlerFunc) *http.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &http.Client{Transport: redirectTransport{target: target}, Timeout: 5 * time.Second}
+}
+
+func TestExactMatchesFirst(t *testing.T) {
+	results := []*MovieResult{
+		{Title: "Dune: Part Two", Year: 2024},
+		{Title: "Dune World", Year: 2021},
+		{Title: "Dune", Year: 2021},
+		{Title: "Jodorowsky's Dune", Year: 2013},
+		{Title: "Dune", Year: 1984},
+		{Title: "Duna", OriginalTitle: "Dune", Year: 1984},
+	}
+	exactMatchesFirst(results, "Dune", 1984,
+		func(r *MovieResult) []string { return []string{r.Title, r.OriginalTitle} },
+		func(r *MovieResult) int { return r.Year })
+
+	want := []string{"Dune 1984", "Duna 1984", "Dune 2021", "Dune: Part Two 2024", "Dune World 2021", "Jodorowsky's Dune 2013"}
+	for i, result := range results {
+		if got := fmt.Sprintf("%s %d", result.Title, result.Year); got != want[i] {
+			t.Errorf("result %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTMDBSearchMovieKeepsExactMatch(t *testing.T) {
+	// Six more popular movies would push the exact match out of the first five
+	body := `{"results": [
+		{"id": 1, "title": "Crash Landing", "release_date": "2019-01-01", "popularity": 90},
+		{"id": 2, "title": "Crash Course", "release_date": "2020-01-01", "popularity": 80},
+		{"id": 3, "title": "The Crash", "release_date": "2017-01-01", "popularity": 70},
+		{"id": 4, "title": "Crash Pad", "release_date": "2017-01-01", "popularity": 60},
+		{"id": 5, "title": "Crash Test", "release_date": "2018-01-01", "popularity": 50},
+		{"id": 6, "title": "Crash Zone", "release_date": "2016-01-01", "popularity": 40},
+		{"id": 7, "title": "Crash", "release_date": "2004-09-10", "popularity": 10}
+	]}`
+	client := NewTMDBClient("key", "en-US", "", false, utils.HTTPClientConfig{Timeout: time.Second}, utils.NewLogger(false, io.Discard))
+	client.httpClient = serveAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	results, err := client.SearchMovie("Crash", 2004)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != maxSearchResults {
+		t.Fatalf("%d results, want %d", len(results), maxSearchResults)
+	}
+	if results[0].ID != "7" {
+		t.Errorf("first result = %s (%s), want the exact match 7", results[0].ID, results[0].Title)
+	}
+	if results[1].ID != "1" {
+		t.Errorf("second result = %s, want the most popular other movie", results[1].ID)
+	}
+}
+
+func TestTVmazeSearchKeepsExactMatch(t *testing.T) {
+	search := `[
+		{"score": 0.9, "show": {"id": 1, "name": "The Office Party"}},
+		{"score": 0.8, "show": {"id": 2, "name": "Office Girls"}},
+		{"score": 0.7, "show": {"id": 3, "name": "Office Hours"}},
+		{"score": 0.6, "show": {"id": 4, "name": "Office Ladies"}},
+		{"score": 0.5, "show": {"id": 5, "name": "The Home Office"}},
+		{"score": 0.4, "show": {"id": 6, "name": "The Office"}}
+	]`
+	client := NewTVmazeClient(utils.HTTPClientConfig{Timeout: time.Second})
+	client.httpClient = serveAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/shows" {
+			w.Write([]byte(search))
+			return
+		}
+		// /shows/<id>: echo the id as the name
+		id := r.URL.Path[len("/shows/"):]
+		w.Write([]byte(`{"id": ` + id + `, "name": "show ` + id + `", "premiered": "2005-03-24"}`))
+	})
+
+	results, err := client.SearchTVShow("The Office")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != maxSearchResults {
+		t.Fatalf("%d results, want %d", len(results), maxSearchResults)
+	}
+	if results[0].ID != "6" {
+		t.Errorf("first result = %s, want the exact match 6", results[0].ID)
+	}
+}
+
+func TestShowStatus(t *testing.T) {
+	logger := utils.NewLogger(false, io.Discard)
+	tests := []struct {
+		name   string
+		lookup func(client *http.Client) (*TVShowResult, error)
+		body   string
+		want   string
+	}{
+		{
+			name: "tmdb ended",
+			lookup: func(client *http.Client) (*TVShowResult, error) {
+				tmdb := NewTMDBClient("key", "en-US", "", false, utils.HTTPClientConfig{Timeout: time.Second}, logger)
+				tmdb.httpClient = client
+				return tmdb.GetTVShowDetailsByID(1396)
+			},
+			body: `{"id": 1396, "name": "Breaking Bad", "status": "Ended"}`,
+			want: "Ended",
+		},
+		{
+			name: "tmdb returning",
+			lookup: func(client *http.Client) (*TVShowResult, error) {
+				tmdb := NewTMDBClient("key", "en-US", "", false, utils.HTTPClientConfig{Timeout: time.Second}, logger)
+				tmdb.httpClient = client
+				return tmdb.GetTVShowDetailsByID(95396)
+			},
+			body: `{"id": 95396, "name": "Severance", "status": "Returning Series"}`,
+			want: "Running",
+		},
+		{
+			name: "trakt canceled",
+			lookup: func(client *http.Client) (*TVShowResult, error) {
+				trakt := NewTraktClient("id", nil, utils.HTTPClientConfig{Timeout: time.Second}, logger)
+				trakt.httpClient = client
+				return trakt.GetTVShowByID("firefly")
+			},
+			body: `{"title": "Firefly", "year": 2002, "ids": {"trakt": 1}, "status": "canceled"}`,
+			want: "Ended",
+		},
+		{
+			name: "anilist finished",
+			lookup: func(client *http.Client) (*TVShowResult, error) {
+				anilist := NewAniListClient(utils.HTTPClientConfig{Timeout: time.Second})
+				anilist.httpClient = client
+				return anilist.GetTVShowByID("457")
+			},
+			body: `{"data": {"Media": {"id": 457, "title": {"romaji": "Mushishi"}, "status": "FINISHED"}}}`,
+			want: "Ended",
+		},
+		{
+			name: "unknown status is kept",
+			lookup: func(client *http.Client) (*TVShowResult, error) {
+				trakt := NewTraktClient("id", nil, utils.HTTPClientConfig{Timeout: time.Second}, logger)
+				trakt.httpClient = client
+				return trakt.GetTVShowByID("show")
+			},
+			body: `{"title": "Show", "ids": {"trakt": 2}, "status": "rumored"}`,
+			want: "rumored",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := serveAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/seasons") {
+					w.Write([]byte(`[]`))
+					return
+				}
+				w.Write([]byte(tt.body))
+			})
+			show, err := tt.lookup(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if show.Status != tt.want {
+				t.Errorf("Status = %q, want %q", show.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTMDBGenres(t *testing.T) {
+	logger := utils.NewLogger(false, io.Discard)
+	tests := []struct {
+		name   string
+		lookup func(tmdb *TMDBClient) ([]string, error)
+		body   string
+		want   []string
+	}{
+		{
+			name: "movie",
+			lookup: func(tmdb *TMDBClient) ([]string, error) {
+				movie, err := tmdb.GetMovieByID("603")
+				if err != nil {
+					return nil, err
+				}
+				return movie.Genres, nil
+			},
+			body: `{"id": 603, "title": "The Matrix", "genres": [{"id": 28}, {"id": 878}]}`,
+			want: []string{"Action", "Science Fiction"},
+		},
+		{
+			name: "tv show",
+			lookup: func(tmdb *TMDBClient) ([]string, error) {
+				show, err := tmdb.GetTVShowDetailsByID(1399)
+				if err != nil {
+					return nil, err
+				}
+				return show.Genres, nil
+			},
+			body: `{"id": 1399, "name": "Game of Thrones", "genres": [{"id": 10765}, {"id": 18}, {"id": 10759}, {"id": 99999}]}`,
+			want: []string{"Sci-Fi & Fantasy", "Drama", "Action & Adventure"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmdb := NewTMDBClient("key", "en-US", "", false, utils.HTTPClientConfig{Timeout: time.Second}, logger)
+			tmdb.httpClient = serveAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			})
+			genres, err := tt.lookup(tmdb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(genres, tt.want) {
+				t.Errorf("Genres = %q, want %q", genres, tt.want)
+			}
+		})
+	}
+}
+
+func TestTMDBSearchParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		region       string
+		includeAdult bool
+		wantRegion   string // Empty when no region is sent
+		wantAdult    string
+	}{
+		{name: "defaults", wantAdult: "false"},
+		{name: "region and adult", region: " gb ", includeAdult: true, wantRegion: "GB", wantAdult: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			tmdb := NewTMDBClient("key", "en-US", tt.region, tt.includeAdult, utils.HTTPClientConfig{Timeout: time.Second}, utils.NewLogger(false, io.Discard))
+			tmdb.httpClient = serveAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Write([]byte(`{"results": [{"id": 11, "title": "Star Wars", "release_date": "1977-05-25"}]}`))
+			})
+			if _, err := tmdb.SearchMovie("Star Wars", 1977); err != nil {
+				t.Fatal(err)
+			}
+			if got := query.Get("include_adult"); got != tt.wantAdult {
+				t.Errorf("include_adult = %q, want %q", got, tt.wantAdult)
+			}
+			if got, sent := query.Get("region"), query.Has("region"); got != tt.wantRegion || sent != (tt.wantRegion != "") {
+				t.Errorf("region = %q (sent %t), want %q", got, sent, tt.wantRegion)
+			}
+			if got := query.Get("language"); got != "en-US" {
+				t.Errorf("language = %q, want en-US", got)
+			}
+		})
+	}
+}
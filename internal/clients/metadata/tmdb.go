@@ -1,21 +1,26 @@
 package metadata
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
+	"reel/internal/utils"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type TMDBClient struct {
-	apiKey     string
-	language   string
-	httpClient *http.Client
+	apiKey       string
+	language     string
+	region       string
+	includeAdult bool
+	httpClient   *http.Client
+	logger       *utils.Logger
 }
 
 type tmdbTVDetails struct {
@@ -23,80 +28,120 @@ type tmdbTVDetails struct {
 	Name       string `json:"name"`
 	Overview   string `json:"overview"`
 	PosterPath string `json:"poster_path"`
+	Status     string `json:"status"` // e.g. "Returning Series", "Ended", "Canceled"
+	Genres     []struct {
+		ID int `json:"id"`
+	} `json:"genres"`
+}
+
+type tmdbMovie struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	OriginalTitle string  `json:"original_title"`
+	ReleaseDate   string  `json:"release_date"`
+	Overview      string  `json:"overview"`
+	PosterPath    string  `json:"poster_path"`
+	VoteAverage   float64 `json:"vote_average"`
+	Popularity    float64 `json:"popularity"`
+	GenreIDs      []int   `json:"genre_ids"` // Search and find results
+	Genres        []struct {
+		ID int `json:"id"`
+	} `json:"genres"` // Movie details
 }
 
 // Define a struct that matches the TMDB API's JSON response
 type tmdbSearchResponse struct {
-	Page    int `json:"page"`
-	Results []struct {
-		ID          int     `json:"id"`
-		Title       string  `json:"title"`
-		ReleaseDate string  `json:"release_date"`
-		Overview    string  `json:"overview"`
-		PosterPath  string  `json:"poster_path"`
-		VoteAverage float64 `json:"vote_average"`
-	} `json:"results"`
-	TotalPages   int `json:"total_pages"`
-	TotalResults int `json:"total_results"`
+	Page         int         `json:"page"`
+	Results      []tmdbMovie `json:"results"`
+	TotalPages   int         `json:"total_pages"`
+	TotalResults int         `json:"total_results"`
+}
+
+type tmdbFindResponse struct {
+	MovieResults []tmdbMovie `json:"movie_results"`
 }
 
-func NewTMDBClient(apiKey, language string, timeout time.Duration) *TMDBClient {
+// NewTMDBClient creates a TMDB client. region (an ISO 3166-1 code) and includeAdult
+// only apply to searches; an empty region leaves TMDB's default.
+func NewTMDBClient(apiKey, language, region string, includeAdult bool, httpConfig utils.HTTPClientConfig, logger *utils.Logger) *TMDBClient {
 	return &TMDBClient{
-		apiKey:   apiKey,
-		language: language,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		apiKey:       apiKey,
+		language:     language,
+		region:       strings.ToUpper(strings.TrimSpace(region)),
+		includeAdult: includeAdult,
+		httpClient:   utils.NewHTTPClient(httpConfig),
+		logger:       logger,
 	}
 }
 
+// Name returns the provider name used in the config.
+func (t *TMDBClient) Name() string {
+	return "tmdb"
+}
+
+// redactURL strips the api_key query parameter so request URLs can be logged safely.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "<unparseable url>"
+	}
+	query := parsed.Query()
+	if query.Has("api_key") {
+		query.Set("api_key", "****")
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
+
+// redactError removes the API key from the URL embedded in transport errors.
+func redactError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		urlErr.URL = redactURL(urlErr.URL)
+	}
+	return err
+}
+
 func (t *TMDBClient) SearchMovie(title string, year int) ([]*MovieResult, error) {
 	params := url.Values{}
 	params.Add("api_key", t.apiKey)
 	params.Add("language", t.language)
 	params.Add("query", title)
+	params.Add("include_adult", strconv.FormatBool(t.includeAdult))
+	if t.region != "" {
+		params.Add("region", t.region)
+	}
 	if year > 0 {
 		params.Add("year", strconv.Itoa(year))
 	}
 
 	searchURL := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?%s", params.Encode())
 
-	// --- Start Logging ---
-	maskedKey := t.apiKey
-	if len(maskedKey) > 8 {
-		maskedKey = maskedKey[:4] + "..." + maskedKey[len(maskedKey)-4:]
-	}
-	log.Printf("TMDB Request URL: %s", searchURL)
-	log.Printf("TMDB API Key: %s", maskedKey)
-	// --- End Logging ---
+	t.logger.Debug("TMDB request URL:", redactURL(searchURL))
 
 	req, err := http.NewRequest("GET", searchURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TMDB request: %w", err)
 	}
-	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36")
 
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search TMDB: %w", err)
+		return nil, fmt.Errorf("failed to search TMDB: %w", redactError(err))
 	}
 	defer resp.Body.Close()
 
-	// --- Start Logging ---
-	log.Printf("TMDB Response Status Code: %d", resp.StatusCode)
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read TMDB response body: %w", err)
 	}
-	log.Printf("TMDB Response Body: %s", string(bodyBytes))
-	// --- End Logging ---
+	t.logger.Debug("TMDB response status:", resp.StatusCode, "body:", string(bodyBytes))
 
-	// Re-create a reader for the JSON decoder since the original has been consumed
-	resp.Body = ioutil.NopCloser(strings.NewReader(string(bodyBytes)))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TMDB search failed with status: %d", resp.StatusCode)
+	}
 
 	var searchResp tmdbSearchResponse
 
-	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&searchResp); err != nil {
 		return nil, fmt.Errorf("failed to decode TMDB response: %w", err)
 	}
 
@@ -104,34 +149,118 @@ func (t *TMDBClient) SearchMovie(title string, year int) ([]*MovieResult, error)
 		return nil, fmt.Errorf("no results found on TMDB for '%s'", title)
 	}
 
+	// Same-named movies are common; the most popular one is the likeliest match
+	// and the one picked when adding by title. Exact title matches go first, so
+	// a less popular one isn't cut off.
+	sort.SliceStable(searchResp.Results, func(i, j int) bool {
+		return searchResp.Results[i].Popularity > searchResp.Results[j].Popularity
+	})
 	var results []*MovieResult
-	for i, result := range searchResp.Results {
-		if i >= 5 {
-			break
+	for _, result := range searchResp.Results {
+		results = append(results, result.toMovieResult())
+	}
+	exactMatchesFirst(results, title, year,
+		func(r *MovieResult) []string { return []string{r.Title, r.OriginalTitle} },
+		func(r *MovieResult) int { return r.Year })
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+
+	return results, nil
+}
+
+func (m tmdbMovie) toMovieResult() *MovieResult {
+	movieYear := 0
+	if m.ReleaseDate != "" {
+		if releaseTime, err := time.Parse("2006-01-02", m.ReleaseDate); err == nil {
+			movieYear = releaseTime.Year()
+		}
+	}
+
+	posterURL := ""
+	if m.PosterPath != "" {
+		posterURL = "https://image.tmdb.org/t/p/w500" + m.PosterPath
+	}
+
+	originalTitle := ""
+	if m.OriginalTitle != m.Title {
+		originalTitle = m.OriginalTitle
+	}
+
+	genreIDs := m.GenreIDs
+	for _, genre := range m.Genres {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+	var genres []string
+	for _, id := range genreIDs {
+		if name, ok := tmdbMovieGenres[id]; ok {
+			genres = append(genres, name)
 		}
-		movieYear := 0
-		if result.ReleaseDate != "" {
-			if releaseTime, err := time.Parse("2006-01-02", result.ReleaseDate); err == nil {
-				movieYear = releaseTime.Year()
-			}
+	}
+
+	return &MovieResult{
+		ID:            strconv.Itoa(m.ID),
+		Title:         m.Title,
+		OriginalTitle: originalTitle,
+		Year:          movieYear,
+		ReleaseDate:   m.ReleaseDate,
+		Overview:      m.Overview,
+		PosterURL:     posterURL,
+		Rating:        m.VoteAverage,
+		Popularity:    m.Popularity,
+		Genres:        NormalizeGenres(genres),
+	}
+}
+
+// GetMovieByID fetches a movie by TMDB ID, or by IMDb ID ("tt...") through TMDB's find endpoint.
+func (t *TMDBClient) GetMovieByID(id string) (*MovieResult, error) {
+	params := url.Values{}
+	params.Add("api_key", t.apiKey)
+	params.Add("language", t.language)
+
+	var detailsURL string
+	if strings.HasPrefix(id, "tt") {
+		params.Add("external_source", "imdb_id")
+		detailsURL = fmt.Sprintf("https://api.themoviedb.org/3/find/%s?%s", url.PathEscape(id), params.Encode())
+	} else {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, fmt.Errorf("invalid TMDB movie ID '%s'", id)
 		}
+		detailsURL = fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?%s", id, params.Encode())
+	}
+
+	t.logger.Debug("TMDB request URL:", redactURL(detailsURL))
 
-		posterURL := ""
-		if result.PosterPath != "" {
-			posterURL = "https://image.tmdb.org/t/p/w500" + result.PosterPath
+	resp, err := t.httpClient.Get(detailsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TMDB movie: %w", redactError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TMDB movie request failed with status: %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(id, "tt") {
+		var findResp tmdbFindResponse
+		if err := json.NewDecoder(resp.Body).Decode(&findResp); err != nil {
+			return nil, fmt.Errorf("failed to decode TMDB find response: %w", err)
 		}
+		if len(findResp.MovieResults) == 0 {
+			return nil, fmt.Errorf("no movie found on TMDB for IMDb ID '%s'", id)
+		}
+		return findResp.MovieResults[0].toMovieResult(), nil
+	}
 
-		results = append(results, &MovieResult{
-			ID:        strconv.Itoa(result.ID),
-			Title:     result.Title,
-			Year:      movieYear,
-			Overview:  result.Overview,
-			PosterURL: posterURL,
-			Rating:    result.VoteAverage,
-		})
+	var movie tmdbMovie
+	if err := json.NewDecoder(resp.Body).Decode(&movie); err != nil {
+		return nil, fmt.Errorf("failed to decode TMDB movie: %w", err)
 	}
+	return movie.toMovieResult(), nil
+}
 
-	return results, nil
+func (t *TMDBClient) GetTVShowByID(id string) (*TVShowResult, error) {
+	return nil, fmt.Errorf("TMDB TV show lookup not implemented")
 }
 
 func (t *TMDBClient) GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error) {
@@ -144,7 +273,7 @@ func (t *TMDBClient) GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error) {
 
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get TMDB details: %w", err)
+		return nil, fmt.Errorf("failed to get TMDB details: %w", redactError(err))
 	}
 	defer resp.Body.Close()
 
@@ -162,8 +291,17 @@ func (t *TMDBClient) GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error) {
 		posterURL = "https://image.tmdb.org/t/p/w500" + details.PosterPath
 	}
 
+	var genres []string
+	for _, genre := range details.Genres {
+		if name, ok := tmdbTVGenres[genre.ID]; ok {
+			genres = append(genres, name)
+		}
+	}
+
 	return &TVShowResult{
 		PosterURL: posterURL,
+		Status:    normalizeShowStatus(details.Status),
+		Genres:    NormalizeGenres(genres),
 	}, nil
 }
 
@@ -26,7 +26,9 @@ type traktShow struct {
 	Title    string                 `json:"title"`
 	Year     int                    `json:"year"`
 	Overview string                 `json:"overview"`
-	IDs      map[string]interface{} `json:"ids"` // Correctly handle mixed types to prevent JSON error
+	Genres   []string               `json:"genres"` // Slugs, e.g. "science-fiction"
+	IDs      map[string]interface{} `json:"ids"`    // Correctly handle mixed types to prevent JSON error
+	Status   string                 `json:"status"` // e.g. "returning series", "ended"
 }
 
 // Trakt episode structs
@@ -37,17 +39,20 @@ type traktEpisode struct {
 	FirstAired string `json:"first_aired"`
 }
 
-func NewTraktClient(clientID string, tmdbClient *TMDBClient, timeout time.Duration, logger *utils.Logger) *TraktClient {
+func NewTraktClient(clientID string, tmdbClient *TMDBClient, httpConfig utils.HTTPClientConfig, logger *utils.Logger) *TraktClient {
 	return &TraktClient{
 		clientID:   clientID,
 		tmdbClient: tmdbClient,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		logger: logger,
+		httpClient: utils.NewHTTPClient(httpConfig),
+		logger:     logger,
 	}
 }
 
+// Name returns the provider name used in the config.
+func (t *TraktClient) Name() string {
+	return "trakt"
+}
+
 func (t *TraktClient) sendRequest(url string, target interface{}) error {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -80,55 +85,87 @@ func (t *TraktClient) SearchTVShow(title string) ([]*TVShowResult, error) {
 
 	var results []*TVShowResult
 	for _, res := range searchResults {
-		// Safely extract the trakt ID
-		var traktID int
-		if id, ok := res.Show.IDs["trakt"].(float64); ok {
-			traktID = int(id)
-		} else {
+		result := t.showResult(res.Show)
+		if result == nil {
 			continue // Skip if we can't get a valid Trakt ID
 		}
+		results = append(results, result)
+	}
 
-		// Get episode list for the show
-		episodesURL := fmt.Sprintf("https://api.trakt.tv/shows/%d/seasons?extended=episodes", traktID)
-		var seasonsData []struct {
-			Number   int            `json:"number"`
-			Episodes []traktEpisode `json:"episodes"`
-		}
-		if err := t.sendRequest(episodesURL, &seasonsData); err != nil {
-			t.logger.Error("Could not get episode data for", res.Show.Title, ":", err)
-		}
+	return results, nil
+}
 
-		result := &TVShowResult{
-			ID:        strconv.Itoa(traktID),
-			Title:     res.Show.Title,
-			Year:      res.Show.Year,
-			Overview:  res.Show.Overview,
-			PosterURL: "",
-			Seasons:   make(map[int][]Episode),
-		}
+// showResult builds a TVShowResult, including the episode list, for a Trakt show.
+// It returns nil when the show has no usable Trakt ID.
+func (t *TraktClient) showResult(show traktShow) *TVShowResult {
+	// Safely extract the trakt ID
+	var traktID int
+	if id, ok := show.IDs["trakt"].(float64); ok {
+		traktID = int(id)
+	} else {
+		return nil
+	}
 
-		for _, season := range seasonsData {
-			if season.Number == 0 { // Skip specials
-				continue
-			}
-			for _, ep := range season.Episodes {
-				parsedTime, err := time.Parse(time.RFC3339, ep.FirstAired)
-				airDate := ""
-				if err == nil {
-					airDate = parsedTime.Format("2006-01-02")
-				}
-
-				result.Seasons[season.Number] = append(result.Seasons[season.Number], Episode{
-					EpisodeNumber: ep.Number,
-					Title:         ep.Title,
-					AirDate:       airDate,
-				})
+	// Get episode list for the show
+	episodesURL := fmt.Sprintf("https://api.trakt.tv/shows/%d/seasons?extended=episodes", traktID)
+	var seasonsData []struct {
+		Number   int            `json:"number"`
+		Episodes []traktEpisode `json:"episodes"`
+	}
+	if err := t.sendRequest(episodesURL, &seasonsData); err != nil {
+		t.logger.Error("Could not get episode data for", show.Title, ":", err)
+	}
+
+	result := &TVShowResult{
+		ID:        strconv.Itoa(traktID),
+		Title:     show.Title,
+		Year:      show.Year,
+		Overview:  show.Overview,
+		PosterURL: "",
+		Status:    normalizeShowStatus(show.Status),
+		Seasons:   make(map[int][]Episode),
+		Genres:    NormalizeGenres(show.Genres),
+	}
+
+	for _, season := range seasonsData {
+		if season.Number == 0 { // Skip specials
+			continue
+		}
+		for _, ep := range season.Episodes {
+			parsedTime, err := time.Parse(time.RFC3339, ep.FirstAired)
+			airDate := ""
+			if err == nil {
+				airDate = parsedTime.Format("2006-01-02")
 			}
+
+			result.Seasons[season.Number] = append(result.Seasons[season.Number], Episode{
+				EpisodeNumber: ep.Number,
+				Title:         ep.Title,
+				AirDate:       airDate,
+			})
 		}
-		results = append(results, result)
 	}
+	return result
+}
 
-	return results, nil
+// GetTVShowByID fetches a show by Trakt ID, slug or IMDb ID.
+func (t *TraktClient) GetTVShowByID(id string) (*TVShowResult, error) {
+	showURL := fmt.Sprintf("https://api.trakt.tv/shows/%s?extended=full", url.PathEscape(id))
+
+	var show traktShow
+	if err := t.sendRequest(showURL, &show); err != nil {
+		return nil, fmt.Errorf("failed to get Trakt show: %w", err)
+	}
+
+	result := t.showResult(show)
+	if result == nil {
+		return nil, fmt.Errorf("no TV show found on Trakt for ID '%s'", id)
+	}
+	return result, nil
+}
+
+func (t *TraktClient) GetMovieByID(id string) (*MovieResult, error) {
+	return nil, fmt.Errorf("Trakt movie lookup not implemented")
 }
 
 func (t *TraktClient) SearchMovie(title string, year int) ([]*MovieResult, error) {
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"reel/internal/utils"
 )
 
 type TVmazeClient struct {
@@ -18,11 +22,12 @@ type tvmazeShowSearch struct {
 }
 
 type tvmazeShow struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	Premiered string `json:"premiered"`
-	Status    string `json:"status"`
-	Summary   string `json:"summary"`
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Premiered string   `json:"premiered"`
+	Status    string   `json:"status"`
+	Summary   string   `json:"summary"`
+	Genres    []string `json:"genres"`
 	Image     struct {
 		Original string `json:"original"`
 	} `json:"image"`
@@ -42,14 +47,17 @@ type tvmazeEpisode struct {
 	Airdate string `json:"airdate"`
 }
 
-func NewTVmazeClient(timeout time.Duration) *TVmazeClient {
+func NewTVmazeClient(httpConfig utils.HTTPClientConfig) *TVmazeClient {
 	return &TVmazeClient{
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+		httpClient: utils.NewHTTPClient(httpConfig),
 	}
 }
 
+// Name returns the provider name used in the config.
+func (t *TVmazeClient) Name() string {
+	return "tvmaze"
+}
+
 func (t *TVmazeClient) SearchMovie(title string, year int) ([]*MovieResult, error) {
 	return nil, fmt.Errorf("TVmaze does not support movie searches")
 }
@@ -81,70 +89,125 @@ func (t *TVmazeClient) SearchTVShow(title string) ([]*TVShowResult, error) {
 		return nil, fmt.Errorf("no TV show results found on TVmaze for '%s'", title)
 	}
 
-	var results []*TVShowResult
-	numResults := len(searchData)
-	if numResults > 5 {
-		numResults = 5
+	// TVmaze ranks by relevance; exact title matches go first so they aren't cut off
+	exactMatchesFirst(searchData, title, 0,
+		func(s tvmazeShowSearch) []string { return []string{s.Show.Name} },
+		func(s tvmazeShowSearch) int { return 0 })
+	if len(searchData) > maxSearchResults {
+		searchData = searchData[:maxSearchResults]
 	}
 
-	for i := 0; i < numResults; i++ {
-		showID := searchData[i].Show.ID
-		infoURL := fmt.Sprintf("https://api.tvmaze.com/shows/%d?embed=episodes", showID)
-
-		req, err := http.NewRequest("GET", infoURL, nil)
+	var results []*TVShowResult
+	for _, found := range searchData {
+		result, err := t.getShow(found.Show.ID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create TVmaze info request: %w", err)
+			return nil, err
 		}
-
-		resp, err := t.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get TVmaze show info: %w", err)
+		if result != nil {
+			results = append(results, result)
 		}
-		defer resp.Body.Close()
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
+	return results, nil
+}
 
-		var showData tvmazeShow
-		if err := json.NewDecoder(resp.Body).Decode(&showData); err != nil {
-			return nil, fmt.Errorf("failed to decode TVmaze show info response: %w", err)
-		}
+// getShow fetches a show with its episodes. It returns nil, nil when TVmaze answers
+// with a non-200 status, so a search can skip that entry.
+func (t *TVmazeClient) getShow(showID int) (*TVShowResult, error) {
+	infoURL := fmt.Sprintf("https://api.tvmaze.com/shows/%d?embed=episodes", showID)
+
+	req, err := http.NewRequest("GET", infoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TVmaze info request: %w", err)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TVmaze show info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil
+	}
+
+	var showData tvmazeShow
+	if err := json.NewDecoder(resp.Body).Decode(&showData); err != nil {
+		return nil, fmt.Errorf("failed to decode TVmaze show info response: %w", err)
+	}
 
-		showYear := 0
-		if showData.Premiered != "" {
-			if premiereTime, err := time.Parse("2006-01-02", showData.Premiered); err == nil {
-				showYear = premiereTime.Year()
-			}
+	showYear := 0
+	if showData.Premiered != "" {
+		if premiereTime, err := time.Parse("2006-01-02", showData.Premiered); err == nil {
+			showYear = premiereTime.Year()
 		}
+	}
+
+	posterURL := ""
+	if showData.Image.Original != "" {
+		posterURL = showData.Image.Original
+	}
+
+	result := &TVShowResult{
+		ID:        fmt.Sprintf("%d", showData.ID),
+		Title:     showData.Name,
+		Year:      showYear,
+		Overview:  showData.Summary,
+		PosterURL: posterURL,
+		Rating:    showData.Rating.Average,
+		Status:    showData.Status,
+		Seasons:   make(map[int][]Episode),
+		Genres:    NormalizeGenres(showData.Genres),
+	}
+
+	for _, ep := range showData.Embedded.Episodes {
+		result.Seasons[ep.Season] = append(result.Seasons[ep.Season], Episode{
+			EpisodeNumber: ep.Number,
+			Title:         ep.Name,
+			AirDate:       ep.Airdate,
+		})
+	}
+	return result, nil
+}
 
-		posterURL := ""
-		if showData.Image.Original != "" {
-			posterURL = showData.Image.Original
+func (t *TVmazeClient) GetMovieByID(id string) (*MovieResult, error) {
+	return nil, fmt.Errorf("TVmaze does not support movie lookups")
+}
+
+// GetTVShowByID fetches a show by TVmaze ID, or by IMDb ID ("tt...") through the lookup endpoint.
+func (t *TVmazeClient) GetTVShowByID(id string) (*TVShowResult, error) {
+	if strings.HasPrefix(id, "tt") {
+		lookupURL := fmt.Sprintf("https://api.tvmaze.com/lookup/shows?imdb=%s", url.QueryEscape(id))
+		resp, err := t.httpClient.Get(lookupURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up TVmaze show: %w", err)
 		}
+		defer resp.Body.Close()
 
-		result := &TVShowResult{
-			ID:        fmt.Sprintf("%d", showData.ID),
-			Title:     showData.Name,
-			Year:      showYear,
-			Overview:  showData.Summary,
-			PosterURL: posterURL,
-			Rating:    showData.Rating.Average,
-			Status:    showData.Status,
-			Seasons:   make(map[int][]Episode),
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("no TV show found on TVmaze for IMDb ID '%s'", id)
 		}
 
-		for _, ep := range showData.Embedded.Episodes {
-			result.Seasons[ep.Season] = append(result.Seasons[ep.Season], Episode{
-				EpisodeNumber: ep.Number,
-				Title:         ep.Name,
-				AirDate:       ep.Airdate,
-			})
+		var show tvmazeShow
+		if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+			return nil, fmt.Errorf("failed to decode TVmaze lookup response: %w", err)
 		}
-		results = append(results, result)
+		id = strconv.Itoa(show.ID)
 	}
 
-	return results, nil
+	showID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TVmaze show ID '%s'", id)
+	}
+
+	result, err := t.getShow(showID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("no TV show found on TVmaze with ID %d", showID)
+	}
+	return result, nil
 }
 
 func (c *TVmazeClient) GetTVShowDetailsByID(tmdbID int) (*TVShowResult, error) {
@@ -8,5 +8,12 @@ type Notifier interface {
 	NotifyDownloadError(media *models.Media, torrentName string)
 	NotifyDownloadComplete(media *models.Media, torrentName string)
 	NotifyPostProcessComplete(media *models.Media, torrentName string)
+	NotifyLowDiskSpace(path string, freeMB uint64) // Automatic downloads are paused until space is freed
 	Test() error
 }
+
+// ShowCompleteNotifier is implemented by the notifiers that report a show that has ended
+// and has every episode downloaded. For the others that event is a no-op.
+type ShowCompleteNotifier interface {
+	NotifyShowComplete(media *models.Media)
+}
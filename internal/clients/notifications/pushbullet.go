@@ -59,6 +59,24 @@ func (c *PushbulletClient) NotifyPostProcessComplete(media *models.Media, torren
 	}
 }
 
+// NotifyShowComplete sends a notification when an ended show has been fully downloaded.
+func (c *PushbulletClient) NotifyShowComplete(media *models.Media) {
+	title := fmt.Sprintf("Show Complete: %s", media.Title)
+	body := fmt.Sprintf("%s has ended and every episode is downloaded. It won't be checked for new episodes anymore.", media.Title)
+	if err := c.sendPush(title, body); err != nil {
+		c.logger.Error("Error sending Pushbullet show complete notification:", err)
+	}
+}
+
+// NotifyLowDiskSpace sends a notification when a library folder runs low on space.
+func (c *PushbulletClient) NotifyLowDiskSpace(path string, freeMB uint64) {
+	title := "Low Disk Space"
+	body := fmt.Sprintf("Only %d MB free in %s. Automatic downloads are paused until space is freed.", freeMB, path)
+	if err := c.sendPush(title, body); err != nil {
+		c.logger.Error("Error sending Pushbullet low disk space notification:", err)
+	}
+}
+
 func (c *PushbulletClient) NotifyNotEnoughSpace(media *models.Media, torrentName string) {
 	title := fmt.Sprintf("Error downloading %s", media.Title)
 	body := fmt.Sprintf("Not enough space on disk")
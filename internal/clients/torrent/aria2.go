@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"reel/internal/utils"
 )
 
 type Aria2Client struct {
@@ -33,11 +36,11 @@ type aria2Response struct {
 	} `json:"error,omitempty"`
 }
 
-func NewAria2Client(host, secret string) *Aria2Client {
+func NewAria2Client(host, secret string, timeout time.Duration) *Aria2Client {
 	return &Aria2Client{
 		host:       host,
 		secret:     secret,
-		httpClient: &http.Client{},
+		httpClient: utils.NewLocalHTTPClient(timeout),
 	}
 }
 
@@ -177,9 +180,50 @@ func (a *Aria2Client) GetTorrentStatus(hash string) (TorrentStatus, error) {
 		Files:        fileList, // Now contains relative paths
 		UploadRatio:  uploadRatio,
 		ETA:          0,
+		State:        aria2State(stringValue(data["status"]), progress),
+		ClientState:  stringValue(data["status"]),
 	}, nil
 }
 
+// ListTorrents returns the GID (which Reel uses as the hash), name and download
+// directory of every active, waiting and stopped download.
+func (a *Aria2Client) ListTorrents() ([]TorrentStatus, error) {
+	keys := []string{"gid", "dir", "bittorrent"}
+	calls := [][]interface{}{
+		{"aria2.tellActive", keys},
+		{"aria2.tellWaiting", 0, 1000, keys},
+		{"aria2.tellStopped", 0, 1000, keys},
+	}
+
+	var list []TorrentStatus
+	for _, call := range calls {
+		result, err := a.sendRequest(call[0].(string), call[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		entries, ok := result.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected response to %s", call[0])
+		}
+		for _, entry := range entries {
+			data, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("unexpected entry in response to %s", call[0])
+			}
+			status := TorrentStatus{}
+			status.Hash, _ = data["gid"].(string)
+			status.DownloadDir, _ = data["dir"].(string)
+			if bittorrent, ok := data["bittorrent"].(map[string]interface{}); ok {
+				if info, ok := bittorrent["info"].(map[string]interface{}); ok {
+					status.Name, _ = info["name"].(string)
+				}
+			}
+			list = append(list, status)
+		}
+	}
+	return list, nil
+}
+
 func (a *Aria2Client) RemoveTorrent(hash string) error {
 	_, err := a.sendRequest("aria2.remove", hash)
 	return err
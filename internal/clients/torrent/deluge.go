@@ -9,6 +9,9 @@ import (
 	"net/http/cookiejar"
 	"strings"
 	"sync"
+	"time"
+
+	"reel/internal/utils"
 )
 
 // DelugeClient implements the TorrentClient interface for Deluge.
@@ -38,19 +41,19 @@ type delugeResponse struct {
 
 // NewDelugeClient creates and authenticates a new client for Deluge.
 // The host URL should be the path to the JSON endpoint, e.g., "http://localhost:8112/json".
-func NewDelugeClient(host, password string) (*DelugeClient, error) {
+func NewDelugeClient(host, password string, timeout time.Duration) (*DelugeClient, error) {
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
 	}
 
+	httpClient := utils.NewLocalHTTPClient(timeout)
+	httpClient.Jar = jar
 	client := &DelugeClient{
-		host:     host,
-		password: password,
-		httpClient: &http.Client{
-			Jar: jar,
-		},
-		reqID: 1,
+		host:       host,
+		password:   password,
+		httpClient: httpClient,
+		reqID:      1,
 	}
 
 	if err := client.login(); err != nil {
@@ -174,19 +177,54 @@ func (d *DelugeClient) GetTorrentStatus(hash string) (TorrentStatus, error) {
 		}
 	}
 
+	progress := data["progress"].(float64) / 100.0 // Deluge progress is 0-100
+	state := stringValue(data["state"])
 	return TorrentStatus{
 		Hash:         hash,
 		Name:         data["name"].(string),
-		Progress:     data["progress"].(float64) / 100.0, // Deluge progress is 0-100
+		Progress:     progress,
 		IsCompleted:  data["progress"].(float64) >= 100.0,
 		DownloadDir:  data["save_path"].(string),
 		UploadRatio:  data["ratio"].(float64),
 		DownloadRate: int64(data["download_payload_rate"].(float64)),
 		UploadRate:   int64(data["upload_payload_rate"].(float64)),
 		Files:        fileList,
+		State:        delugeState(state, progress),
+		ClientState:  state,
 	}, nil
 }
 
+// stringValue returns v if it is a string, or "".
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// ListTorrents returns the hash, name and save path of every torrent.
+func (d *DelugeClient) ListTorrents() ([]TorrentStatus, error) {
+	keys := []string{"name", "save_path"}
+	result, err := d.sendRequest("core.get_torrents_status", []interface{}{map[string]interface{}{}, keys})
+	if err != nil {
+		return nil, err
+	}
+
+	torrents, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to core.get_torrents_status")
+	}
+	list := make([]TorrentStatus, 0, len(torrents))
+	for hash, entry := range torrents {
+		data, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected entry for torrent %s", hash)
+		}
+		name, _ := data["name"].(string)
+		savePath, _ := data["save_path"].(string)
+		list = append(list, TorrentStatus{Hash: hash, Name: name, DownloadDir: savePath})
+	}
+	return list, nil
+}
+
 // RemoveTorrent removes a torrent and its data.
 func (d *DelugeClient) RemoveTorrent(hash string) error {
 	_, err := d.sendRequest("core.remove_torrent", []interface{}{hash, true}) // true to remove data
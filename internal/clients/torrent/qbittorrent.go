@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"reel/internal/utils"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,26 +24,31 @@ type qBittorrentClient struct {
 	logger     *utils.Logger
 }
 
-type qbTorrentProperties struct {
+// qbTorrentInfo is a torrent as listed by /api/v2/torrents/info, the only endpoint
+// that reports its state.
+type qbTorrentInfo struct {
+	Hash        string  `json:"hash"`
 	Name        string  `json:"name"`
-	Size        int64   `json:"size"`
 	Progress    float64 `json:"progress"`
 	Ratio       float64 `json:"ratio"`
 	SavePath    string  `json:"save_path"`
 	State       string  `json:"state"`
 	ContentPath string  `json:"content_path"`
+	DLSpeed     int64   `json:"dlspeed"`
+	UPSpeed     int64   `json:"upspeed"`
+	ETA         int     `json:"eta"`
 }
 
 type qbTorrentFile struct {
 	Name string `json:"name"`
 }
 
-func NewQBittorrentClient(host, username, password string, logger *utils.Logger) *qBittorrentClient {
+func NewQBittorrentClient(host, username, password string, timeout time.Duration, logger *utils.Logger) *qBittorrentClient {
 	return &qBittorrentClient{
 		host:       host,
 		username:   username,
 		password:   password,
-		httpClient: &http.Client{},
+		httpClient: utils.NewLocalHTTPClient(timeout),
 		logger:     logger,
 	}
 }
@@ -254,9 +260,9 @@ func (q *qBittorrentClient) GetTorrentStatus(hash string) (TorrentStatus, error)
 		return TorrentStatus{}, err
 	}
 
-	// First, get the main torrent properties
-	propertiesURL := fmt.Sprintf("%s/api/v2/torrents/properties?hash=%s", q.host, hash)
-	req, err := http.NewRequest("GET", propertiesURL, nil)
+	// The properties endpoint has no state, so the torrent is looked up in the list
+	infoURL := fmt.Sprintf("%s/api/v2/torrents/info?hashes=%s", q.host, url.QueryEscape(hash))
+	req, err := http.NewRequest("GET", infoURL, nil)
 	if err != nil {
 		return TorrentStatus{}, err
 	}
@@ -269,11 +275,7 @@ func (q *qBittorrentClient) GetTorrentStatus(hash string) (TorrentStatus, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// If torrent not found, qBittorrent returns 404
-		if resp.StatusCode == http.StatusNotFound {
-			return TorrentStatus{}, fmt.Errorf("torrent with hash %s not found", hash)
-		}
-		return TorrentStatus{}, fmt.Errorf("failed to get torrent properties with status: %s", resp.Status)
+		return TorrentStatus{}, fmt.Errorf("failed to get torrent info with status: %s", resp.Status)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -281,10 +283,14 @@ func (q *qBittorrentClient) GetTorrentStatus(hash string) (TorrentStatus, error)
 		return TorrentStatus{}, err
 	}
 
-	var props qbTorrentProperties
-	if err := json.Unmarshal(body, &props); err != nil {
-		return TorrentStatus{}, fmt.Errorf("failed to decode torrent properties: %w", err)
+	var infos []qbTorrentInfo
+	if err := json.Unmarshal(body, &infos); err != nil {
+		return TorrentStatus{}, fmt.Errorf("failed to decode torrent info: %w", err)
+	}
+	if len(infos) == 0 {
+		return TorrentStatus{}, fmt.Errorf("torrent with hash %s not found", hash)
 	}
+	info := infos[0]
 
 	// --- New: Get the file list ---
 	filesURL := fmt.Sprintf("%s/api/v2/torrents/files?hash=%s", q.host, hash)
@@ -320,17 +326,67 @@ func (q *qBittorrentClient) GetTorrentStatus(hash string) (TorrentStatus, error)
 	}
 	// --- End of new section ---
 
+	// qBittorrent reports 8640000 (100 days) when the ETA is unknown
+	eta := info.ETA
+	if eta >= 8640000 {
+		eta = 0
+	}
+
 	return TorrentStatus{
-		Hash:        hash,
-		Name:        props.Name,
-		Progress:    props.Progress,
-		IsCompleted: props.Progress >= 1.0,
-		DownloadDir: props.SavePath,
-		UploadRatio: props.Ratio,
-		Files:       fileList, // Populate the files list
+		Hash:         hash,
+		Name:         info.Name,
+		Progress:     info.Progress,
+		IsCompleted:  info.Progress >= 1.0,
+		DownloadDir:  info.SavePath,
+		UploadRatio:  info.Ratio,
+		Files:        fileList, // Populate the files list
+		DownloadRate: info.DLSpeed,
+		UploadRate:   info.UPSpeed,
+		ETA:          eta,
+		State:        qbittorrentState(info.State),
+		ClientState:  info.State,
 	}, nil
 }
 
+// ListTorrents returns the hash, name and save path of every torrent.
+func (q *qBittorrentClient) ListTorrents() ([]TorrentStatus, error) {
+	cookie, err := q.login()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v2/torrents/info", q.host), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(cookie)
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list torrents with status: %s", resp.Status)
+	}
+
+	var torrents []struct {
+		Hash     string `json:"hash"`
+		Name     string `json:"name"`
+		SavePath string `json:"save_path"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&torrents); err != nil {
+		return nil, fmt.Errorf("failed to decode torrent list: %w", err)
+	}
+
+	list := make([]TorrentStatus, 0, len(torrents))
+	for _, t := range torrents {
+		list = append(list, TorrentStatus{Hash: t.Hash, Name: t.Name, DownloadDir: t.SavePath})
+	}
+	return list, nil
+}
+
 func (q *qBittorrentClient) RemoveTorrent(hash string) error {
 	cookie, err := q.login()
 	if err != nil {
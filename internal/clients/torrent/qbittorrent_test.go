@@ -0,0 +1,100 @@
+package torrent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reel/internal/config"
+	"reel/internal/utils"
+	"testing"
+	"time"
+)
+
+// newFakeQBittorrent serves the qBittorrent Web API endpoints used by
+// GetTorrentStatus for the given torrents.
+func newFakeQBittorrent(t *testing.T, torrents ...qbTorrentInfo) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+	})
+	mux.HandleFunc("/api/v2/torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		found := []qbTorrentInfo{}
+		for _, torrent := range torrents {
+			if torrent.Hash == r.URL.Query().Get("hashes") {
+				found = append(found, torrent)
+			}
+		}
+		json.NewEncoder(w).Encode(found)
+	})
+	mux.HandleFunc("/api/v2/torrents/files", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]qbTorrentFile{{Name: "Show.S01E01.mkv"}})
+	})
+	mux.HandleFunc("/api/v2/torrents/properties", func(w http.ResponseWriter, r *http.Request) {
+		// The real endpoint has no state
+		json.NewEncoder(w).Encode(map[string]interface{}{"save_path": "/downloads", "ratio": 1.5})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQBittorrentPausedCompleteTorrent(t *testing.T) {
+	// A season pack with unselected episodes stops short of 100% and is paused once
+	// its wanted files are done
+	server := newFakeQBittorrent(t, qbTorrentInfo{Hash: "abc", Name: "Show.S01", Progress: 0.8, State: "pausedUP", SavePath: "/downloads", ETA: 8640000})
+	client := NewQBittorrentClient(server.URL, "admin", "secret", time.Second, utils.NewLogger(false, io.Discard))
+
+	status, err := client.GetTorrentStatus("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.ClientState != "pausedUP" || status.State != StateComplete {
+		t.Errorf("state = %q (%q), want pausedUP (complete)", status.ClientState, status.State)
+	}
+	if status.IsCompleted {
+		t.Error("IsCompleted set below 100%")
+	}
+	if status.ETA != 0 {
+		t.Errorf("ETA = %d, want 0 for an unknown ETA", status.ETA)
+	}
+	if status.DownloadDir != "/downloads" || len(status.Files) != 1 {
+		t.Errorf("download dir %q and files %v not filled in", status.DownloadDir, status.Files)
+	}
+
+	clientConfig := config.TorrentClientConfig{Type: "qbittorrent"}
+	if !clientConfig.IsCompletedState(status.ClientState) {
+		t.Error("paused-complete torrent not detected as complete by the default completed_states")
+	}
+	clientConfig.CompletedStates = []string{"uploading"}
+	if clientConfig.IsCompletedState(status.ClientState) {
+		t.Error("completed_states override ignored")
+	}
+}
+
+func TestQBittorrentUnknownTorrent(t *testing.T) {
+	server := newFakeQBittorrent(t)
+	client := NewQBittorrentClient(server.URL, "admin", "secret", time.Second, utils.NewLogger(false, io.Discard))
+	if _, err := client.GetTorrentStatus("missing"); err == nil {
+		t.Error("GetTorrentStatus() of an unknown torrent succeeded")
+	}
+}
+
+func TestQBittorrentNormalizedState(t *testing.T) {
+	server := newFakeQBittorrent(t,
+		qbTorrentInfo{Hash: "stalled", State: "stalledDL", Progress: 0.4},
+		qbTorrentInfo{Hash: "seeding", State: "uploading", Progress: 1, UPSpeed: 2048},
+	)
+	client := NewQBittorrentClient(server.URL, "admin", "secret", time.Second, utils.NewLogger(false, io.Discard))
+
+	for hash, want := range map[string]string{"stalled": StateStalled, "seeding": StateSeeding} {
+		status, err := client.GetTorrentStatus(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State != want {
+			t.Errorf("%s: State = %q, want %q", hash, status.State, want)
+		}
+	}
+}
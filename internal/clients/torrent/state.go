@@ -0,0 +1,121 @@
+package torrent
+
+import "strings"
+
+// Normalized torrent states, the same for every client. TorrentStatus.ClientState keeps
+// the client's own name.
+const (
+	StateDownloading = "downloading"
+	StateSeeding     = "seeding"
+	StatePaused      = "paused"
+	StateStalled     = "stalled"
+	StateError       = "error"
+	StateChecking    = "checking"
+	StateComplete    = "complete" // Finished and no longer seeding
+)
+
+// stateRanks orders the states by how much they need attention, for summing up
+// several torrents in one state.
+var stateRanks = map[string]int{
+	StateComplete:    1,
+	StateSeeding:     2,
+	StatePaused:      3,
+	StateChecking:    4,
+	StateDownloading: 5,
+	StateStalled:     6,
+	StateError:       7,
+}
+
+// MostUrgentState returns whichever of two states needs more attention, e.g. an error
+// over a download in progress.
+func MostUrgentState(a, b string) string {
+	if stateRanks[b] > stateRanks[a] {
+		return b
+	}
+	return a
+}
+
+// finishedState is the state of a stopped torrent: complete once it has all its data.
+func finishedState(progress float64, unfinished string) string {
+	if progress >= 1.0 {
+		return StateComplete
+	}
+	return unfinished
+}
+
+// qbittorrentState maps a qBittorrent torrent state.
+func qbittorrentState(state string) string {
+	switch state {
+	case "downloading", "forcedDL", "metaDL", "forcedMetaDL", "queuedDL", "allocating":
+		return StateDownloading
+	case "stalledDL":
+		return StateStalled
+	case "uploading", "forcedUP", "stalledUP", "queuedUP":
+		return StateSeeding
+	case "pausedUP", "stoppedUP":
+		return StateComplete
+	case "pausedDL", "stoppedDL":
+		return StatePaused
+	case "checkingDL", "checkingUP", "checkingResumeData", "moving":
+		return StateChecking
+	case "error", "missingFiles":
+		return StateError
+	}
+	return ""
+}
+
+// transmissionState maps the name of a Transmission status (see transmissionStatuses).
+func transmissionState(status string, progress float64) string {
+	switch status {
+	case "stopped":
+		return finishedState(progress, StatePaused)
+	case "check_wait", "checking":
+		return StateChecking
+	case "download_wait", "downloading":
+		return StateDownloading
+	case "seed_wait", "seeding":
+		return StateSeeding
+	}
+	return ""
+}
+
+// delugeState maps a Deluge torrent state.
+func delugeState(state string, progress float64) string {
+	switch strings.ToLower(state) {
+	case "downloading":
+		return StateDownloading
+	case "seeding":
+		return StateSeeding
+	case "paused":
+		return finishedState(progress, StatePaused)
+	case "queued":
+		if progress >= 1.0 {
+			return StateSeeding
+		}
+		return StateDownloading
+	case "checking", "allocating", "moving":
+		return StateChecking
+	case "error":
+		return StateError
+	}
+	return ""
+}
+
+// aria2State maps an aria2 download status. An active download that has all its data
+// is seeding.
+func aria2State(status string, progress float64) string {
+	switch status {
+	case "active", "waiting":
+		if progress >= 1.0 {
+			return StateSeeding
+		}
+		return StateDownloading
+	case "paused":
+		return finishedState(progress, StatePaused)
+	case "complete":
+		return StateComplete
+	case "error", "removed":
+		return StateError
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package torrent
+
+import "testing"
+
+func TestClientStates(t *testing.T) {
+	tests := []struct {
+		client   string
+		state    string
+		progress float64
+		want     string
+	}{
+		{"qbittorrent", "downloading", 0.5, StateDownloading},
+		{"qbittorrent", "metaDL", 0, StateDownloading},
+		{"qbittorrent", "stalledDL", 0.5, StateStalled},
+		{"qbittorrent", "stalledUP", 1, StateSeeding},
+		{"qbittorrent", "uploading", 1, StateSeeding},
+		{"qbittorrent", "pausedUP", 0.8, StateComplete},
+		{"qbittorrent", "stoppedUP", 1, StateComplete},
+		{"qbittorrent", "pausedDL", 0.5, StatePaused},
+		{"qbittorrent", "checkingResumeData", 0.5, StateChecking},
+		{"qbittorrent", "missingFiles", 1, StateError},
+		{"qbittorrent", "unknown", 0, ""},
+
+		{"transmission", "stopped", 0.5, StatePaused},
+		{"transmission", "stopped", 1, StateComplete},
+		{"transmission", "check_wait", 0.5, StateChecking},
+		{"transmission", "download_wait", 0, StateDownloading},
+		{"transmission", "downloading", 0.5, StateDownloading},
+		{"transmission", "seeding", 1, StateSeeding},
+		{"transmission", "", 0, ""},
+
+		{"deluge", "Downloading", 0.5, StateDownloading},
+		{"deluge", "Seeding", 1, StateSeeding},
+		{"deluge", "Paused", 0.5, StatePaused},
+		{"deluge", "Paused", 1, StateComplete},
+		{"deluge", "Queued", 0.2, StateDownloading},
+		{"deluge", "Queued", 1, StateSeeding},
+		{"deluge", "Checking", 0.2, StateChecking},
+		{"deluge", "Error", 0.2, StateError},
+
+		{"aria2", "active", 0.5, StateDownloading},
+		{"aria2", "active", 1, StateSeeding},
+		{"aria2", "waiting", 0, StateDownloading},
+		{"aria2", "paused", 0.5, StatePaused},
+		{"aria2", "paused", 1, StateComplete},
+		{"aria2", "complete", 1, StateComplete},
+		{"aria2", "removed", 0.5, StateError},
+	}
+	mappers := map[string]func(state string, progress float64) string{
+		"qbittorrent":  func(state string, _ float64) string { return qbittorrentState(state) },
+		"transmission": transmissionState,
+		"deluge":       delugeState,
+		"aria2":        aria2State,
+	}
+	for _, tt := range tests {
+		if got := mappers[tt.client](tt.state, tt.progress); got != tt.want {
+			t.Errorf("%s state %q at %.0f%% = %q, want %q", tt.client, tt.state, tt.progress*100, got, tt.want)
+		}
+	}
+}
+
+func TestTransmissionStatusName(t *testing.T) {
+	tests := []struct {
+		status interface{}
+		want   string
+	}{
+		{float64(0), "stopped"},
+		{float64(4), "downloading"},
+		{float64(6), "seeding"},
+		{float64(7), ""},
+		{float64(-1), ""},
+		{nil, ""},
+		{"4", ""},
+	}
+	for _, tt := range tests {
+		if got := transmissionStatusName(tt.status); got != tt.want {
+			t.Errorf("transmissionStatusName(%v) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestMostUrgentState(t *testing.T) {
+	tests := []struct{ a, b, want string }{
+		{StateDownloading, StateError, StateError},
+		{StateStalled, StateDownloading, StateStalled},
+		{StateSeeding, StateComplete, StateSeeding},
+		{"", StatePaused, StatePaused},
+	}
+	for _, tt := range tests {
+		if got := MostUrgentState(tt.a, tt.b); got != tt.want {
+			t.Errorf("MostUrgentState(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
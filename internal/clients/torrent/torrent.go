@@ -4,6 +4,7 @@ type TorrentClient interface {
 	AddTorrent(magnetLink string, downloadPath string) (string, error)
 	AddTorrentFile(fileContent []byte, downloadPath string) (string, error)
 	GetTorrentStatus(hash string) (TorrentStatus, error)
+	ListTorrents() ([]TorrentStatus, error) // Every torrent in the client, with its hash, name and download directory
 	RemoveTorrent(hash string) error
 	AddTrackers(hash string, trackers []string) error
 	HealthCheck() (bool, error)
@@ -20,4 +21,6 @@ type TorrentStatus struct {
 	UploadRate   int64    `json:"upload_rate"`
 	ETA          int      `json:"eta"`
 	UploadRatio  float64  `json:"upload_ratio"`
+	State        string   `json:"state,omitempty"`        // Normalized state, one of the State constants
+	ClientState  string   `json:"client_state,omitempty"` // The client's own state name, e.g. qBittorrent's "pausedUP"
 }
\ No newline at end of file
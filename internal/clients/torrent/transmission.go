@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"reel/internal/utils"
 )
 
 type TransmissionClient struct {
@@ -17,12 +20,12 @@ type TransmissionClient struct {
 	httpClient *http.Client
 }
 
-func NewTransmissionClient(host, username, password string) *TransmissionClient {
+func NewTransmissionClient(host, username, password string, timeout time.Duration) *TransmissionClient {
 	return &TransmissionClient{
 		host:       host,
 		username:   username,
 		password:   password,
-		httpClient: &http.Client{},
+		httpClient: utils.NewLocalHTTPClient(timeout),
 	}
 }
 
@@ -117,28 +120,7 @@ func (t *TransmissionClient) GetTorrentStatus(hash string) (TorrentStatus, error
 	if arguments, ok := response["arguments"].(map[string]interface{}); ok {
 		if torrents, ok := arguments["torrents"].([]interface{}); ok && len(torrents) > 0 {
 			if torrent, ok := torrents[0].(map[string]interface{}); ok {
-				status := TorrentStatus{
-					Hash:        hash,
-					Name:        torrent["name"].(string),
-					Progress:    getFloat(torrent, "percentDone"),
-					DownloadDir: torrent["downloadDir"].(string),
-					Files:       []string{},
-					UploadRatio: getFloat(torrent, "uploadRatio"),
-				}
-
-				if files, ok := torrent["files"].([]interface{}); ok {
-					for _, file := range files {
-						if fileMap, ok := file.(map[string]interface{}); ok {
-							status.Files = append(status.Files, fileMap["name"].(string))
-						}
-					}
-				}
-
-				if status.Progress >= 1.0 {
-					status.IsCompleted = true
-				}
-
-				return status, nil
+				return transmissionTorrentStatus(hash, torrent), nil
 			}
 		}
 	}
@@ -159,27 +141,7 @@ func (t *TransmissionClient) GetTorrentStatus(hash string) (TorrentStatus, error
 			for _, tdata := range torrents {
 				if torrent, ok := tdata.(map[string]interface{}); ok {
 					if h, ok := torrent["hashString"].(string); ok && strings.EqualFold(h, hash) {
-						status := TorrentStatus{
-							Hash:        hash,
-							Name:        torrent["name"].(string),
-							Progress:    getFloat(torrent, "percentDone"),
-							DownloadDir: torrent["downloadDir"].(string),
-							Files:       []string{},
-							UploadRatio: getFloat(torrent, "uploadRatio"),
-						}
-
-						if files, ok := torrent["files"].([]interface{}); ok {
-							for _, file := range files {
-								if fileMap, ok := file.(map[string]interface{}); ok {
-									status.Files = append(status.Files, fileMap["name"].(string))
-								}
-							}
-						}
-
-						if status.Progress >= 1.0 {
-							status.IsCompleted = true
-						}
-						return status, nil
+						return transmissionTorrentStatus(hash, torrent), nil
 					}
 				}
 			}
@@ -189,6 +151,81 @@ func (t *TransmissionClient) GetTorrentStatus(hash string) (TorrentStatus, error
 	return TorrentStatus{}, fmt.Errorf("torrent not found")
 }
 
+// transmissionTorrentStatus converts a torrent of a torrent-get response. Transmission
+// reports an ETA of -1 or -2 when it doesn't know one, which becomes 0.
+func transmissionTorrentStatus(hash string, torrent map[string]interface{}) TorrentStatus {
+	status := TorrentStatus{
+		Hash:         hash,
+		Name:         stringValue(torrent["name"]),
+		Progress:     getFloat(torrent, "percentDone"),
+		DownloadDir:  stringValue(torrent["downloadDir"]),
+		Files:        []string{},
+		DownloadRate: int64(getFloat(torrent, "rateDownload")),
+		UploadRate:   int64(getFloat(torrent, "rateUpload")),
+		UploadRatio:  getFloat(torrent, "uploadRatio"),
+		ClientState:  transmissionStatusName(torrent["status"]),
+	}
+	if eta := int(getFloat(torrent, "eta")); eta > 0 {
+		status.ETA = eta
+	}
+
+	if files, ok := torrent["files"].([]interface{}); ok {
+		for _, file := range files {
+			if fileMap, ok := file.(map[string]interface{}); ok {
+				if name, ok := fileMap["name"].(string); ok {
+					status.Files = append(status.Files, name)
+				}
+			}
+		}
+	}
+
+	status.IsCompleted = status.Progress >= 1.0
+	status.State = transmissionState(status.ClientState, status.Progress)
+	return status
+}
+
+// transmissionStatuses names Transmission's numeric torrent status.
+var transmissionStatuses = []string{"stopped", "check_wait", "checking", "download_wait", "downloading", "seed_wait", "seeding"}
+
+// transmissionStatusName returns the name of a torrent's status field, or "" when
+// it is missing or unknown.
+func transmissionStatusName(status interface{}) string {
+	code, ok := status.(float64)
+	if !ok || code < 0 || int(code) >= len(transmissionStatuses) {
+		return ""
+	}
+	return transmissionStatuses[int(code)]
+}
+
+// ListTorrents returns the hash, name and download directory of every torrent.
+func (t *TransmissionClient) ListTorrents() ([]TorrentStatus, error) {
+	args := map[string]interface{}{
+		"fields": []string{"hashString", "name", "downloadDir"},
+	}
+	response, err := t.sendRequest("torrent-get", args)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments, _ := response["arguments"].(map[string]interface{})
+	torrents, ok := arguments["torrents"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("could not read the torrent list from the response")
+	}
+	list := make([]TorrentStatus, 0, len(torrents))
+	for _, tdata := range torrents {
+		torrent, ok := tdata.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected torrent entry in the response")
+		}
+		hash, _ := torrent["hashString"].(string)
+		name, _ := torrent["name"].(string)
+		downloadDir, _ := torrent["downloadDir"].(string)
+		list = append(list, TorrentStatus{Hash: hash, Name: name, DownloadDir: downloadDir})
+	}
+	return list, nil
+}
+
 func (t *TransmissionClient) RemoveTorrent(hash string) error {
 	method := "torrent-remove"
 	args := map[string]interface{}{
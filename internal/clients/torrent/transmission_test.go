@@ -0,0 +1,166 @@
+package torrent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// transmissionTorrentGet is a torrent-get response as Transmission 4.0 sends it,
+// trimmed to the requested fields.
+const transmissionTorrentGet = `{
+  "arguments": {
+    "torrents": [
+      {
+        "downloadDir": "/downloads/tv",
+        "eta": 754,
+        "files": [
+          {"bytesCompleted": 912261120, "length": 1824522240, "name": "Severance.S02E01.1080p.WEB.H264-GRP/Severance.S02E01.1080p.WEB.H264-GRP.mkv"},
+          {"bytesCompleted": 0, "length": 48213, "name": "Severance.S02E01.1080p.WEB.H264-GRP/Severance.S02E01.1080p.WEB.H264-GRP.nfo"}
+        ],
+        "hashString": "4f3a9b1e0c2d8e7f6a5b4c3d2e1f0a9b8c7d6e5f",
+        "name": "Severance.S02E01.1080p.WEB.H264-GRP",
+        "percentDone": 0.5,
+        "rateDownload": 1209856,
+        "rateUpload": 32768,
+        "status": 4,
+        "uploadRatio": 0.0213
+      },
+      {
+        "downloadDir": "/downloads/movies",
+        "eta": -1,
+        "files": [{"bytesCompleted": 8589934592, "length": 8589934592, "name": "Heat.1995.1080p.BluRay.x264-GRP.mkv"}],
+        "hashString": "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+        "name": "Heat.1995.1080p.BluRay.x264-GRP.mkv",
+        "percentDone": 1,
+        "rateDownload": 0,
+        "rateUpload": 524288,
+        "status": 6,
+        "uploadRatio": 1.52
+      },
+      {
+        "downloadDir": "/downloads/movies",
+        "eta": -2,
+        "files": [],
+        "hashString": "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+        "name": "Alien.1979.2160p.UHD.BluRay-GRP",
+        "percentDone": 0.1,
+        "rateDownload": 0,
+        "rateUpload": 0,
+        "status": 0,
+        "uploadRatio": 0
+      }
+    ]
+  },
+  "result": "success"
+}`
+
+// newFakeTransmission serves torrent-get from the recorded response, asking for a
+// session id first as Transmission does.
+func newFakeTransmission(t *testing.T) *httptest.Server {
+	t.Helper()
+	var recorded struct {
+		Arguments struct {
+			Torrents []map[string]interface{} `json:"torrents"`
+		} `json:"arguments"`
+	}
+	if err := json.Unmarshal([]byte(transmissionTorrentGet), &recorded); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Transmission-Session-Id") != "session" {
+			w.Header().Set("X-Transmission-Session-Id", "session")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		var req struct {
+			Method    string `json:"method"`
+			Arguments struct {
+				IDs []string `json:"ids"`
+			} `json:"arguments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "torrent-get" {
+			t.Errorf("unexpected request %+v (%v)", req, err)
+		}
+		torrents := []map[string]interface{}{}
+		for _, torrent := range recorded.Arguments.Torrents {
+			if len(req.Arguments.IDs) == 0 || slices.Contains(req.Arguments.IDs, torrent["hashString"].(string)) {
+				torrents = append(torrents, torrent)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"arguments": map[string]interface{}{"torrents": torrents}, "result": "success"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTransmissionTorrentStatus(t *testing.T) {
+	server := newFakeTransmission(t)
+	client := NewTransmissionClient(strings.TrimPrefix(server.URL, "http://"), "", "", time.Second)
+
+	tests := []struct {
+		name string
+		hash string
+		want TorrentStatus
+	}{
+		{
+			name: "downloading",
+			hash: "4f3a9b1e0c2d8e7f6a5b4c3d2e1f0a9b8c7d6e5f",
+			want: TorrentStatus{
+				Name: "Severance.S02E01.1080p.WEB.H264-GRP", Progress: 0.5, DownloadDir: "/downloads/tv",
+				DownloadRate: 1209856, UploadRate: 32768, ETA: 754, UploadRatio: 0.0213,
+				ClientState: "downloading", State: StateDownloading,
+			},
+		},
+		{
+			name: "seeding with no ETA",
+			hash: "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+			want: TorrentStatus{
+				Name: "Heat.1995.1080p.BluRay.x264-GRP.mkv", Progress: 1, DownloadDir: "/downloads/movies",
+				UploadRate: 524288, UploadRatio: 1.52, IsCompleted: true,
+				ClientState: "seeding", State: StateSeeding,
+			},
+		},
+		{
+			name: "stopped partway, hash in upper case",
+			hash: "0F1E2D3C4B5A69788796A5B4C3D2E1F00F1E2D3C",
+			want: TorrentStatus{
+				Name: "Alien.1979.2160p.UHD.BluRay-GRP", Progress: 0.1, DownloadDir: "/downloads/movies",
+				ClientState: "stopped", State: StatePaused,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := client.GetTorrentStatus(tt.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := status
+			got.Hash, got.Files = "", nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTorrentStatus() = %+v, want %+v", got, tt.want)
+			}
+			if status.Hash != tt.hash {
+				t.Errorf("Hash = %q, want %q", status.Hash, tt.hash)
+			}
+		})
+	}
+
+	status, err := client.GetTorrentStatus("4f3a9b1e0c2d8e7f6a5b4c3d2e1f0a9b8c7d6e5f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := []string{
+		"Severance.S02E01.1080p.WEB.H264-GRP/Severance.S02E01.1080p.WEB.H264-GRP.mkv",
+		"Severance.S02E01.1080p.WEB.H264-GRP/Severance.S02E01.1080p.WEB.H264-GRP.nfo",
+	}
+	if !slices.Equal(status.Files, wantFiles) {
+		t.Errorf("Files = %q, want %q", status.Files, wantFiles)
+	}
+}
@@ -2,53 +2,297 @@ package config
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"reel/internal/utils"
+
+	"github.com/robfig/cron/v3"
 	"gopkg.in/yaml.v3"
 )
 
 // SourceConfig defines the structure for an indexer source
 type SourceConfig struct {
-	Type       string `yaml:"type"`
-	URL        string `yaml:"url"`
-	APIKey     string `yaml:"api_key"`
-	SearchMode string `yaml:"search_mode,omitempty"`
+	Name       string   `yaml:"name,omitempty"` // Optional display label; derived from the URL when empty
+	Type       string   `yaml:"type"`
+	URL        string   `yaml:"url"`
+	APIKey     string   `yaml:"api_key"`
+	SearchMode string   `yaml:"search_mode,omitempty"`
+	Categories []string `yaml:"categories,omitempty"` // Media types searched on this source ("movie", "tvshow", "anime"); empty means all
+	// IndexerCategories are the Newznab category ids searched on a Prowlarr source, e.g.
+	// [5070] for anime. Empty uses the default for the section's media type.
+	IndexerCategories []int `yaml:"indexer_categories,omitempty"`
+	// QueryTemplate formats text queries, e.g. "{title} S{season}E{episode}" or "{title} {year}".
+	QueryTemplate string `yaml:"query_template,omitempty"`
+	// QuerySeparator replaces the spaces of a rendered query, e.g. "." for "Show.Name.S01E01".
+	QuerySeparator string `yaml:"query_separator,omitempty"`
+	// EpisodeTitleFallback searches for "<show> <episode title>" when the numbered episode
+	// searches find nothing, for sources that index episodes by title.
+	EpisodeTitleFallback bool `yaml:"episode_title_fallback,omitempty"`
+	// Priority favors results from this source: each level adds to their score (default 0).
+	Priority int `yaml:"priority,omitempty"`
+	// Headers are sent with every request to this source, e.g. a tracker cookie or token.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// FlareSolverrURL is a FlareSolverr instance used to get past Cloudflare challenges
+	// (Scarf and Jackett sources only).
+	FlareSolverrURL string `yaml:"flaresolverr_url,omitempty"`
+	// Private marks a private tracker: torrents grabbed from it never get the
+	// extra_trackers_list, which private trackers may ban for.
+	Private bool `yaml:"private,omitempty"`
+}
+
+// CORSConfig lets a web UI hosted on another origin call the API. Without allowed
+// origins, only same-origin requests are possible.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`           // e.g. "https://reel.example.com"; "*" allows any origin
+	AllowedMethods   []string `yaml:"allowed_methods,omitempty"` // Defaults to GET, POST, PUT, PATCH, DELETE
+	AllowedHeaders   []string `yaml:"allowed_headers,omitempty"` // Defaults to Authorization, Content-Type
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+// Default query templates, used when a source has no query_template.
+const (
+	DefaultEpisodeQueryTemplate = "{title} S{season}E{episode}"
+	DefaultMovieQueryTemplate   = "{title} {year}"
+)
+
+// EpisodeQuery renders the text query for an episode search on this source.
+func (s SourceConfig) EpisodeQuery(title string, season, episode int) string {
+	template := s.QueryTemplate
+	if template == "" {
+		template = DefaultEpisodeQueryTemplate
+	}
+	return s.renderQuery(template, title, 0, season, episode)
+}
+
+// SeasonQueries renders the text queries of a season pack search, in the order they
+// are tried: "{title} S01", then "{title} Season 1".
+func (s SourceConfig) SeasonQueries(title string, season int) []string {
+	return []string{
+		s.renderQuery("{title} S{season}", title, 0, season, 0),
+		s.renderQuery(fmt.Sprintf("{title} Season %d", season), title, 0, 0, 0),
+	}
+}
+
+// DailyEpisodeQuery renders the text query for an episode of a date-based show, named
+// by its air date: "{title} 2024 01 15" (the separator applies to the date too).
+func (s SourceConfig) DailyEpisodeQuery(title string, airDate time.Time) string {
+	return s.renderQuery("{title} "+airDate.Format("2006 01 02"), title, 0, 0, 0)
+}
+
+// MovieQuery renders the text query for a movie search on this source.
+func (s SourceConfig) MovieQuery(title string, year int) string {
+	template := s.QueryTemplate
+	if template == "" {
+		template = DefaultMovieQueryTemplate
+	}
+	return s.renderQuery(template, title, year, 0, 0)
+}
+
+// renderQuery fills in the template tokens. Season and episode are zero-padded to two
+// digits; tokens without a value (e.g. {year} for an unknown year) render empty, and
+// the leftover whitespace is collapsed.
+func (s SourceConfig) renderQuery(template, title string, year, season, episode int) string {
+	value := func(n int, format string) string {
+		if n <= 0 {
+			return ""
+		}
+		return fmt.Sprintf(format, n)
+	}
+	replacer := strings.NewReplacer(
+		"{title}", title,
+		"{year}", value(year, "%d"),
+		"{season}", value(season, "%02d"),
+		"{episode}", value(episode, "%02d"),
+	)
+	query := strings.Join(strings.Fields(replacer.Replace(template)), " ")
+	if s.QuerySeparator != "" {
+		query = strings.ReplaceAll(query, " ", s.QuerySeparator)
+	}
+	return query
+}
+
+// SearchesMediaType reports whether the source should be searched for the media type.
+// Sources without categories are searched for every type.
+func (s SourceConfig) SearchesMediaType(mediaType string) bool {
+	if len(s.Categories) == 0 {
+		return true
+	}
+	for _, category := range s.Categories {
+		if strings.EqualFold(category, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// genericPathSegments are URL path parts that never name an indexer.
+var genericPathSegments = map[string]bool{
+	"api": true, "v1": true, "v2.0": true, "torznab": true, "results": true, "indexers": true,
+}
+
+// Label returns the source's display name: Name when set, otherwise a name derived
+// from the URL for the source type (Jackett's indexer id, Torznab's last meaningful
+// path segment, or the host).
+func (s SourceConfig) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.Type == "prowlarr" {
+		return "Prowlarr"
+	}
+
+	parsed, err := url.Parse(s.URL)
+	if err != nil {
+		return s.URL
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	// Jackett: /api/v2.0/indexers/<name>/results/torznab
+	if s.Type == "jackett" {
+		for i, segment := range segments {
+			if segment == "indexers" && i+1 < len(segments) {
+				return segments[i+1]
+			}
+		}
+	}
+
+	if s.Type != "rss" {
+		for i := len(segments) - 1; i >= 0; i-- {
+			if segments[i] != "" && !genericPathSegments[strings.ToLower(segments[i])] {
+				return segments[i]
+			}
+		}
+	}
+	return parsed.Host
 }
 
 type FileRenamingConfig struct {
 	MovieTemplate  string `yaml:"movie_template"`
 	SeriesTemplate string `yaml:"series_template"`
 	AnimeTemplate  string `yaml:"anime_template"`
+	// What to do when a file already exists under the name a file is moved or renamed to;
+	// one of the Collision constants, CollisionSkip when empty
+	OnCollision string `yaml:"on_collision"`
+}
+
+// Ways of handling a file that already exists at the destination: "skip" leaves it and
+// keeps the new file out (or under its original name), "overwrite" replaces it, and
+// "rename-with-suffix" adds " (2)", " (3)"... to the new file's name.
+const (
+	CollisionSkip             = "skip"
+	CollisionOverwrite        = "overwrite"
+	CollisionRenameWithSuffix = "rename-with-suffix"
+)
+
+// QualityProfile is a named quality range that media items can reference instead of
+// storing their own min/max quality.
+type QualityProfile struct {
+	MinQuality     string   `yaml:"min_quality" json:"min_quality"`
+	MaxQuality     string   `yaml:"max_quality" json:"max_quality"`
+	PreferredWords []string `yaml:"preferred_words" json:"preferred_words"` // e.g. "remux", "hdr"; each match raises the score
+	PreferredOrder []string `yaml:"preferred_order" json:"preferred_order"` // resolutions, most wanted first
+}
+
+// TorrentClientConfig is the connection to a download client.
+type TorrentClientConfig struct {
+	Type         string `yaml:"type"`
+	Host         string `yaml:"host"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	Secret       string `yaml:"secret"`
+	DownloadPath string `yaml:"download_path"`
+	// CompletedStates are client states that mark a torrent as complete even when its
+	// progress is below 100%, e.g. when some files were unselected. Empty uses
+	// DefaultCompletedStates for the client type.
+	CompletedStates []string `yaml:"completed_states,omitempty"`
+	// Timeout bounds every request to the client, in seconds; 0 uses
+	// DefaultTorrentClientTimeout.
+	Timeout int `yaml:"timeout,omitempty"`
+}
+
+// DefaultTorrentClientTimeout bounds every request to a download client when its
+// timeout is not set, so a hung client can't hold up the download status polls.
+// Adding a torrent by URL makes some clients fetch it first, hence the slack.
+const DefaultTorrentClientTimeout = 60 * time.Second
+
+// RequestTimeout returns how long a request to the client may take.
+func (t TorrentClientConfig) RequestTimeout() time.Duration {
+	if t.Timeout <= 0 {
+		return DefaultTorrentClientTimeout
+	}
+	return time.Duration(t.Timeout) * time.Second
+}
+
+// SameConnection reports whether both settings reach the same client the same way, so
+// one client instance can serve them.
+func (t TorrentClientConfig) SameConnection(other TorrentClientConfig) bool {
+	return t.Type == other.Type && t.Host == other.Host && t.Username == other.Username &&
+		t.Password == other.Password && t.Secret == other.Secret && t.DownloadPath == other.DownloadPath &&
+		t.RequestTimeout() == other.RequestTimeout()
+}
+
+// DefaultCompletedStates are the states of each client type in which a torrent has
+// finished downloading its wanted files and is seeding or stopped.
+var DefaultCompletedStates = map[string][]string{
+	"qbittorrent":  {"uploading", "stalledUP", "pausedUP", "stoppedUP", "queuedUP", "forcedUP"},
+	"transmission": {"seed_wait", "seeding"},
+	"deluge":       {"Seeding"},
+	"aria2":        {"complete"},
+}
+
+// IsCompletedState reports whether a torrent in the client's state is complete.
+// States are compared case-insensitively.
+func (t TorrentClientConfig) IsCompletedState(state string) bool {
+	if state == "" {
+		return false
+	}
+	states := t.CompletedStates
+	if len(states) == 0 {
+		states = DefaultCompletedStates[strings.ToLower(t.Type)]
+	}
+	for _, completed := range states {
+		if strings.EqualFold(completed, state) {
+			return true
+		}
+	}
+	return false
 }
 
 type Config struct {
 	App struct {
-		Port                   int    `yaml:"port"`
-		DataPath               string `yaml:"data_path"`
-		UIEnabled              bool   `yaml:"ui_enabled"`
-		UIPassword             string `yaml:"ui_password"`
-		Debug                  bool   `yaml:"debug"`
-		JWTSecret              string `yaml:"jwt_secret"`
-		FilterLogLevel         string `yaml:"filter_log_level"` // "none" or "detail"
-		MagnetToTorrentEnabled bool   `yaml:"magnet_to_torrent_enabled"`
-		MagnetToTorrentTimeout int    `yaml:"magnet_to_torrent_timeout"`
-		SearchTimeout          int    `yaml:"search_timeout"`
+		Port                   int        `yaml:"port"`
+		DataPath               string     `yaml:"data_path"` // Holds the database, logs and temporary files; defaults to ./data
+		UIEnabled              bool       `yaml:"ui_enabled"`
+		UIPassword             string     `yaml:"ui_password"`
+		Debug                  bool       `yaml:"debug"`
+		JWTSecret              string     `yaml:"jwt_secret"`
+		FilterLogLevel         string     `yaml:"filter_log_level"` // "none" or "detail"
+		MagnetToTorrentEnabled bool       `yaml:"magnet_to_torrent_enabled"`
+		MagnetToTorrentTimeout int        `yaml:"magnet_to_torrent_timeout"`
+		SearchTimeout          int        `yaml:"search_timeout"`
+		UserAgent              string     `yaml:"user_agent"` // Sent to indexers and metadata providers
+		CORS                   CORSConfig `yaml:"cors"`
+		WebhookToken           string     `yaml:"webhook_token"`          // Shared secret of the inbound webhooks, which are disabled without it
+		ProxyImages            bool       `yaml:"proxy_images"`           // Serve posters from a local cache instead of the image CDNs
+		SaveIndexerResponses   bool       `yaml:"save_indexer_responses"` // Debug: keep the raw response of every indexer search
+		Proxy                  string     `yaml:"proxy"`                  // http:// or socks5:// proxy of indexer, metadata and RSS requests
 	} `yaml:"app"`
 
-	TorrentClient struct {
-		Type         string `yaml:"type"`
-		Host         string `yaml:"host"`
-		Username     string `yaml:"username"`
-		Password     string `yaml:"password"`
-		Secret       string `yaml:"secret"`
-		DownloadPath string `yaml:"download_path"`
-	} `yaml:"torrent_client"`
+	TorrentClient TorrentClientConfig `yaml:"torrent_client"`
 
 	Metadata struct {
 		Language string `yaml:"language"`
 		Timeout  int    `yaml:"timeout"`
 		TMDB     struct {
-			APIKey string `yaml:"api_key"`
+			APIKey       string `yaml:"api_key"`
+			Region       string `yaml:"region"`        // ISO 3166-1 code, e.g. "US"; empty uses TMDB's default
+			IncludeAdult bool   `yaml:"include_adult"` // Include adult titles in search results
 		} `yaml:"tmdb"`
 		IMDB struct {
 			APIKey string `yaml:"api_key"`
@@ -65,31 +309,41 @@ type Config struct {
 	} `yaml:"metadata"`
 
 	Movies struct {
-		Providers         []string       `yaml:"providers"`
-		Sources           []SourceConfig `yaml:"sources"`
-		DownloadFolder    string         `yaml:"download_folder"`
-		DestinationFolder string         `yaml:"destination_folder"`
-		MoveMethod        []string       `yaml:"move_method"`
+		Providers         []string             `yaml:"providers"`
+		Sources           []SourceConfig       `yaml:"sources"`
+		DownloadFolder    string               `yaml:"download_folder"`
+		DestinationFolder string               `yaml:"destination_folder"`
+		MoveMethod        MoveMethods          `yaml:"move_method"`
+		MinReleaseAge     *int                 `yaml:"min_release_age_minutes,omitempty"` // Overrides automation.min_release_age_minutes
+		TorrentClient     *TorrentClientConfig `yaml:"torrent_client,omitempty"`          // Overrides the global torrent_client
 	} `yaml:"movies"`
 
 	TVShows struct {
-		Providers         []string       `yaml:"providers"`
-		Sources           []SourceConfig `yaml:"sources"`
-		DownloadFolder    string         `yaml:"download_folder"`
-		DestinationFolder string         `yaml:"destination_folder"`
-		MoveMethod        []string       `yaml:"move_method"`
+		Providers         []string             `yaml:"providers"`
+		Sources           []SourceConfig       `yaml:"sources"`
+		DownloadFolder    string               `yaml:"download_folder"`
+		DestinationFolder string               `yaml:"destination_folder"`
+		MoveMethod        MoveMethods          `yaml:"move_method"`
+		MinReleaseAge     *int                 `yaml:"min_release_age_minutes,omitempty"` // Overrides automation.min_release_age_minutes
+		TorrentClient     *TorrentClientConfig `yaml:"torrent_client,omitempty"`          // Overrides the global torrent_client
 	} `yaml:"tv-shows"`
 
 	Anime struct {
-		Providers         []string       `yaml:"providers"`
-		Sources           []SourceConfig `yaml:"sources"`
-		DownloadFolder    string         `yaml:"download_folder"`
-		DestinationFolder string         `yaml:"destination_folder"`
-		MoveMethod        []string       `yaml:"move_method"`
+		Providers         []string             `yaml:"providers"`
+		Sources           []SourceConfig       `yaml:"sources"`
+		DownloadFolder    string               `yaml:"download_folder"`
+		DestinationFolder string               `yaml:"destination_folder"`
+		MoveMethod        MoveMethods          `yaml:"move_method"`
+		MinReleaseAge     *int                 `yaml:"min_release_age_minutes,omitempty"` // Overrides automation.min_release_age_minutes
+		TorrentClient     *TorrentClientConfig `yaml:"torrent_client,omitempty"`          // Overrides the global torrent_client
+		FolderLayout      string               `yaml:"folder_layout,omitempty"`           // "seasons" (default) or "flat"
 	} `yaml:"anime"`
 
 	Database struct {
-		Path string `yaml:"path"`
+		Path                   string `yaml:"path"`                      // Defaults to reel.db in the data path
+		MaxOpenConns           int    `yaml:"max_open_conns"`            // 0 keeps the driver's default (1 for SQLite)
+		MaxIdleConns           int    `yaml:"max_idle_conns"`            // 0 keeps the driver's default (1 for SQLite)
+		ConnMaxLifetimeMinutes int    `yaml:"conn_max_lifetime_minutes"` // 0 keeps connections open indefinitely
 	} `yaml:"database"`
 
 	Notifications struct {
@@ -103,19 +357,247 @@ type Config struct {
 		MaxConcurrentDownloads    int      `yaml:"max_concurrent_downloads"`
 		QualityPreferences        []string `yaml:"quality_preferences"`
 		MinSeeders                int      `yaml:"min_seeders"`
+		MinPeers                  int      `yaml:"min_peers"` // Minimum seeders + leechers
 		KeepTorrentsForDays       int      `yaml:"keep_torrents_for_days"`
 		KeepTorrentsSeedRatio     float64  `yaml:"keep_torrents_seed_ratio"`
 		EpisodeDownloadDelayHours int      `yaml:"episode_download_delay_hours"`
+		MinReleaseAgeMinutes      int      `yaml:"min_release_age_minutes"`    // Releases younger than this are left for a later search
+		CleanOrphanedDownloads    bool     `yaml:"clean_orphaned_downloads"`   // Remove leftover download folders no torrent or media refers to
+		ScanLibraryBeforeSearch   bool     `yaml:"scan_library_before_search"` // Mark episodes already in the library as downloaded instead of searching
+		DryRun                    bool     `yaml:"dry_run"`                    // Automatic searches only log the releases they would grab
+		SearchSpreadMinutes       int      `yaml:"search_spread_minutes"`      // Spreads scheduled searches and new-episode checks over this window
+		MinFreeSpaceMB            int      `yaml:"min_free_space_mb"`          // Stop starting downloads while a destination folder has less free space
+		DefaultLanguage           string   `yaml:"default_language"`           // Used by new media items added without a language
+		DefaultMinQuality         string   `yaml:"default_min_quality"`        // Used by new media items added without a quality range or profile
+		DefaultMaxQuality         string   `yaml:"default_max_quality"`
 		RejectCommon              []string `yaml:"reject-common"`
+		MatchDescription          bool     `yaml:"match_description"` // Reject patterns and preferred words also look at the indexer's description
+		PreferredOrigins          []string `yaml:"preferred_origins"` // Release origins (internal, scene, p2p), most wanted first
 		Notifications             []string `yaml:"notifications"`
+		// Minutes an episode can't be grabbed again after a download of it started, see GrabCooldown
+		GrabCooldownMinutes *int `yaml:"grab_cooldown_minutes"`
+		// Automatic retries of failed media before giving up, see MaxRetries
+		MaxRetries *int `yaml:"max_retries"`
+		// Cron expressions replacing the default interval of scheduled tasks, by ScheduledTasks name
+		Schedules map[string]string `yaml:"schedules"`
+		// Consecutive failed searches after which an indexer is skipped, and for how long; see IndexerBreaker
+		IndexerFailureThreshold *int `yaml:"indexer_failure_threshold"`
+		IndexerCooldownMinutes  int  `yaml:"indexer_cooldown_minutes"`
+		// Episodes wanted of shows added without a starting episode, one of MonitorModes
+		DefaultMonitorMode string `yaml:"default_monitor_mode"`
+		// Skip the specials (season 0) of shows, see IgnoresSpecials
+		IgnoreSpecials *bool `yaml:"ignore_specials"`
 	} `yaml:"automation"`
 
+	QualityProfiles map[string]QualityProfile `yaml:"quality_profiles"`
+
 	RejectCommon      []string `yaml:"reject-common"`
 	ExtraTrackersList []string `yaml:"extra_trackers_list"`
 
 	FileRenaming FileRenamingConfig `yaml:"file_renaming"`
 }
 
+// MinReleaseAge returns how old a release must be before it is grabbed for the media
+// type ("movie", "tvshow" or "anime"): the section's own setting, or the automation one.
+func (c *Config) MinReleaseAge(mediaType string) time.Duration {
+	minutes := c.Automation.MinReleaseAgeMinutes
+	var override *int
+	switch mediaType {
+	case "movie":
+		override = c.Movies.MinReleaseAge
+	case "tvshow":
+		override = c.TVShows.MinReleaseAge
+	case "anime":
+		override = c.Anime.MinReleaseAge
+	}
+	if override != nil {
+		minutes = *override
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// DefaultGrabCooldown is the grab cooldown when automation.grab_cooldown_minutes is not set.
+const DefaultGrabCooldown = 10 * time.Minute
+
+// GrabCooldown returns how long an episode is kept from being grabbed again after its
+// download started, so the RSS check and a search finding it at the same time don't
+// both download it. 0 turns the guard off.
+func (c *Config) GrabCooldown() time.Duration {
+	if c.Automation.GrabCooldownMinutes == nil {
+		return DefaultGrabCooldown
+	}
+	return time.Duration(*c.Automation.GrabCooldownMinutes) * time.Minute
+}
+
+// DefaultMaxRetries is the retry limit when automation.max_retries is not set.
+const DefaultMaxRetries = 5
+
+// MaxRetries returns how many times failed media is retried automatically before it is
+// left failed. 0 never retries.
+func (c *Config) MaxRetries() int {
+	if c.Automation.MaxRetries == nil {
+		return DefaultMaxRetries
+	}
+	return *c.Automation.MaxRetries
+}
+
+// IgnoresSpecials reports whether the specials (season 0) of shows are skipped instead
+// of searched, which they rarely match. On unless automation.ignore_specials is false;
+// a show can still include them (see models.Media.IncludeSpecials).
+func (c *Config) IgnoresSpecials() bool {
+	return c.Automation.IgnoreSpecials == nil || *c.Automation.IgnoreSpecials
+}
+
+// Defaults of automation.indexer_failure_threshold and automation.indexer_cooldown_minutes.
+const (
+	DefaultIndexerFailureThreshold = 3
+	DefaultIndexerCooldown         = 15 * time.Minute
+)
+
+// IndexerBreaker returns after how many consecutive failed searches an indexer is
+// skipped, and how long it is skipped before it is tried again. A threshold of 0 never
+// skips an indexer.
+func (c *Config) IndexerBreaker() (threshold int, cooldown time.Duration) {
+	threshold = DefaultIndexerFailureThreshold
+	if c.Automation.IndexerFailureThreshold != nil {
+		threshold = *c.Automation.IndexerFailureThreshold
+	}
+	cooldown = time.Duration(c.Automation.IndexerCooldownMinutes) * time.Minute
+	if cooldown <= 0 {
+		cooldown = DefaultIndexerCooldown
+	}
+	return threshold, cooldown
+}
+
+// TorrentClientFor returns the download client settings of the media type ("movie",
+// "tvshow" or "anime"): the section's own torrent_client, or the global one.
+func (c *Config) TorrentClientFor(mediaType string) TorrentClientConfig {
+	var override *TorrentClientConfig
+	switch mediaType {
+	case "movie":
+		override = c.Movies.TorrentClient
+	case "tvshow":
+		override = c.TVShows.TorrentClient
+	case "anime":
+		override = c.Anime.TorrentClient
+	}
+	if override != nil {
+		return *override
+	}
+	return c.TorrentClient
+}
+
+// Folder layouts of anime: "seasons" stores episodes in S01, S02... subfolders of the
+// show folder, "flat" straight in the show folder, for shows numbered without seasons.
+const (
+	FolderLayoutSeasons = "seasons"
+	FolderLayoutFlat    = "flat"
+)
+
+// SeasonFolder returns the subfolder of the show folder that episodes of the season are
+// stored in for the media type, or "" when they go straight in the show folder.
+func (c *Config) SeasonFolder(mediaType string, seasonNumber int) string {
+	if seasonNumber <= 0 || (mediaType == "anime" && c.Anime.FolderLayout == FolderLayoutFlat) {
+		return ""
+	}
+	return fmt.Sprintf("S%02d", seasonNumber)
+}
+
+// DefaultDataPath is used when app.data_path is not set.
+const DefaultDataPath = "./data"
+
+// DefaultMaxConcurrentDownloads is used when automation.max_concurrent_downloads is not
+// set, or is below 1, which would never start a download.
+const DefaultMaxConcurrentDownloads = 3
+
+// ApplyDefaults fills in the settings that have a default other than their zero value.
+func (c *Config) ApplyDefaults() {
+	if c.App.DataPath == "" {
+		c.App.DataPath = DefaultDataPath
+	}
+	if c.Automation.MaxConcurrentDownloads < 1 {
+		c.Automation.MaxConcurrentDownloads = DefaultMaxConcurrentDownloads
+	}
+}
+
+// DatabasePath returns the SQLite database file: database.path if set, otherwise
+// reel.db in the data path.
+func (c *Config) DatabasePath() string {
+	if c.Database.Path != "" {
+		return c.Database.Path
+	}
+	return filepath.Join(c.App.DataPath, "reel.db")
+}
+
+// PrepareDataPath creates the data path and the database directory and checks that
+// the data path is writable, so that a bad volume mapping fails at startup rather than
+// on the first write.
+func (c *Config) PrepareDataPath() error {
+	for _, dir := range []string{c.App.DataPath, filepath.Dir(c.DatabasePath())} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory '%s': %w", dir, err)
+		}
+	}
+
+	probe, err := os.CreateTemp(c.App.DataPath, ".write-test-*")
+	if err != nil {
+		return fmt.Errorf("data path '%s' is not writable: %w", c.App.DataPath, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// RequestHeaders returns the headers for outbound indexer and metadata requests: the
+// configured User-Agent (or the default one) plus the extra headers, which win on conflict.
+func (c *Config) RequestHeaders(extra map[string]string) map[string]string {
+	userAgent := c.App.UserAgent
+	if userAgent == "" {
+		userAgent = utils.DefaultUserAgent
+	}
+	headers := map[string]string{"User-Agent": userAgent}
+	for name, value := range extra {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers
+}
+
+// Defaults of app.search_timeout and metadata.timeout.
+const (
+	DefaultSearchTimeout   = 30 * time.Second
+	DefaultMetadataTimeout = 15 * time.Second
+)
+
+// SearchHTTPConfig returns the settings of the HTTP clients of indexers: the
+// app.search_timeout, the User-Agent plus the source's extra headers (see
+// RequestHeaders) and the app.proxy.
+func (c *Config) SearchHTTPConfig(extra map[string]string) utils.HTTPClientConfig {
+	timeout := time.Duration(c.App.SearchTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultSearchTimeout
+	}
+	return utils.HTTPClientConfig{Timeout: timeout, Headers: c.RequestHeaders(extra), Proxy: c.ProxyURL()}
+}
+
+// MetadataHTTPConfig returns the settings of the HTTP clients of metadata providers:
+// the metadata.timeout, the User-Agent and the app.proxy.
+func (c *Config) MetadataHTTPConfig() utils.HTTPClientConfig {
+	timeout := time.Duration(c.Metadata.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultMetadataTimeout
+	}
+	return utils.HTTPClientConfig{Timeout: timeout, Headers: c.RequestHeaders(nil), Proxy: c.ProxyURL()}
+}
+
+// ProxyURL returns the app.proxy that indexer and metadata requests go through, or nil
+// to connect directly. Validate rejects an invalid one.
+func (c *Config) ProxyURL() *url.URL {
+	proxy, err := utils.ParseProxyURL(c.App.Proxy)
+	if err != nil {
+		return nil
+	}
+	return proxy
+}
+
 func Load(path string) (*Config, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil, fmt.Errorf("config file not found at '%s'", path)
@@ -132,9 +614,110 @@ func Load(path string) (*Config, error) {
 	}
 
 	loadFromEnv(cfg)
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
 	return cfg, nil
 }
 
+// ReleaseOrigins are the origins automation.preferred_origins can name, as release names
+// tag them (INTERNAL, SCENE, P2P).
+var ReleaseOrigins = []string{"internal", "scene", "p2p"}
+
+// Names of the scheduled tasks whose schedule automation.schedules can set.
+const (
+	TaskPendingSearch = "pending_search"
+	TaskNewEpisodes   = "new_episodes"
+	TaskStatusUpdate  = "status_update"
+	TaskRSS           = "rss"
+	TaskCleanup       = "cleanup"
+	TaskOrphanCleanup = "orphan_cleanup"
+	TaskRetry         = "retry"
+	TaskHealthCheck   = "health_check"
+)
+
+// ScheduledTasks lists the task names automation.schedules accepts.
+var ScheduledTasks = []string{
+	TaskPendingSearch, TaskNewEpisodes, TaskStatusUpdate, TaskRSS,
+	TaskCleanup, TaskOrphanCleanup, TaskRetry, TaskHealthCheck,
+}
+
+// Monitor modes of a show being added, telling which of its episodes are wanted:
+// every one, only those airing from today, or those of its latest season. The other
+// episodes are skipped.
+const (
+	MonitorAll          = "all"
+	MonitorFuture       = "future"
+	MonitorLatestSeason = "latest-season"
+)
+
+// MonitorModes lists the monitor modes automation.default_monitor_mode accepts.
+var MonitorModes = []string{MonitorAll, MonitorFuture, MonitorLatestSeason}
+
+// Validate checks the parts of the config that would otherwise only fail once used,
+// such as a move_method chain naming an unknown method.
+func (c *Config) Validate() error {
+	sections := []struct {
+		name    string
+		methods MoveMethods
+	}{
+		{"movies", c.Movies.MoveMethod},
+		{"tv-shows", c.TVShows.MoveMethod},
+		{"anime", c.Anime.MoveMethod},
+	}
+	for _, section := range sections {
+		if err := validateMoveMethods(section.name, section.methods); err != nil {
+			return err
+		}
+	}
+	switch c.FileRenaming.OnCollision {
+	case "", CollisionSkip, CollisionOverwrite, CollisionRenameWithSuffix:
+	default:
+		return fmt.Errorf("file_renaming.on_collision: unknown policy '%s' (use '%s', '%s' or '%s')", c.FileRenaming.OnCollision, CollisionSkip, CollisionOverwrite, CollisionRenameWithSuffix)
+	}
+	switch c.Anime.FolderLayout {
+	case "", FolderLayoutSeasons, FolderLayoutFlat:
+	default:
+		return fmt.Errorf("anime.folder_layout: unknown layout '%s' (use '%s' or '%s')", c.Anime.FolderLayout, FolderLayoutSeasons, FolderLayoutFlat)
+	}
+	if c.App.CORS.AllowCredentials && slices.Contains(c.App.CORS.AllowedOrigins, "*") {
+		return fmt.Errorf("app.cors: allowed_origins \"*\" can't be combined with allow_credentials, which would let any site call the API as the user; list the origins instead")
+	}
+	for _, origin := range c.Automation.PreferredOrigins {
+		if !slices.Contains(ReleaseOrigins, strings.ToLower(origin)) {
+			return fmt.Errorf("automation.preferred_origins: unknown origin '%s' (use %s)", origin, strings.Join(ReleaseOrigins, ", "))
+		}
+	}
+	if _, err := utils.ParseProxyURL(c.App.Proxy); err != nil {
+		return fmt.Errorf("app.proxy: %w", err)
+	}
+	if c.Automation.DefaultMonitorMode != "" && !slices.Contains(MonitorModes, c.Automation.DefaultMonitorMode) {
+		return fmt.Errorf("automation.default_monitor_mode: unknown mode '%s' (use %s)", c.Automation.DefaultMonitorMode, strings.Join(MonitorModes, ", "))
+	}
+	for task, spec := range c.Automation.Schedules {
+		if !slices.Contains(ScheduledTasks, task) {
+			return fmt.Errorf("automation.schedules: unknown task '%s' (use %s)", task, strings.Join(ScheduledTasks, ", "))
+		}
+		if _, err := cron.ParseStandard(spec); err != nil {
+			return fmt.Errorf("automation.schedules.%s: invalid cron expression '%s': %w", task, spec, err)
+		}
+	}
+	if (c.Automation.IndexerFailureThreshold != nil && *c.Automation.IndexerFailureThreshold < 0) || c.Automation.IndexerCooldownMinutes < 0 {
+		return fmt.Errorf("automation: indexer_failure_threshold and indexer_cooldown_minutes must not be negative")
+	}
+	if c.Automation.GrabCooldownMinutes != nil && *c.Automation.GrabCooldownMinutes < 0 {
+		return fmt.Errorf("automation.grab_cooldown_minutes must not be negative")
+	}
+	if c.Automation.MaxRetries != nil && *c.Automation.MaxRetries < 0 {
+		return fmt.Errorf("automation.max_retries must not be negative")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 || c.Database.ConnMaxLifetimeMinutes < 0 {
+		return fmt.Errorf("database: max_open_conns, max_idle_conns and conn_max_lifetime_minutes must not be negative")
+	}
+	return validateMediaDefaults(c.Automation.DefaultLanguage, c.Automation.DefaultMinQuality, c.Automation.DefaultMaxQuality)
+}
+
 func (c *Config) Save(path string) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
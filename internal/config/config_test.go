@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reel/internal/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSchedules(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules map[string]string
+		wantErr   string
+	}{
+		{"none", nil, ""},
+		{"five fields", map[string]string{TaskPendingSearch: "0 2 * * *"}, ""},
+		{"descriptor", map[string]string{TaskRSS: "@every 15m"}, ""},
+		{"range", map[string]string{TaskPendingSearch: "0 0-6 * * *"}, ""},
+		{"unknown task", map[string]string{"upgrade": "0 2 * * *"}, "unknown task 'upgrade'"},
+		{"too many fields", map[string]string{TaskRSS: "0 0 2 * * *"}, "invalid cron expression"},
+		{"out of range", map[string]string{TaskRetry: "0 25 * * *"}, "invalid cron expression"},
+		{"not cron", map[string]string{TaskCleanup: "nightly"}, "automation.schedules.cleanup"},
+		{"empty", map[string]string{TaskCleanup: ""}, "invalid cron expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Automation.Schedules = tt.schedules
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentDownloadsDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		value int
+		want  int
+	}{
+		{"unset", 0, DefaultMaxConcurrentDownloads},
+		{"negative", -2, DefaultMaxConcurrentDownloads},
+		{"one", 1, 1},
+		{"set", 5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Automation.MaxConcurrentDownloads = tt.value
+			cfg.ApplyDefaults()
+			if got := cfg.Automation.MaxConcurrentDownloads; got != tt.want {
+				t.Errorf("config: got %d, want %d", got, tt.want)
+			}
+
+			settings := Settings{Automation: AutomationSettings{MaxConcurrentDownloads: tt.value}}
+			settings.ApplyDefaults()
+			if err := settings.Validate(); err != nil {
+				t.Fatalf("Validate() = %v, want nil", err)
+			}
+			if got := settings.Automation.MaxConcurrentDownloads; got != tt.want {
+				t.Errorf("settings: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClientConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           func(*Config)
+		wantSearch    time.Duration
+		wantMetadata  time.Duration
+		wantTorrent   time.Duration
+		wantUserAgent string
+		wantProxy     string
+	}{
+		{
+			name:          "defaults",
+			cfg:           func(*Config) {},
+			wantSearch:    DefaultSearchTimeout,
+			wantMetadata:  DefaultMetadataTimeout,
+			wantTorrent:   DefaultTorrentClientTimeout,
+			wantUserAgent: utils.DefaultUserAgent,
+		},
+		{
+			name: "configured",
+			cfg: func(c *Config) {
+				c.App.SearchTimeout = 120
+				c.Metadata.Timeout = 10
+				c.TorrentClient.Timeout = 5
+				c.App.UserAgent = "Mozilla/5.0"
+				c.App.Proxy = "socks5://127.0.0.1:1080"
+			},
+			wantSearch:    120 * time.Second,
+			wantMetadata:  10 * time.Second,
+			wantTorrent:   5 * time.Second,
+			wantUserAgent: "Mozilla/5.0",
+			wantProxy:     "socks5://127.0.0.1:1080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			tt.cfg(cfg)
+
+			search := cfg.SearchHTTPConfig(map[string]string{"cookie": "session=1"})
+			metadata := cfg.MetadataHTTPConfig()
+			if search.Timeout != tt.wantSearch || metadata.Timeout != tt.wantMetadata {
+				t.Errorf("timeouts = %v and %v, want %v and %v", search.Timeout, metadata.Timeout, tt.wantSearch, tt.wantMetadata)
+			}
+			if got := cfg.TorrentClient.RequestTimeout(); got != tt.wantTorrent {
+				t.Errorf("download client timeout = %v, want %v", got, tt.wantTorrent)
+			}
+			for _, httpConfig := range []utils.HTTPClientConfig{search, metadata} {
+				if got := httpConfig.Headers["User-Agent"]; got != tt.wantUserAgent {
+					t.Errorf("User-Agent = %q, want %q", got, tt.wantUserAgent)
+				}
+				proxy := ""
+				if httpConfig.Proxy != nil {
+					proxy = httpConfig.Proxy.String()
+				}
+				if proxy != tt.wantProxy {
+					t.Errorf("proxy = %q, want %q", proxy, tt.wantProxy)
+				}
+			}
+			if search.Headers["Cookie"] != "session=1" {
+				t.Errorf("the source's headers were not added: %v", search.Headers)
+			}
+		})
+	}
+}
+
+func TestSourceLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		source SourceConfig
+		want   string
+	}{
+		{"jackett", SourceConfig{Type: "jackett", URL: "http://jackett:9117/api/v2.0/indexers/1337x/results/torznab"}, "1337x"},
+		{"jackett with trailing slash", SourceConfig{Type: "jackett", URL: "http://jackett:9117/api/v2.0/indexers/rarbg/results/torznab/"}, "rarbg"},
+		{"jackett all indexers", SourceConfig{Type: "jackett", URL: "http://jackett:9117/api/v2.0/indexers/all/results/torznab"}, "all"},
+		{"prowlarr", SourceConfig{Type: "prowlarr", URL: "http://prowlarr:9696/api/v1"}, "Prowlarr"},
+		{"prowlarr without a path", SourceConfig{Type: "prowlarr", URL: "http://prowlarr:9696"}, "Prowlarr"},
+		{"torznab", SourceConfig{Type: "torznab", URL: "http://localhost:8080/torznab/movies"}, "movies"},
+		{"torznab without a name in the path", SourceConfig{Type: "torznab", URL: "http://indexer.example.com/api"}, "indexer.example.com"},
+		{"rss", SourceConfig{Type: "rss", URL: "https://example.com/feeds/tv.xml"}, "example.com"},
+		{"name set", SourceConfig{Type: "jackett", Name: "Public", URL: "http://jackett:9117/api/v2.0/indexers/1337x/results/torznab"}, "Public"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.source.Label(); got != tt.want {
+				t.Errorf("Label() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTemplates(t *testing.T) {
+	tests := []struct {
+		name   string
+		source SourceConfig
+		query  func(s SourceConfig) string
+		want   string
+	}{
+		{"default movie", SourceConfig{}, func(s SourceConfig) string { return s.MovieQuery("Dune", 1984) }, "Dune 1984"},
+		{"movie without a year", SourceConfig{}, func(s SourceConfig) string { return s.MovieQuery("Dune", 0) }, "Dune"},
+		{"dotted movie", SourceConfig{QuerySeparator: "."}, func(s SourceConfig) string { return s.MovieQuery("Dune Part Two", 2024) }, "Dune.Part.Two.2024"},
+		{"custom movie", SourceConfig{QueryTemplate: "{title} ({year})"}, func(s SourceConfig) string { return s.MovieQuery("Heat", 1995) }, "Heat (1995)"},
+		{"default episode", SourceConfig{}, func(s SourceConfig) string { return s.EpisodeQuery("The Bear", 3, 1) }, "The Bear S03E01"},
+		{"dotted episode", SourceConfig{QuerySeparator: "."}, func(s SourceConfig) string { return s.EpisodeQuery("The Bear", 3, 1) }, "The.Bear.S03E01"},
+		{"custom episode", SourceConfig{QueryTemplate: "{title} {season}x{episode}"}, func(s SourceConfig) string { return s.EpisodeQuery("Severance", 1, 9) }, "Severance 01x09"},
+		{"season", SourceConfig{QuerySeparator: "."}, func(s SourceConfig) string { return s.SeasonQueries("Severance", 2)[0] }, "Severance.S02"},
+		{"daily episode", SourceConfig{}, func(s SourceConfig) string {
+			return s.DailyEpisodeQuery("The Daily Show", time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC))
+		}, "The Daily Show 2024 03 14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query(tt.source); got != tt.want {
+				t.Errorf("query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials bool
+		wantErr     bool
+	}{
+		{"any origin", []string{"*"}, false, false},
+		{"listed origins with credentials", []string{"https://reel.example.com"}, true, false},
+		{"any origin with credentials", []string{"https://reel.example.com", "*"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.App.CORS = CORSConfig{AllowedOrigins: tt.origins, AllowCredentials: tt.credentials}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    int
+		wantErr bool
+	}{
+		{"unset", "", DefaultMaxRetries, false},
+		{"never retry", "automation:\n  max_retries: 0\n", 0, false},
+		{"set", "automation:\n  max_retries: 2\n", 2, false},
+		{"negative", "automation:\n  max_retries: -1\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := cfg.MaxRetries(); got != tt.want {
+				t.Errorf("MaxRetries() = %d, want %d", got, tt.want)
+			}
+			if got := cfg.Settings().Automation.MaxRetries; got != tt.want {
+				t.Errorf("settings max_retries = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MoveMethods is the fallback chain of post-processing methods for a media type, tried
+// in order until one succeeds, e.g. ["hardlink", "copy"].
+type MoveMethods []string
+
+var validMoveMethods = map[string]bool{
+	"hardlink": true,
+	"symlink":  true,
+	"move":     true,
+	"copy":     true,
+}
+
+// UnmarshalYAML accepts a list of methods or a single one ("move_method: copy").
+// Entries are trimmed and lowercased, and blank or repeated entries are dropped.
+func (m *MoveMethods) UnmarshalYAML(node *yaml.Node) error {
+	var methods []string
+	if node.Kind == yaml.ScalarNode {
+		methods = []string{node.Value}
+	} else if err := node.Decode(&methods); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	*m = nil
+	for _, method := range methods {
+		method = strings.ToLower(strings.TrimSpace(method))
+		if method == "" || seen[method] {
+			continue
+		}
+		seen[method] = true
+		*m = append(*m, method)
+	}
+	return nil
+}
+
+// validateMoveMethods rejects a chain holding an unknown method.
+func validateMoveMethods(section string, methods []string) error {
+	for _, method := range methods {
+		if !validMoveMethods[method] {
+			return fmt.Errorf("%s.move_method: unknown method %q (valid methods are hardlink, symlink, move and copy)", section, method)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadMoveMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		moveMethod string
+		want       MoveMethods
+		wantErr    string
+	}{
+		{"chain", `["hardlink", "copy"]`, MoveMethods{"hardlink", "copy"}, ""},
+		{"single method", `copy`, MoveMethods{"copy"}, ""},
+		{"case, blanks and repeats", `[" Hardlink", "", "COPY", "copy"]`, MoveMethods{"hardlink", "copy"}, ""},
+		{"unknown method in the chain", `["hardlink", "reflink", "copy"]`, nil, `tv-shows.move_method: unknown method "reflink"`},
+		{"unknown single method", `rsync`, nil, `tv-shows.move_method: unknown method "rsync"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte("tv-shows:\n  move_method: "+tt.moveMethod+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(cfg.TVShows.MoveMethod, tt.want) {
+				t.Errorf("move_method = %q, want %q", cfg.TVShows.MoveMethod, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"reel/internal/utils"
+)
+
+// qualities are the resolutions media items can be limited to, lowest first; the same
+// ones release names are parsed for and core.RESOLUTION_RANK ranks.
+var qualities = utils.Resolutions()
+
+// languageCodeRegex matches an ISO 639-1 or 639-2 language code, e.g. "en" or "spa".
+var languageCodeRegex = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+func qualityRank(quality string) int {
+	for i, q := range qualities {
+		if q == quality {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateMediaDefaults checks the language and quality range given to new media
+// items that don't set their own.
+func validateMediaDefaults(language, minQuality, maxQuality string) error {
+	if language != "" && !languageCodeRegex.MatchString(language) {
+		return fmt.Errorf("automation.default_language: %q is not a language code (e.g. \"en\")", language)
+	}
+	for _, setting := range []struct{ name, quality string }{
+		{"default_min_quality", minQuality},
+		{"default_max_quality", maxQuality},
+	} {
+		if setting.quality != "" && qualityRank(setting.quality) < 0 {
+			return fmt.Errorf("automation.%s: unknown quality %q (valid qualities are %s)", setting.name, setting.quality, strings.Join(qualities, ", "))
+		}
+	}
+	if minQuality != "" && maxQuality != "" && qualityRank(minQuality) > qualityRank(maxQuality) {
+		return fmt.Errorf("automation.default_min_quality %q is higher than automation.default_max_quality %q", minQuality, maxQuality)
+	}
+	return nil
+}
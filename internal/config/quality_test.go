@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMediaDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		language   string
+		minQuality string
+		maxQuality string
+		wantErr    string
+	}{
+		{"unset", "", "", "", ""},
+		{"range", "en", "720p", "2160p", ""},
+		{"bad language", "english", "", "", "automation.default_language"},
+		{"unknown min", "", "4k", "", "automation.default_min_quality: unknown quality \"4k\""},
+		{"unknown max", "", "", "8k", "automation.default_max_quality: unknown quality \"8k\""},
+		// The min quality is reported first, every time
+		{"both unknown", "", "4k", "8k", "automation.default_min_quality"},
+		{"inverted", "", "1080p", "720p", "higher than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				err := validateMediaDefaults(tt.language, tt.minQuality, tt.maxQuality)
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Fatalf("validateMediaDefaults() = %v, want nil", err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateMediaDefaults() = %v, want an error containing %q", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestQualitiesMatchReleaseResolutions(t *testing.T) {
+	if qualityRank("360p") != 0 || qualityRank("1080p") <= qualityRank("720p") || qualityRank("4320p") != len(qualities)-1 {
+		t.Errorf("qualities are not ordered lowest first: %v", qualities)
+	}
+	if qualityRank("4k") != -1 {
+		t.Error("a synonym passed for a quality")
+	}
+}
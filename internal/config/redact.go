@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secret values in config output sent to clients.
+const RedactedValue = "****"
+
+// secretKeys are the YAML keys whose values are treated as secrets, wherever they appear.
+var secretKeys = map[string]bool{
+	"api_key":       true,
+	"password":      true,
+	"ui_password":   true,
+	"jwt_secret":    true,
+	"webhook_token": true,
+	"secret":        true,
+	"client_id":     true,
+	"proxy":         true, // May hold a user and password
+}
+
+// secretMapKeys are the YAML keys whose mapping values are all treated as secrets,
+// e.g. a source's custom headers, which usually carry cookies or tokens.
+var secretMapKeys = map[string]bool{
+	"headers": true,
+}
+
+// RedactYAML returns the config document with every non-empty secret value replaced
+// by RedactedValue. Comments and key order are preserved.
+func RedactYAML(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	walkSecrets(&root, "", func(path string, value *yaml.Node) {
+		if value.Value != "" {
+			value.Value = RedactedValue
+			value.Style = yaml.DoubleQuotedStyle
+		}
+	})
+
+	return encodeYAML(&root)
+}
+
+// RestoreRedactedYAML puts the original secret values back into an edited config
+// document wherever the client sent RedactedValue unchanged, so saving a redacted
+// config does not overwrite real credentials.
+func RestoreRedactedYAML(edited, original []byte) ([]byte, error) {
+	var originalRoot yaml.Node
+	if err := yaml.Unmarshal(original, &originalRoot); err != nil {
+		return nil, fmt.Errorf("failed to parse current config file: %w", err)
+	}
+	secrets := make(map[string]string)
+	walkSecrets(&originalRoot, "", func(path string, value *yaml.Node) {
+		secrets[path] = value.Value
+	})
+
+	var editedRoot yaml.Node
+	if err := yaml.Unmarshal(edited, &editedRoot); err != nil {
+		return nil, fmt.Errorf("new configuration is invalid: %w", err)
+	}
+
+	restored := false
+	walkSecrets(&editedRoot, "", func(path string, value *yaml.Node) {
+		if value.Value != RedactedValue {
+			return
+		}
+		value.Value = secrets[path]
+		value.Style = yaml.DoubleQuotedStyle
+		restored = true
+	})
+	if !restored {
+		return edited, nil
+	}
+
+	return encodeYAML(&editedRoot)
+}
+
+// walkSecrets calls fn for every scalar value stored under a secret key. The path is a
+// dotted key path with sequence indexes, e.g. "movies.sources.0.api_key".
+func walkSecrets(node *yaml.Node, path string, fn func(path string, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkSecrets(child, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			if secretKeys[key.Value] && value.Kind == yaml.ScalarNode {
+				fn(childPath, value)
+				continue
+			}
+			if secretMapKeys[key.Value] && value.Kind == yaml.MappingNode {
+				for j := 0; j+1 < len(value.Content); j += 2 {
+					if value.Content[j+1].Kind == yaml.ScalarNode {
+						fn(childPath+"."+value.Content[j].Value, value.Content[j+1])
+					}
+				}
+				continue
+			}
+			walkSecrets(value, childPath, fn)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkSecrets(child, path+"."+strconv.Itoa(i), fn)
+		}
+	}
+}
+
+func encodeYAML(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
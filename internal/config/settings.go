@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings is the structured, JSON-editable subset of Config exposed to the UI.
+// It deliberately contains no credentials.
+type Settings struct {
+	Automation AutomationSettings `json:"automation"`
+	Movies     MediaTypeSettings  `json:"movies"`
+	TVShows    MediaTypeSettings  `json:"tv_shows"`
+	Anime      MediaTypeSettings  `json:"anime"`
+}
+
+// AutomationSettings mirrors the editable fields of Config.Automation.
+type AutomationSettings struct {
+	SearchInterval            string   `json:"search_interval"`
+	MaxConcurrentDownloads    int      `json:"max_concurrent_downloads"`
+	QualityPreferences        []string `json:"quality_preferences"`
+	MinSeeders                int      `json:"min_seeders"`
+	MinPeers                  int      `json:"min_peers"`
+	KeepTorrentsForDays       int      `json:"keep_torrents_for_days"`
+	KeepTorrentsSeedRatio     float64  `json:"keep_torrents_seed_ratio"`
+	EpisodeDownloadDelayHours int      `json:"episode_download_delay_hours"`
+	MaxRetries                int      `json:"max_retries"`
+	MinReleaseAgeMinutes      int      `json:"min_release_age_minutes"`
+}
+
+// MediaTypeSettings holds the folder and move fields of a movies/tv-shows/anime section.
+type MediaTypeSettings struct {
+	DownloadFolder    string   `json:"download_folder"`
+	DestinationFolder string   `json:"destination_folder"`
+	MoveMethod        []string `json:"move_method"`
+}
+
+// Settings returns the editable subset of the config. Its slices are copies, so the
+// settings can be changed without touching the config.
+func (c *Config) Settings() Settings {
+	a := c.Automation
+	return Settings{
+		Automation: AutomationSettings{
+			SearchInterval:            a.SearchInterval,
+			MaxConcurrentDownloads:    a.MaxConcurrentDownloads,
+			QualityPreferences:        slices.Clone(a.QualityPreferences),
+			MinSeeders:                a.MinSeeders,
+			MinPeers:                  a.MinPeers,
+			KeepTorrentsForDays:       a.KeepTorrentsForDays,
+			KeepTorrentsSeedRatio:     a.KeepTorrentsSeedRatio,
+			EpisodeDownloadDelayHours: a.EpisodeDownloadDelayHours,
+			MaxRetries:                c.MaxRetries(),
+			MinReleaseAgeMinutes:      a.MinReleaseAgeMinutes,
+		},
+		Movies: MediaTypeSettings{
+			DownloadFolder:    c.Movies.DownloadFolder,
+			DestinationFolder: c.Movies.DestinationFolder,
+			MoveMethod:        slices.Clone(c.Movies.MoveMethod),
+		},
+		TVShows: MediaTypeSettings{
+			DownloadFolder:    c.TVShows.DownloadFolder,
+			DestinationFolder: c.TVShows.DestinationFolder,
+			MoveMethod:        slices.Clone(c.TVShows.MoveMethod),
+		},
+		Anime: MediaTypeSettings{
+			DownloadFolder:    c.Anime.DownloadFolder,
+			DestinationFolder: c.Anime.DestinationFolder,
+			MoveMethod:        slices.Clone(c.Anime.MoveMethod),
+		},
+	}
+}
+
+// ApplyDefaults fills in the settings that have a default, as Config.ApplyDefaults does.
+func (s *Settings) ApplyDefaults() {
+	if s.Automation.MaxConcurrentDownloads < 1 {
+		s.Automation.MaxConcurrentDownloads = DefaultMaxConcurrentDownloads
+	}
+}
+
+// Validate checks the settings for values the rest of the application can't use.
+func (s Settings) Validate() error {
+	a := s.Automation
+	if a.SearchInterval != "" {
+		if _, err := time.ParseDuration(a.SearchInterval); err != nil {
+			return fmt.Errorf("automation.search_interval: %w", err)
+		}
+	}
+	if a.MinSeeders < 0 {
+		return fmt.Errorf("automation.min_seeders must not be negative")
+	}
+	if a.MinPeers < 0 {
+		return fmt.Errorf("automation.min_peers must not be negative")
+	}
+	if a.KeepTorrentsForDays < 0 {
+		return fmt.Errorf("automation.keep_torrents_for_days must not be negative")
+	}
+	if a.KeepTorrentsSeedRatio < 0 {
+		return fmt.Errorf("automation.keep_torrents_seed_ratio must not be negative")
+	}
+	if a.EpisodeDownloadDelayHours < 0 {
+		return fmt.Errorf("automation.episode_download_delay_hours must not be negative")
+	}
+	if a.MaxRetries < 0 {
+		return fmt.Errorf("automation.max_retries must not be negative")
+	}
+	if a.MinReleaseAgeMinutes < 0 {
+		return fmt.Errorf("automation.min_release_age_minutes must not be negative")
+	}
+
+	for name, mt := range map[string]MediaTypeSettings{"movies": s.Movies, "tv_shows": s.TVShows, "anime": s.Anime} {
+		if err := validateMoveMethods(name, mt.MoveMethod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type settingValue struct {
+	path  []string
+	value interface{}
+}
+
+// ApplySettingsYAML writes the settings into a config document, leaving every other
+// key, and the file's comments, untouched.
+func ApplySettingsYAML(data []byte, s Settings) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(root.Content) == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	doc := root.Content[0]
+
+	a := s.Automation
+	values := []settingValue{
+		{[]string{"automation", "search_interval"}, a.SearchInterval},
+		{[]string{"automation", "max_concurrent_downloads"}, a.MaxConcurrentDownloads},
+		{[]string{"automation", "quality_preferences"}, a.QualityPreferences},
+		{[]string{"automation", "min_seeders"}, a.MinSeeders},
+		{[]string{"automation", "min_peers"}, a.MinPeers},
+		{[]string{"automation", "keep_torrents_for_days"}, a.KeepTorrentsForDays},
+		{[]string{"automation", "keep_torrents_seed_ratio"}, a.KeepTorrentsSeedRatio},
+		{[]string{"automation", "episode_download_delay_hours"}, a.EpisodeDownloadDelayHours},
+		{[]string{"automation", "max_retries"}, a.MaxRetries},
+		{[]string{"automation", "min_release_age_minutes"}, a.MinReleaseAgeMinutes},
+	}
+	for section, mt := range map[string]MediaTypeSettings{"movies": s.Movies, "tv-shows": s.TVShows, "anime": s.Anime} {
+		values = append(values,
+			settingValue{[]string{section, "download_folder"}, mt.DownloadFolder},
+			settingValue{[]string{section, "destination_folder"}, mt.DestinationFolder},
+			settingValue{[]string{section, "move_method"}, mt.MoveMethod},
+		)
+	}
+
+	for _, v := range values {
+		if err := setYAMLValue(doc, v.path, v.value); err != nil {
+			return nil, err
+		}
+	}
+
+	return encodeYAML(&root)
+}
+
+// setYAMLValue sets the value at path in a mapping node, creating missing keys.
+// Comments and quoting/flow style of an existing value are kept.
+func setYAMLValue(node *yaml.Node, path []string, value interface{}) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("config key %q is not a mapping", path[0])
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		existing := node.Content[i+1]
+		if len(path) > 1 {
+			return setYAMLValue(existing, path[1:], value)
+		}
+		var replacement yaml.Node
+		if err := replacement.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", path[0], err)
+		}
+		if replacement.Kind == existing.Kind && replacement.Tag == existing.Tag {
+			replacement.Style = existing.Style
+			if existing.Kind == yaml.SequenceNode && len(existing.Content) > 0 {
+				for _, item := range replacement.Content {
+					item.Style = existing.Content[0].Style
+				}
+			}
+		}
+		replacement.HeadComment = existing.HeadComment
+		replacement.LineComment = existing.LineComment
+		replacement.FootComment = existing.FootComment
+		*existing = replacement
+		return nil
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	if len(path) == 1 {
+		if err := child.Encode(value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", path[0], err)
+		}
+	} else if err := setYAMLValue(child, path[1:], value); err != nil {
+		return err
+	}
+	node.Content = append(node.Content, key, child)
+	return nil
+}
@@ -1,17 +1,26 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha1"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"maps"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -29,59 +38,46 @@ import (
 )
 
 // --- Quality Scoring Logic ---
+// QUALITY_SCORES are the points of the tags utils.ParseReleaseName finds in a release
+// name. The resolution only adds a little here, the rank is used for filtering.
 var QUALITY_SCORES = map[string]int{
-	// Resolution - These are now synonyms, the rank will be used for filtering
-	"4k": 8, "2160p": 8, "uhd": 8,
-	"1440p": 6, "2k": 6,
-	"1080p": 5, "fhd": 5,
-	"720p": 4, "hd": 4,
-	"480p": 3, "sd": 2,
-	"360p": 1,
-	"xvid": 1,
+	// Resolution
+	"4320p": 8, "2160p": 8, "1440p": 6, "1080p": 5, "720p": 4, "480p": 3, "360p": 1,
 	// Source quality
-	"remux":  10,
-	"bluray": 8, "blu-ray": 8, "bdrip": 8, "brrip": 6,
-	"webdl": 7, "web-dl": 7, "web": 6, "webrip": 5,
-	"hdtv": 4, "dvdrip": 3,
-	"cam": 1, "ts": 1,
+	"Remux": 10, "BluRay": 8, "BDRip": 8, "BRRip": 6,
+	"WEB-DL": 7, "WEB": 6, "WEBRip": 5,
+	"HDTV": 4, "DVDRip": 3,
+	"CAM": 1, "TS": 1,
 	// Codec
-	"av1": 5, "x265": 3, "h265": 3, "hevc": 3,
-	"x264": 2, "h264": 2, "avc": 2,
+	"AV1": 5, "H.265": 3, "H.264": 2,
 	// Audio
-	"atmos": 3, "truehd": 3, "dts-hd": 3, "dts-x": 3,
-	"dts": 2, "ac3": 1, "aac": 1,
-	// Special
-	"repack": 1, "proper": 1, "extended": 1, "uncut": 1, "directors": 1,
-	"hdr": 2, "hdr10": 2, "dolbyvision": 3, "dv": 3, "imax": 2,
+	"Atmos": 3, "TrueHD": 3, "DTS-HD": 3, "DTS-X": 3,
+	"DTS": 2, "DD+": 1, "DD": 1, "AAC": 1,
+	// HDR
+	"HDR": 2, "HDR10": 2, "HDR10+": 2, "DV": 3,
+	// Edition
+	"IMAX": 2, "Extended": 1, "Uncut": 1, "Director's Cut": 1,
 }
 
-var RESOLUTION_SYNONYMS = map[string][]string{
-	"4320p": {"4320p", "8k"},
-	"2160p": {"2160p", "4k", "uhd"},
-	"1440p": {"1440p", "2k"},
-	"1080p": {"1080p", "fhd"},
-	"720p":  {"720p", "hd", "hdtv", "xvid"},
-	"480p":  {"480p", "576p", "sd", "msd", "dvdrip", "ntsc", "pal"},
-	"360p":  {"360p"},
-}
+// PROPER_SCORE is the bonus of a PROPER or REPACK.
+const PROPER_SCORE = 1
 
-var RESOLUTION_RANK = map[string]int{
-	"360p":  0,
-	"480p":  1,
-	"720p":  2,
-	"1080p": 3,
-	"1440p": 4,
-	"2160p": 5,
-	"4320p": 6,
-}
+// RESOLUTION_RANK ranks the resolutions of utils.Resolutions, lowest first.
+var RESOLUTION_RANK = resolutionRanks()
 
-// Ordered from highest to lowest for matching
-var SUPPORTED_RESOLUTIONS = []string{"2160p", "1440p", "1080p", "720p", "480p", "360p"}
+func resolutionRanks() map[string]int {
+	ranks := make(map[string]int)
+	for i, resolution := range utils.Resolutions() {
+		ranks[resolution] = i
+	}
+	return ranks
+}
 
 // --- RSS Parsing Structs ---
 type rssItem struct {
-	Title string `xml:"title"`
-	Link  string `xml:"link"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
 }
 type rssChannel struct {
 	Items []rssItem `xml:"item"`
@@ -92,12 +88,13 @@ type rssFeed struct {
 }
 
 func getQualityScore(title string) int {
+	release := utils.ParseReleaseName(title)
 	score := 0
-	lowerTitle := strings.ToLower(title)
-	for key, value := range QUALITY_SCORES {
-		if strings.Contains(lowerTitle, key) {
-			score += value
-		}
+	for _, tag := range release.Tags() {
+		score += QUALITY_SCORES[tag]
+	}
+	if release.Proper {
+		score += PROPER_SCORE
 	}
 	return score
 }
@@ -108,19 +105,80 @@ type IndexerClientWithMode struct {
 }
 
 type Manager struct {
-	config          *config.Config
+	// clientsMu guards the config and the clients built from it, which reloadConfig
+	// replaces (the maps and slices are swapped whole, never changed in place). Read
+	// them with Config, indexers, metadataProviders, downloadClients, notifierList,
+	// processor and sharedHTTPClient.
+	clientsMu sync.RWMutex
+	config    *config.Config
+	// configFileMu serializes the changes to the config file, which read it, edit it
+	// and write it back
+	configFileMu    sync.Mutex
 	db              *sql.DB
 	mediaRepo       *models.MediaRepository
 	indexerClients  map[models.MediaType][]IndexerClientWithMode
 	metadataClients map[models.MediaType][]metadata.Client
 	torrentClient   torrent.TorrentClient
-	torrentSelector *TorrentSelector
-	notifiers       []notifications.Notifier
-	postProcessor   *PostProcessor
-	logger          *utils.Logger
-	scheduler       *cron.Cron
-	searchQueue     chan models.Media
-	httpClient      *http.Client
+	// Download clients of the media types whose section overrides torrent_client
+	sectionTorrentClients map[models.MediaType]torrent.TorrentClient
+	torrentSelector       *TorrentSelector
+	notifiers             []notifications.Notifier
+	postProcessor         *PostProcessor
+	logger                *utils.Logger
+	scheduler             *cron.Cron
+	searchQueue           chan models.Media
+	httpClient            *http.Client
+
+	// queuedMedia holds the ids of the media waiting in or being searched by the search
+	// queue, so an item isn't queued again before its search is done.
+	queuedMu    sync.Mutex
+	queuedMedia map[int]bool
+
+	// refreshing holds the ids of the media whose metadata is being refreshed
+	refreshMu  sync.Mutex
+	refreshing map[int]bool
+
+	readinessMu     sync.Mutex
+	readinessCache  *ReadinessStatus
+	readinessExpiry time.Time
+
+	// Serializes download status polls, which can also be triggered by completion webhooks
+	downloadStatusMu sync.Mutex
+
+	// Transfer rates of active downloads by media ID, replaced on every status poll
+	transfersMu sync.Mutex
+	transfers   map[int]models.TransferStats
+
+	// Download folder entries found orphaned by the last cleanup run
+	orphanCandidates map[string]bool
+
+	// Latest background health check, served by GetSystemStatus, and the recent checks
+	// of each client by healthKey, loaded from the database on the first check.
+	// healthCheckMu keeps a forced refresh from running alongside the scheduled check.
+	healthMu      sync.Mutex
+	healthStatus  *SystemStatus
+	healthHistory map[string][]models.HealthCheck
+	healthCheckMu sync.Mutex
+
+	// Destination folders found below automation.min_free_space_mb, so the warning
+	// and notification are only sent when a folder runs low, not on every check
+	diskSpaceMu  sync.Mutex
+	lowDiskSpace map[string]bool
+
+	// When each episode's latest download started, keyed by episodeGrabKey, so the RSS
+	// check and a search don't both grab it (see automation.grab_cooldown_minutes)
+	recentGrabsMu sync.Mutex
+	recentGrabs   map[string]time.Time
+
+	// Consecutive failed searches of each indexer, by source URL, and until when the
+	// indexers that failed too often are skipped (see automation.indexer_failure_threshold)
+	indexerFailuresMu sync.Mutex
+	indexerFailures   map[string]int
+	indexerTripped    map[string]time.Time
+
+	// Scheduler entries of the scheduled tasks, replaced when automation.schedules changes
+	scheduleMu  sync.Mutex
+	taskEntries []cron.EntryID
 }
 
 type SubtitleTrack struct {
@@ -130,17 +188,53 @@ type SubtitleTrack struct {
 }
 
 type SystemStatus struct {
-	TorrentClient   ClientStatus            `json:"torrent_client"`
-	IndexerClients  map[string]ClientStatus `json:"indexer_clients"`
-	MetadataClients []string                `json:"metadata_clients"`
+	TorrentClient         ClientStatus            `json:"torrent_client"`
+	SectionTorrentClients map[string]ClientStatus `json:"section_torrent_clients,omitempty"` // Download clients of sections that override torrent_client, by section
+	IndexerClients        map[string]ClientStatus `json:"indexer_clients"`
+	MetadataClients       []string                `json:"metadata_clients"`
+	CheckedAt             time.Time               `json:"checked_at"`
 }
 
 type ClientStatus struct {
-	Type   string `json:"type"`
-	Name   string `json:"name"`
-	Status bool   `json:"status"`
+	Type         string                 `json:"type"`
+	Name         string                 `json:"name"`
+	Status       bool                   `json:"status"`
+	Capabilities *indexers.Capabilities `json:"capabilities,omitempty"`
+	LastChecked  time.Time              `json:"last_checked"`
+	History      []models.HealthCheck   `json:"history,omitempty"` // Recent checks, oldest first
+	// Set while an indexer is skipped by searches after failing too often in a row
+	TrippedUntil *time.Time `json:"tripped_until,omitempty"`
+}
+
+// recordCheck appends the client's current status to its history, keeping the most
+// recent healthHistoryLength checks. The history is copied, as the previous status
+// it came from may still be read.
+func (c *ClientStatus) recordCheck(previous []models.HealthCheck) {
+	history := append(append([]models.HealthCheck(nil), previous...), models.HealthCheck{CheckedAt: c.LastChecked, Status: c.Status})
+	if len(history) > healthHistoryLength {
+		history = history[len(history)-healthHistoryLength:]
+	}
+	c.History = history
+}
+
+// ReadinessStatus reports whether the external services Reel depends on are reachable.
+type ReadinessStatus struct {
+	Ready          bool      `json:"ready"`
+	TorrentClient  bool      `json:"torrent_client"`
+	IndexerHealthy bool      `json:"indexer_healthy"`
+	CheckedAt      time.Time `json:"checked_at"`
 }
 
+// healthCheckInterval is how often the download client and indexers are checked for
+// the status page, and healthHistoryLength how many past checks are kept per client.
+const (
+	healthCheckInterval = 5 * time.Minute
+	healthHistoryLength = 24
+)
+
+// readinessCacheTTL bounds how often readiness probes reach out to the torrent client and indexers.
+const readinessCacheTTL = 10 * time.Second
+
 type CalendarEvent struct {
 	Title  string `json:"title"`
 	Start  string `json:"start"`
@@ -148,165 +242,76 @@ type CalendarEvent struct {
 }
 
 func NewManager(cfg *config.Config, db *sql.DB, logger *utils.Logger) *Manager {
+	mediaRepo := models.NewMediaRepository(db, logger)
 	m := &Manager{
 		config:          cfg,
 		db:              db,
-		mediaRepo:       models.NewMediaRepository(db, logger),
-		torrentSelector: NewTorrentSelector(cfg, logger), // Assuming this exists
+		mediaRepo:       mediaRepo,
+		torrentSelector: NewTorrentSelector(cfg, logger),
 		notifiers:       make([]notifications.Notifier, 0),
 		logger:          logger,
 		scheduler:       cron.New(),
 		searchQueue:     make(chan models.Media, 100),
+		queuedMedia:     make(map[int]bool),
+		refreshing:      make(map[int]bool),
+		recentGrabs:     make(map[string]time.Time),
+		indexerFailures: make(map[string]int),
+		indexerTripped:  make(map[string]time.Time),
 		indexerClients:  make(map[models.MediaType][]IndexerClientWithMode),
 		metadataClients: make(map[models.MediaType][]metadata.Client),
 		httpClient:      &http.Client{},
 	}
 
-	// Show log info
-	logger.Info("Reloading the config...")
-
-	// --- Initialize Indexer Search Timeout ---
-	searchTimeout := time.Duration(cfg.App.SearchTimeout) * time.Second
-	if cfg.App.SearchTimeout <= 0 {
-		searchTimeout = 30 * time.Second // Default if not set or invalid
-	}
-	// The manager's generic http client can use the indexer timeout
-	m.httpClient.Timeout = searchTimeout
-
-	// --- Initialize Metadata Client Timeout ---
-	metadataTimeout := time.Duration(cfg.Metadata.Timeout) * time.Second
-	if cfg.Metadata.Timeout <= 0 {
-		metadataTimeout = 15 * time.Second // Default if not set or invalid
+	if err := mediaRepo.BackfillNormalizedTitles(); err != nil {
+		logger.Error("Failed to backfill normalized media titles, duplicate checks may miss older items:", err)
 	}
 
-	// --- Initialize Notifiers ---
-	for _, notifierName := range cfg.Automation.Notifications {
-		switch notifierName {
-		case "pushbullet":
-			if cfg.Notifications.Pushbullet.APIKey != "" {
-				client := notifications.NewPushbulletClient(cfg.Notifications.Pushbullet.APIKey, logger)
-				m.notifiers = append(m.notifiers, client)
-				logger.Info("Pushbullet notifier enabled.")
-			}
-			// Other notifiers will go here in the future
-		}
+	// Notifiers, metadata/indexer clients and the torrent client are built from the config.
+	if err := m.reloadConfig(cfg); err != nil {
+		logger.Fatal(err)
 	}
 
-	m.postProcessor = NewPostProcessor(cfg, logger, models.NewMediaRepository(db, logger), m.notifiers)
-
-	// --- Initialize Clients based on new Config Structure ---
-
-	// Create a TMDB client instance to be shared
-	tmdbClient := metadata.NewTMDBClient(cfg.Metadata.TMDB.APIKey, cfg.Metadata.Language, metadataTimeout)
-
-	// Helper function to initialize metadata providers
-	initMetadataProvider := func(provider string) metadata.Client {
-		switch provider {
-		case "tmdb":
-			return tmdbClient // Return the shared instance
-		case "imdb":
-			return metadata.NewIMDBClient(cfg.Metadata.IMDB.APIKey, metadataTimeout, m.logger)
-		case "tvmaze":
-			return metadata.NewTVmazeClient(metadataTimeout)
-		case "anilist":
-			return metadata.NewAniListClient(metadataTimeout)
-		case "trakt":
-			return metadata.NewTraktClient(cfg.Metadata.Trakt.ClientID, tmdbClient, metadataTimeout, m.logger) // Pass TMDB client
-		}
-		return nil
-	}
+	go m.startSearchQueueWorker()
+	go m.validateIndexerSearchModes()
 
-	// Helper function to initialize indexer sources
-	initIndexerClient := func(source config.SourceConfig) indexers.Client {
-		timeout := time.Duration(m.config.App.SearchTimeout) * time.Second
-		if m.config.App.SearchTimeout == 0 {
-			timeout = 30 * time.Second
-		}
-		switch source.Type {
-		case "scarf":
-			return indexers.NewScarfClient(source.URL, source.APIKey, timeout)
-		case "jackett":
-			return indexers.NewJackettClient(source.URL, source.APIKey, timeout)
-		case "prowlarr":
-			return indexers.NewProwlarrClient(source.URL, source.APIKey, timeout)
-		}
-		return nil
-	}
+	return m
+}
 
-	// Initialize Movie Clients
-	for _, providerName := range cfg.Movies.Providers {
-		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeMovie] = append(m.metadataClients[models.MediaTypeMovie], client)
-		}
-	}
-	for _, source := range cfg.Movies.Sources {
-		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeMovie] = append(m.indexerClients[models.MediaTypeMovie], IndexerClientWithMode{
-					Client: client,
-					Source: source,
-				})
+// validateIndexerSearchModes warns about sources whose configured search_mode the
+// indexer does not advertise in its caps, since such searches silently return nothing.
+func (m *Manager) validateIndexerSearchModes() {
+	checked := make(map[string]bool)
+	for _, clients := range m.indexers() {
+		for _, clientWithMode := range clients {
+			source := clientWithMode.Source
+			searchMode := source.SearchMode
+			if searchMode == "season" {
+				searchMode = "search" // season packs are looked up with the generic search
 			}
-		}
-	}
-
-	// Initialize TV Show Clients
-	for _, providerName := range cfg.TVShows.Providers {
-		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeTVShow] = append(m.metadataClients[models.MediaTypeTVShow], client)
-		}
-	}
-	for _, source := range cfg.TVShows.Sources {
-		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeTVShow] = append(m.indexerClients[models.MediaTypeTVShow], IndexerClientWithMode{
-					Client: client,
-					Source: source,
-				})
+			key := source.URL + "|" + searchMode
+			if searchMode == "" || checked[key] {
+				continue
 			}
-		}
-	}
+			checked[key] = true
 
-	// Initialize Anime Clients
-	for _, providerName := range cfg.Anime.Providers {
-		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeAnime] = append(m.metadataClients[models.MediaTypeAnime], client)
-		}
-	}
-	for _, source := range cfg.Anime.Sources {
-		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeAnime] = append(m.indexerClients[models.MediaTypeAnime], IndexerClientWithMode{
-					Client: client,
-					Source: source,
-				})
+			caps, err := clientWithMode.Client.Capabilities()
+			if err != nil {
+				m.logger.Warn("Could not fetch caps for indexer", source.URL, "to validate search mode:", err)
+				continue
+			}
+			if caps == nil || caps.SupportsSearchMode(searchMode) {
+				continue
 			}
-		}
-	}
 
-	// Setup Torrent Client (this remains global)
-	switch cfg.TorrentClient.Type {
-	case "transmission":
-		m.torrentClient = torrent.NewTransmissionClient(cfg.TorrentClient.Host, cfg.TorrentClient.Username, cfg.TorrentClient.Password)
-	case "qbittorrent":
-		m.torrentClient = torrent.NewQBittorrentClient(cfg.TorrentClient.Host, cfg.TorrentClient.Username, cfg.TorrentClient.Password, m.logger)
-	case "aria2":
-		m.torrentClient = torrent.NewAria2Client(cfg.TorrentClient.Host, cfg.TorrentClient.Secret)
-	case "deluge":
-		client, err := torrent.NewDelugeClient(cfg.TorrentClient.Host, cfg.TorrentClient.Password)
-		if err != nil {
-			m.logger.Fatal("Failed to create Deluge client:", err)
+			available := make([]string, 0, len(caps.SearchModes))
+			for mode := range caps.SearchModes {
+				available = append(available, mode)
+			}
+			sort.Strings(available)
+			m.logger.Warn(fmt.Sprintf("Indexer %s does not support search_mode '%s' (available: %s)",
+				source.URL, searchMode, strings.Join(available, ", ")))
 		}
-		m.torrentClient = client
-	default:
-		m.logger.Fatal("Unsupported torrent client type:", cfg.TorrentClient.Type)
 	}
-
-	m.reloadConfig(cfg)
-
-	go m.startSearchQueueWorker()
-
-	return m
 }
 
 func (m *Manager) startSearchQueueWorker() {
@@ -314,52 +319,158 @@ func (m *Manager) startSearchQueueWorker() {
 	for media := range m.searchQueue {
 		switch media.Type {
 		case models.MediaTypeMovie:
-			m.searchAndDownloadMovie(&media)
+			m.searchAndDownloadMovie(&media, m.Config().Automation.DryRun)
 		case models.MediaTypeTVShow, models.MediaTypeAnime:
-			m.searchAndDownloadNextEpisode(&media)
+			m.searchAndDownloadNextEpisode(&media, m.Config().Automation.DryRun)
 		}
+		m.queuedMu.Lock()
+		delete(m.queuedMedia, media.ID)
+		m.queuedMu.Unlock()
 		time.Sleep(30 * time.Second)
 	}
 }
 
-func (m *Manager) AddMedia(mediaType models.MediaType, id string, title string, year int, language, minQuality, maxQuality string, autoDownload bool, startSeason, startEpisode int) (*models.Media, error) {
-	m.logger.Info("Parameters - Type:", mediaType, "ID:", id, "Title:", title, "Year:", year, "StartSeason:", startSeason, "StartEpisode:", startEpisode)
+// errSearchQueueFull is returned by enqueueSearch when the queue has no room left.
+var errSearchQueueFull = errors.New("search queue is full")
+
+// enqueueSearch adds media to the search queue unless it is already queued or being
+// searched, and reports whether it was added. With wait, it blocks until the queue has
+// room; otherwise a full queue returns errSearchQueueFull.
+func (m *Manager) enqueueSearch(media models.Media, wait bool) (bool, error) {
+	m.queuedMu.Lock()
+	if m.queuedMedia[media.ID] {
+		m.queuedMu.Unlock()
+		m.logger.WithField("media_id", media.ID).Debug("Already in the search queue, not queuing again:", media.Title)
+		return false, nil
+	}
+	m.queuedMedia[media.ID] = true
+	m.queuedMu.Unlock()
+
+	if wait {
+		m.searchQueue <- media
+		return true, nil
+	}
+	select {
+	case m.searchQueue <- media:
+		return true, nil
+	default:
+		m.queuedMu.Lock()
+		delete(m.queuedMedia, media.ID)
+		m.queuedMu.Unlock()
+		return false, errSearchQueueFull
+	}
+}
+
+// ErrInvalidSetting is wrapped by the errors for an unknown quality profile or monitor
+// mode passed to AddMedia or UpdateMediaSettings, or specials included for a movie.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// AddMediaOptions describes a media item for AddMedia. An empty Language, or quality
+// range without a QualityProfile, takes the automation defaults.
+type AddMediaOptions struct {
+	Type            models.MediaType
+	ID              string // Metadata ID, pinning the exact title
+	Provider        string // Metadata provider the ID comes from
+	Title           string // Looked up when empty and an ID is given
+	Year            int
+	Language        string
+	MinQuality      string
+	MaxQuality      string
+	QualityProfile  string
+	AutoDownload    bool
+	DateBased       bool
+	IncludeSpecials bool
+	StartSeason     int
+	StartEpisode    int
+	MonitorMode     string
+}
+
+// AddMedia adds a movie, show or anime to the library. When the library already holds
+// the same title (see MediaRepository.FindExisting), that item is returned instead and
+// existing is true. Episodes before StartSeason/StartEpisode are skipped, and so are
+// those left out by MonitorMode (see config.MonitorModes). Without a starting episode or
+// a mode, automation.default_monitor_mode applies. Specials are skipped with
+// automation.ignore_specials, unless IncludeSpecials is set.
+func (m *Manager) AddMedia(opts AddMediaOptions) (media *models.Media, existing bool, err error) {
+	cfg := m.Config()
+	if opts.MonitorMode == "" && opts.StartSeason == 0 && opts.StartEpisode == 0 {
+		opts.MonitorMode = cfg.Automation.DefaultMonitorMode
+	}
+	if opts.MonitorMode != "" && !slices.Contains(config.MonitorModes, opts.MonitorMode) {
+		return nil, false, fmt.Errorf("%w: unknown monitor mode '%s' (use %s)", ErrInvalidSetting, opts.MonitorMode, strings.Join(config.MonitorModes, ", "))
+	}
+	if opts.IncludeSpecials && opts.Type == models.MediaTypeMovie {
+		return nil, false, fmt.Errorf("%w: movies have no specials to include", ErrInvalidSetting)
+	}
+	if opts.QualityProfile != "" {
+		if _, ok := cfg.QualityProfiles[opts.QualityProfile]; !ok {
+			return nil, false, fmt.Errorf("%w: unknown quality profile '%s'", ErrInvalidSetting, opts.QualityProfile)
+		}
+	} else {
+		if opts.MinQuality == "" {
+			opts.MinQuality = cfg.Automation.DefaultMinQuality
+		}
+		if opts.MaxQuality == "" {
+			opts.MaxQuality = cfg.Automation.DefaultMaxQuality
+		}
+	}
+	if opts.Language == "" {
+		opts.Language = cfg.Automation.DefaultLanguage
+	}
+	m.logger.Info("Parameters - Type:", opts.Type, "ID:", opts.ID, "Title:", opts.Title, "Year:", opts.Year, "StartSeason:", opts.StartSeason, "StartEpisode:", opts.StartEpisode, "MonitorMode:", opts.MonitorMode)
 
 	var overview, posterURL *string
 	var rating *float64
 	var tvShowData *metadata.TVShowResult
-	var metadataID *int
+	var tmdbID *int
+	var metadataProvider, metadataID string
+	var genres []string
 
-	m.logger.Info("Looking for metadata providers for type:", mediaType)
-	providers := m.metadataClients[mediaType]
+	m.logger.Info("Looking for metadata providers for type:", opts.Type)
+	providers := m.metadataProviders(opts.Type)
 	m.logger.Info("Found", len(providers), "metadata providers")
 
 	if len(providers) > 0 {
 		client := providers[0]
-		m.logger.Info("Using first metadata provider")
+		// An ID only makes sense to the provider that returned it
+		for _, candidate := range providers {
+			if opts.Provider != "" && candidate.Name() == opts.Provider {
+				client = candidate
+			}
+		}
+		m.logger.Info("Using metadata provider", client.Name())
 
-		switch mediaType {
+		switch opts.Type {
 		case models.MediaTypeMovie:
 			m.logger.Info("Processing movie metadata...")
-			movieData, err := client.SearchMovie(title, year)
+			var movieData []*metadata.MovieResult
+			var err error
+			if opts.ID != "" {
+				// An explicit ID pins the exact title; fall back to the title search if it fails
+				movie, idErr := client.GetMovieByID(opts.ID)
+				if idErr != nil {
+					m.logger.Warn("Movie lookup by ID", opts.ID, "failed, searching by title:", idErr)
+				} else {
+					movieData = []*metadata.MovieResult{movie}
+				}
+			}
+			if movieData == nil {
+				movieData, err = client.SearchMovie(opts.Title, opts.Year)
+			}
 			if err != nil {
 				m.logger.Error("Movie metadata search failed:", err)
 			} else if len(movieData) > 0 {
 				m.logger.Info("Movie metadata found - ID:", movieData[0].ID, "Title:", movieData[0].Title)
-				if tmdbID, parseErr := strconv.Atoi(movieData[0].ID); parseErr == nil {
-					metadataID = &tmdbID
-					m.logger.Info("Parsed TMDB ID:", *metadataID)
-				} else {
-					m.logger.Error("Failed to parse TMDB ID:", movieData[0].ID, "Error:", parseErr)
-				}
+				metadataProvider, metadataID = client.Name(), movieData[0].ID
 				overview = &movieData[0].Overview
 				posterURL = &movieData[0].PosterURL
 				rating = &movieData[0].Rating
-				if title == "" {
-					title = movieData[0].Title
+				genres = movieData[0].Genres
+				if opts.Title == "" {
+					opts.Title = movieData[0].Title
 				}
-				if year == 0 {
-					year = movieData[0].Year
+				if opts.Year == 0 {
+					opts.Year = movieData[0].Year
 				}
 				m.logger.Info("Movie data processed successfully")
 			} else {
@@ -367,51 +478,92 @@ func (m *Manager) AddMedia(mediaType models.MediaType, id string, title string,
 			}
 		case models.MediaTypeTVShow, models.MediaTypeAnime:
 			m.logger.Info("Processing TV show/anime metadata...")
-			tvShowDataSlice, err := client.SearchTVShow(title)
+			var tvShowDataSlice []*metadata.TVShowResult
+			var err error
+			if opts.ID != "" {
+				show, idErr := client.GetTVShowByID(opts.ID)
+				if idErr != nil {
+					m.logger.Warn("TV show/anime lookup by ID", opts.ID, "failed, searching by title:", idErr)
+				} else {
+					tvShowDataSlice = []*metadata.TVShowResult{show}
+				}
+			}
+			if tvShowDataSlice == nil {
+				tvShowDataSlice, err = client.SearchTVShow(opts.Title)
+			}
 			if err != nil {
 				m.logger.Error("TV show/anime metadata search failed:", err)
 			} else if len(tvShowDataSlice) > 0 {
 				tvShowData = tvShowDataSlice[0]
 				m.logger.Info("TV show/anime metadata found - ID:", tvShowData.ID, "Title:", tvShowData.Title)
+				metadataProvider, metadataID = client.Name(), tvShowData.ID
 				overview = &tvShowData.Overview
 				posterURL = &tvShowData.PosterURL
 				rating = &tvShowData.Rating
-				if title == "" {
-					title = tvShowData.Title
+				genres = tvShowData.Genres
+				if opts.Title == "" {
+					opts.Title = tvShowData.Title
 				}
-				if year == 0 {
-					year = tvShowData.Year
+				if opts.Year == 0 {
+					opts.Year = tvShowData.Year
 				}
 				m.logger.Info("TV show/anime data processed successfully")
 			} else {
 				m.logger.Info("No TV show/anime metadata found")
 			}
 		}
+	} else {
+		m.logger.Warn("No metadata provider configured for", opts.Type, "- adding", opts.Title, "without metadata")
+	}
+	if metadataProvider == "tmdb" {
+		if tmdb, err := strconv.Atoi(metadataID); err == nil {
+			tmdbID = &tmdb
+		}
+	}
+
+	// The metadata lookup resolves title variants to the same ID, title and year, so
+	// check for the item before any rows are created
+	titles := []string{opts.Title}
+	if tvShowData != nil {
+		titles = append(titles, tvShowData.Title)
+	}
+	duplicate, err := m.mediaRepo.FindExisting(opts.Type, metadataProvider, metadataID, titles, opts.Year)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check for existing media: %w", err)
+	}
+	if duplicate != nil {
+		m.logger.Info("Media already in library, returning existing item - ID:", duplicate.ID, "Title:", duplicate.Title)
+		return duplicate, true, nil
 	}
 
 	var tvShowID *int
-	if (mediaType == models.MediaTypeTVShow || mediaType == models.MediaTypeAnime) && tvShowData != nil {
+	if (opts.Type == models.MediaTypeTVShow || opts.Type == models.MediaTypeAnime) && tvShowData != nil {
 		m.logger.Info("Creating TV show/anime database entries...")
-		show := &models.TVShow{
-			Status:   tvShowData.Status,
-			TVmazeID: tvShowData.ID, // Using TVmazeID for both for now
+		show := &models.TVShow{Status: tvShowData.Status}
+		if metadataProvider == "tvmaze" {
+			show.TVmazeID = tvShowData.ID
 		}
 
 		m.logger.Info("Creating TV show/anime record...")
 		if err := m.mediaRepo.CreateTVShow(show); err != nil {
 			m.logger.Error("CRITICAL: Failed to create TV show/anime:", err)
-			return nil, fmt.Errorf("failed to create tv show/anime: %w", err)
+			return nil, false, fmt.Errorf("failed to create tv show/anime: %w", err)
 		}
 		m.logger.Info("TV show/anime created with ID:", show.ID)
 		tvShowID = &show.ID
 
+		today := time.Now().Format("2006-01-02")
+		latestSeason := 0
+		for seasonNum := range tvShowData.Seasons {
+			latestSeason = max(latestSeason, seasonNum)
+		}
 		m.logger.Info("Creating", len(tvShowData.Seasons), "seasons...")
 		for seasonNum, episodes := range tvShowData.Seasons {
 			m.logger.Info("Creating season", seasonNum, "with", len(episodes), "episodes")
 			season := &models.Season{ShowID: show.ID, SeasonNumber: seasonNum}
 			if err := m.mediaRepo.CreateSeason(season); err != nil {
 				m.logger.Error("CRITICAL: Failed to create season:", seasonNum, "Error:", err)
-				return nil, fmt.Errorf("failed to create season: %w", err)
+				return nil, false, fmt.Errorf("failed to create season: %w", err)
 			}
 			m.logger.Info("Season", seasonNum, "created with ID:", season.ID)
 
@@ -423,7 +575,16 @@ func (m *Manager) AddMedia(mediaType models.MediaType, id string, title string,
 						status = models.StatusTBA
 					}
 				}
-				if seasonNum < startSeason || (seasonNum == startSeason && ep.EpisodeNumber < startEpisode) {
+				if seasonNum < opts.StartSeason || (seasonNum == opts.StartSeason && ep.EpisodeNumber < opts.StartEpisode) {
+					status = models.StatusSkipped
+				}
+				if opts.MonitorMode == config.MonitorFuture && ep.AirDate != "" && ep.AirDate < today {
+					status = models.StatusSkipped
+				}
+				if opts.MonitorMode == config.MonitorLatestSeason && seasonNum < latestSeason {
+					status = models.StatusSkipped
+				}
+				if seasonNum == 0 && cfg.IgnoresSpecials() && !opts.IncludeSpecials {
 					status = models.StatusSkipped
 				}
 				episode := &models.Episode{
@@ -435,7 +596,7 @@ func (m *Manager) AddMedia(mediaType models.MediaType, id string, title string,
 				}
 				if err := m.mediaRepo.CreateEpisode(episode); err != nil {
 					m.logger.Error("CRITICAL: Failed to create episode:", ep.EpisodeNumber, "Error:", err)
-					return nil, fmt.Errorf("failed to create episode: %w", err)
+					return nil, false, fmt.Errorf("failed to create episode: %w", err)
 				}
 			}
 		}
@@ -443,89 +604,182 @@ func (m *Manager) AddMedia(mediaType models.MediaType, id string, title string,
 	}
 
 	m.logger.Info("Creating main media record...")
-	media := &models.Media{
-		Type:         mediaType,
-		TMDBId:       metadataID,
-		TVShowID:     tvShowID,
-		Title:        title,
-		Year:         year,
-		Language:     language,
-		MinQuality:   minQuality,
-		MaxQuality:   maxQuality,
-		Status:       models.StatusPending,
-		Overview:     overview,
-		PosterURL:    posterURL,
-		Rating:       rating,
-		AutoDownload: autoDownload,
-	}
-
-	m.logger.Info("About to create media record - TMDB ID:", metadataID, "TV Show ID:", tvShowID)
+	media = &models.Media{
+		Type:           opts.Type,
+		TMDBId:         tmdbID,
+		TVShowID:       tvShowID,
+		Title:          opts.Title,
+		Year:           opts.Year,
+		Language:       opts.Language,
+		MinQuality:     opts.MinQuality,
+		MaxQuality:     opts.MaxQuality,
+		QualityProfile: opts.QualityProfile,
+		Status:         models.StatusPending,
+		Overview:       overview,
+		PosterURL:      posterURL,
+		Rating:         rating,
+		AutoDownload:   opts.AutoDownload,
+		Monitored:      true,
+		DateBased:      opts.DateBased && opts.Type != models.MediaTypeMovie,
+	}
+	media.IncludeSpecials = opts.IncludeSpecials
+	media.MetadataProvider, media.MetadataID = metadataProvider, metadataID
+
+	m.logger.Info("About to create media record - Metadata ID:", metadataProvider, metadataID, "TV Show ID:", tvShowID)
 
 	if err := m.mediaRepo.Create(media); err != nil {
 		m.logger.Error("CRITICAL: Failed to create media entry:", err)
 		m.logger.Error("Media details - Title:", media.Title, "Type:", media.Type, "TMDB ID:", media.TMDBId, "TV Show ID:", media.TVShowID)
-		return nil, fmt.Errorf("failed to create media: %w", err)
+		return nil, false, fmt.Errorf("failed to create media: %w", err)
 	}
 
 	m.logger.Info("Media ID:", media.ID, "Title:", media.Title, "Type:", media.Type)
 
-	if autoDownload {
+	if len(genres) > 0 {
+		if err := m.mediaRepo.SetGenres(media.ID, genres); err != nil {
+			m.logger.Warn("Failed to store genres of", media.Title, ":", err)
+		} else {
+			media.Genres = genres
+		}
+	}
+
+	if opts.AutoDownload {
 		m.logger.Info("Adding to search queue...")
-		select {
-		case m.searchQueue <- *media:
+		if _, err := m.enqueueSearch(*media, false); err != nil {
+			m.logger.Error("Could not queue", media.Title, "for a search:", err)
+		} else {
 			m.logger.Info("Added to search queue successfully")
-		default:
-			m.logger.Error("Search queue is full!")
 		}
 	}
 
-	return media, nil
+	return media, false, nil
 }
 
 func (m *Manager) GetTVShowDetails(mediaID int) (*models.TVShow, error) {
 	return m.mediaRepo.GetTVShowByMediaID(mediaID)
 }
 
-func (m *Manager) searchAndDownloadMovie(media *models.Media) {
-	m.logger.Info("Starting automatic search for movie:", media.Title)
-	m.mediaRepo.UpdateStatus(media.ID, models.StatusSearching)
+// Grab is a release the automatic search picked for a movie (season and episode 0) or
+// an episode.
+type Grab struct {
+	Season  int                    `json:"season,omitempty"`
+	Episode int                    `json:"episode,omitempty"`
+	Torrent indexers.IndexerResult `json:"torrent"`
+}
+
+// DryRunSearch runs the automatic search for a media item (the movie, or the next pending
+// episodes of a show) and returns the releases it would grab, without adding them to the
+// torrent client or changing any status.
+func (m *Manager) DryRunSearch(id int) ([]Grab, error) {
+	media, err := m.mediaRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media %w", models.ErrNotFound)
+	}
+
+	var grabs []Grab
+	switch media.Type {
+	case models.MediaTypeMovie:
+		grabs = m.searchAndDownloadMovie(media, true)
+	case models.MediaTypeTVShow, models.MediaTypeAnime:
+		grabs = m.searchAndDownloadNextEpisode(media, true)
+	}
+	if grabs == nil {
+		grabs = []Grab{}
+	}
+	return grabs, nil
+}
+
+// searchAndDownloadMovie searches for a movie and downloads the best release. In a dry
+// run the release is only logged and returned, and the movie's status is left alone.
+func (m *Manager) searchAndDownloadMovie(media *models.Media, dryRun bool) []Grab {
+	logger := m.logger.WithField("media_id", media.ID)
+	if !dryRun && !m.hasFreeSpace(media.Type) {
+		logger.Debug("Skipping search for", media.Title, ": destination folder is low on disk space")
+		return nil
+	}
+	logger.Info("Starting automatic search for movie:", media.Title)
+	if !dryRun {
+		m.mediaRepo.UpdateStatus(media.ID, models.StatusSearching)
+	}
 
 	results, err := m.performSearch(media, 0, 0)
 	if err != nil {
-		m.logger.Error("Search failed for", media.Title, ":", err)
-		m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
-		return
+		logger.Error("Search failed for", media.Title, ":", err)
+		if !dryRun {
+			m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
+		}
+		return nil
 	}
 
-	bestTorrent := m.torrentSelector.SelectBestTorrent(media, results, 0, 0, []string{media.Title})
+	bestTorrent := m.torrentSelector.SelectBestTorrent(media, results, 0, 0, []string{media.Title}, m.selectionFacts(media, 0, 0))
 	if bestTorrent == nil {
-		m.logger.Info("No suitable torrent found for:", media.Title)
-		m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
-		return
+		logger.Info("No suitable torrent found for:", media.Title)
+		if !dryRun {
+			m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
+		}
+		return nil
 	}
 
-	m.StartDownload(media.ID, *bestTorrent)
+	if dryRun {
+		logger.Info("Dry run: would download", bestTorrent.Title, "from", bestTorrent.Indexer, "for", media.Title)
+	} else {
+		m.StartDownload(context.Background(), media.ID, *bestTorrent)
+	}
+	return []Grab{{Torrent: *bestTorrent}}
 }
 
-func (m *Manager) searchAndDownloadNextEpisode(media *models.Media) {
+// searchAndDownloadNextEpisode searches for the pending and failed episodes of a show and
+// downloads the best release of each, up to max_concurrent_downloads. In a dry run the
+// releases are only logged and returned, and no episode status changes.
+func (m *Manager) searchAndDownloadNextEpisode(media *models.Media, dryRun bool) []Grab {
+	cfg := m.Config()
+	logger := m.logger.WithField("media_id", media.ID)
+	if !dryRun && !m.hasFreeSpace(media.Type) {
+		logger.Debug("Skipping search for", media.Title, ": destination folder is low on disk space")
+		return nil
+	}
 	show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
 	if err != nil {
-		m.logger.Error("Could not get TV show details for", media.Title, ":", err)
-		return
+		logger.Error("Could not get TV show details for", media.Title, ":", err)
+		return nil
 	}
 
+	var grabs []Grab
 	downloadsStarted := 0
+	foundInLibrary := 0
+	defer func() {
+		if foundInLibrary > 0 {
+			m.updateShowProgress(media.ID)
+		}
+	}()
 	for _, season := range show.Seasons {
 		for _, episode := range season.Episodes {
-			if downloadsStarted >= m.config.Automation.MaxConcurrentDownloads {
-				return
+			if downloadsStarted >= cfg.Automation.MaxConcurrentDownloads {
+				return grabs
 			}
 			// Check for both "pending" and "failed" episodes to retry.
 			if episode.Status == models.StatusPending || episode.Status == models.StatusFailed {
-				m.logger.Info("Searching for episode:", media.Title, fmt.Sprintf("S%02dE%02d", season.SeasonNumber, episode.EpisodeNumber))
+				// A video copied into the library by hand doesn't need to be grabbed again
+				if cfg.Automation.ScanLibraryBeforeSearch {
+					if path, err := m.GetMediaFilePath(media.ID, season.SeasonNumber, episode.EpisodeNumber); err == nil {
+						logger.Info(fmt.Sprintf("S%02dE%02d of %s is already in the library, skipping search: %s",
+							season.SeasonNumber, episode.EpisodeNumber, media.Title, path))
+						if dryRun {
+							continue
+						}
+						m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, season.SeasonNumber, episode.EpisodeNumber, models.StatusDownloaded, nil, nil)
+						foundInLibrary++
+						continue
+					}
+				}
+
+				logger.Info("Searching for episode:", media.Title, fmt.Sprintf("S%02dE%02d", season.SeasonNumber, episode.EpisodeNumber))
 				results, err := m.performSearch(media, season.SeasonNumber, episode.EpisodeNumber)
 				if err != nil {
-					m.logger.Error("Episode search failed:", err)
+					logger.Error("Episode search failed:", err)
 					continue
 				}
 
@@ -539,35 +793,82 @@ func (m *Manager) searchAndDownloadNextEpisode(media *models.Media) {
 					}
 				}
 
-				bestTorrent := m.torrentSelector.SelectBestTorrent(media, results, season.SeasonNumber, episode.EpisodeNumber, searchTerms)
+				bestTorrent := m.torrentSelector.SelectBestTorrent(media, results, season.SeasonNumber, episode.EpisodeNumber, searchTerms, m.selectionFacts(media, season.SeasonNumber, episode.EpisodeNumber))
 				if bestTorrent != nil {
-					m.StartEpisodeDownload(media.ID, season.SeasonNumber, episode.EpisodeNumber, *bestTorrent)
+					grabs = append(grabs, Grab{Season: season.SeasonNumber, Episode: episode.EpisodeNumber, Torrent: *bestTorrent})
 					downloadsStarted++
+					if dryRun {
+						logger.Info("Dry run: would download", bestTorrent.Title, "from", bestTorrent.Indexer, "for",
+							media.Title, fmt.Sprintf("S%02dE%02d", season.SeasonNumber, episode.EpisodeNumber))
+						continue
+					}
+					m.autoStartEpisodeDownload(media.ID, season.SeasonNumber, episode.EpisodeNumber, *bestTorrent)
 					time.Sleep(5 * time.Second) // Add a 5-second delay between each download
 				}
 			}
 		}
 	}
 	if downloadsStarted == 0 {
-		m.logger.Info("No pending episodes to download for", media.Title)
+		logger.Info("No pending episodes to download for", media.Title)
 	}
+	return grabs
 }
 
-func (m *Manager) GetAllMedia() ([]models.Media, error) {
-
-	result, err := m.mediaRepo.GetAll()
+// GetAllMedia returns the library, or only the items of a genre when one is given.
+func (m *Manager) GetAllMedia(genre string) ([]models.Media, error) {
+	var result []models.Media
+	var err error
+	if genre != "" {
+		result, err = m.mediaRepo.GetByGenre(genre)
+	} else {
+		result, err = m.mediaRepo.GetAll()
+	}
 	if err != nil {
 		m.logger.Error("Manager.GetAllMedia: Repository error:", err)
 		return nil, err
 	}
 
+	m.transfersMu.Lock()
+	for i := range result {
+		if stats, ok := m.transfers[result[i].ID]; ok {
+			result[i].Transfer = &stats
+		}
+	}
+	m.transfersMu.Unlock()
+
+	genres, err := m.mediaRepo.GetAllGenres()
+	if err != nil {
+		m.logger.Error("Manager.GetAllMedia: Failed to get genres:", err)
+	}
+	for i := range result {
+		result[i].Genres = genres[result[i].ID]
+	}
+
+	// Attach the episode summary to shows so list views don't need the full episode tree
+	summaries, err := m.mediaRepo.GetEpisodeSummaries()
+	if err != nil {
+		m.logger.Error("Manager.GetAllMedia: Failed to get episode summaries:", err)
+		return result, nil
+	}
+	for i := range result {
+		if result[i].TVShowID == nil {
+			continue
+		}
+		summary, ok := summaries[*result[i].TVShowID]
+		result[i].MetadataIncomplete = !ok // Shows without episodes have no summary
+		result[i].PendingCount = &summary.PendingCount
+		result[i].DownloadedCount = &summary.DownloadedCount
+		result[i].NextAirDate = summary.NextAirDate
+	}
+
 	//m.logger.Info("Manager.GetAllMedia: Retrieved", len(result), "items from repository")
 	return result, nil
 }
 
 // pixelotes/reel/reel-912718c2894dddc773eede72733de790bc7912b3/internal/core/manager.go
 func (m *Manager) cleanupCompletedTorrents() {
-	if m.config.Automation.KeepTorrentsForDays <= 0 && m.config.Automation.KeepTorrentsSeedRatio <= 0 { // Modified line
+	cfg := m.Config()
+	if cfg.Automation.KeepTorrentsForDays <= 0 && cfg.Automation.KeepTorrentsSeedRatio <= 0 { // Modified line
 		return // Feature is disabled
 	}
 
@@ -577,27 +878,28 @@ func (m *Manager) cleanupCompletedTorrents() {
 		return
 	}
 
-	cleanupThreshold := time.Now().AddDate(0, 0, -m.config.Automation.KeepTorrentsForDays)
+	cleanupThreshold := time.Now().AddDate(0, 0, -cfg.Automation.KeepTorrentsForDays)
 
 	for _, media := range downloadedMedia {
 		if media.CompletedAt != nil && media.TorrentHash != nil {
-			status, err := m.torrentClient.GetTorrentStatus(*media.TorrentHash)
+			client := m.torrentClientFor(media.Type)
+			status, err := client.GetTorrentStatus(*media.TorrentHash)
 			if err != nil {
 				m.logger.Error("Failed to get torrent status for cleanup:", err)
 				continue
 			}
 
 			shouldDelete := false
-			if m.config.Automation.KeepTorrentsForDays > 0 && media.CompletedAt.Before(cleanupThreshold) {
+			if cfg.Automation.KeepTorrentsForDays > 0 && media.CompletedAt.Before(cleanupThreshold) {
 				shouldDelete = true
 			}
-			if m.config.Automation.KeepTorrentsSeedRatio > 0 && status.UploadRatio >= m.config.Automation.KeepTorrentsSeedRatio {
+			if cfg.Automation.KeepTorrentsSeedRatio > 0 && status.UploadRatio >= cfg.Automation.KeepTorrentsSeedRatio {
 				shouldDelete = true
 			}
 
 			if shouldDelete {
 				m.logger.Info("Cleaning up torrent for:", media.Title)
-				if err := m.torrentClient.RemoveTorrent(*media.TorrentHash); err != nil {
+				if err := client.RemoveTorrent(*media.TorrentHash); err != nil {
 					m.logger.Error("Failed to remove torrent from client:", err)
 				} else {
 					m.mediaRepo.UpdateStatus(media.ID, models.StatusArchived)
@@ -607,104 +909,610 @@ func (m *Manager) cleanupCompletedTorrents() {
 	}
 }
 
-func (m *Manager) StartScheduler() {
-	m.scheduler.AddFunc("@every 30m", m.processPendingMedia)
-	m.scheduler.AddFunc("@every 6h", m.checkForNewEpisodes)
-	m.scheduler.AddFunc("@every 10s", m.updateDownloadStatus)
-	m.scheduler.AddFunc("@every 1h", m.processRSSFeeds)
-	m.scheduler.AddFunc("@every 24h", m.cleanupCompletedTorrents)
-	m.scheduler.AddFunc("@every 1h", m.retryFailedDownloads)
-	m.scheduler.Start()
-	m.logger.Info("Scheduler started.")
-	go m.processPendingMedia()
-	go m.processRSSFeeds()
-}
-
-func (m *Manager) Stop() {
-	if m.scheduler != nil {
-		m.scheduler.Stop()
-	}
-}
-
-func (m *Manager) processPendingMedia() {
-	pendingMedia, err := m.mediaRepo.GetByStatus(models.StatusPending)
-	if err != nil {
-		m.logger.Error("Failed to get pending media:", err)
+// orphanGracePeriod is how long a download folder is left alone after its last change
+// before it can be considered orphaned.
+const orphanGracePeriod = 24 * time.Hour
+
+// cleanupOrphanedDownloads removes entries of the download folders that no torrent in
+// the download client and no tracked movie or episode refers to. Torrents are matched
+// by the content path and name the client reports, tracked items by their torrent name.
+// To stay on the safe side it does nothing if any of those lists can't be read, never
+// touches hidden entries, configured folders, entries changed within orphanGracePeriod
+// or entries that a symlink in a destination folder points into, and only logs an
+// orphan the first time it sees it: it is removed on the next run if it is still
+// orphaned then.
+func (m *Manager) cleanupOrphanedDownloads() {
+	cfg := m.Config()
+	if !cfg.Automation.CleanOrphanedDownloads {
+		return
 	}
 
-	failedMedia, err := m.mediaRepo.GetByStatus(models.StatusFailed)
-	if err != nil {
-		m.logger.Error("Failed to get failed media:", err)
+	var torrents []torrent.TorrentStatus
+	for _, client := range m.allTorrentClients() {
+		clientTorrents, err := client.ListTorrents()
+		if err != nil {
+			m.logger.Error("Orphaned download cleanup skipped, could not list torrents:", err)
+			return
+		}
+		torrents = append(torrents, clientTorrents...)
 	}
-
-	// New: Get all series that have at least one failed episode.
-	seriesWithFailedEpisodes, err := m.mediaRepo.GetSeriesWithFailedEpisodes()
+	trackedNames, err := m.mediaRepo.GetTorrentNames()
 	if err != nil {
-		m.logger.Error("Failed to get series with failed episodes:", err)
-	}
-
-	// Use a map to collect and de-duplicate all media items that need processing.
-	mediaMap := make(map[int]models.Media)
-	for _, item := range pendingMedia {
-		mediaMap[item.ID] = item
+		m.logger.Error("Orphaned download cleanup skipped, could not get tracked torrents:", err)
+		return
 	}
-	for _, item := range failedMedia {
-		mediaMap[item.ID] = item
+	known := make(map[string]bool)
+	contentPaths := make(map[string]bool)
+	for _, t := range torrents {
+		known[orphanKey(t.Name)] = true
+		if t.DownloadDir != "" && t.Name != "" {
+			contentPaths[filepath.Clean(filepath.Join(t.DownloadDir, t.Name))] = true
+		}
 	}
-	for _, item := range seriesWithFailedEpisodes {
-		mediaMap[item.ID] = item
+	for _, name := range trackedNames {
+		known[orphanKey(name)] = true
 	}
 
-	if len(mediaMap) > 0 {
-		m.logger.Info(fmt.Sprintf("Processing %d media items (pending, failed series, and series with failed episodes).", len(mediaMap)))
-		for _, media := range mediaMap {
-			if media.AutoDownload {
-				// We must create a copy of the media object to avoid a race condition
-				// when it is processed in the search queue worker goroutine.
-				mediaCopy := media
-				m.searchQueue <- mediaCopy
-			}
+	// Folders from the config, which may be nested in one another
+	protected := make(map[string]bool)
+	downloadFolders := orphanScanFolders(cfg)
+	for _, folder := range append(downloadFolders, cfg.Movies.DestinationFolder, cfg.TVShows.DestinationFolder, cfg.Anime.DestinationFolder, cfg.App.DataPath) {
+		if folder != "" {
+			protected[filepath.Clean(folder)] = true
 		}
 	}
-}
 
-func (m *Manager) checkForNewEpisodes() {
-	m.logger.Info("Checking for new episodes...")
-	media, err := m.mediaRepo.GetAll()
+	linked, err := libraryLinkTargets(cfg.Movies.DestinationFolder, cfg.TVShows.DestinationFolder, cfg.Anime.DestinationFolder)
 	if err != nil {
-		m.logger.Error("Failed to get all media for new episode check:", err)
+		m.logger.Error("Orphaned download cleanup skipped, could not read the library links:", err)
 		return
 	}
 
-	for _, item := range media {
-		if item.Type == models.MediaTypeTVShow || item.Type == models.MediaTypeAnime {
-			if item.Status == models.StatusMonitoring || item.Status == models.StatusPending {
-				provider := m.metadataClients[item.Type][0] // Assuming first provider
-				m.updateShowMetadata(&item, provider)
-			}
+	candidates := make(map[string]bool)
+	scanned := make(map[string]bool)
+	for _, folder := range downloadFolders {
+		if folder == "" || scanned[filepath.Clean(folder)] {
+			continue
 		}
-	}
-}
+		scanned[filepath.Clean(folder)] = true
 
-// pixelotes/reel/reel-912718c2894dddc773eede72733de790bc7912b3/internal/core/manager.go
-func (m *Manager) updateShowMetadata(media *models.Media, provider metadata.Client) {
-	m.logger.Info("Updating metadata for show:", media.Title)
-	remoteShowSlice, err := provider.SearchTVShow(media.Title)
-	if err != nil {
-		m.logger.Error("Failed to fetch remote show data for", media.Title, ":", err)
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			m.logger.Warn("Could not read download folder", folder, "for orphan cleanup:", err)
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(folder, entry.Name())
+			if strings.HasPrefix(entry.Name(), ".") || containsProtected(path, protected) || containsProtected(path, linked) {
+				continue
+			}
+			if contentPaths[filepath.Clean(path)] || known[orphanKey(entry.Name())] || known[orphanKey(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))] {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) < orphanGracePeriod {
+				continue
+			}
+
+			candidates[path] = true
+			if !m.orphanCandidates[path] {
+				m.logger.Info("Orphaned download found, it will be removed on the next cleanup if still orphaned:", path)
+				continue
+			}
+			if err := os.RemoveAll(path); err != nil {
+				m.logger.Error("Failed to remove orphaned download", path, ":", err)
+				continue
+			}
+			m.logger.Info("Removed orphaned download:", path)
+			delete(candidates, path)
+		}
+	}
+	m.orphanCandidates = candidates
+}
+
+// orphanScanFolders returns the folders downloads land in: the download path of every
+// download client (the global one and those of the sections that override it) and the
+// download folder of each section.
+func orphanScanFolders(cfg *config.Config) []string {
+	folders := []string{cfg.TorrentClient.DownloadPath}
+	for _, section := range torrentClientSections {
+		folders = append(folders, cfg.TorrentClientFor(string(section.mediaType)).DownloadPath)
+	}
+	return append(folders, cfg.Movies.DownloadFolder, cfg.TVShows.DownloadFolder, cfg.Anime.DownloadFolder)
+}
+
+// libraryLinkTargets returns the cleaned targets of the symlinks under the given
+// folders, which the symlink move method points into the download folders.
+func libraryLinkTargets(folders ...string) (map[string]bool, error) {
+	targets := make(map[string]bool)
+	for _, folder := range folders {
+		if folder == "" {
+			continue
+		}
+		err := filepath.WalkDir(folder, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == folder {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if entry.Type()&os.ModeSymlink == 0 {
+				return nil
+			}
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			targets[filepath.Clean(target)] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return targets, nil
+}
+
+// orphanKey normalizes a torrent or file name for matching: lowercase letters and
+// digits only, since clients and indexers format names slightly differently.
+func orphanKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// containsProtected reports whether path is, or contains, one of the protected folders.
+func containsProtected(path string, protected map[string]bool) bool {
+	path = filepath.Clean(path)
+	for folder := range protected {
+		if folder == path || strings.HasPrefix(folder, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pendingMediaInterval is how often pending media is queued for a search, and
+// newEpisodeCheckInterval how often shows are checked for new episodes.
+const (
+	pendingMediaInterval    = 30 * time.Minute
+	newEpisodeCheckInterval = 6 * time.Hour
+)
+
+// spreadOffset places a media item in the search spread window from a hash of its ID,
+// so each item keeps the same slot on every run. Multiplying by 2^32/φ (Fibonacci
+// hashing) spreads consecutive IDs evenly across the window.
+func spreadOffset(mediaID int, window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	hash := uint32(mediaID) * 2654435769
+	return time.Duration(float64(hash) / (1 << 32) * float64(window))
+}
+
+// spread runs fn for a media item at its offset in the task's spread window (see
+// spreadWindow). Without a window, fn runs right away.
+func (m *Manager) spread(mediaID int, window time.Duration, fn func()) {
+	offset := spreadOffset(mediaID, window)
+	if offset == 0 {
+		fn()
 		return
 	}
+	m.logger.WithField("media_id", mediaID).Debug("Scheduled in", offset.Round(time.Second))
+	time.AfterFunc(offset, fn)
+}
+
+// spreadWindow returns the window over which a task spreads its searches: the search
+// spread, capped to the task's interval so runs never overlap. A task with a cron
+// expression isn't spread, since it was scheduled for a chosen time.
+func (m *Manager) spreadWindow(task string) time.Duration {
+	cfg := m.Config()
+	if cfg.Automation.Schedules[task] != "" {
+		return 0
+	}
+	window := time.Duration(cfg.Automation.SearchSpreadMinutes) * time.Minute
+	if interval := defaultTaskIntervals[task]; window > interval {
+		window = interval
+	}
+	return window
+}
+
+// defaultTaskIntervals are how often the scheduled tasks run unless automation.schedules
+// gives them a cron expression.
+var defaultTaskIntervals = map[string]time.Duration{
+	config.TaskPendingSearch: pendingMediaInterval,
+	config.TaskNewEpisodes:   newEpisodeCheckInterval,
+	config.TaskStatusUpdate:  10 * time.Second,
+	config.TaskRSS:           time.Hour,
+	config.TaskCleanup:       24 * time.Hour,
+	config.TaskOrphanCleanup: 12 * time.Hour,
+	config.TaskRetry:         15 * time.Minute,
+	config.TaskHealthCheck:   healthCheckInterval,
+}
+
+// taskSchedule returns the cron spec of a scheduled task: its automation.schedules
+// entry, or its default interval.
+func (m *Manager) taskSchedule(task string) string {
+	if spec := m.Config().Automation.Schedules[task]; spec != "" {
+		return spec
+	}
+	return fmt.Sprintf("@every %s", defaultTaskIntervals[task])
+}
+
+// scheduleTasks (re)registers the scheduled tasks with their current schedules,
+// replacing those registered before, so a reloaded automation.schedules applies.
+func (m *Manager) scheduleTasks() {
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+
+	for _, entry := range m.taskEntries {
+		m.scheduler.Remove(entry)
+	}
+	m.taskEntries = m.taskEntries[:0]
+
+	tasks := []struct {
+		name string
+		run  func()
+	}{
+		{config.TaskPendingSearch, m.processPendingMedia},
+		{config.TaskNewEpisodes, m.checkForNewEpisodes},
+		{config.TaskStatusUpdate, m.updateDownloadStatus},
+		{config.TaskRSS, m.processRSSFeeds},
+		{config.TaskCleanup, m.cleanupCompletedTorrents},
+		{config.TaskOrphanCleanup, m.cleanupOrphanedDownloads},
+		{config.TaskRetry, m.retryFailedDownloads},
+		{config.TaskHealthCheck, func() { m.checkHealth() }},
+	}
+	for _, task := range tasks {
+		spec := m.taskSchedule(task.name)
+		entry, err := m.scheduler.AddFunc(spec, task.run)
+		if err != nil {
+			// Not expected: automation.schedules is checked when the config is loaded
+			m.logger.Error("Could not schedule", task.name, "with", spec, ":", err)
+			continue
+		}
+		m.taskEntries = append(m.taskEntries, entry)
+	}
+}
+
+// schedulerStarted reports whether StartScheduler registered the scheduled tasks.
+func (m *Manager) schedulerStarted() bool {
+	m.scheduleMu.Lock()
+	defer m.scheduleMu.Unlock()
+	return len(m.taskEntries) > 0
+}
+
+func (m *Manager) StartScheduler() {
+	m.scheduleTasks()
+	go m.checkHealth()
+	m.scheduler.Start()
+	m.logger.Info("Scheduler started.")
+	go m.processPendingMedia()
+	go m.processRSSFeeds()
+}
+
+func (m *Manager) Stop() {
+	if m.scheduler != nil {
+		m.scheduler.Stop()
+	}
+}
+
+func (m *Manager) processPendingMedia() {
+	pendingMedia, err := m.mediaRepo.GetByStatus(models.StatusPending)
+	if err != nil {
+		m.logger.Error("Failed to get pending media:", err)
+	}
+
+	// Failed media is not collected here: retryFailedDownloads re-queues it with backoff.
+
+	// New: Get all series that have at least one failed episode.
+	seriesWithFailedEpisodes, err := m.mediaRepo.GetSeriesWithFailedEpisodes()
+	if err != nil {
+		m.logger.Error("Failed to get series with failed episodes:", err)
+	}
+
+	// Use a map to collect and de-duplicate all media items that need processing.
+	mediaMap := make(map[int]models.Media)
+	for _, item := range pendingMedia {
+		mediaMap[item.ID] = item
+	}
+	// Failed episodes wait for the same backoff as failed media. A pending series is
+	// searched anyway, and a failed one is left to retryFailedDownloads.
+	now, maxRetries := time.Now(), m.Config().MaxRetries()
+	for _, item := range seriesWithFailedEpisodes {
+		if _, pending := mediaMap[item.ID]; pending || item.Status == models.StatusFailed {
+			continue
+		}
+		if item.AutoDownload && item.Monitored && m.retryDue(&item, now, maxRetries) {
+			mediaMap[item.ID] = item
+		}
+	}
+
+	if len(mediaMap) > 0 {
+		m.logger.Info(fmt.Sprintf("Processing %d media items (pending and series with failed episodes).", len(mediaMap)))
+		for _, media := range mediaMap {
+			if media.AutoDownload && media.Monitored && m.hasFreeSpace(media.Type) {
+				// We must create a copy of the media object to avoid a race condition
+				// when it is processed in the search queue worker goroutine.
+				mediaCopy := media
+				m.spread(media.ID, m.spreadWindow(config.TaskPendingSearch), func() { m.enqueueSearch(mediaCopy, true) })
+			}
+		}
+	}
+}
+
+// destinationFolder returns the library folder of a media type.
+func (m *Manager) destinationFolder(mediaType models.MediaType) string {
+	cfg := m.Config()
+	switch mediaType {
+	case models.MediaTypeMovie:
+		return cfg.Movies.DestinationFolder
+	case models.MediaTypeTVShow:
+		return cfg.TVShows.DestinationFolder
+	case models.MediaTypeAnime:
+		return cfg.Anime.DestinationFolder
+	}
+	return ""
+}
+
+// diskUsage reads the free space of a folder; tests replace it to simulate a full disk.
+var diskUsage = disk.Usage
+
+// hasFreeSpace reports whether the destination folder of a media type has at least
+// automation.min_free_space_mb free, so automatic downloads can start. Once a folder
+// runs low, a warning is logged and notifiers are told; the check keeps running on
+// every search, so downloads resume as soon as space is freed.
+func (m *Manager) hasFreeSpace(mediaType models.MediaType) bool {
+	minFreeMB := m.Config().Automation.MinFreeSpaceMB
+	path := m.destinationFolder(mediaType)
+	if minFreeMB <= 0 || path == "" {
+		return true
+	}
+
+	usage, err := diskUsage(path)
+	if err != nil {
+		// Without a reading, don't hold up downloads: the per-download check still applies.
+		m.logger.Warn("Failed to check free space of", path, ":", err)
+		return true
+	}
+	freeMB := usage.Free / (1024 * 1024)
+	low := freeMB < uint64(minFreeMB)
+
+	m.diskSpaceMu.Lock()
+	wasLow := m.lowDiskSpace[path]
+	if m.lowDiskSpace == nil {
+		m.lowDiskSpace = make(map[string]bool)
+	}
+	m.lowDiskSpace[path] = low
+	m.diskSpaceMu.Unlock()
+
+	switch {
+	case low && !wasLow:
+		m.logger.Warn(fmt.Sprintf("Only %d MB free in %s (minimum %d MB): pausing automatic downloads until space is freed", freeMB, path, minFreeMB))
+		for _, n := range m.notifierList() {
+			go n.NotifyLowDiskSpace(path, freeMB)
+		}
+	case !low && wasLow:
+		m.logger.Info(fmt.Sprintf("%d MB free in %s again: resuming automatic downloads", freeMB, path))
+	}
+	return !low
+}
 
-	if len(remoteShowSlice) == 0 {
-		m.logger.Error("No remote show data found for", media.Title)
+func (m *Manager) checkForNewEpisodes() {
+	m.logger.Info("Checking for new episodes...")
+	media, err := m.mediaRepo.GetAll()
+	if err != nil {
+		m.logger.Error("Failed to get all media for new episode check:", err)
 		return
 	}
-	remoteShow := remoteShowSlice[0]
+
+	skippedTypes := make(map[models.MediaType]bool)
+	for _, item := range media {
+		if item.Type == models.MediaTypeTVShow || item.Type == models.MediaTypeAnime {
+			if !item.Monitored {
+				continue
+			}
+			if item.Status == models.StatusMonitoring || item.Status == models.StatusPending {
+				providers := m.metadataProviders(item.Type)
+				if len(providers) == 0 {
+					if !skippedTypes[item.Type] {
+						skippedTypes[item.Type] = true
+						m.logger.Warn(fmt.Sprintf("No metadata provider for %s; skipping new-episode check", item.Type))
+					}
+					continue
+				}
+				show := item
+				provider, id := metadataSource(&show, providers)
+				m.spread(show.ID, m.spreadWindow(config.TaskNewEpisodes), func() {
+					if m.beginRefresh(show.ID) {
+						defer m.endRefresh(show.ID)
+						m.updateShowMetadata(&show, provider, id)
+					}
+				})
+			}
+		}
+	}
+}
+
+// ErrRefreshInProgress is returned by RefreshMetadata while the item's metadata is
+// already being refreshed.
+var ErrRefreshInProgress = errors.New("metadata refresh already in progress")
+
+// beginRefresh marks a media item's metadata as being refreshed. It returns false if a
+// refresh of the item is already running.
+func (m *Manager) beginRefresh(mediaID int) bool {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+	if m.refreshing[mediaID] {
+		return false
+	}
+	m.refreshing[mediaID] = true
+	return true
+}
+
+func (m *Manager) endRefresh(mediaID int) {
+	m.refreshMu.Lock()
+	delete(m.refreshing, mediaID)
+	m.refreshMu.Unlock()
+}
+
+// RefreshMetadata fetches a media item's metadata right away rather than on the next
+// scheduled check: the overview, poster, rating and genres, and for shows new seasons
+// and episodes. It returns the updated item; found is false if the item doesn't exist.
+func (m *Manager) RefreshMetadata(id int) (media *models.Media, found bool, err error) {
+	media, err = m.mediaRepo.GetByID(id)
+	if err != nil || media == nil {
+		return nil, false, err
+	}
+	if !m.beginRefresh(id) {
+		return nil, true, ErrRefreshInProgress
+	}
+	defer m.endRefresh(id)
+
+	providers := m.metadataProviders(media.Type)
+	if len(providers) == 0 {
+		return nil, true, fmt.Errorf("no metadata provider configured for %s", media.Type)
+	}
+	provider, metadataID := metadataSource(media, providers)
+	if media.Type == models.MediaTypeMovie {
+		err = m.updateMovieMetadata(media, provider, metadataID)
+	} else {
+		err = m.updateShowMetadata(media, provider, metadataID)
+	}
+	if err != nil {
+		return nil, true, err
+	}
+
+	media, err = m.mediaRepo.GetByID(id)
+	if err != nil || media == nil {
+		return nil, true, err
+	}
+	if genres, err := m.mediaRepo.GetAllGenres(); err == nil {
+		media.Genres = genres[id]
+	}
+	return media, true, nil
+}
+
+// metadataSource returns the provider to refresh a media item's metadata from, and the
+// item's ID there: the provider it was added from when that one is still configured
+// (TMDB for items that only have a tmdb_id), or else the first provider and no ID, for
+// a lookup by title.
+func metadataSource(media *models.Media, providers []metadata.Client) (metadata.Client, string) {
+	provider, id := media.MetadataProvider, media.MetadataID
+	if provider == "" && media.TMDBId != nil {
+		provider, id = "tmdb", strconv.Itoa(*media.TMDBId)
+	}
+	for _, client := range providers {
+		if provider != "" && client.Name() == provider {
+			return client, id
+		}
+	}
+	return providers[0], ""
+}
+
+// updateMovieMetadata refreshes a movie's metadata, looked up by its id at the provider
+// (see metadataSource) or else by title and year.
+func (m *Manager) updateMovieMetadata(media *models.Media, provider metadata.Client, id string) error {
+	m.logger.Info("Updating metadata for movie:", media.Title)
+	var movie *metadata.MovieResult
+	if id != "" {
+		found, err := provider.GetMovieByID(id)
+		if err != nil {
+			m.logger.Warn("Movie lookup by ID", id, "failed, searching by title:", err)
+		} else {
+			movie = found
+		}
+	}
+	if movie == nil {
+		results, err := provider.SearchMovie(media.Title, media.Year)
+		if err != nil {
+			return fmt.Errorf("failed to fetch movie data for %s: %w", media.Title, err)
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no movie data found for %s", media.Title)
+		}
+		movie = results[0]
+	}
+	return m.storeMetadata(media, movie.Overview, movie.PosterURL, movie.Rating, movie.Genres)
+}
+
+// storeMetadata saves refreshed descriptive metadata. Empty values keep what is stored.
+func (m *Manager) storeMetadata(media *models.Media, overview, posterURL string, rating float64, genres []string) error {
+	var overviewValue, posterValue *string
+	var ratingValue *float64
+	if overview != "" {
+		overviewValue = &overview
+	}
+	if posterURL != "" {
+		posterValue = &posterURL
+	}
+	if rating > 0 {
+		ratingValue = &rating
+	}
+	if err := m.mediaRepo.UpdateMetadata(media.ID, overviewValue, posterValue, ratingValue); err != nil {
+		return fmt.Errorf("failed to store metadata of %s: %w", media.Title, err)
+	}
+	if len(genres) > 0 {
+		if err := m.mediaRepo.SetGenres(media.ID, genres); err != nil {
+			m.logger.Warn("Failed to store genres of", media.Title, ":", err)
+		}
+	}
+	return nil
+}
+
+// updateShowMetadata refreshes a show's metadata and adds the seasons and episodes the
+// provider announced since the last check. The show is looked up by its id at the
+// provider (see metadataSource), or else by title.
+func (m *Manager) updateShowMetadata(media *models.Media, provider metadata.Client, id string) error {
+	m.logger.Info("Updating metadata for show:", media.Title)
+	var remoteShow *metadata.TVShowResult
+	if id != "" {
+		found, err := provider.GetTVShowByID(id)
+		if err != nil {
+			m.logger.Warn("Show lookup by ID", id, "failed, searching by title:", err)
+		} else {
+			remoteShow = found
+		}
+	}
+	if remoteShow == nil {
+		remoteShowSlice, err := provider.SearchTVShow(media.Title)
+		if err != nil {
+			m.logger.Error("Failed to fetch remote show data for", media.Title, ":", err)
+			return fmt.Errorf("failed to fetch show data for %s: %w", media.Title, err)
+		}
+
+		if len(remoteShowSlice) == 0 {
+			m.logger.Error("No remote show data found for", media.Title)
+			return fmt.Errorf("no show data found for %s", media.Title)
+		}
+		remoteShow = remoteShowSlice[0]
+	}
 
 	localShow, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
 	if err != nil {
 		m.logger.Error("Failed to get local show data for", media.Title, ":", err)
-		return
+		return err
+	}
+	if localShow == nil {
+		return fmt.Errorf("no show data stored for %s", media.Title)
+	}
+
+	if err := m.storeMetadata(media, remoteShow.Overview, remoteShow.PosterURL, remoteShow.Rating, remoteShow.Genres); err != nil {
+		m.logger.Error(err.Error())
+	}
+
+	// Keep the canonical status current so ended shows can be archived once complete
+	if remoteShow.Status != "" && remoteShow.Status != localShow.Status {
+		if err := m.mediaRepo.UpdateTVShowStatus(localShow.ID, remoteShow.Status); err != nil {
+			m.logger.Error("Failed to update show status for", media.Title, ":", err)
+		}
 	}
 
 	// Logic to compare and update seasons and episodes
@@ -745,6 +1553,9 @@ func (m *Manager) updateShowMetadata(media *models.Media, provider metadata.Clie
 						status = models.StatusTBA
 					}
 				}
+				if seasonNum == 0 && m.skipsSpecials(media) {
+					status = models.StatusSkipped
+				}
 				newEpisode := &models.Episode{
 					SeasonID:      localSeason.ID,
 					EpisodeNumber: remoteEpisode.EpisodeNumber,
@@ -754,12 +1565,12 @@ func (m *Manager) updateShowMetadata(media *models.Media, provider metadata.Clie
 				}
 				m.mediaRepo.CreateEpisode(newEpisode)
 				// If a new episode is found, set the media status to pending
-				if media.Status == models.StatusMonitoring {
+				if status != models.StatusSkipped && media.Status == models.StatusMonitoring {
 					m.mediaRepo.UpdateStatus(media.ID, models.StatusPending)
 				}
 			} else if localEpisode.Status == models.StatusTBA && remoteEpisode.AirDate != "" {
 				airDate, _ := time.Parse("2006-01-02", remoteEpisode.AirDate)
-				downloadDelay := time.Duration(m.config.Automation.EpisodeDownloadDelayHours) * time.Hour
+				downloadDelay := time.Duration(m.Config().Automation.EpisodeDownloadDelayHours) * time.Hour
 				if airDate.Add(downloadDelay).Before(time.Now()) {
 					m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, seasonNum, localEpisode.EpisodeNumber, models.StatusPending, nil, nil)
 					// If a TBA episode becomes available, set the media status to pending
@@ -771,6 +1582,55 @@ func (m *Manager) updateShowMetadata(media *models.Media, provider metadata.Clie
 		}
 	}
 	m.updateShowProgress(media.ID)
+	return nil
+}
+
+// skipsSpecials reports whether the specials (season 0) of a show are skipped.
+func (m *Manager) skipsSpecials(media *models.Media) bool {
+	return m.Config().IgnoresSpecials() && !media.IncludeSpecials
+}
+
+// setIncludeSpecials turns a show's specials on or off. Specials waiting to be searched
+// are skipped when they are turned off, and skipped ones are wanted again when they are
+// turned on (pending once aired, TBA before).
+func (m *Manager) setIncludeSpecials(media *models.Media, includeSpecials bool) error {
+	if err := m.mediaRepo.SetIncludeSpecials(media.ID, includeSpecials); err != nil {
+		return err
+	}
+	media.IncludeSpecials = includeSpecials
+	show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+	if err != nil || show == nil {
+		return err
+	}
+	skip := m.skipsSpecials(media)
+	wanted := false
+	for _, season := range show.Seasons {
+		if season.SeasonNumber != 0 {
+			continue
+		}
+		for _, episode := range season.Episodes {
+			status := episode.Status
+			switch {
+			case skip && (status == models.StatusPending || status == models.StatusTBA):
+				status = models.StatusSkipped
+			case !skip && status == models.StatusSkipped:
+				status = models.StatusPending
+				if airDate, err := time.Parse("2006-01-02", episode.AirDate); err == nil && airDate.After(time.Now()) {
+					status = models.StatusTBA
+				}
+				wanted = wanted || status == models.StatusPending
+			}
+			if status != episode.Status {
+				if err := m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, 0, episode.EpisodeNumber, status, nil, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if wanted && media.Status == models.StatusMonitoring {
+		return m.mediaRepo.UpdateStatus(media.ID, models.StatusPending)
+	}
+	return nil
 }
 
 func (m *Manager) updateShowProgress(mediaID int) {
@@ -783,10 +1643,11 @@ func (m *Manager) updateShowProgress(mediaID int) {
 		return // Not a show, nothing to do
 	}
 
-	var downloadableEpisodes, downloadedEpisodes, pendingEpisodes, downloadingEpisodes, tbaEpisodes int
+	var totalEpisodes, downloadableEpisodes, downloadedEpisodes, pendingEpisodes, downloadingEpisodes, tbaEpisodes int
 
 	for _, season := range show.Seasons {
 		for _, episode := range season.Episodes {
+			totalEpisodes++
 			// Count episodes for progress calculation
 			if episode.Status != models.StatusSkipped && episode.Status != models.StatusTBA {
 				downloadableEpisodes++
@@ -813,110 +1674,209 @@ func (m *Manager) updateShowProgress(mediaID int) {
 
 	// Determine the new overall status for the media item
 	var newStatus models.MediaStatus
-	if downloadingEpisodes > 0 {
+	if totalEpisodes == 0 {
+		// Without episodes there is nothing to call complete: keep monitoring so the next
+		// new-episode check refreshes the metadata
+		m.logger.WithField("media_id", mediaID).Warn("Show has no episodes, its metadata may be incomplete; keeping it monitored")
+		newStatus = models.StatusMonitoring
+	} else if downloadingEpisodes > 0 {
 		newStatus = models.StatusDownloading
 	} else if pendingEpisodes > 0 {
 		newStatus = models.StatusPending
 	} else {
 		if tbaEpisodes > 0 || strings.ToLower(show.Status) == "running" {
 			newStatus = models.StatusMonitoring
+		} else if strings.EqualFold(show.Status, "ended") {
+			// Nothing more will air: archive it so it stops being checked for new episodes
+			newStatus = models.StatusArchived
 		} else {
 			newStatus = models.StatusDownloaded
 		}
 	}
 
+	if newStatus == models.StatusArchived {
+		media, err := m.mediaRepo.GetByID(mediaID)
+		if err == nil && media != nil && media.Status != models.StatusArchived {
+			m.logger.WithField("media_id", mediaID).Info("Show has ended and is complete, archiving:", media.Title)
+			m.notifyShowComplete(media)
+		}
+	}
+
 	// Use the generic UpdateProgress which now handles status correctly
 	m.mediaRepo.UpdateProgress(mediaID, newStatus, progress, nil)
 	m.logger.Info("Updated show progress for Media ID", mediaID, "New Status:", newStatus, "Progress:", progress)
 }
 
+// TorrentCompleted handles a download client's completion callback. If a movie or an
+// episode is downloading the torrent, the download status is checked right away rather
+// than on the next poll, which also starts post-processing. It reports whether the
+// torrent was found.
+func (m *Manager) TorrentCompleted(hash string) (bool, error) {
+	downloading, err := m.mediaRepo.IsTorrentDownloading(hash)
+	if err != nil || !downloading {
+		return false, err
+	}
+	m.logger.WithField("torrent_hash", hash).Info("Download client reported a completed torrent, checking downloads")
+	go m.updateDownloadStatus()
+	return true, nil
+}
+
+// downloadComplete reports whether a torrent is done: the client says so, or the torrent
+// is in one of the client's completed states (a torrent with unselected files may never
+// reach 100%).
+func (m *Manager) downloadComplete(mediaType models.MediaType, status torrent.TorrentStatus) bool {
+	return status.IsCompleted || m.Config().TorrentClientFor(string(mediaType)).IsCompletedState(status.ClientState)
+}
+
 func (m *Manager) updateDownloadStatus() {
-	// Get all media items (movies or series) that have at least one active download.
-	downloadingMedia, err := m.mediaRepo.GetByStatus(models.StatusDownloading)
+	m.downloadStatusMu.Lock()
+	defer m.downloadStatusMu.Unlock()
+
+	transfers := make(map[int]models.TransferStats)
+	defer func() {
+		m.transfersMu.Lock()
+		m.transfers = transfers
+		m.transfersMu.Unlock()
+	}()
+
+	// The progress of unfinished downloads changes on every poll, so it is written in
+	// one transaction at the end rather than a statement per download. Completions and
+	// failures are still written right away.
+	var progress []models.ProgressUpdate
+	defer func() {
+		if err := m.mediaRepo.UpdateProgressBatch(progress); err != nil {
+			m.logger.Error("Failed to store download progress:", err)
+		}
+	}()
+
+	// Movies track their torrent on the media row itself.
+	downloadingMovies, err := m.mediaRepo.GetByStatus(models.StatusDownloading)
 	if err != nil {
 		m.logger.Error("Failed to get downloading media:", err)
 		return
 	}
 
-	for _, media := range downloadingMedia {
-		// --- Logic for Movies (remains the same) ---
-		if media.Type == models.MediaTypeMovie {
-			if media.TorrentHash == nil {
-				continue
-			}
-			status, err := m.torrentClient.GetTorrentStatus(*media.TorrentHash)
-			if err != nil {
-				m.logger.Error("Failed to get torrent status for", media.Title, ":", err)
-				m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
-				continue
-			}
+	for _, media := range downloadingMovies {
+		if media.Type != models.MediaTypeMovie || media.TorrentHash == nil {
+			continue
+		}
+		status, err := m.torrentClientFor(media.Type).GetTorrentStatus(*media.TorrentHash)
+		if err != nil {
+			m.logger.WithFields(map[string]interface{}{"media_id": media.ID, "torrent_hash": *media.TorrentHash}).Error("Failed to get torrent status for", media.Title, ":", err)
+			m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed)
+			continue
+		}
 
-			if status.IsCompleted {
-				var completedAt *time.Time
-				now := time.Now()
-				completedAt = &now
-				go m.postProcessor.ProcessDownload(media, status, 0, 0, status.DownloadDir)
-				m.mediaRepo.UpdateProgress(media.ID, models.StatusDownloaded, 1.0, completedAt)
-			} else {
-				m.mediaRepo.UpdateProgress(media.ID, models.StatusDownloading, status.Progress, nil)
-			}
-			continue // Move to the next media item
+		if m.downloadComplete(media.Type, status) {
+			var completedAt *time.Time
+			now := time.Now()
+			completedAt = &now
+			go m.processor().ProcessDownload(media, status, 0, 0, status.DownloadDir, false)
+			m.mediaRepo.UpdateProgress(media.ID, models.StatusDownloaded, 1.0, completedAt)
+		} else {
+			progress = append(progress, models.ProgressUpdate{MediaID: media.ID, Progress: status.Progress})
+			addTransfer(transfers, media.ID, status)
 		}
+	}
 
-		// --- New Per-Episode Logic for TV Shows & Anime ---
-		if media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime {
-			if media.TVShowID == nil {
-				continue
-			}
+	// Shows and anime track one torrent per episode. They are looked up by their
+	// episodes rather than the parent status, so a show whose overall status has
+	// moved on still gets its in-flight episodes polled.
+	downloadingShows, err := m.mediaRepo.GetSeriesWithDownloadingEpisodes()
+	if err != nil {
+		m.logger.Error("Failed to get series with downloading episodes:", err)
+		return
+	}
 
-			// Get full show details once to map season IDs to season numbers
-			show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
-			if err != nil {
-				m.logger.Error("Could not get show details for status update:", err)
-				continue
-			}
-			seasonMap := make(map[int]int)
-			for _, s := range show.Seasons {
-				seasonMap[s.ID] = s.SeasonNumber
-			}
+	for _, media := range downloadingShows {
+		progress = append(progress, m.updateEpisodeDownloadStatus(media, transfers)...)
+	}
+}
 
-			// Get all individual episodes for this series that are in a 'downloading' state.
-			downloadingEpisodes, err := m.mediaRepo.GetDownloadingEpisodesForShow(*media.TVShowID)
-			if err != nil {
-				m.logger.Error("Could not get downloading episodes for show:", media.Title, err)
-				continue
-			}
+// addTransfer adds a torrent's rates to the media's transfer stats. Rates add up
+// across a show's episodes, and the ETA is that of the slowest one.
+func addTransfer(transfers map[int]models.TransferStats, mediaID int, status torrent.TorrentStatus) {
+	stats := transfers[mediaID]
+	stats.DownloadRate += status.DownloadRate
+	stats.UploadRate += status.UploadRate
+	if status.ETA > stats.ETA {
+		stats.ETA = status.ETA
+	}
+	stats.State = torrent.MostUrgentState(stats.State, status.State)
+	transfers[mediaID] = stats
+}
 
-			// Loop through each downloading episode and check its unique hash.
-			for _, episode := range downloadingEpisodes {
-				if episode.TorrentHash == nil {
-					continue
-				}
+// updateEpisodeDownloadStatus polls the torrent client for every downloading episode
+// of a show using each episode's own hash, so concurrent episode downloads complete
+// independently of one another. The transfer rates of unfinished episodes are added
+// to transfers, and their progress is returned for the caller to store.
+func (m *Manager) updateEpisodeDownloadStatus(media models.Media, transfers map[int]models.TransferStats) (progress []models.ProgressUpdate) {
+	logger := m.logger.WithField("media_id", media.ID)
+	if media.TVShowID == nil {
+		return nil
+	}
 
-				status, err := m.torrentClient.GetTorrentStatus(*episode.TorrentHash)
-				if err != nil {
-					m.logger.Error("Failed to get torrent status for episode:", media.Title, episode.Title, err)
-					// Mark this specific episode as failed
-					seasonNum := seasonMap[episode.SeasonID]
-					m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, seasonNum, episode.EpisodeNumber, models.StatusFailed, nil, nil)
-					continue
-				}
+	// Get full show details once to map season IDs to season numbers
+	show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+	if err != nil || show == nil {
+		logger.Error("Could not get show details for status update:", err)
+		return nil
+	}
+	seasonMap := make(map[int]int)
+	for _, s := range show.Seasons {
+		seasonMap[s.ID] = s.SeasonNumber
+	}
+
+	downloadingEpisodes, err := m.mediaRepo.GetDownloadingEpisodesForShow(*media.TVShowID)
+	if err != nil {
+		logger.Error("Could not get downloading episodes for show:", media.Title, err)
+		return nil
+	}
+
+	processedPacks := make(map[string]bool)
+	countedTransfers := make(map[string]bool)
+
+	for _, episode := range downloadingEpisodes {
+		if episode.TorrentHash == nil {
+			continue
+		}
+		seasonNum := seasonMap[episode.SeasonID]
+		episodeLabel := fmt.Sprintf("S%02dE%02d", seasonNum, episode.EpisodeNumber)
+		episodeLogger := logger.WithField("torrent_hash", *episode.TorrentHash)
+
+		status, err := m.torrentClientFor(media.Type).GetTorrentStatus(*episode.TorrentHash)
+		if err != nil {
+			episodeLogger.Error("Failed to get torrent status for episode:", media.Title, episodeLabel, err)
+			m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, seasonNum, episode.EpisodeNumber, models.StatusFailed, nil, nil)
+			continue
+		}
 
-				if status.IsCompleted {
-					m.logger.Info("Episode download completed:", media.Title, fmt.Sprintf("S%02dE%02d", seasonMap[episode.SeasonID], episode.EpisodeNumber))
-					// Post-process this specific, completed episode
-					go m.postProcessor.ProcessDownload(media, status, seasonMap[episode.SeasonID], episode.EpisodeNumber, status.DownloadDir)
-					// Update this specific episode's status to downloaded
-					m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, seasonMap[episode.SeasonID], episode.EpisodeNumber, models.StatusDownloaded, nil, nil)
+		if m.downloadComplete(media.Type, status) {
+			episodeLogger.Info("Episode download completed:", media.Title, episodeLabel)
+			m.mediaRepo.UpdateEpisodeProgress(episode.ID, 1.0)
+			if episode.Pack {
+				// Process the pack once; its files are named after their own episode numbers
+				if !processedPacks[*episode.TorrentHash] {
+					processedPacks[*episode.TorrentHash] = true
+					go m.processor().ProcessDownload(media, status, seasonNum, 0, status.DownloadDir, false)
 				}
-				// If not complete, we don't need to do anything here.
-				// The overall show progress will be updated below.
+			} else {
+				go m.processor().ProcessDownload(media, status, seasonNum, episode.EpisodeNumber, status.DownloadDir, m.replacesRelease(media.ID, seasonNum, episode.EpisodeNumber))
+			}
+			m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, seasonNum, episode.EpisodeNumber, models.StatusDownloaded, episode.TorrentHash, episode.TorrentName)
+		} else {
+			progress = append(progress, models.ProgressUpdate{EpisodeID: episode.ID, Progress: status.Progress})
+			if !countedTransfers[*episode.TorrentHash] {
+				countedTransfers[*episode.TorrentHash] = true
+				addTransfer(transfers, media.ID, status)
 			}
-
-			// After checking all episodes for this show, update its overall progress and status.
-			m.updateShowProgress(media.ID)
 		}
 	}
+
+	// After checking all episodes for this show, update its overall progress and status.
+	// It follows from the episodes' statuses, so the pending progress writes don't matter.
+	m.updateShowProgress(media.ID)
+	return progress
 }
 
 func (m *Manager) DeleteMedia(id int) error {
@@ -933,6 +1893,9 @@ func (m *Manager) RetryMedia(id int) error {
 	}
 
 	if media.Status == models.StatusFailed {
+		if err := m.mediaRepo.ResetRetry(media.ID); err != nil {
+			return err
+		}
 		if err := m.mediaRepo.UpdateStatus(media.ID, models.StatusPending); err != nil {
 			return err
 		}
@@ -940,6 +1903,63 @@ func (m *Manager) RetryMedia(id int) error {
 	return nil
 }
 
+// Default and maximum number of items returned by GetRecent.
+const (
+	defaultRecentLimit = 20
+	maxRecentLimit     = 100
+)
+
+// GetRecent returns the newest media items ("added") or the latest completed movies and
+// episodes ("downloaded"). A limit of 0 uses the default; larger limits are capped.
+func (m *Manager) GetRecent(kind string, limit int) ([]models.RecentItem, error) {
+	if limit <= 0 {
+		limit = defaultRecentLimit
+	}
+	if limit > maxRecentLimit {
+		limit = maxRecentLimit
+	}
+
+	switch kind {
+	case "added":
+		return m.mediaRepo.GetRecentlyAdded(limit)
+	case "downloaded":
+		return m.mediaRepo.GetRecentlyDownloaded(limit)
+	}
+	return nil, fmt.Errorf("unknown type '%s', expected 'added' or 'downloaded'", kind)
+}
+
+// SearchAllPending queues every pending or monitored auto-download item for an immediate
+// search instead of waiting for the next scheduled pass. Items go through the search
+// queue worker, which paces indexer requests. It returns how many items were queued.
+func (m *Manager) SearchAllPending() (int, error) {
+	pendingMedia, err := m.mediaRepo.GetByStatus(models.StatusPending)
+	if err != nil {
+		return 0, err
+	}
+	monitoringMedia, err := m.mediaRepo.GetByStatus(models.StatusMonitoring)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, media := range append(pendingMedia, monitoringMedia...) {
+		if !media.AutoDownload || !media.Monitored {
+			continue
+		}
+		added, err := m.enqueueSearch(media, false)
+		if err != nil {
+			m.logger.Warn("Search queue is full, stopped queuing after", queued, "items")
+			return queued, nil
+		}
+		if added {
+			queued++
+		}
+	}
+
+	m.logger.Info(fmt.Sprintf("Queued %d media items for an immediate search.", queued))
+	return queued, nil
+}
+
 func (m *Manager) ClearFailedMedia() error {
 	failedMedia, err := m.mediaRepo.GetByStatus(models.StatusFailed)
 	if err != nil {
@@ -953,112 +1973,557 @@ func (m *Manager) ClearFailedMedia() error {
 	return nil
 }
 
+// Bounds for SearchMetadata, which queries every provider of a media type at once.
+const (
+	metadataSearchConcurrency = 3
+	metadataSearchTimeout     = 20 * time.Second
+)
+
+// SearchMetadata searches all metadata providers configured for the media type
+// concurrently and merges their results. Results keep the providers' order of
+// preference, are tagged with the provider they came from, and are deduplicated by
+// title and year. Providers that fail or don't answer in time are skipped; an error is
+// only returned when none of them answered.
 func (m *Manager) SearchMetadata(query string, mediaType string) ([]interface{}, error) {
-	providers := m.metadataClients[models.MediaType(mediaType)]
+	providers := m.metadataProviders(models.MediaType(mediaType))
 	if len(providers) == 0 {
 		return nil, fmt.Errorf("no metadata provider configured for '%s'", mediaType)
 	}
 
-	client := providers[0] // Use first provider
-	var results []interface{}
-	if mediaType == string(models.MediaTypeMovie) {
-		res, err := client.SearchMovie(query, 0)
-		if err != nil {
-			return nil, err
+	isMovie := mediaType == string(models.MediaTypeMovie)
+	if !isMovie && mediaType != string(models.MediaTypeTVShow) && mediaType != string(models.MediaTypeAnime) {
+		return nil, fmt.Errorf("unsupported media type for metadata search: %s", mediaType)
+	}
+
+	type providerResults struct {
+		results []interface{}
+		err     error
+		done    bool
+	}
+	// Buffered so late providers don't block once the deadline has passed
+	answers := make(chan int, len(providers))
+	collected := make([]providerResults, len(providers))
+	var mu sync.Mutex
+	semaphore := make(chan struct{}, metadataSearchConcurrency)
+
+	for i, client := range providers {
+		go func(i int, client metadata.Client) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			var results []interface{}
+			var err error
+			if isMovie {
+				var movies []*metadata.MovieResult
+				movies, err = client.SearchMovie(query, 0)
+				for _, movie := range movies {
+					movie.Provider = client.Name()
+					results = append(results, movie)
+				}
+			} else {
+				var shows []*metadata.TVShowResult
+				shows, err = client.SearchTVShow(query)
+				for _, show := range shows {
+					show.Provider = client.Name()
+					results = append(results, show)
+				}
+			}
+
+			mu.Lock()
+			collected[i] = providerResults{results: results, err: err, done: true}
+			mu.Unlock()
+			answers <- i
+		}(i, client)
+	}
+
+	deadline := time.After(metadataSearchTimeout)
+wait:
+	for range providers {
+		select {
+		case <-answers:
+		case <-deadline:
+			m.logger.Warn("Metadata search timed out for some providers:", query)
+			break wait
 		}
-		for _, r := range res {
-			results = append(results, r)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var merged []interface{}
+	var firstErr error
+	answered := false
+	seen := make(map[string]bool)
+	for i, provider := range collected {
+		if !provider.done {
+			continue
 		}
-	} else if mediaType == string(models.MediaTypeTVShow) || mediaType == string(models.MediaTypeAnime) {
-		res, err := client.SearchTVShow(query)
-		if err != nil {
-			return nil, err
+		if provider.err != nil {
+			m.logger.Warn("Metadata search failed for provider", providers[i].Name(), ":", provider.err)
+			if firstErr == nil {
+				firstErr = provider.err
+			}
+			continue
 		}
-		for _, r := range res {
-			results = append(results, r)
+		answered = true
+		for _, result := range provider.results {
+			var key string
+			switch r := result.(type) {
+			case *metadata.MovieResult:
+				key = fmt.Sprintf("%s|%d", utils.NormalizeTitle(r.Title), r.Year)
+			case *metadata.TVShowResult:
+				key = fmt.Sprintf("%s|%d", utils.NormalizeTitle(r.Title), r.Year)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, result)
 		}
-	} else {
+	}
+
+	if !answered {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("metadata search for '%s' timed out", query)
+	}
+	return merged, nil
+}
+
+// ResolveMetadataLink looks up the title a metadata link points to (see
+// metadata.ParseLink) with the provider the link belongs to, which must be configured
+// for the media type. IMDb links are tried with every provider of the type in order,
+// as some of them accept IMDb IDs. The result is a *metadata.MovieResult or
+// *metadata.TVShowResult tagged with the provider, like a SearchMetadata result.
+func (m *Manager) ResolveMetadataLink(link *metadata.Link, mediaType string) (interface{}, error) {
+	isMovie := mediaType == string(models.MediaTypeMovie)
+	if !isMovie && mediaType != string(models.MediaTypeTVShow) && mediaType != string(models.MediaTypeAnime) {
 		return nil, fmt.Errorf("unsupported media type for metadata search: %s", mediaType)
 	}
-	return results, nil
+	if (link.Kind == metadata.LinkMovie && !isMovie) || (link.Kind == metadata.LinkShow && isMovie) {
+		return nil, fmt.Errorf("the link points to a %s, not a %s", link.Kind, mediaType)
+	}
+
+	var clients []metadata.Client
+	for _, client := range m.metadataProviders(models.MediaType(mediaType)) {
+		if link.Provider == "imdb" || client.Name() == link.Provider {
+			clients = append(clients, client)
+		}
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("%s is not configured as a metadata provider for '%s'", link.Provider, mediaType)
+	}
+
+	var lastErr error
+	for _, client := range clients {
+		if isMovie {
+			movie, err := client.GetMovieByID(link.ID)
+			if err == nil {
+				movie.Provider = client.Name()
+				return movie, nil
+			}
+			lastErr = err
+		} else {
+			show, err := client.GetTVShowByID(link.ID)
+			if err == nil {
+				show.Provider = client.Name()
+				return show, nil
+			}
+			lastErr = err
+		}
+		m.logger.Debug("Metadata lookup of", link.ID, "failed for provider", client.Name(), ":", lastErr)
+	}
+	return nil, lastErr
 }
 
+// GetSystemStatus returns the result of the latest background health check, running
+// one first if none has completed yet.
 func (m *Manager) GetSystemStatus() (*SystemStatus, error) {
+	m.healthMu.Lock()
+	status := m.healthStatus
+	m.healthMu.Unlock()
+	if status == nil {
+		status = m.checkHealth()
+	}
+	return m.withTrippedIndexers(status), nil
+}
+
+// RefreshSystemStatus checks the clients right away instead of waiting for the next
+// scheduled check, and returns the new status.
+func (m *Manager) RefreshSystemStatus() (*SystemStatus, error) {
+	return m.withTrippedIndexers(m.checkHealth()), nil
+}
+
+// withTrippedIndexers returns a copy of status marking the indexers searches skip right
+// now, which changes between health checks.
+func (m *Manager) withTrippedIndexers(status *SystemStatus) *SystemStatus {
+	marked := *status
+	marked.IndexerClients = make(map[string]ClientStatus, len(status.IndexerClients))
+	for url, indexerStatus := range status.IndexerClients {
+		if until, tripped := m.indexerTrippedUntil(url); tripped {
+			indexerStatus.TrippedUntil = &until
+		}
+		marked.IndexerClients[url] = indexerStatus
+	}
+	return &marked
+}
+
+// healthKey identifies a client in the health history: the download clients by section
+// ("" for the global one) and type, so a new type starts a new history, and the
+// indexers by URL.
+func healthKey(kind, name, clientType string) string {
+	return kind + ":" + name + ":" + clientType
+}
+
+// checkHealth checks the download client and every indexer, and stores the result
+// with each client's recent history for GetSystemStatus. The history is also kept in
+// the database, so it survives restarts.
+func (m *Manager) checkHealth() *SystemStatus {
+	m.healthCheckMu.Lock()
+	defer m.healthCheckMu.Unlock()
+
+	m.healthMu.Lock()
+	history := m.healthHistory
+	m.healthMu.Unlock()
+	if history == nil {
+		var err error
+		if history, err = m.mediaRepo.GetHealthHistory(); err != nil {
+			m.logger.Error("Failed to load the health check history:", err)
+			history = make(map[string][]models.HealthCheck)
+		}
+	}
+
+	cfg := m.Config()
+	torrentClient, sectionTorrentClients := m.downloadClients()
+	now := time.Now()
 	status := &SystemStatus{
 		IndexerClients:  make(map[string]ClientStatus),
 		MetadataClients: []string{},
+		CheckedAt:       now,
+	}
+	checks := make(map[string]bool)
+	newHistory := make(map[string][]models.HealthCheck)
+	record := func(key string, clientStatus *ClientStatus) {
+		clientStatus.recordCheck(history[key])
+		checks[key] = clientStatus.Status
+		newHistory[key] = clientStatus.History
 	}
 
 	// Torrent Client Status
-	torrentStatus, _ := m.torrentClient.HealthCheck()
+	torrentStatus, _ := torrentClient.HealthCheck()
 	status.TorrentClient = ClientStatus{
-		Type:   m.config.TorrentClient.Type,
-		Status: torrentStatus,
+		Type:        cfg.TorrentClient.Type,
+		Status:      torrentStatus,
+		LastChecked: now,
 	}
+	record(healthKey("torrent", "", cfg.TorrentClient.Type), &status.TorrentClient)
 
-	// Indexer Clients Status (deduplicated)
-	uniqueIndexers := make(map[string]config.SourceConfig)
-	allSources := append(m.config.Movies.Sources, m.config.TVShows.Sources...)
-	allSources = append(allSources, m.config.Anime.Sources...)
-	for _, source := range allSources {
-		if source.Type != "rss" {
-			uniqueIndexers[source.URL] = source
+	for _, section := range torrentClientSections {
+		client, ok := sectionTorrentClients[section.mediaType]
+		if !ok {
+			continue
+		}
+		healthy, _ := client.HealthCheck()
+		sectionStatus := ClientStatus{
+			Type:        cfg.TorrentClientFor(string(section.mediaType)).Type,
+			Status:      healthy,
+			LastChecked: now,
+		}
+		record(healthKey("torrent", section.name, sectionStatus.Type), &sectionStatus)
+		if status.SectionTorrentClients == nil {
+			status.SectionTorrentClients = make(map[string]ClientStatus)
+		}
+		status.SectionTorrentClients[section.name] = sectionStatus
+	}
+
+	// Indexer Clients Status (deduplicated by URL, reusing the configured clients)
+	for _, clients := range m.indexers() {
+		for _, clientWithMode := range clients {
+			source := clientWithMode.Source
+			if _, seen := status.IndexerClients[source.URL]; seen {
+				continue
+			}
+			ok, _ := clientWithMode.Client.HealthCheck()
+			caps, _ := clientWithMode.Client.Capabilities()
+
+			indexerStatus := ClientStatus{
+				Type:         source.Type,
+				Name:         source.Label(),
+				Status:       ok,
+				Capabilities: caps,
+				LastChecked:  now,
+			}
+			record(healthKey("indexer", source.URL, ""), &indexerStatus)
+			status.IndexerClients[source.URL] = indexerStatus
+			if ok {
+				// A healthy indexer is searched again without waiting for its cooldown
+				m.resetIndexerFailures(source)
+			}
+		}
+	}
+	if err := m.mediaRepo.AddHealthChecks(checks, now, healthHistoryLength); err != nil {
+		m.logger.Error("Failed to store the health checks:", err)
+	}
+
+	// Metadata Clients
+	uniqueProviders := make(map[string]bool)
+	for _, provider := range cfg.Movies.Providers {
+		uniqueProviders[provider] = true
+	}
+	for _, provider := range cfg.TVShows.Providers {
+		uniqueProviders[provider] = true
+	}
+	for _, provider := range cfg.Anime.Providers {
+		uniqueProviders[provider] = true
+	}
+	for provider := range uniqueProviders {
+		status.MetadataClients = append(status.MetadataClients, provider)
+	}
+
+	m.healthMu.Lock()
+	m.healthStatus = status
+	m.healthHistory = newHistory
+	m.healthMu.Unlock()
+	return status
+}
+
+// CheckDatabase verifies the database connection is usable.
+func (m *Manager) CheckDatabase() error {
+	var one int
+	return m.db.QueryRow("SELECT 1").Scan(&one)
+}
+
+// CheckReadiness reports whether the torrent client is reachable and at least one
+// search indexer is healthy. Results are cached briefly so frequent probes stay cheap.
+// Setups without search indexers (e.g. RSS only) only depend on the torrent client.
+func (m *Manager) CheckReadiness() ReadinessStatus {
+	m.readinessMu.Lock()
+	defer m.readinessMu.Unlock()
+
+	if m.readinessCache != nil && time.Now().Before(m.readinessExpiry) {
+		return *m.readinessCache
+	}
+
+	status := ReadinessStatus{CheckedAt: time.Now()}
+	if global, sections := m.downloadClients(); global != nil {
+		status.TorrentClient, _ = global.HealthCheck()
+		for _, client := range sections {
+			if !status.TorrentClient {
+				break
+			}
+			status.TorrentClient, _ = client.HealthCheck()
+		}
+	}
+
+	checked := make(map[string]bool)
+	for _, clients := range m.indexers() {
+		for _, clientWithMode := range clients {
+			if checked[clientWithMode.Source.URL] {
+				continue
+			}
+			checked[clientWithMode.Source.URL] = true
+			if ok, _ := clientWithMode.Client.HealthCheck(); ok {
+				status.IndexerHealthy = true
+				break
+			}
+		}
+		if status.IndexerHealthy {
+			break
+		}
+	}
+	if len(checked) == 0 {
+		status.IndexerHealthy = true
+	}
+
+	status.Ready = status.TorrentClient && status.IndexerHealthy
+	m.readinessCache = &status
+	m.readinessExpiry = status.CheckedAt.Add(readinessCacheTTL)
+	return status
+}
+
+// SeasonDownload summarizes an on-demand season download.
+type SeasonDownload struct {
+	Season       int   `json:"season"`
+	Episodes     []int `json:"episodes"`      // Missing episodes the download covers
+	EpisodeLimit int   `json:"episode_limit"` // Most single episodes started when no season pack is found
+}
+
+// DownloadSeason grabs every missing episode of a season, whether or not the show is
+// monitored: skipped episodes are set back to pending, then a season pack is searched
+// for on every source and, if none is found, the episodes are searched one by one (up
+// to max_concurrent_downloads of them). The search runs in the background; the returned
+// summary lists the episodes it covers.
+func (m *Manager) DownloadSeason(mediaID, seasonNumber int) (*SeasonDownload, error) {
+	media, err := m.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media %w", models.ErrNotFound)
+	}
+	if media.Type != models.MediaTypeTVShow && media.Type != models.MediaTypeAnime {
+		return nil, fmt.Errorf("media is not a TV show or anime")
+	}
+
+	show, err := m.mediaRepo.GetTVShowByMediaID(mediaID)
+	if err != nil || show == nil {
+		return nil, fmt.Errorf("could not get show details: %v", err)
+	}
+
+	summary := &SeasonDownload{Season: seasonNumber, Episodes: []int{}, EpisodeLimit: m.Config().Automation.MaxConcurrentDownloads}
+	found := false
+	for _, season := range show.Seasons {
+		if season.SeasonNumber != seasonNumber {
+			continue
+		}
+		found = true
+		for _, episode := range season.Episodes {
+			switch episode.Status {
+			case models.StatusSkipped:
+				m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episode.EpisodeNumber, models.StatusPending, nil, nil)
+				summary.Episodes = append(summary.Episodes, episode.EpisodeNumber)
+			case models.StatusPending, models.StatusFailed:
+				summary.Episodes = append(summary.Episodes, episode.EpisodeNumber)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("season %d not found", seasonNumber)
+	}
+
+	if len(summary.Episodes) > 0 {
+		m.logger.WithField("media_id", mediaID).Info(fmt.Sprintf("Season %d download requested for %s: %d missing episodes", seasonNumber, media.Title, len(summary.Episodes)))
+		go m.searchAndDownloadSeason(media, seasonNumber, summary.Episodes)
+	}
+	return summary, nil
+}
+
+// searchAndDownloadSeason downloads the given episodes of a season, as a season pack
+// when one is found or else one episode at a time.
+func (m *Manager) searchAndDownloadSeason(media *models.Media, seasonNumber int, episodes []int) {
+	logger := m.logger.WithFields(map[string]interface{}{"media_id": media.ID, "season": seasonNumber})
+
+	searchTerms := []string{media.Title}
+	if media.Type == models.MediaTypeAnime {
+		animeSearchTerms, err := m.mediaRepo.GetAnimeSearchTerms(media.ID)
+		if err == nil {
+			for _, term := range animeSearchTerms {
+				searchTerms = append(searchTerms, term.Term)
+			}
+		}
+	}
+
+	if pack := m.findSeasonPack(media, seasonNumber, episodes[0], searchTerms); pack != nil {
+		logger.Info("Downloading season pack:", pack.Title)
+		err := m.StartEpisodeDownload(context.Background(), media.ID, seasonNumber, episodes[0], *pack)
+		if err == nil {
+			return
+		}
+		logger.Warn("Season pack download failed, searching episodes one by one:", err)
+	} else {
+		logger.Info(fmt.Sprintf("No season pack found for %s season %d, searching episodes one by one", media.Title, seasonNumber))
+	}
+
+	downloadsStarted := 0
+	for _, episodeNumber := range episodes {
+		if downloadsStarted >= m.Config().Automation.MaxConcurrentDownloads {
+			logger.Info(fmt.Sprintf("Started %d downloads, leaving the rest of season %d pending", downloadsStarted, seasonNumber))
+			return
+		}
+		episodeLabel := fmt.Sprintf("S%02dE%02d", seasonNumber, episodeNumber)
+		results, err := m.performSearch(media, seasonNumber, episodeNumber)
+		if err != nil {
+			logger.Error("Episode search failed for", episodeLabel, ":", err)
+			continue
+		}
+		bestTorrent := m.torrentSelector.SelectBestTorrent(media, results, seasonNumber, episodeNumber, searchTerms, m.selectionFacts(media, seasonNumber, episodeNumber))
+		if bestTorrent == nil {
+			logger.Info("No suitable torrent found for", media.Title, episodeLabel)
+			continue
+		}
+		if err := m.StartEpisodeDownload(context.Background(), media.ID, seasonNumber, episodeNumber, *bestTorrent); err != nil {
+			logger.Error("Failed to start download for", episodeLabel, ":", err)
+			continue
 		}
+		downloadsStarted++
+		time.Sleep(5 * time.Second) // Add a 5-second delay between each download
 	}
+}
 
-	for key, source := range uniqueIndexers {
-		var client indexers.Client
-		switch source.Type {
-		case "scarf":
-			client = indexers.NewScarfClient(source.URL, source.APIKey, 30*time.Second)
-		case "jackett":
-			client = indexers.NewJackettClient(source.URL, source.APIKey, m.httpClient.Timeout)
-		case "prowlarr":
-			client = indexers.NewProwlarrClient(source.URL, source.APIKey, m.httpClient.Timeout)
-		}
-		if client != nil {
-			ok, _ := client.HealthCheck()
-
-			// Parse the indexer name from the URL
-			var indexerName string
-			parsedURL, err := url.Parse(source.URL)
-			if err == nil {
-				pathParts := strings.Split(strings.Trim(parsedURL.Path, "/"), "/")
-				if len(pathParts) > 0 {
-					indexerName = pathParts[len(pathParts)-1]
-				}
+// findSeasonPack searches every source of the media type for packs of the season,
+// whatever its search mode, and returns the best one (nil if none passes the filters).
+func (m *Manager) findSeasonPack(media *models.Media, seasonNumber, episodeNumber int, searchTerms []string) *indexers.IndexerResult {
+	var packs []indexers.IndexerResult
+	for _, searchTerm := range searchTerms {
+		for _, clientWithMode := range m.indexers()[media.Type] {
+			if !clientWithMode.Source.SearchesMediaType(string(media.Type)) {
+				continue
 			}
-
-			status.IndexerClients[key] = ClientStatus{
-				Type:   source.Type,
-				Name:   indexerName,
-				Status: ok,
+			results, err := searchSeasonPacks(clientWithMode, searchTerm, seasonNumber)
+			if err != nil {
+				m.logger.Error("Season pack search failed for indexer", clientWithMode.Source.Label(), ":", err)
+				continue
+			}
+			for _, result := range results {
+				if !result.SeasonPack {
+					continue
+				}
+				result.Priority = clientWithMode.Source.Priority
+				result.Private = clientWithMode.Source.Private
+				if clientWithMode.Source.Type != "prowlarr" {
+					result.Indexer = clientWithMode.Source.Label()
+				}
+				packs = append(packs, result)
 			}
 		}
 	}
+	return m.torrentSelector.SelectBestTorrent(media, packs, seasonNumber, episodeNumber, searchTerms, m.selectionFacts(media, seasonNumber, episodeNumber))
+}
 
-	// Metadata Clients
-	uniqueProviders := make(map[string]bool)
-	for _, provider := range m.config.Movies.Providers {
-		uniqueProviders[provider] = true
+// selectionFacts looks up what the torrent selector needs to know about a movie or an
+// episode besides the media item: its blacklisted releases and, for date-based shows,
+// the episode's air date.
+func (m *Manager) selectionFacts(media *models.Media, season, episode int) SelectionFacts {
+	return SelectionFacts{
+		Blacklisted: m.blacklistedReleases(media),
+		AirDate:     m.episodeAirDate(media, season, episode),
 	}
-	for _, provider := range m.config.TVShows.Providers {
-		uniqueProviders[provider] = true
+}
+
+// blacklistedReleases returns the lower-cased titles of the media item's blacklisted
+// releases; nothing is filtered when they can't be read.
+func (m *Manager) blacklistedReleases(media *models.Media) map[string]bool {
+	blacklisted, err := m.mediaRepo.GetBlacklistedReleases(media.ID)
+	if err != nil {
+		m.logger.Error("Failed to get blacklisted releases for", media.Title, ":", err)
+		return nil
 	}
-	for _, provider := range m.config.Anime.Providers {
-		uniqueProviders[provider] = true
+	return blacklisted
+}
+
+// episodeAirDate returns the air date of an episode of a date-based show, or the zero
+// time for other media and for episodes without a known air date.
+func (m *Manager) episodeAirDate(media *models.Media, season, episode int) time.Time {
+	if !media.DateBased || season <= 0 || episode <= 0 {
+		return time.Time{}
 	}
-	for provider := range uniqueProviders {
-		status.MetadataClients = append(status.MetadataClients, provider)
+	ep, err := m.mediaRepo.GetEpisodeByDetails(media.ID, season, episode)
+	if err != nil || ep.AirDate == "" {
+		return time.Time{}
 	}
-
-	return status, nil
+	airDate, err := time.Parse("2006-01-02", ep.AirDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return airDate
 }
 
 func (m *Manager) performSearch(media *models.Media, season, episode int) ([]indexers.IndexerResult, error) {
-	clients := m.indexerClients[media.Type]
+	logger := m.logger.WithField("media_id", media.ID)
+	clients := m.indexers()[media.Type]
 	if len(clients) == 0 {
-		m.logger.Warn("No search-based indexers configured for media type:", media.Type)
+		logger.Warn("No search-based indexers configured for media type:", media.Type)
 		return nil, nil
 	}
 
@@ -1079,9 +2544,26 @@ func (m *Manager) performSearch(media *models.Media, season, episode int) ([]ind
 	if media.TMDBId != nil {
 		tmdbIDStr = strconv.Itoa(*media.TMDBId)
 	}
+	airDate := m.episodeAirDate(media, season, episode)
+	titleQuery := ""
+	if season > 0 && episode > 0 && airDate.IsZero() && media.Type != models.MediaTypeMovie {
+		if ep, err := m.mediaRepo.GetEpisodeByDetails(media.ID, season, episode); err == nil {
+			titleQuery = episodeTitleQuery(ep.Title)
+		}
+	}
 
-	for _, searchTerm := range searchTerms {
+	for i, searchTerm := range searchTerms {
+		if i > 0 {
+			time.Sleep(5 * time.Second) // 5-second delay between search terms
+		}
 		for _, clientWithMode := range clients {
+			if !clientWithMode.Source.SearchesMediaType(string(media.Type)) {
+				continue
+			}
+			if !m.allowIndexerSearch(clientWithMode.Source.URL) {
+				logger.Debug("Skipping indexer", clientWithMode.Source.Label(), "after repeated failures")
+				continue
+			}
 			client := clientWithMode.Client
 			searchMode := clientWithMode.Source.SearchMode
 
@@ -1089,14 +2571,24 @@ func (m *Manager) performSearch(media *models.Media, season, episode int) ([]ind
 			var err error
 
 			query := searchTerm
-			if media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime {
+			if !airDate.IsZero() {
+				// Date-based shows are searched by air date; there are no season packs to ask for
+				if searchMode == "season" {
+					searchMode = "search"
+				}
+				query = clientWithMode.Source.DailyEpisodeQuery(searchTerm, airDate)
+				results, err = client.SearchTVShows(query, 0, 0, searchMode)
+			} else if (media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime) && searchMode == "season" && season > 0 {
+				results, err = searchSeasonPacks(clientWithMode, searchTerm, season)
+			} else if media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime {
 				if searchMode == "search" && season > 0 && episode > 0 {
-					query = fmt.Sprintf("%s S%02dE%02d", searchTerm, season, episode)
+					query = clientWithMode.Source.EpisodeQuery(searchTerm, season, episode)
 				}
 				results, err = client.SearchTVShows(query, season, episode, searchMode)
 
-				// Fallback for "search" mode if no results are found
-				if len(results) == 0 && searchMode == "search" && season > 0 && episode > 0 {
+				// Fallback for "search" mode if no results are found, unless the source
+				// defines its own query format
+				if len(results) == 0 && searchMode == "search" && season > 0 && episode > 0 && clientWithMode.Source.QueryTemplate == "" {
 					query = fmt.Sprintf("%s %dx%02d", searchTerm, season, episode)
 					var fallbackResults []indexers.IndexerResult
 					fallbackResults, err = client.SearchTVShows(query, season, episode, searchMode)
@@ -1104,36 +2596,140 @@ func (m *Manager) performSearch(media *models.Media, season, episode int) ([]ind
 						results = append(results, fallbackResults...)
 					}
 				}
-			} else { // Movie
-				if media.Year > 0 {
-					query = fmt.Sprintf("%s %d", searchTerm, media.Year)
+
+				// Last, for sources indexing episodes by title, search for the episode's title
+				if len(results) == 0 && err == nil && titleQuery != "" && clientWithMode.Source.EpisodeTitleFallback {
+					query = searchTerm + " " + titleQuery
+					var titleResults []indexers.IndexerResult
+					titleResults, err = client.SearchTVShows(query, 0, 0, "search")
+					if err == nil {
+						results = episodeTitleResults(titleResults, titleQuery)
+					}
 				}
+			} else { // Movie
+				query = clientWithMode.Source.MovieQuery(searchTerm, media.Year)
 				results, err = client.SearchMovies(query, tmdbIDStr, searchMode)
 			}
 
+			m.recordIndexerSearch(clientWithMode.Source, err)
 			if err != nil {
-				m.logger.Error("Search failed for indexer:", err)
+				logger.Error("Search failed for indexer", clientWithMode.Source.Label(), ":", err)
 				continue
 			}
+			for i := range results {
+				results[i].Priority = clientWithMode.Source.Priority
+				results[i].Private = clientWithMode.Source.Private
+				// Prowlarr results already name the indexer they came from
+				if clientWithMode.Source.Type != "prowlarr" {
+					results[i].Indexer = clientWithMode.Source.Label()
+				}
+			}
 			allResults = append(allResults, results...)
 		}
-		time.Sleep(5 * time.Second) // 5-second delay between search terms
 	}
 
-	m.logger.Info(fmt.Sprintf("Found %d total results for %s", len(allResults), media.Title))
+	logger.Info(fmt.Sprintf("Found %d total results for %s", len(allResults), media.Title))
 	return allResults, nil
 }
 
+// allowIndexerSearch reports whether an indexer, by source URL, may be searched. An
+// indexer that failed too often in a row is skipped until its cooldown is over; then one
+// search is let through to probe it, and the others keep skipping it for another
+// cooldown unless that search succeeds.
+func (m *Manager) allowIndexerSearch(url string) bool {
+	m.indexerFailuresMu.Lock()
+	defer m.indexerFailuresMu.Unlock()
+	until, tripped := m.indexerTripped[url]
+	if !tripped {
+		return true
+	}
+	if time.Now().Before(until) {
+		return false
+	}
+	_, cooldown := m.Config().IndexerBreaker()
+	m.indexerTripped[url] = time.Now().Add(cooldown)
+	return true
+}
+
+// recordIndexerSearch counts the failed searches of an indexer in a row, and trips it
+// once they reach automation.indexer_failure_threshold. A successful search clears it.
+func (m *Manager) recordIndexerSearch(source config.SourceConfig, err error) {
+	if err == nil {
+		m.resetIndexerFailures(source)
+		return
+	}
+	threshold, cooldown := m.Config().IndexerBreaker()
+	if threshold <= 0 {
+		return
+	}
+	m.indexerFailuresMu.Lock()
+	defer m.indexerFailuresMu.Unlock()
+	m.indexerFailures[source.URL]++
+	if failures := m.indexerFailures[source.URL]; failures >= threshold {
+		if _, tripped := m.indexerTripped[source.URL]; !tripped {
+			m.logger.Warn(fmt.Sprintf("Indexer %s failed %d searches in a row, skipping it for %s", source.Label(), failures, cooldown))
+		}
+		m.indexerTripped[source.URL] = time.Now().Add(cooldown)
+	}
+}
+
+// resetIndexerFailures clears the failures of an indexer that works again.
+func (m *Manager) resetIndexerFailures(source config.SourceConfig) {
+	m.indexerFailuresMu.Lock()
+	defer m.indexerFailuresMu.Unlock()
+	if _, tripped := m.indexerTripped[source.URL]; tripped {
+		m.logger.Info("Indexer", source.Label(), "works again, searching it")
+	}
+	delete(m.indexerFailures, source.URL)
+	delete(m.indexerTripped, source.URL)
+}
+
+// indexerTrippedUntil returns until when searches skip an indexer, if they do. Once the
+// cooldown is over it is no longer skipped, though the next search only probes it.
+func (m *Manager) indexerTrippedUntil(url string) (time.Time, bool) {
+	m.indexerFailuresMu.Lock()
+	defer m.indexerFailuresMu.Unlock()
+	until, tripped := m.indexerTripped[url]
+	return until, tripped && time.Now().Before(until)
+}
+
+// searchSeasonPacks runs the queries of a "season" mode source, which asks for whole
+// seasons instead of single episodes, and flags the results that are packs for the season.
+// The Torznab generic search is used since "season" is not a Torznab search type.
+func searchSeasonPacks(clientWithMode IndexerClientWithMode, title string, season int) ([]indexers.IndexerResult, error) {
+	var results []indexers.IndexerResult
+	var err error
+	for _, query := range clientWithMode.Source.SeasonQueries(title, season) {
+		results, err = clientWithMode.Client.SearchTVShows(query, 0, 0, "search")
+		if err != nil || len(results) > 0 {
+			break
+		}
+	}
+	for i := range results {
+		results[i].SeasonPack = isSeasonPack(results[i].Title, season)
+	}
+	return results, err
+}
+
 func (m *Manager) processRSSFeeds() {
+	cfg := m.Config()
 	m.logger.Info("Starting RSS feed processing...")
 
-	allSources := append(m.config.TVShows.Sources, m.config.Anime.Sources...)
+	allSources := append(cfg.TVShows.Sources, cfg.Anime.Sources...)
 
 	for _, source := range allSources {
 		if source.Type == "rss" {
 			m.logger.Info("Fetching RSS feed:", source.URL)
 
-			resp, err := m.httpClient.Get(source.URL)
+			req, err := http.NewRequest("GET", source.URL, nil)
+			if err != nil {
+				m.logger.Error("Failed to create RSS feed request", source.URL, ":", err)
+				continue
+			}
+			for name, value := range source.Headers {
+				req.Header.Set(name, value)
+			}
+			resp, err := m.sharedHTTPClient().Do(req)
 			if err != nil {
 				m.logger.Error("Failed to fetch RSS feed", source.URL, ":", err)
 				continue
@@ -1153,13 +2749,14 @@ func (m *Manager) processRSSFeeds() {
 				continue
 			}
 
-			m.matchFeedItems(feed.Channel.Items)
+			m.matchFeedItems(source, feed.Channel.Items)
 		}
 	}
 	m.logger.Info("Finished RSS feed processing.")
 }
 
-func (m *Manager) matchFeedItems(items []rssItem) {
+func (m *Manager) matchFeedItems(source config.SourceConfig, items []rssItem) {
+	cfg := m.Config()
 	// 1. Get all TV shows and anime from the library that are being monitored or are pending.
 	mediaToMonitor, err := m.mediaRepo.GetByStatus(models.StatusMonitoring)
 	if err != nil {
@@ -1183,9 +2780,14 @@ func (m *Manager) matchFeedItems(items []rssItem) {
 			Title:       item.Title,
 			DownloadURL: item.Link,
 			Indexer:     "RSS",
+			Description: item.Description,
+			Private:     source.Private,
 		}
 
 		for _, media := range allMedia {
+			if !media.Monitored {
+				continue
+			}
 			searchTerms := []string{media.Title}
 			if media.Type == models.MediaTypeAnime {
 				animeSearchTerms, err := m.mediaRepo.GetAnimeSearchTerms(media.ID)
@@ -1196,9 +2798,29 @@ func (m *Manager) matchFeedItems(items []rssItem) {
 				}
 			}
 
-			for _, term := range searchTerms {
-				if !strings.Contains(strings.ToLower(item.Title), strings.ToLower(term)) {
-					continue
+			if utils.TitleMatches(item.Title, searchTerms) {
+				blacklisted := m.blacklistedReleases(&media)
+
+				// Episodes of date-based shows are found by the air date in the release name
+				if media.DateBased {
+					if titleDate, ok := airDateFromTitle(item.Title); ok {
+						episode, seasonNumber, err := m.mediaRepo.GetEpisodeByAirDate(media.ID, titleDate.Format("2006-01-02"))
+						if err != nil || episode == nil || episode.Status != models.StatusPending {
+							continue
+						}
+						bestTorrent := m.torrentSelector.SelectBestTorrent(&media, []indexers.IndexerResult{indexerResult}, seasonNumber, episode.EpisodeNumber, searchTerms, SelectionFacts{Blacklisted: blacklisted, AirDate: titleDate})
+						if bestTorrent != nil {
+							m.logger.Info("Found match in RSS feed for", media.Title, titleDate.Format("2006-01-02"))
+							if cfg.Automation.DryRun {
+								m.logger.Info("Dry run: would download", bestTorrent.Title, "from RSS")
+								goto nextItem
+							}
+							m.autoStartEpisodeDownload(media.ID, seasonNumber, episode.EpisodeNumber, *bestTorrent)
+							time.Sleep(10 * time.Second) // Avoid overwhelming the download client
+							goto nextItem
+						}
+						continue
+					}
 				}
 
 				show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
@@ -1209,10 +2831,14 @@ func (m *Manager) matchFeedItems(items []rssItem) {
 				for _, season := range show.Seasons {
 					for _, episode := range season.Episodes {
 						if episode.Status == models.StatusPending {
-							bestTorrent := m.torrentSelector.SelectBestTorrent(&media, []indexers.IndexerResult{indexerResult}, season.SeasonNumber, episode.EpisodeNumber, searchTerms)
+							bestTorrent := m.torrentSelector.SelectBestTorrent(&media, []indexers.IndexerResult{indexerResult}, season.SeasonNumber, episode.EpisodeNumber, searchTerms, SelectionFacts{Blacklisted: blacklisted, AirDate: m.episodeAirDate(&media, season.SeasonNumber, episode.EpisodeNumber)})
 							if bestTorrent != nil {
 								m.logger.Info("Found match in RSS feed for", media.Title, fmt.Sprintf("S%02dE%02d", season.SeasonNumber, episode.EpisodeNumber))
-								m.StartEpisodeDownload(media.ID, season.SeasonNumber, episode.EpisodeNumber, *bestTorrent)
+								if cfg.Automation.DryRun {
+									m.logger.Info("Dry run: would download", bestTorrent.Title, "from RSS")
+									goto nextItem
+								}
+								m.autoStartEpisodeDownload(media.ID, season.SeasonNumber, episode.EpisodeNumber, *bestTorrent)
 								time.Sleep(10 * time.Second) // Avoid overwhelming the download client
 								goto nextItem                // Move to the next RSS item once a match is found and downloaded
 							}
@@ -1225,19 +2851,21 @@ func (m *Manager) matchFeedItems(items []rssItem) {
 	}
 }
 
-func (m *Manager) PerformSearch(id int) ([]indexers.IndexerResult, error) {
+// PerformSearch runs a manual search for a media item. The rejected results are the ones
+// the torrent selector dropped, with the reason for each.
+func (m *Manager) PerformSearch(id int) ([]indexers.IndexerResult, []RejectedResult, error) {
 	media, err := m.mediaRepo.GetByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if media == nil {
-		return nil, fmt.Errorf("media not found")
+		return nil, nil, fmt.Errorf("media %w", models.ErrNotFound)
 	}
 
 	// For manual search, we don't know the episode yet, so just search for the show title
 	results, err := m.performSearch(media, 0, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	searchTerms := []string{media.Title}
@@ -1251,30 +2879,181 @@ func (m *Manager) PerformSearch(id int) ([]indexers.IndexerResult, error) {
 	}
 
 	// Use the TorrentSelector to filter and score the results
-	filteredResults := m.torrentSelector.FilterAndScoreTorrents(media, results, 0, 0, searchTerms)
+	filteredResults, rejected := m.torrentSelector.FilterAndScoreTorrentsWithRejects(media, results, 0, 0, searchTerms, m.selectionFacts(media, 0, 0))
+	m.saveSearchResults(media.ID, filteredResults)
+
+	return filteredResults, rejected, nil
+}
+
+// posterCacheDir is the directory under the data path that caches proxied posters;
+// posters larger than maxPosterSize are not downloaded.
+const (
+	posterCacheDir     = "posters"
+	maxPosterSize      = 10 << 20
+	posterFetchTimeout = 30 * time.Second
+)
+
+// ErrPosterUnavailable is wrapped by the error of GetPoster when the poster couldn't be
+// downloaded for the cache, as opposed to the media item failing to load.
+var ErrPosterUnavailable = errors.New("poster unavailable")
+
+// GetPoster returns a media item's poster URL and, with app.proxy_images, the path of
+// its cached copy, downloading the poster first if it isn't cached yet. found is false if
+// the media item doesn't exist or has no poster.
+func (m *Manager) GetPoster(mediaID int) (posterURL, path string, found bool, err error) {
+	cfg := m.Config()
+	media, err := m.mediaRepo.GetByID(mediaID)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to get media: %w", err)
+	}
+	if media == nil || media.PosterURL == nil || *media.PosterURL == "" {
+		return "", "", false, nil
+	}
+	posterURL = *media.PosterURL
+	if !cfg.App.ProxyImages {
+		return posterURL, "", true, nil
+	}
+
+	// Keyed by URL, so a changed poster is downloaded again
+	sum := sha1.Sum([]byte(posterURL))
+	path = filepath.Join(cfg.App.DataPath, posterCacheDir, hex.EncodeToString(sum[:]))
+	if _, err := os.Stat(path); err == nil {
+		return posterURL, path, true, nil
+	}
+	if err := m.downloadPoster(posterURL, path); err != nil {
+		return posterURL, "", true, fmt.Errorf("%w: %w", ErrPosterUnavailable, err)
+	}
+	return posterURL, path, true, nil
+}
+
+// downloadPoster saves a poster image to path, through a temporary file so concurrent
+// requests never serve a partial image.
+func (m *Manager) downloadPoster(posterURL, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create poster cache: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), posterFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", posterURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create poster request: %w", err)
+	}
+	resp, err := m.sharedHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch poster: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("poster request failed with status: %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/") {
+		return fmt.Errorf("poster is not an image (%s)", contentType)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".poster-*")
+	if err != nil {
+		return fmt.Errorf("failed to create poster file: %w", err)
+	}
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxPosterSize+1))
+	tmp.Close()
+	if err == nil && n > maxPosterSize {
+		err = fmt.Errorf("poster is larger than %d MB", maxPosterSize>>20)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// checkDuplicateTorrent returns an error when another media item is already downloading
+// the torrent, e.g. a crossover episode or a movie added twice: tracking one torrent
+// under two items would confuse status polling and cleanup. An empty hash passes.
+func (m *Manager) checkDuplicateTorrent(mediaID int, hash string) error {
+	if hash == "" {
+		return nil
+	}
+	other, err := m.mediaRepo.GetByTorrentHash(hash)
+	if err != nil {
+		m.logger.Warn("Failed to check for duplicate torrent", hash, ":", err)
+		return nil
+	}
+	if other == nil || other.ID == mediaID {
+		return nil
+	}
+	m.logger.WithFields(map[string]interface{}{"media_id": mediaID, "torrent_hash": hash}).Warn(
+		"Torrent is already downloading for", other.Title, fmt.Sprintf("(media %d), skipping", other.ID))
+	return fmt.Errorf("torrent is already downloading for '%s' (media %d)", other.Title, other.ID)
+}
+
+// searchResultTTL is how long manual search results can be downloaded by ID.
+const searchResultTTL = time.Hour
+
+// searchResultID identifies a result by where it can be downloaded from, so the same
+// release keeps its ID across searches.
+func searchResultID(result indexers.IndexerResult) string {
+	sum := sha1.Sum([]byte(result.Indexer + "\x00" + result.DownloadURL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// saveSearchResults sets the ID of manual search results and stores them, so they can
+// be downloaded by ID. A failure only costs that shortcut, so it is logged and ignored.
+func (m *Manager) saveSearchResults(mediaID int, results []indexers.IndexerResult) {
+	stored := make(map[string][]byte, len(results))
+	for i := range results {
+		results[i].ID = searchResultID(results[i])
+		data, err := json.Marshal(results[i])
+		if err != nil {
+			m.logger.Warn("Failed to encode search result", results[i].Title, ":", err)
+			continue
+		}
+		stored[results[i].ID] = data
+	}
+	if err := m.mediaRepo.SaveSearchResults(mediaID, stored, searchResultTTL); err != nil {
+		m.logger.WithField("media_id", mediaID).Warn("Failed to store search results:", err)
+	}
+}
 
-	return filteredResults, nil
+// ResolveSearchResult returns the stored manual search result a download request refers
+// to when it only carries the result's ID; found is false if the ID is unknown or has
+// expired. Requests with a download URL are returned as is.
+func (m *Manager) ResolveSearchResult(mediaID int, result indexers.IndexerResult) (resolved indexers.IndexerResult, found bool, err error) {
+	if result.ID == "" || result.DownloadURL != "" {
+		return result, true, nil
+	}
+	data, err := m.mediaRepo.GetSearchResult(mediaID, result.ID, searchResultTTL)
+	if err != nil || data == nil {
+		return result, false, err
+	}
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return result, false, fmt.Errorf("failed to decode stored search result: %w", err)
+	}
+	return resolved, true, nil
 }
 
-func (m *Manager) StartDownload(id int, torrent indexers.IndexerResult) error {
+func (m *Manager) StartDownload(ctx context.Context, id int, torrent indexers.IndexerResult) error {
+	cfg := m.Config()
+	logger := m.logger.WithField("media_id", id)
 	media, err := m.mediaRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
 	if media == nil {
-		return fmt.Errorf("media not found")
+		return fmt.Errorf("media %w", models.ErrNotFound)
 	}
 
 	var downloadPath string
 	switch media.Type {
 	case models.MediaTypeMovie:
-		downloadPath = m.config.Movies.DownloadFolder
+		downloadPath = cfg.Movies.DownloadFolder
 	case models.MediaTypeTVShow:
-		downloadPath = m.config.TVShows.DownloadFolder
+		downloadPath = cfg.TVShows.DownloadFolder
 	case models.MediaTypeAnime:
-		downloadPath = m.config.Anime.DownloadFolder
+		downloadPath = cfg.Anime.DownloadFolder
 	default:
-		downloadPath = m.config.TorrentClient.DownloadPath // Fallback
+		downloadPath = cfg.TorrentClient.DownloadPath // Fallback
 	}
 
 	// --- New Disk Space Check ---
@@ -1283,12 +3062,12 @@ func (m *Manager) StartDownload(id int, torrent indexers.IndexerResult) error {
 
 	usage, err := disk.Usage(downloadPath)
 	if err != nil {
-		m.logger.Error("Failed to check disk space for path", downloadPath, ":", err)
+		logger.Error("Failed to check disk space for path", downloadPath, ":", err)
 		return fmt.Errorf("could not verify disk space: %w", err)
 	}
 
 	if usage.Free < requiredSpace {
-		m.logger.Warn(fmt.Sprintf("Not enough disk space in %s. Required: %d bytes, Available: %d bytes", downloadPath, requiredSpace, usage.Free))
+		logger.Warn(fmt.Sprintf("Not enough disk space in %s. Required: %d bytes, Available: %d bytes", downloadPath, requiredSpace, usage.Free))
 		// You would need to add a new notification method like NotifyNotEnoughSpace to your notifiers
 		m.notifyNotEnoughSpace(media, torrent.Title)
 		m.mediaRepo.UpdateStatus(id, models.StatusFailed)
@@ -1296,149 +3075,499 @@ func (m *Manager) StartDownload(id int, torrent indexers.IndexerResult) error {
 	}
 	// --- End of Check ---
 
-	m.logger.Info("Sending to download client:", m.config.TorrentClient.Type)
+	knownHash := utils.InfoHash(torrent.DownloadURL, torrent.TorrentFile)
+	if err := m.checkDuplicateTorrent(id, knownHash); err != nil {
+		m.mediaRepo.UpdateStatus(id, models.StatusFailed)
+		return err
+	}
+
+	client := m.torrentClientFor(media.Type)
+	logger.Info("Sending to download client:", cfg.TorrentClientFor(string(media.Type)).Type)
 
 	var hash string
 
-	if m.config.App.MagnetToTorrentEnabled && strings.HasPrefix(torrent.DownloadURL, "magnet:") {
-		timeout := time.Duration(m.config.App.MagnetToTorrentTimeout) * time.Second
+	if len(torrent.TorrentFile) > 0 {
+		hash, err = client.AddTorrentFile(torrent.TorrentFile, downloadPath)
+	} else if cfg.App.MagnetToTorrentEnabled && strings.HasPrefix(torrent.DownloadURL, "magnet:") {
+		timeout := time.Duration(cfg.App.MagnetToTorrentTimeout) * time.Second
 		if timeout <= 0 {
 			timeout = 60 * time.Second // Default to 60 seconds
 		}
-		m.logger.Info("Attempting to convert magnet to .torrent with timeout:", timeout)
-		torrentFileBytes, convErr := utils.ConvertMagnetToTorrent(torrent.DownloadURL, timeout, m.config.App.DataPath, m.logger)
+		logger.Info("Attempting to convert magnet to .torrent with timeout:", timeout)
+		torrentFileBytes, convErr := utils.ConvertMagnetToTorrent(torrent.DownloadURL, timeout, cfg.App.DataPath, cfg.ProxyURL(), m.logger)
 		if convErr == nil {
-			m.logger.Info("Magnet conversion successful, adding as .torrent file.")
-			hash, err = m.torrentClient.AddTorrentFile(torrentFileBytes, downloadPath)
+			logger.Info("Magnet conversion successful, adding as .torrent file.")
+			hash, err = client.AddTorrentFile(torrentFileBytes, downloadPath)
 		} else {
-			m.logger.Warn("Magnet conversion failed:", convErr, "- falling back to magnet link.")
-			hash, err = m.torrentClient.AddTorrent(torrent.DownloadURL, downloadPath)
+			logger.Warn("Magnet conversion failed:", convErr, "- falling back to magnet link.")
+			hash, err = client.AddTorrent(torrent.DownloadURL, downloadPath)
 		}
 	} else {
-		hash, err = m.torrentClient.AddTorrent(torrent.DownloadURL, downloadPath)
+		hash, err = client.AddTorrent(torrent.DownloadURL, downloadPath)
 	}
 
 	if err != nil {
-		m.logger.Error("Failed to add torrent to client:", err)
+		logger.Error("Failed to add torrent to client:", err)
+		m.mediaRepo.UpdateStatus(id, models.StatusFailed)
+		return err
+	}
+	// The hash of a release downloaded from a URL is only known now. The torrent stays in
+	// the client, since it belongs to the other media item.
+	if knownHash == "" {
+		if err := m.checkDuplicateTorrent(id, hash); err != nil {
+			m.mediaRepo.UpdateStatus(id, models.StatusFailed)
+			return err
+		}
+	}
+	if err := m.confirmTorrentAdded(ctx, client, hash); err != nil {
+		logger.Error("Download client did not register the torrent:", err)
 		m.mediaRepo.UpdateStatus(id, models.StatusFailed)
+		m.notifyDownloadError(media, torrent.Title)
 		return err
 	}
 
-	m.addExtraTrackers(hash)
+	m.addExtraTrackers(client, hash, torrent)
+	m.recordGrab(id, 0, 0, torrent, hash)
 
 	// Notidication
 	m.notifyDownloadStarted(media, torrent.Title)
-	m.logger.Info("Torrent successfully sent to download client! Hash:", hash)
+	logger.WithField("torrent_hash", hash).Info("Torrent successfully sent to download client!")
 
 	if err := m.mediaRepo.UpdateDownloadInfo(id, models.StatusDownloading, &hash, &torrent.Title); err != nil {
-		m.logger.Error("Failed to update media status after adding torrent:", err)
+		logger.Error("Failed to update media status after adding torrent:", err)
 		return err
 	}
+	if media.RetryCount > 0 || media.NextRetryAt != nil {
+		if err := m.mediaRepo.ResetRetry(id); err != nil {
+			logger.Error("Failed to reset the retry count after the grab:", err)
+		}
+	}
 	return nil
 }
 
-func (m *Manager) StartEpisodeDownload(mediaID int, seasonNumber int, episodeNumber int, torrent indexers.IndexerResult) error {
+// StartEpisodeDownload sends an episode's release to the download client. It is what the
+// user asks for, so it goes ahead even when an automatic grab of the episode just started.
+func (m *Manager) StartEpisodeDownload(ctx context.Context, mediaID int, seasonNumber int, episodeNumber int, torrent indexers.IndexerResult) error {
+	return m.startEpisodeDownload(ctx, mediaID, seasonNumber, episodeNumber, torrent, false)
+}
+
+// autoStartEpisodeDownload is StartEpisodeDownload for the automatic searches (RSS, the
+// pending search), which may race each other for an episode: a download of the episode
+// started within the grab cooldown makes it refuse.
+func (m *Manager) autoStartEpisodeDownload(mediaID int, seasonNumber int, episodeNumber int, torrent indexers.IndexerResult) error {
+	return m.startEpisodeDownload(context.Background(), mediaID, seasonNumber, episodeNumber, torrent, true)
+}
+
+func (m *Manager) startEpisodeDownload(ctx context.Context, mediaID int, seasonNumber int, episodeNumber int, torrent indexers.IndexerResult, automatic bool) error {
+	cfg := m.Config()
+	logger := m.logger.WithFields(map[string]interface{}{"media_id": mediaID, "season": seasonNumber, "episode": episodeNumber})
 	media, err := m.mediaRepo.GetByID(mediaID)
 	if err != nil {
-		return err
+		return err
+	}
+	if media == nil {
+		return fmt.Errorf("media %w", models.ErrNotFound)
+	}
+
+	if media.Type != models.MediaTypeTVShow && media.Type != models.MediaTypeAnime {
+		return fmt.Errorf("media is not a TV show or anime")
+	}
+
+	if automatic && !m.claimEpisodeGrab(mediaID, seasonNumber, episodeNumber) {
+		logger.Warn("Episode download started moments ago, not grabbing", torrent.Title, "as well")
+		return fmt.Errorf("a download of S%02dE%02d started less than %s ago", seasonNumber, episodeNumber, cfg.GrabCooldown())
+	}
+	// The claim is given back unless the torrent makes it to the download client
+	grabbed := false
+	defer func() {
+		if automatic && !grabbed {
+			m.releaseEpisodeGrab(mediaID, seasonNumber, episodeNumber)
+		}
+	}()
+
+	var downloadPath string
+	switch media.Type {
+	case models.MediaTypeTVShow:
+		downloadPath = cfg.TVShows.DownloadFolder
+	case models.MediaTypeAnime:
+		downloadPath = cfg.Anime.DownloadFolder
+	default:
+		downloadPath = cfg.TorrentClient.DownloadPath // Fallback
+	}
+
+	// --- New Disk Space Check ---
+	const securityBuffer int64 = 500 * 1024 * 1024 // 500MB
+	requiredSpace := uint64(torrent.Size + securityBuffer)
+
+	usage, err := disk.Usage(downloadPath)
+	if err != nil {
+		logger.Error("Failed to check disk space for path", downloadPath, ":", err)
+		return fmt.Errorf("could not verify disk space: %w", err)
+	}
+
+	if usage.Free < requiredSpace {
+		logger.Warn(fmt.Sprintf("Not enough disk space in %s. Required: %d bytes, Available: %d bytes", downloadPath, requiredSpace, usage.Free))
+		// You would need to add a new notification method like NotifyNotEnoughSpace to your notifiers
+		m.notifyNotEnoughSpace(media, torrent.Title)
+		m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusFailed, nil, nil)
+		return fmt.Errorf("not enough disk space to download '%s'", torrent.Title)
+	}
+	// --- End of Check ---
+
+	logger.Info(fmt.Sprintf("Starting manual download for %s S%02dE%02d: %s",
+		media.Title, seasonNumber, episodeNumber, torrent.Title))
+
+	knownHash := utils.InfoHash(torrent.DownloadURL, torrent.TorrentFile)
+	if err := m.checkDuplicateTorrent(mediaID, knownHash); err != nil {
+		return err
+	}
+
+	// Start the torrent download
+	client := m.torrentClientFor(media.Type)
+	var hash string
+
+	if len(torrent.TorrentFile) > 0 {
+		hash, err = client.AddTorrentFile(torrent.TorrentFile, downloadPath)
+	} else if cfg.App.MagnetToTorrentEnabled && strings.HasPrefix(torrent.DownloadURL, "magnet:") {
+		timeout := time.Duration(cfg.App.MagnetToTorrentTimeout) * time.Second
+		if timeout <= 0 {
+			timeout = 60 * time.Second // Default to 60 seconds
+		}
+		logger.Info("Attempting to convert magnet to .torrent with timeout:", timeout)
+		torrentFileBytes, convErr := utils.ConvertMagnetToTorrent(torrent.DownloadURL, timeout, cfg.App.DataPath, cfg.ProxyURL(), m.logger)
+		if convErr == nil {
+			logger.Info("Magnet conversion successful, adding as .torrent file.")
+			hash, err = client.AddTorrentFile(torrentFileBytes, downloadPath)
+		} else {
+			logger.Warn("Magnet conversion failed:", convErr, "- falling back to magnet link.")
+			hash, err = client.AddTorrent(torrent.DownloadURL, downloadPath)
+		}
+	} else {
+		hash, err = client.AddTorrent(torrent.DownloadURL, downloadPath)
+	}
+
+	if err != nil {
+		logger.Error("Failed to add episode torrent to client:", err)
+		return err
+	}
+	if knownHash == "" {
+		if err := m.checkDuplicateTorrent(mediaID, hash); err != nil {
+			return err
+		}
+	}
+	if err := m.confirmTorrentAdded(ctx, client, hash); err != nil {
+		logger.Error("Download client did not register the episode torrent:", err)
+		m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusFailed, nil, nil)
+		m.notifyDownloadError(media, torrent.Title)
+		return err
+	}
+	grabbed = true
+	if !automatic {
+		// Automatic searches leave the episode alone for the cooldown too
+		m.noteEpisodeGrab(mediaID, seasonNumber, episodeNumber)
+	}
+
+	m.addExtraTrackers(client, hash, torrent)
+	m.recordGrab(mediaID, seasonNumber, episodeNumber, torrent, hash)
+
+	logger.WithField("torrent_hash", hash).Info("Episode torrent successfully sent to download client!")
+
+	// Update the specific episode status in database
+	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusDownloading, &hash, &torrent.Title); err != nil {
+		logger.Error("Failed to update episode status after adding torrent:", err)
+		return err
+	}
+	// A pack is processed as a whole even when it was grabbed for one missing episode,
+	// so its files keep their own episode numbers
+	pack := true
+	if torrent.SeasonPack || isSeasonPack(torrent.Title, seasonNumber) {
+		m.attachSeasonPack(media.ID, seasonNumber, hash, torrent.Title)
+	} else if rangeSeason, first, last, ok := episodeRange(torrent.Title); ok && rangeSeason == seasonNumber {
+		m.attachEpisodes(media.ID, seasonNumber, hash, torrent.Title, func(episode int) bool {
+			return episode >= first && episode <= last
+		})
+	} else {
+		pack = false
+	}
+	if pack {
+		if err := m.mediaRepo.MarkTorrentPack(hash); err != nil {
+			logger.Error("Failed to flag the episodes of the pack:", err)
+		}
+	}
+	if media.RetryCount > 0 || media.NextRetryAt != nil {
+		if err := m.mediaRepo.ResetRetry(mediaID); err != nil {
+			logger.Error("Failed to reset the retry count after the grab:", err)
+		}
+	}
+
+	return nil
+}
+
+// episodeGrabKey identifies an episode in recentGrabs.
+func episodeGrabKey(mediaID, seasonNumber, episodeNumber int) string {
+	return fmt.Sprintf("%d:%d:%d", mediaID, seasonNumber, episodeNumber)
+}
+
+// claimEpisodeGrab reserves an episode for a download about to start. It returns false
+// when another download of the episode started within the grab cooldown, or is being
+// started right now. Checking and claiming at once keeps two grabs from both passing.
+func (m *Manager) claimEpisodeGrab(mediaID, seasonNumber, episodeNumber int) bool {
+	cooldown := m.Config().GrabCooldown()
+	if cooldown <= 0 {
+		return true
+	}
+	m.recentGrabsMu.Lock()
+	defer m.recentGrabsMu.Unlock()
+	now := time.Now()
+	for key, startedAt := range m.recentGrabs {
+		if now.Sub(startedAt) >= cooldown {
+			delete(m.recentGrabs, key)
+		}
+	}
+	key := episodeGrabKey(mediaID, seasonNumber, episodeNumber)
+	if _, ok := m.recentGrabs[key]; ok {
+		return false
+	}
+	m.recentGrabs[key] = now
+	return true
+}
+
+// noteEpisodeGrab records a download the user started, so the automatic searches don't
+// grab the episode again within the cooldown.
+func (m *Manager) noteEpisodeGrab(mediaID, seasonNumber, episodeNumber int) {
+	if m.Config().GrabCooldown() <= 0 {
+		return
+	}
+	m.recentGrabsMu.Lock()
+	defer m.recentGrabsMu.Unlock()
+	m.recentGrabs[episodeGrabKey(mediaID, seasonNumber, episodeNumber)] = time.Now()
+}
+
+// releaseEpisodeGrab gives back the claim of a download that didn't start.
+func (m *Manager) releaseEpisodeGrab(mediaID, seasonNumber, episodeNumber int) {
+	m.recentGrabsMu.Lock()
+	defer m.recentGrabsMu.Unlock()
+	delete(m.recentGrabs, episodeGrabKey(mediaID, seasonNumber, episodeNumber))
+}
+
+// recordGrab adds a release sent to the download client to the release history;
+// season and episode are 0 for movies.
+func (m *Manager) recordGrab(mediaID, seasonNumber, episodeNumber int, torrent indexers.IndexerResult, hash string) {
+	entry := &models.ReleaseHistoryEntry{
+		MediaID:       mediaID,
+		SeasonNumber:  seasonNumber,
+		EpisodeNumber: episodeNumber,
+		Event:         models.ReleaseGrabbed,
+		ReleaseTitle:  torrent.Title,
+		Indexer:       torrent.Indexer,
+		TorrentHash:   hash,
+	}
+	if err := m.mediaRepo.AddReleaseHistory(entry); err != nil {
+		m.logger.WithField("media_id", mediaID).Warn("Failed to record grab in release history:", err)
+	}
+}
+
+// GetEpisodeHistory returns the releases grabbed and blacklisted for an episode, newest first.
+func (m *Manager) GetEpisodeHistory(mediaID, seasonNumber, episodeNumber int) ([]models.ReleaseHistoryEntry, error) {
+	if _, err := m.mediaRepo.GetEpisodeByDetails(mediaID, seasonNumber, episodeNumber); err != nil {
+		return nil, err
+	}
+	return m.mediaRepo.GetReleaseHistory(mediaID, seasonNumber, episodeNumber)
+}
+
+// ErrNoReleaseToReplace is returned by RedownloadEpisode for an episode that was never
+// grabbed.
+var ErrNoReleaseToReplace = errors.New("no release to replace")
+
+// RedownloadEpisode blacklists the release last grabbed for an episode, so it is never
+// picked again for the show, sets the episode back to pending and searches for it again.
+// The old torrent is left alone; the new release's file overwrites the imported one
+// (see replacesRelease).
+func (m *Manager) RedownloadEpisode(mediaID, seasonNumber, episodeNumber int) ([]indexers.IndexerResult, []RejectedResult, error) {
+	episode, err := m.mediaRepo.GetEpisodeByDetails(mediaID, seasonNumber, episodeNumber)
+	if err != nil {
+		return nil, nil, err
 	}
-	if media == nil {
-		return fmt.Errorf("media not found")
+	if episode.TorrentName == nil || *episode.TorrentName == "" {
+		return nil, nil, fmt.Errorf("episode S%02dE%02d has %w", seasonNumber, episodeNumber, ErrNoReleaseToReplace)
 	}
 
-	if media.Type != models.MediaTypeTVShow && media.Type != models.MediaTypeAnime {
-		return fmt.Errorf("media is not a TV show or anime")
+	entry := &models.ReleaseHistoryEntry{
+		MediaID:       mediaID,
+		SeasonNumber:  seasonNumber,
+		EpisodeNumber: episodeNumber,
+		Event:         models.ReleaseBlacklisted,
+		ReleaseTitle:  *episode.TorrentName,
 	}
-
-	var downloadPath string
-	switch media.Type {
-	case models.MediaTypeTVShow:
-		downloadPath = m.config.TVShows.DownloadFolder
-	case models.MediaTypeAnime:
-		downloadPath = m.config.Anime.DownloadFolder
-	default:
-		downloadPath = m.config.TorrentClient.DownloadPath // Fallback
+	if episode.TorrentHash != nil {
+		entry.TorrentHash = *episode.TorrentHash
 	}
+	if err := m.mediaRepo.AddReleaseHistory(entry); err != nil {
+		return nil, nil, fmt.Errorf("failed to blacklist release: %w", err)
+	}
+	m.logger.WithFields(map[string]interface{}{"media_id": mediaID, "season": seasonNumber, "episode": episodeNumber}).
+		Info("Blacklisted release", *episode.TorrentName, "and searching again")
 
-	// --- New Disk Space Check ---
-	const securityBuffer int64 = 500 * 1024 * 1024 // 500MB
-	requiredSpace := uint64(torrent.Size + securityBuffer)
+	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusPending, nil, nil); err != nil {
+		return nil, nil, err
+	}
+	return m.PerformEpisodeSearch(mediaID, seasonNumber, episodeNumber)
+}
 
-	usage, err := disk.Usage(downloadPath)
+// replacesRelease reports whether a download of the episode replaces a release the user
+// rejected through RedownloadEpisode, so post-processing overwrites the old file.
+func (m *Manager) replacesRelease(mediaID, seasonNumber, episodeNumber int) bool {
+	history, err := m.mediaRepo.GetReleaseHistory(mediaID, seasonNumber, episodeNumber)
 	if err != nil {
-		m.logger.Error("Failed to check disk space for path", downloadPath, ":", err)
-		return fmt.Errorf("could not verify disk space: %w", err)
+		m.logger.Error("Failed to get release history:", err)
+		return false
 	}
-
-	if usage.Free < requiredSpace {
-		m.logger.Warn(fmt.Sprintf("Not enough disk space in %s. Required: %d bytes, Available: %d bytes", downloadPath, requiredSpace, usage.Free))
-		// You would need to add a new notification method like NotifyNotEnoughSpace to your notifiers
-		m.notifyNotEnoughSpace(media, torrent.Title)
-		m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusFailed, nil, nil)
-		return fmt.Errorf("not enough disk space to download '%s'", torrent.Title)
+	for _, entry := range history {
+		if entry.Event == models.ReleaseBlacklisted {
+			return true
+		}
 	}
-	// --- End of Check ---
+	return false
+}
 
-	m.logger.Info(fmt.Sprintf("Starting manual download for %s S%02dE%02d: %s",
-		media.Title, seasonNumber, episodeNumber, torrent.Title))
+// Attempts and delay when checking that the download client registered a new torrent.
+const (
+	torrentConfirmAttempts = 3
+	torrentConfirmDelay    = 2 * time.Second
+)
 
-	// Start the torrent download
-	var hash string
+// confirmTorrentAdded checks that the download client really holds a torrent it just
+// accepted: qBittorrent, for one, answers OK to duplicate or invalid magnets without
+// adding them. The lookup is retried briefly, as clients may take a moment to list it,
+// unless ctx (the request of a user's download) ends first. An unconfirmed torrent is
+// removed from the client, so one that shows up late isn't left downloading untracked.
+func (m *Manager) confirmTorrentAdded(ctx context.Context, client torrent.TorrentClient, hash string) error {
+	var err error
+	for attempt := 1; attempt <= torrentConfirmAttempts; attempt++ {
+		if _, err = client.GetTorrentStatus(hash); err == nil {
+			return nil
+		}
+		if attempt < torrentConfirmAttempts {
+			timer := time.NewTimer(torrentConfirmDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				m.removeUnconfirmedTorrent(client, hash)
+				return fmt.Errorf("torrent %s not found in the download client before the request ended: %w", hash, ctx.Err())
+			case <-timer.C:
+			}
+		}
+	}
+	m.removeUnconfirmedTorrent(client, hash)
+	return fmt.Errorf("torrent %s not found in the download client after adding it: %w", hash, err)
+}
 
-	if m.config.App.MagnetToTorrentEnabled && strings.HasPrefix(torrent.DownloadURL, "magnet:") {
-		timeout := time.Duration(m.config.App.MagnetToTorrentTimeout) * time.Second
-		if timeout <= 0 {
-			timeout = 60 * time.Second // Default to 60 seconds
+// removeUnconfirmedTorrent removes a torrent confirmTorrentAdded gave up on.
+func (m *Manager) removeUnconfirmedTorrent(client torrent.TorrentClient, hash string) {
+	if err := client.RemoveTorrent(hash); err != nil {
+		m.logger.Debug("Could not remove unconfirmed torrent", hash, ":", err)
+	}
+}
+
+// ManualTorrentResult checks a release the user found themselves, given as a magnet
+// link or the contents of a .torrent file, and turns it into a result for
+// AddManualTorrent. The title is the torrent's name.
+func ManualTorrentResult(magnet string, torrentFile []byte) (indexers.IndexerResult, error) {
+	result := indexers.IndexerResult{Indexer: "manual", PublishDate: time.Now()}
+	switch {
+	case len(torrentFile) > 0:
+		name, size, err := utils.ParseTorrentFile(torrentFile)
+		if err != nil {
+			return result, err
 		}
-		m.logger.Info("Attempting to convert magnet to .torrent with timeout:", timeout)
-		torrentFileBytes, convErr := utils.ConvertMagnetToTorrent(torrent.DownloadURL, timeout, m.config.App.DataPath, m.logger)
-		if convErr == nil {
-			m.logger.Info("Magnet conversion successful, adding as .torrent file.")
-			hash, err = m.torrentClient.AddTorrentFile(torrentFileBytes, downloadPath)
-		} else {
-			m.logger.Warn("Magnet conversion failed:", convErr, "- falling back to magnet link.")
-			hash, err = m.torrentClient.AddTorrent(torrent.DownloadURL, downloadPath)
+		result.Title = name
+		result.Size = size
+		result.TorrentFile = torrentFile
+	case magnet != "":
+		name, err := utils.ParseMagnet(magnet)
+		if err != nil {
+			return result, err
 		}
-	} else {
-		hash, err = m.torrentClient.AddTorrent(torrent.DownloadURL, downloadPath)
+		result.Title = name
+		result.DownloadURL = magnet
+	default:
+		return result, fmt.Errorf("a magnet link or a torrent file is required")
 	}
+	return result, nil
+}
 
+// AddManualTorrent downloads a manually submitted release and tracks it like a search
+// result. Season and episode are required for TV shows and anime, and ignored for movies.
+func (m *Manager) AddManualTorrent(ctx context.Context, mediaID, seasonNumber, episodeNumber int, result indexers.IndexerResult) error {
+	media, err := m.mediaRepo.GetByID(mediaID)
 	if err != nil {
-		m.logger.Error("Failed to add episode torrent to client:", err)
 		return err
 	}
+	if media == nil {
+		return fmt.Errorf("media %w", models.ErrNotFound)
+	}
+	if result.Title == "" {
+		result.Title = media.Title // magnets without a display name
+	}
 
-	m.addExtraTrackers(hash)
+	m.logger.WithField("media_id", mediaID).Info("Manual torrent submitted:", result.Title)
+	if media.Type == models.MediaTypeMovie {
+		return m.StartDownload(ctx, mediaID, result)
+	}
+	if seasonNumber <= 0 || episodeNumber <= 0 {
+		return fmt.Errorf("season and episode are required for TV shows and anime")
+	}
+	return m.StartEpisodeDownload(ctx, mediaID, seasonNumber, episodeNumber, result)
+}
 
-	m.logger.Info("Episode torrent successfully sent to download client! Hash:", hash)
+// attachSeasonPack marks the season's other missing episodes as downloading with the
+// pack's torrent, so they are tracked with it instead of being searched for separately.
+func (m *Manager) attachSeasonPack(mediaID, seasonNumber int, hash, torrentName string) {
+	m.attachEpisodes(mediaID, seasonNumber, hash, torrentName, func(int) bool { return true })
+}
 
-	// Update the specific episode status in database
-	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episodeNumber, models.StatusDownloading, &hash, &torrent.Title); err != nil {
-		m.logger.Error("Failed to update episode status after adding torrent:", err)
-		return err
+// attachEpisodes marks the season's missing episodes accepted by covers as downloading
+// with the given torrent, for releases that hold more than one episode.
+func (m *Manager) attachEpisodes(mediaID, seasonNumber int, hash, torrentName string, covers func(episode int) bool) {
+	show, err := m.mediaRepo.GetTVShowByMediaID(mediaID)
+	if err != nil || show == nil {
+		m.logger.WithField("media_id", mediaID).Error("Could not get show details to attach torrent episodes:", err)
+		return
+	}
+	for _, season := range show.Seasons {
+		if season.SeasonNumber != seasonNumber {
+			continue
+		}
+		for _, episode := range season.Episodes {
+			if covers(episode.EpisodeNumber) && (episode.Status == models.StatusPending || episode.Status == models.StatusFailed) {
+				m.mediaRepo.UpdateEpisodeDownloadInfo(mediaID, seasonNumber, episode.EpisodeNumber, models.StatusDownloading, &hash, &torrentName)
+			}
+		}
 	}
-
-	return nil
 }
 
 // PerformEpisodeSearch performs a manual search for a specific episode
-func (m *Manager) PerformEpisodeSearch(mediaID int, seasonNumber int, episodeNumber int) ([]indexers.IndexerResult, error) {
+func (m *Manager) PerformEpisodeSearch(mediaID int, seasonNumber int, episodeNumber int) ([]indexers.IndexerResult, []RejectedResult, error) {
+	logger := m.logger.WithFields(map[string]interface{}{"media_id": mediaID, "season": seasonNumber, "episode": episodeNumber})
 	media, err := m.mediaRepo.GetByID(mediaID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if media == nil {
-		return nil, fmt.Errorf("media not found")
+		return nil, nil, fmt.Errorf("media %w", models.ErrNotFound)
 	}
 
 	if media.Type != models.MediaTypeTVShow && media.Type != models.MediaTypeAnime {
-		return nil, fmt.Errorf("media is not a TV show or anime")
+		return nil, nil, fmt.Errorf("media is not a TV show or anime")
+	}
+	if _, err := m.mediaRepo.GetEpisodeByDetails(mediaID, seasonNumber, episodeNumber); err != nil {
+		return nil, nil, err
 	}
 
 	// Perform search with specific season/episode
 	results, err := m.performSearch(media, seasonNumber, episodeNumber)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	searchTerms := []string{media.Title}
@@ -1452,20 +3581,32 @@ func (m *Manager) PerformEpisodeSearch(mediaID int, seasonNumber int, episodeNum
 	}
 
 	// Use the TorrentSelector to filter and score the results
-	filteredResults := m.torrentSelector.FilterAndScoreTorrents(media, results, seasonNumber, episodeNumber, searchTerms)
+	filteredResults, rejected := m.torrentSelector.FilterAndScoreTorrentsWithRejects(media, results, seasonNumber, episodeNumber, searchTerms, m.selectionFacts(media, seasonNumber, episodeNumber))
+	m.saveSearchResults(media.ID, filteredResults)
 
-	m.logger.Info(fmt.Sprintf("Found %d results for %s S%02dE%02d",
+	logger.Info(fmt.Sprintf("Found %d results for %s S%02dE%02d",
 		len(filteredResults), media.Title, seasonNumber, episodeNumber))
 
-	return filteredResults, nil
+	return filteredResults, rejected, nil
 }
 
-func (m *Manager) addExtraTrackers(hash string) {
-	if len(m.config.ExtraTrackersList) > 0 {
+// extraTrackersDelay is the wait before extra trackers are added, so the download
+// client has loaded the torrent; tests shorten it.
+var extraTrackersDelay = 10 * time.Second
+
+// addExtraTrackers adds the extra_trackers_list to a torrent sent to the download client,
+// unless it came from a private source.
+func (m *Manager) addExtraTrackers(client torrent.TorrentClient, hash string, release indexers.IndexerResult) {
+	cfg := m.Config()
+	if release.Private {
+		m.logger.Debug("Not adding extra trackers to", release.Title, "from a private source")
+		return
+	}
+	if len(cfg.ExtraTrackersList) > 0 {
 		go func() {
-			time.Sleep(10 * time.Second)
+			time.Sleep(extraTrackersDelay)
 			m.logger.Info("Adding extra trackers to torrent:", hash)
-			err := m.torrentClient.AddTrackers(hash, m.config.ExtraTrackersList)
+			err := client.AddTrackers(hash, cfg.ExtraTrackersList)
 			if err != nil {
 				m.logger.Error("Failed to add extra trackers:", err)
 			} else {
@@ -1475,6 +3616,46 @@ func (m *Manager) addExtraTrackers(hash string) {
 	}
 }
 
+// retryBackoff is the wait before each successive automatic retry of failed media.
+// Retries past the end of the list reuse the last delay.
+var retryBackoff = []time.Duration{1 * time.Hour, 4 * time.Hour, 12 * time.Hour, 24 * time.Hour}
+
+func retryDelay(retryCount int) time.Duration {
+	if retryCount >= len(retryBackoff) {
+		return retryBackoff[len(retryBackoff)-1]
+	}
+	return retryBackoff[retryCount]
+}
+
+// retryDue applies the retry backoff to failed media, or to a series with failed
+// episodes. A newly failed item first gets a retry scheduled; it is only due once that
+// time has passed, which counts as an attempt, and never after maxRetries attempts.
+func (m *Manager) retryDue(media *models.Media, now time.Time, maxRetries int) bool {
+	if media.RetryCount >= maxRetries {
+		return false
+	}
+	if media.NextRetryAt == nil {
+		nextRetryAt := now.Add(retryDelay(media.RetryCount))
+		if err := m.mediaRepo.ScheduleRetry(media.ID, media.RetryCount, &nextRetryAt); err != nil {
+			m.logger.Error("Failed to schedule retry for", media.Title, ":", err)
+			return false
+		}
+		m.logger.Info(fmt.Sprintf("Scheduled retry %d/%d for %s at %s", media.RetryCount+1, maxRetries, media.Title, nextRetryAt.Format(time.RFC3339)))
+		return false
+	}
+	if now.Before(*media.NextRetryAt) {
+		return false
+	}
+
+	media.RetryCount++
+	if err := m.mediaRepo.ScheduleRetry(media.ID, media.RetryCount, nil); err != nil {
+		m.logger.Error("Failed to update retry count:", err)
+		return false
+	}
+	return true
+}
+
+// retryFailedDownloads re-queues failed media once a retry is due, see retryDue.
 func (m *Manager) retryFailedDownloads() {
 	failedMedia, err := m.mediaRepo.GetByStatus(models.StatusFailed)
 	if err != nil {
@@ -1482,43 +3663,68 @@ func (m *Manager) retryFailedDownloads() {
 		return
 	}
 
-	if len(failedMedia) > 0 {
-		m.logger.Info(fmt.Sprintf("Retrying %d failed media items.", len(failedMedia)))
-		for i := range failedMedia {
-			if failedMedia[i].AutoDownload {
-				mediaCopy := failedMedia[i]
-				if err := m.mediaRepo.UpdateStatus(mediaCopy.ID, models.StatusPending); err != nil {
-					m.logger.Error("Failed to update status for retry:", err)
-					continue
-				}
-				m.searchQueue <- mediaCopy
-			}
+	now := time.Now()
+	maxRetries := m.Config().MaxRetries()
+	requeued := 0
+	for i := range failedMedia {
+		mediaCopy := failedMedia[i]
+		if !mediaCopy.AutoDownload || !mediaCopy.Monitored || !m.retryDue(&mediaCopy, now, maxRetries) {
+			continue
+		}
+		if err := m.mediaRepo.UpdateStatus(mediaCopy.ID, models.StatusPending); err != nil {
+			m.logger.Error("Failed to update status for retry:", err)
+			continue
+		}
+		m.logger.Info(fmt.Sprintf("Retrying %s (%d/%d)", mediaCopy.Title, mediaCopy.RetryCount, maxRetries))
+		if added, _ := m.enqueueSearch(mediaCopy, true); added {
+			requeued++
 		}
 	}
+
+	if requeued > 0 {
+		m.logger.Info(fmt.Sprintf("Retrying %d failed media items.", requeued))
+	}
 }
 
 func (m *Manager) notifyDownloadStarted(media *models.Media, torrentName string) {
-	for _, n := range m.notifiers {
+	for _, n := range m.notifierList() {
 		// Run in a goroutine to avoid blocking the main application flow.
 		go n.NotifyDownloadStart(media, torrentName)
 	}
 }
 
 func (m *Manager) notifyNotEnoughSpace(media *models.Media, torrentName string) {
-	for _, n := range m.notifiers {
+	for _, n := range m.notifierList() {
 		// Run in a goroutine to avoid blocking the main application flow.
 		go n.NotifyNotEnoughSpace(media, torrentName)
 	}
 }
 
+func (m *Manager) notifyDownloadError(media *models.Media, torrentName string) {
+	for _, n := range m.notifierList() {
+		// Run in a goroutine to avoid blocking the main application flow.
+		go n.NotifyDownloadError(media, torrentName)
+	}
+}
+
 func (m *Manager) notifyDownloadCompleted(media *models.Media, torrentName string) {
-	for _, n := range m.notifiers {
+	for _, n := range m.notifierList() {
 		// Run in a goroutine to avoid blocking the main application flow.
 		go n.NotifyDownloadComplete(media, torrentName)
 	}
 }
 
+func (m *Manager) notifyShowComplete(media *models.Media) {
+	for _, n := range m.notifierList() {
+		if n, ok := n.(notifications.ShowCompleteNotifier); ok {
+			// Run in a goroutine to avoid blocking the main application flow.
+			go n.NotifyShowComplete(media)
+		}
+	}
+}
+
 func (m *Manager) GetMediaFilePath(mediaID int, seasonNumber int, episodeNumber int) (string, error) {
+	cfg := m.Config()
 	media, err := m.mediaRepo.GetByID(mediaID)
 	if err != nil {
 		return "", err
@@ -1530,11 +3736,11 @@ func (m *Manager) GetMediaFilePath(mediaID int, seasonNumber int, episodeNumber
 	var baseDestPath string
 	switch media.Type {
 	case models.MediaTypeMovie:
-		baseDestPath = m.config.Movies.DestinationFolder
+		baseDestPath = cfg.Movies.DestinationFolder
 	case models.MediaTypeTVShow:
-		baseDestPath = m.config.TVShows.DestinationFolder
+		baseDestPath = cfg.TVShows.DestinationFolder
 	case models.MediaTypeAnime:
-		baseDestPath = m.config.Anime.DestinationFolder
+		baseDestPath = cfg.Anime.DestinationFolder
 	default:
 		return "", fmt.Errorf("unknown media type: %s", media.Type)
 	}
@@ -1547,8 +3753,7 @@ func (m *Manager) GetMediaFilePath(mediaID int, seasonNumber int, episodeNumber
 		if seasonNumber <= 0 {
 			return "", fmt.Errorf("season number must be provided for TV shows")
 		}
-		seasonFolderName := fmt.Sprintf("S%02d", seasonNumber)
-		fullPath = filepath.Join(fullPath, seasonFolderName)
+		fullPath = filepath.Join(fullPath, cfg.SeasonFolder(string(media.Type), seasonNumber))
 	}
 
 	// Scan the directory for a video file
@@ -1799,10 +4004,90 @@ func getLanguageLabel(langCode string) string {
 	return strings.ToUpper(langCode)
 }
 
-// UpdateMediaSettings updates the settings for a given media item.
-func (m *Manager) UpdateMediaSettings(id int, minQuality, maxQuality string, autoDownload bool) error {
+// UpdateMediaSettings updates the settings for a given media item. A nil monitored,
+// qualityProfile, dateBased or includeSpecials leaves that setting unchanged; an empty
+// profile clears it.
+func (m *Manager) UpdateMediaSettings(id int, minQuality, maxQuality string, autoDownload bool, monitored *bool, qualityProfile *string, dateBased, includeSpecials *bool) error {
 	m.logger.Info(fmt.Sprintf("Updating settings for media ID %d: minQ=%s, maxQ=%s, auto=%t", id, minQuality, maxQuality, autoDownload))
-	return m.mediaRepo.UpdateSettings(id, minQuality, maxQuality, autoDownload)
+	if qualityProfile != nil && *qualityProfile != "" {
+		if _, ok := m.Config().QualityProfiles[*qualityProfile]; !ok {
+			return fmt.Errorf("%w: unknown quality profile '%s'", ErrInvalidSetting, *qualityProfile)
+		}
+	}
+	var media *models.Media
+	if includeSpecials != nil {
+		var err error
+		if media, err = m.mediaRepo.GetByID(id); err != nil {
+			return err
+		}
+		if media != nil && media.Type == models.MediaTypeMovie && *includeSpecials {
+			return fmt.Errorf("%w: movies have no specials to include", ErrInvalidSetting)
+		}
+	}
+	if err := m.mediaRepo.UpdateSettings(id, minQuality, maxQuality, autoDownload); err != nil {
+		return err
+	}
+	if qualityProfile != nil {
+		if err := m.mediaRepo.SetQualityProfile(id, *qualityProfile); err != nil {
+			return err
+		}
+	}
+	if dateBased != nil {
+		if err := m.mediaRepo.SetDateBased(id, *dateBased); err != nil {
+			return err
+		}
+	}
+	if includeSpecials != nil {
+		if media != nil && media.IncludeSpecials != *includeSpecials {
+			if err := m.setIncludeSpecials(media, *includeSpecials); err != nil {
+				return err
+			}
+		}
+	}
+	if monitored != nil {
+		return m.SetMonitored(id, *monitored)
+	}
+	return nil
+}
+
+// NamedQualityProfile is a configured quality profile together with its name.
+type NamedQualityProfile struct {
+	Name string `json:"name"`
+	config.QualityProfile
+}
+
+// GetQualityProfiles returns the configured quality profiles sorted by name.
+func (m *Manager) GetQualityProfiles() []NamedQualityProfile {
+	cfg := m.Config()
+	profiles := make([]NamedQualityProfile, 0, len(cfg.QualityProfiles))
+	for name, profile := range cfg.QualityProfiles {
+		profiles = append(profiles, NamedQualityProfile{Name: name, QualityProfile: profile})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// SetMonitored pauses (false) or resumes (true) automatic searching for a media item.
+// Paused items are skipped by the scheduled searches, RSS matching and retries.
+func (m *Manager) SetMonitored(id int, monitored bool) error {
+	media, err := m.mediaRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if media == nil {
+		return fmt.Errorf("media with id %d %w", id, models.ErrNotFound)
+	}
+	m.logger.WithField("media_id", id).Info(fmt.Sprintf("Setting monitored=%t for %s", monitored, media.Title))
+	return m.mediaRepo.SetMonitored(id, monitored)
+}
+
+// Config returns the configuration in use, which SaveAndReloadConfig replaces.
+func (m *Manager) Config() *config.Config {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.config
 }
 
 // This function reads the config file content
@@ -1821,31 +4106,43 @@ func (m *Manager) GetConfig() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
-}
 
-func (m *Manager) TestIndexerConnection(indexerKey string) (bool, error) {
-	var clientToTest indexers.Client
-	var sourceURL string
+	// Never hand credentials to the UI; SaveAndReloadConfig restores them on save.
+	redacted, err := config.RedactYAML(data)
+	if err != nil {
+		return "", err
+	}
+	return string(redacted), nil
+}
 
-	// Find the client that matches the key.
-	for _, clients := range m.indexerClients {
+// findIndexerClient returns the configured indexer whose URL or label equals key,
+// the same keys used for indexers in the system status.
+func (m *Manager) findIndexerClient(key string) (IndexerClientWithMode, bool) {
+	indexerClients := m.indexers()
+	for _, clients := range indexerClients {
 		for _, clientWithMode := range clients {
-			// A simple check to see if the key is part of the URL.
-			if strings.Contains(clientWithMode.Source.URL, indexerKey) {
-				clientToTest = clientWithMode.Client
-				sourceURL = clientWithMode.Source.URL
-				break
+			if clientWithMode.Source.URL == key {
+				return clientWithMode, true
 			}
 		}
-		if clientToTest != nil {
-			break
+	}
+	for _, clients := range indexerClients {
+		for _, clientWithMode := range clients {
+			if strings.EqualFold(clientWithMode.Source.Label(), key) {
+				return clientWithMode, true
+			}
 		}
 	}
+	return IndexerClientWithMode{}, false
+}
 
-	if clientToTest == nil {
+func (m *Manager) TestIndexerConnection(indexerKey string) (bool, error) {
+	clientWithMode, found := m.findIndexerClient(indexerKey)
+	if !found {
 		return false, fmt.Errorf("indexer '%s' not found in any configuration", indexerKey)
 	}
+	clientToTest := clientWithMode.Client
+	sourceURL := clientWithMode.Source.URL
 
 	// Perform the actual health check on the found client.
 	ok, err := clientToTest.HealthCheck()
@@ -1861,10 +4158,11 @@ func (m *Manager) TestIndexerConnection(indexerKey string) (bool, error) {
 }
 
 func (m *Manager) TestTorrentConnection() (bool, error) {
-	if m.torrentClient == nil {
+	client, _ := m.downloadClients()
+	if client == nil {
 		return false, fmt.Errorf("torrent client not initialized")
 	}
-	return m.torrentClient.HealthCheck()
+	return client.HealthCheck()
 }
 
 func (m *Manager) GetAnimeSearchTerms(mediaID int) ([]models.AnimeSearchTerm, error) {
@@ -1910,23 +4208,64 @@ func (m *Manager) GetCalendarEvents() ([]CalendarEvent, error) {
 	return events, nil
 }
 
-func (m *Manager) reloadConfig(cfg *config.Config) {
-	m.config = cfg
-	m.notifiers = make([]notifications.Notifier, 0)
-	m.indexerClients = make(map[models.MediaType][]IndexerClientWithMode)
-	m.metadataClients = make(map[models.MediaType][]metadata.Client)
-
-	// --- Initialize Timeouts ---
-	searchTimeout := time.Duration(cfg.App.SearchTimeout) * time.Second
-	if cfg.App.SearchTimeout <= 0 {
-		searchTimeout = 30 * time.Second
+// indexerResponseDir is where app.save_indexer_responses keeps search responses,
+// under the data path.
+const indexerResponseDir = "debug"
+
+// newIndexerClient builds the search client for a source of the media type's section,
+// sending the given headers with every request and saving search responses with the
+// recorder when it isn't nil. RSS sources are read by the feed checker rather than
+// searched, so they (and unknown types) get nil.
+func newIndexerClient(source config.SourceConfig, mediaType models.MediaType, httpConfig utils.HTTPClientConfig, recorder *indexers.ResponseRecorder) indexers.Client {
+	recorder = recorder.For(source.Label())
+	switch source.Type {
+	case "scarf":
+		return indexers.NewScarfClient(source.URL, source.APIKey, httpConfig, source.FlareSolverrURL, recorder)
+	case "jackett":
+		return indexers.NewJackettClient(source.URL, source.APIKey, httpConfig, source.FlareSolverrURL, recorder)
+	case "prowlarr":
+		categories := source.IndexerCategories
+		if len(categories) == 0 && mediaType == models.MediaTypeAnime {
+			categories = indexers.ProwlarrAnimeCategories
+		}
+		return indexers.NewProwlarrClient(source.URL, source.APIKey, categories, httpConfig, recorder)
 	}
-	m.httpClient.Timeout = searchTimeout
+	return nil
+}
+
+// configClients are the clients built from a config, which installConfig swaps in.
+type configClients struct {
+	httpClient            *http.Client
+	notifiers             []notifications.Notifier
+	postProcessor         *PostProcessor
+	indexerClients        map[models.MediaType][]IndexerClientWithMode
+	metadataClients       map[models.MediaType][]metadata.Client
+	torrentClient         torrent.TorrentClient
+	sectionTorrentClients map[models.MediaType]torrent.TorrentClient
+}
 
-	metadataTimeout := time.Duration(cfg.Metadata.Timeout) * time.Second
-	if cfg.Metadata.Timeout <= 0 {
-		metadataTimeout = 15 * time.Second
+// reloadConfig builds the clients of cfg, then swaps them and cfg in at once, so
+// readers see either the old clients or the new ones. When a client can't be built
+// nothing is swapped and the error is returned.
+func (m *Manager) reloadConfig(cfg *config.Config) error {
+	clients, err := m.buildClients(cfg)
+	if err != nil {
+		return err
 	}
+	m.installConfig(cfg, clients)
+	return nil
+}
+
+// buildClients creates the notifiers, metadata/indexer clients and download clients
+// of cfg, without using them yet.
+func (m *Manager) buildClients(cfg *config.Config) (*configClients, error) {
+	notifiers := make([]notifications.Notifier, 0)
+	indexerClients := make(map[models.MediaType][]IndexerClientWithMode)
+	metadataClients := make(map[models.MediaType][]metadata.Client)
+
+	// Indexers and metadata providers get their timeout, the User-Agent and the proxy
+	// from the config; download clients connect directly
+	httpClient := utils.NewHTTPClient(cfg.SearchHTTPConfig(nil))
 
 	// --- Initialize Notifiers ---
 	for _, notifierName := range cfg.Automation.Notifications {
@@ -1934,16 +4273,26 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 		case "pushbullet":
 			if cfg.Notifications.Pushbullet.APIKey != "" {
 				client := notifications.NewPushbulletClient(cfg.Notifications.Pushbullet.APIKey, m.logger)
-				m.notifiers = append(m.notifiers, client)
+				notifiers = append(notifiers, client)
 				m.logger.Info("Pushbullet notifier enabled.")
 			}
 		}
 	}
 
-	m.postProcessor = NewPostProcessor(cfg, m.logger, models.NewMediaRepository(m.db, m.logger), m.notifiers)
+	postProcessor := NewPostProcessor(cfg, m.logger, models.NewMediaRepository(m.db, m.logger), notifiers)
+
+	// Debug mode: keep the raw response of every indexer search
+	var recorder *indexers.ResponseRecorder
+	if cfg.App.SaveIndexerResponses {
+		recorder = indexers.NewResponseRecorder(filepath.Join(cfg.App.DataPath, indexerResponseDir), m.logger)
+		m.logger.Warn("Saving indexer responses to", filepath.Join(cfg.App.DataPath, indexerResponseDir), "(app.save_indexer_responses)")
+	}
+
+	// Indexers add their source's own headers to the User-Agent
+	metadataHTTP := cfg.MetadataHTTPConfig()
 
 	// Create a TMDB client instance to be shared
-	tmdbClient := metadata.NewTMDBClient(cfg.Metadata.TMDB.APIKey, cfg.Metadata.Language, metadataTimeout)
+	tmdbClient := metadata.NewTMDBClient(cfg.Metadata.TMDB.APIKey, cfg.Metadata.Language, cfg.Metadata.TMDB.Region, cfg.Metadata.TMDB.IncludeAdult, metadataHTTP, m.logger)
 
 	// Helper function to initialize metadata providers
 	initMetadataProvider := func(provider string) metadata.Client {
@@ -1951,26 +4300,13 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 		case "tmdb":
 			return tmdbClient
 		case "imdb":
-			return metadata.NewIMDBClient(cfg.Metadata.IMDB.APIKey, metadataTimeout, m.logger)
+			return metadata.NewIMDBClient(cfg.Metadata.IMDB.APIKey, metadataHTTP, m.logger)
 		case "tvmaze":
-			return metadata.NewTVmazeClient(metadataTimeout)
+			return metadata.NewTVmazeClient(metadataHTTP)
 		case "anilist":
-			return metadata.NewAniListClient(metadataTimeout)
+			return metadata.NewAniListClient(metadataHTTP)
 		case "trakt":
-			return metadata.NewTraktClient(cfg.Metadata.Trakt.ClientID, tmdbClient, metadataTimeout, m.logger)
-		}
-		return nil
-	}
-
-	// Helper function to initialize indexer sources
-	initIndexerClient := func(source config.SourceConfig) indexers.Client {
-		switch source.Type {
-		case "scarf":
-			return indexers.NewScarfClient(source.URL, source.APIKey, searchTimeout)
-		case "jackett":
-			return indexers.NewJackettClient(source.URL, source.APIKey, searchTimeout)
-		case "prowlarr":
-			return indexers.NewProwlarrClient(source.URL, source.APIKey, searchTimeout)
+			return metadata.NewTraktClient(cfg.Metadata.Trakt.ClientID, tmdbClient, metadataHTTP, m.logger)
 		}
 		return nil
 	}
@@ -1978,13 +4314,13 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 	// Initialize Movie Clients
 	for _, providerName := range cfg.Movies.Providers {
 		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeMovie] = append(m.metadataClients[models.MediaTypeMovie], client)
+			metadataClients[models.MediaTypeMovie] = append(metadataClients[models.MediaTypeMovie], client)
 		}
 	}
 	for _, source := range cfg.Movies.Sources {
 		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeMovie] = append(m.indexerClients[models.MediaTypeMovie], IndexerClientWithMode{
+			if client := newIndexerClient(source, models.MediaTypeMovie, cfg.SearchHTTPConfig(source.Headers), recorder); client != nil {
+				indexerClients[models.MediaTypeMovie] = append(indexerClients[models.MediaTypeMovie], IndexerClientWithMode{
 					Client: client,
 					Source: source,
 				})
@@ -1995,13 +4331,13 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 	// Initialize TV Show Clients
 	for _, providerName := range cfg.TVShows.Providers {
 		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeTVShow] = append(m.metadataClients[models.MediaTypeTVShow], client)
+			metadataClients[models.MediaTypeTVShow] = append(metadataClients[models.MediaTypeTVShow], client)
 		}
 	}
 	for _, source := range cfg.TVShows.Sources {
 		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeTVShow] = append(m.indexerClients[models.MediaTypeTVShow], IndexerClientWithMode{
+			if client := newIndexerClient(source, models.MediaTypeTVShow, cfg.SearchHTTPConfig(source.Headers), recorder); client != nil {
+				indexerClients[models.MediaTypeTVShow] = append(indexerClients[models.MediaTypeTVShow], IndexerClientWithMode{
 					Client: client,
 					Source: source,
 				})
@@ -2012,13 +4348,13 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 	// Initialize Anime Clients
 	for _, providerName := range cfg.Anime.Providers {
 		if client := initMetadataProvider(providerName); client != nil {
-			m.metadataClients[models.MediaTypeAnime] = append(m.metadataClients[models.MediaTypeAnime], client)
+			metadataClients[models.MediaTypeAnime] = append(metadataClients[models.MediaTypeAnime], client)
 		}
 	}
 	for _, source := range cfg.Anime.Sources {
 		if source.Type != "rss" {
-			if client := initIndexerClient(source); client != nil {
-				m.indexerClients[models.MediaTypeAnime] = append(m.indexerClients[models.MediaTypeAnime], IndexerClientWithMode{
+			if client := newIndexerClient(source, models.MediaTypeAnime, cfg.SearchHTTPConfig(source.Headers), recorder); client != nil {
+				indexerClients[models.MediaTypeAnime] = append(indexerClients[models.MediaTypeAnime], IndexerClientWithMode{
 					Client: client,
 					Source: source,
 				})
@@ -2026,38 +4362,188 @@ func (m *Manager) reloadConfig(cfg *config.Config) {
 		}
 	}
 
-	// Setup Torrent Client
-	switch cfg.TorrentClient.Type {
+	// Setup Torrent Client, plus those of the sections that use another one
+	torrentClient, err := m.newTorrentClient(cfg.TorrentClient)
+	if err != nil {
+		return nil, fmt.Errorf("torrent_client: %w", err)
+	}
+	sectionTorrentClients := make(map[models.MediaType]torrent.TorrentClient)
+	for _, section := range torrentClientSections {
+		override := cfg.TorrentClientFor(string(section.mediaType))
+		if override.SameConnection(cfg.TorrentClient) {
+			continue
+		}
+		client, err := m.newTorrentClient(override)
+		if err != nil {
+			return nil, fmt.Errorf("%s.torrent_client: %w", section.name, err)
+		}
+		sectionTorrentClients[section.mediaType] = client
+		m.logger.Info("Using", override.Type, "at", override.Host, "as the download client for", section.name)
+	}
+
+	return &configClients{
+		httpClient:            httpClient,
+		notifiers:             notifiers,
+		postProcessor:         postProcessor,
+		indexerClients:        indexerClients,
+		metadataClients:       metadataClients,
+		torrentClient:         torrentClient,
+		sectionTorrentClients: sectionTorrentClients,
+	}, nil
+}
+
+// installConfig swaps cfg and its clients in, along with the selector's config.
+func (m *Manager) installConfig(cfg *config.Config, clients *configClients) {
+	m.clientsMu.Lock()
+	m.config = cfg
+	m.torrentSelector.SetConfig(cfg)
+	m.httpClient = clients.httpClient
+	m.notifiers = clients.notifiers
+	m.postProcessor = clients.postProcessor
+	m.indexerClients = clients.indexerClients
+	m.metadataClients = clients.metadataClients
+	m.torrentClient = clients.torrentClient
+	m.sectionTorrentClients = clients.sectionTorrentClients
+	m.clientsMu.Unlock()
+
+	if err := m.torrentSelector.SetFilterLogging(cfg.App.FilterLogLevel == "detail"); err != nil {
+		m.logger.Error("Could not create filter.log:", err)
+	}
+	m.logger.Info("Configuration reloaded successfully.")
+}
+
+// indexers returns the search indexers by media type.
+func (m *Manager) indexers() map[models.MediaType][]IndexerClientWithMode {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.indexerClients
+}
+
+// metadataProviders returns the metadata providers of a media type, in config order.
+func (m *Manager) metadataProviders(mediaType models.MediaType) []metadata.Client {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.metadataClients[mediaType]
+}
+
+// downloadClients returns the global download client and those of the sections that
+// override it, by media type.
+func (m *Manager) downloadClients() (torrent.TorrentClient, map[models.MediaType]torrent.TorrentClient) {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.torrentClient, m.sectionTorrentClients
+}
+
+// processor returns the post-processor of the config in use.
+func (m *Manager) processor() *PostProcessor {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.postProcessor
+}
+
+// sharedHTTPClient returns the HTTP client for feeds and posters, which carries the
+// config's timeout, User-Agent and proxy.
+func (m *Manager) sharedHTTPClient() *http.Client {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.httpClient
+}
+
+// notifierList returns the enabled notifiers.
+func (m *Manager) notifierList() []notifications.Notifier {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return m.notifiers
+}
+
+// torrentClientSections are the config sections that can override torrent_client.
+var torrentClientSections = []struct {
+	name      string
+	mediaType models.MediaType
+}{
+	{"movies", models.MediaTypeMovie},
+	{"tv-shows", models.MediaTypeTVShow},
+	{"anime", models.MediaTypeAnime},
+}
+
+// newTorrentClient creates the download client described by a torrent_client block.
+func (m *Manager) newTorrentClient(cfg config.TorrentClientConfig) (torrent.TorrentClient, error) {
+	switch cfg.Type {
 	case "transmission":
-		m.torrentClient = torrent.NewTransmissionClient(cfg.TorrentClient.Host, cfg.TorrentClient.Username, cfg.TorrentClient.Password)
+		return torrent.NewTransmissionClient(cfg.Host, cfg.Username, cfg.Password, cfg.RequestTimeout()), nil
 	case "qbittorrent":
-		m.torrentClient = torrent.NewQBittorrentClient(cfg.TorrentClient.Host, cfg.TorrentClient.Username, cfg.TorrentClient.Password, m.logger)
+		return torrent.NewQBittorrentClient(cfg.Host, cfg.Username, cfg.Password, cfg.RequestTimeout(), m.logger), nil
 	case "aria2":
-		m.torrentClient = torrent.NewAria2Client(cfg.TorrentClient.Host, cfg.TorrentClient.Secret)
+		return torrent.NewAria2Client(cfg.Host, cfg.Secret, cfg.RequestTimeout()), nil
 	case "deluge":
-		client, err := torrent.NewDelugeClient(cfg.TorrentClient.Host, cfg.TorrentClient.Password)
+		client, err := torrent.NewDelugeClient(cfg.Host, cfg.Password, cfg.RequestTimeout())
 		if err != nil {
-			m.logger.Fatal("Failed to create Deluge client:", err)
+			return nil, fmt.Errorf("failed to create Deluge client: %w", err)
 		}
-		m.torrentClient = client
-	default:
-		m.logger.Fatal("Unsupported torrent client type:", cfg.TorrentClient.Type)
+		return client, nil
 	}
+	return nil, fmt.Errorf("unsupported torrent client type: %s", cfg.Type)
+}
 
-	m.logger.Info("Configuration reloaded successfully.")
+// torrentClientFor returns the download client of a media type: its section's own
+// client, or the global one.
+func (m *Manager) torrentClientFor(mediaType models.MediaType) torrent.TorrentClient {
+	global, sections := m.downloadClients()
+	if client, ok := sections[mediaType]; ok {
+		return client
+	}
+	return global
+}
+
+// allTorrentClients returns the global download client followed by those of the
+// sections that override it.
+func (m *Manager) allTorrentClients() []torrent.TorrentClient {
+	global, sections := m.downloadClients()
+	clients := []torrent.TorrentClient{global}
+	for _, section := range torrentClientSections {
+		if client, ok := sections[section.mediaType]; ok {
+			clients = append(clients, client)
+		}
+	}
+	return clients
 }
 
 func (m *Manager) SaveAndReloadConfig(configContent string) error {
+	m.configFileMu.Lock()
+	defer m.configFileMu.Unlock()
+	return m.saveAndReloadConfig(configContent)
+}
+
+// saveAndReloadConfig does the work of SaveAndReloadConfig; the caller holds configFileMu.
+func (m *Manager) saveAndReloadConfig(configContent string) error {
 	configPath := "config/config.yml"
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		configPath = "config.yml"
 	}
 
+	// Put back any secrets the client received redacted and left unchanged.
+	if current, err := ioutil.ReadFile(configPath); err == nil {
+		restored, err := config.RestoreRedactedYAML([]byte(configContent), current)
+		if err != nil {
+			return err
+		}
+		configContent = string(restored)
+	}
+
 	// First, validate the new config content
 	var newCfg config.Config
 	if err := yaml.Unmarshal([]byte(configContent), &newCfg); err != nil {
 		return fmt.Errorf("new configuration is invalid: %w", err)
 	}
+	newCfg.ApplyDefaults()
+	if err := newCfg.Validate(); err != nil {
+		return fmt.Errorf("new configuration is invalid: %w", err)
+	}
+	// A client that can't be built rejects the config before anything is saved
+	clients, err := m.buildClients(&newCfg)
+	if err != nil {
+		return fmt.Errorf("new configuration is invalid: %w", err)
+	}
 
 	// If valid, write the new config to the file
 	if err := ioutil.WriteFile(configPath, []byte(configContent), 0644); err != nil {
@@ -2065,7 +4551,91 @@ func (m *Manager) SaveAndReloadConfig(configContent string) error {
 	}
 
 	// Now, reload the config in the manager
-	m.reloadConfig(&newCfg)
+	schedulesChanged := !maps.Equal(m.Config().Automation.Schedules, newCfg.Automation.Schedules)
+	m.installConfig(&newCfg, clients)
+	if schedulesChanged && m.schedulerStarted() {
+		m.scheduleTasks()
+	}
+	go m.checkHealth() // So the status page shows the new clients
 
 	return nil
 }
+
+// Levels of the torrent filter log: "detail" logs every passed and rejected result to
+// filter.log, "none" nothing.
+const (
+	filterLogDetail = "detail"
+	filterLogNone   = "none"
+)
+
+// FilterLogLevel returns the current level of the torrent filter log.
+func (m *Manager) FilterLogLevel() string {
+	if m.torrentSelector.FilterLogging() {
+		return filterLogDetail
+	}
+	return filterLogNone
+}
+
+// SetFilterLogLevel switches the torrent filter log without a restart. The config file is
+// left alone, so app.filter_log_level applies again once the config is reloaded.
+func (m *Manager) SetFilterLogLevel(level string) error {
+	if level != filterLogDetail && level != filterLogNone {
+		return fmt.Errorf("unknown filter log level '%s' (use '%s' or '%s')", level, filterLogNone, filterLogDetail)
+	}
+	if err := m.torrentSelector.SetFilterLogging(level == filterLogDetail); err != nil {
+		return fmt.Errorf("failed to switch filter logging: %w", err)
+	}
+	m.logger.Info("Filter log level set to", level)
+	return nil
+}
+
+// GetFilterLog returns the last lines of filter.log, none if it doesn't exist yet.
+func (m *Manager) GetFilterLog(lines int) ([]string, error) {
+	tail, err := utils.TailFile(filepath.Join(m.Config().App.DataPath, utils.FilterLogName), lines)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	return tail, err
+}
+
+// GetSettings returns the structured, credential-free subset of the running config.
+func (m *Manager) GetSettings() config.Settings {
+	return m.Config().Settings()
+}
+
+// UpdateSettings merges a partial JSON settings document into the current settings,
+// validates the result, and persists it through SaveAndReloadConfig.
+func (m *Manager) UpdateSettings(patch []byte) (config.Settings, error) {
+	m.configFileMu.Lock()
+	defer m.configFileMu.Unlock()
+	settings := m.Config().Settings()
+
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return config.Settings{}, fmt.Errorf("invalid settings: %w", err)
+	}
+	settings.ApplyDefaults()
+	if err := settings.Validate(); err != nil {
+		return config.Settings{}, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	configPath := "config/config.yml"
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		configPath = "config.yml"
+	}
+	current, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return config.Settings{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	updated, err := config.ApplySettingsYAML(current, settings)
+	if err != nil {
+		return config.Settings{}, err
+	}
+	if err := m.saveAndReloadConfig(string(updated)); err != nil {
+		return config.Settings{}, err
+	}
+
+	return m.Config().Settings(), nil
+}
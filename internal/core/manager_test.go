@@ -0,0 +1,2007 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reel/internal/clients/indexers"
+	"reel/internal/clients/metadata"
+	"reel/internal/clients/notifications"
+	"reel/internal/clients/torrent"
+	"reel/internal/config"
+	"reel/internal/database"
+	"reel/internal/database/models"
+	"reel/internal/utils"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/shirou/gopsutil/disk"
+)
+
+// fakeTorrentClient is an in-memory download client.
+type fakeTorrentClient struct {
+	mu       sync.Mutex
+	torrents map[string]torrent.TorrentStatus
+	added    []string            // Download URLs, in the order they were added
+	trackers map[string][]string // Trackers added to each torrent, by hash
+}
+
+func newFakeTorrentClient(torrents ...torrent.TorrentStatus) *fakeTorrentClient {
+	client := &fakeTorrentClient{torrents: make(map[string]torrent.TorrentStatus)}
+	for _, t := range torrents {
+		client.torrents[t.Hash] = t
+	}
+	return client
+}
+
+func (c *fakeTorrentClient) AddTorrent(magnetLink string, downloadPath string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash := utils.InfoHash(magnetLink, nil)
+	c.torrents[hash] = torrent.TorrentStatus{Hash: hash, DownloadDir: downloadPath}
+	c.added = append(c.added, magnetLink)
+	return hash, nil
+}
+
+func (c *fakeTorrentClient) AddTorrentFile(fileContent []byte, downloadPath string) (string, error) {
+	return c.AddTorrent(string(fileContent), downloadPath)
+}
+
+func (c *fakeTorrentClient) GetTorrentStatus(hash string) (torrent.TorrentStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.torrents[hash]
+	if !ok {
+		return torrent.TorrentStatus{}, os.ErrNotExist
+	}
+	return status, nil
+}
+
+func (c *fakeTorrentClient) ListTorrents() ([]torrent.TorrentStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var torrents []torrent.TorrentStatus
+	for _, t := range c.torrents {
+		torrents = append(torrents, t)
+	}
+	return torrents, nil
+}
+
+func (c *fakeTorrentClient) RemoveTorrent(hash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.torrents, hash)
+	return nil
+}
+
+func (c *fakeTorrentClient) AddTrackers(hash string, trackers []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.trackers == nil {
+		c.trackers = make(map[string][]string)
+	}
+	c.trackers[hash] = append(c.trackers[hash], trackers...)
+	return nil
+}
+
+func (c *fakeTorrentClient) HealthCheck() (bool, error) { return true, nil }
+
+func newTestRepo(t *testing.T) *models.MediaRepository {
+	t.Helper()
+	return models.NewMediaRepository(openTestDB(t), utils.NewLogger(false, io.Discard))
+}
+
+// openTestDB returns a migrated database in a temporary folder.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "reel.db"), database.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.RunMigrations(db, utils.NewLogger(false, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestManager returns a manager around cfg and client without the clients,
+// scheduler and workers NewManager starts.
+func newTestManager(t *testing.T, cfg *config.Config, client torrent.TorrentClient) *Manager {
+	t.Helper()
+	logger := utils.NewLogger(false, io.Discard)
+	repo := newTestRepo(t)
+	return &Manager{
+		config:                cfg,
+		mediaRepo:             repo,
+		torrentClient:         client,
+		sectionTorrentClients: make(map[models.MediaType]torrent.TorrentClient),
+		torrentSelector:       NewTorrentSelector(cfg, logger),
+		postProcessor:         NewPostProcessor(cfg, logger, repo, nil),
+		logger:                logger,
+		scheduler:             cron.New(),
+		searchQueue:           make(chan models.Media, 100),
+		queuedMedia:           make(map[int]bool),
+		refreshing:            make(map[int]bool),
+		recentGrabs:           make(map[string]time.Time),
+		indexerFailures:       make(map[string]int),
+		indexerTripped:        make(map[string]time.Time),
+		indexerClients:        make(map[models.MediaType][]IndexerClientWithMode),
+	}
+}
+
+// writeOld creates a file whose modification time is past orphanGracePeriod.
+func writeOld(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	for p := path; p != filepath.Dir(p); p = filepath.Dir(p) {
+		os.Chtimes(p, old, old)
+		if filepath.Base(filepath.Dir(p)) == "downloads" {
+			break
+		}
+	}
+}
+
+func TestCleanupOrphanedDownloads(t *testing.T) {
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	library := filepath.Join(root, "library")
+
+	// The client names the folder differently from the release that was grabbed
+	writeOld(t, filepath.Join(downloads, "Seeding.Movie.2020", "movie.mkv"))
+	// Removed from the client, but the library links into it
+	writeOld(t, filepath.Join(downloads, "Linked.Movie.2021", "movie.mkv"))
+	// Nothing refers to it
+	writeOld(t, filepath.Join(downloads, "Forgotten.Movie.2019", "movie.mkv"))
+	if err := os.MkdirAll(filepath.Join(library, "Linked Movie (2021)"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(downloads, "Linked.Movie.2021", "movie.mkv"), filepath.Join(library, "Linked Movie (2021)", "Linked Movie (2021).mkv")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Automation.CleanOrphanedDownloads = true
+	cfg.TorrentClient.DownloadPath = downloads
+	cfg.Movies.DestinationFolder = library
+	client := newFakeTorrentClient(torrent.TorrentStatus{Hash: "aaaa", Name: "Seeding.Movie.2020", DownloadDir: downloads})
+	m := newTestManager(t, cfg, client)
+	tracked := createMovie(t, m.mediaRepo, "Seeding Movie", 2020)
+	hash, name := "aaaa", "Seeding Movie 2020 1080p WEB-DL" // the indexer title
+	if err := m.mediaRepo.UpdateDownloadInfo(tracked.ID, models.StatusDownloading, &hash, &name); err != nil {
+		t.Fatal(err)
+	}
+
+	// Orphans are only removed on the second run that finds them
+	m.cleanupOrphanedDownloads()
+	m.cleanupOrphanedDownloads()
+
+	for entry, want := range map[string]bool{"Seeding.Movie.2020": true, "Linked.Movie.2021": true, "Forgotten.Movie.2019": false} {
+		_, err := os.Stat(filepath.Join(downloads, entry))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", entry, exists, want)
+		}
+	}
+}
+
+func TestCleanupOrphanedDownloadsOfSectionClient(t *testing.T) {
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	animeDownloads := filepath.Join(root, "anime", "downloads")
+
+	// Seeding in the anime client, which the global client doesn't know about
+	writeOld(t, filepath.Join(animeDownloads, "Seeding.Anime.S01", "episode.mkv"))
+	// Left behind in the anime client's download path
+	writeOld(t, filepath.Join(animeDownloads, "Forgotten.Anime.S01", "episode.mkv"))
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Automation.CleanOrphanedDownloads = true
+	cfg.TorrentClient = config.TorrentClientConfig{Type: "qbittorrent", Host: "global", DownloadPath: downloads}
+	cfg.Anime.TorrentClient = &config.TorrentClientConfig{Type: "transmission", Host: "anime", DownloadPath: animeDownloads}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	m.sectionTorrentClients[models.MediaTypeAnime] = newFakeTorrentClient(torrent.TorrentStatus{Hash: "bbbb", Name: "Seeding.Anime.S01", DownloadDir: animeDownloads})
+
+	m.cleanupOrphanedDownloads()
+	m.cleanupOrphanedDownloads()
+
+	for entry, want := range map[string]bool{"Seeding.Anime.S01": true, "Forgotten.Anime.S01": false} {
+		_, err := os.Stat(filepath.Join(animeDownloads, entry))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", entry, exists, want)
+		}
+	}
+}
+
+func TestDownloadsUseTheirSectionClient(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	cfg.TVShows.DownloadFolder = t.TempDir()
+	movies, shows := newFakeTorrentClient(), newFakeTorrentClient()
+	m := newTestManager(t, cfg, movies)
+	m.sectionTorrentClients[models.MediaTypeTVShow] = shows
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	show := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+	movieHash, episodeHash := strings.Repeat("1", 40), strings.Repeat("2", 40)
+
+	if err := m.StartDownload(context.Background(), movie.ID, indexers.IndexerResult{Title: "Heat.1995.1080p", DownloadURL: "magnet:?xt=urn:btih:" + movieHash}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.StartEpisodeDownload(context.Background(), show.ID, 1, 1, indexers.IndexerResult{Title: "Severance.S01E01.1080p", DownloadURL: "magnet:?xt=urn:btih:" + episodeHash}); err != nil {
+		t.Fatal(err)
+	}
+	for name, tt := range map[string]struct {
+		client *fakeTorrentClient
+		hash   string
+	}{"movie": {movies, movieHash}, "episode": {shows, episodeHash}} {
+		if torrents, _ := tt.client.ListTorrents(); len(torrents) != 1 || torrents[0].Hash != tt.hash {
+			t.Fatalf("%s client holds %v, want only %s", name, torrents, tt.hash)
+		}
+		// Each client reports progress for its own torrent only
+		tt.client.mu.Lock()
+		tt.client.torrents[tt.hash] = torrent.TorrentStatus{Hash: tt.hash, Progress: 0.5}
+		tt.client.mu.Unlock()
+	}
+
+	m.updateDownloadStatus()
+
+	media, err := m.mediaRepo.GetByID(movie.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.Status != models.StatusDownloading || media.Progress != 0.5 {
+		t.Errorf("movie is %s at %v, want downloading at 0.5", media.Status, media.Progress)
+	}
+	tvShow, err := m.mediaRepo.GetTVShowByMediaID(show.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if episode := tvShow.Seasons[0].Episodes[0]; episode.Status != models.StatusDownloading || episode.Progress != 0.5 {
+		t.Errorf("episode is %s at %v, want downloading at 0.5", episode.Status, episode.Progress)
+	}
+}
+
+func createMovie(t *testing.T, repo *models.MediaRepository, title string, year int) *models.Media {
+	t.Helper()
+	media := &models.Media{Type: models.MediaTypeMovie, Title: title, Year: year, Language: "en", Status: models.StatusPending, Monitored: true, AutoDownload: true}
+	if err := repo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+// createShow adds a monitored show with one season holding a pending episode per air date.
+func createShow(t *testing.T, repo *models.MediaRepository, title string, seasonNumber int, airDates ...string) *models.Media {
+	t.Helper()
+	show := &models.TVShow{Status: "Running"}
+	if err := repo.CreateTVShow(show); err != nil {
+		t.Fatal(err)
+	}
+	season := &models.Season{ShowID: show.ID, SeasonNumber: seasonNumber}
+	if err := repo.CreateSeason(season); err != nil {
+		t.Fatal(err)
+	}
+	for i, airDate := range airDates {
+		if err := repo.CreateEpisode(&models.Episode{SeasonID: season.ID, EpisodeNumber: i + 1, Title: title, AirDate: airDate, Status: models.StatusPending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	media := &models.Media{Type: models.MediaTypeTVShow, Title: title, Year: 2020, Language: "en", TVShowID: &show.ID, Status: models.StatusMonitoring, Monitored: true, AutoDownload: true}
+	if err := repo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+func TestEpisodeGrabCooldown(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.TVShows.DownloadFolder = t.TempDir()
+	client := newFakeTorrentClient()
+	m := newTestManager(t, cfg, client)
+	media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+
+	release := func(n int) indexers.IndexerResult {
+		return indexers.IndexerResult{
+			Title:       fmt.Sprintf("Severance.S01E01.1080p.WEB-GRP%d", n),
+			DownloadURL: fmt.Sprintf("magnet:?xt=urn:btih:%040d", n),
+		}
+	}
+
+	// The RSS check and the pending search find the episode at the same time
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.autoStartEpisodeDownload(media.ID, 1, 1, release(i+1))
+		}()
+	}
+	wg.Wait()
+	if len(client.added) != 1 {
+		t.Fatalf("racing automatic grabs added %d torrents, want 1", len(client.added))
+	}
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("expected exactly one grab to be refused, got %v", errs)
+	}
+
+	// The user's own choice goes ahead within the cooldown
+	if err := m.StartEpisodeDownload(context.Background(), media.ID, 1, 1, release(3)); err != nil {
+		t.Fatalf("manual download refused: %v", err)
+	}
+	if len(client.added) != 2 {
+		t.Fatalf("manual download added %d torrents, want 2 in total", len(client.added))
+	}
+
+	// ...and holds the automatic searches back
+	m.releaseEpisodeGrab(media.ID, 1, 1)
+	if err := m.StartEpisodeDownload(context.Background(), media.ID, 1, 1, release(4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.autoStartEpisodeDownload(media.ID, 1, 1, release(5)); err == nil {
+		t.Error("automatic grab after a manual download was not refused")
+	}
+	if len(client.added) != 3 {
+		t.Errorf("%d torrents were added, want 3", len(client.added))
+	}
+}
+
+// fakeMetadataClient is a metadata provider holding one movie and one show.
+type fakeMetadataClient struct {
+	name  string
+	movie metadata.MovieResult
+	show  metadata.TVShowResult
+	byID  []string // IDs looked up, in order
+}
+
+func (c *fakeMetadataClient) Name() string { return c.name }
+func (c *fakeMetadataClient) SearchMovie(title string, year int) ([]*metadata.MovieResult, error) {
+	movie := c.movie
+	return []*metadata.MovieResult{&movie}, nil
+}
+func (c *fakeMetadataClient) SearchTVShow(title string) ([]*metadata.TVShowResult, error) {
+	show := c.show
+	return []*metadata.TVShowResult{&show}, nil
+}
+func (c *fakeMetadataClient) GetTVShowDetailsByID(tmdbID int) (*metadata.TVShowResult, error) {
+	return c.GetTVShowByID(strconv.Itoa(tmdbID))
+}
+func (c *fakeMetadataClient) GetMovieByID(id string) (*metadata.MovieResult, error) {
+	c.byID = append(c.byID, id)
+	movie := c.movie
+	return &movie, nil
+}
+func (c *fakeMetadataClient) GetTVShowByID(id string) (*metadata.TVShowResult, error) {
+	c.byID = append(c.byID, id)
+	show := c.show
+	return &show, nil
+}
+
+func TestAddMediaInheritsDefaults(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Automation.DefaultLanguage = "es"
+	cfg.Automation.DefaultMinQuality = "720p"
+	cfg.Automation.DefaultMaxQuality = "1080p"
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+
+	tests := []struct {
+		name                             string
+		language, minQuality, maxQuality string
+		want                             [3]string
+	}{
+		{"unset", "", "", "", [3]string{"es", "720p", "1080p"}},
+		{"explicit", "en", "480p", "2160p", [3]string{"en", "480p", "2160p"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media, _, err := m.AddMedia(AddMediaOptions{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995 + i, Language: tt.language, MinQuality: tt.minQuality, MaxQuality: tt.maxQuality})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, err := m.mediaRepo.GetByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := [3]string{stored.Language, stored.MinQuality, stored.MaxQuality}; got != tt.want {
+				t.Errorf("stored language and quality %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddMediaStoresProviderID(t *testing.T) {
+	tmdb := &fakeMetadataClient{name: "tmdb", movie: metadata.MovieResult{ID: "603", Title: "The Matrix", Year: 1999}}
+	trakt := &fakeMetadataClient{name: "trakt", movie: metadata.MovieResult{ID: "481", Title: "Heat", Year: 1995}}
+	tvmaze := &fakeMetadataClient{name: "tvmaze", show: metadata.TVShowResult{ID: "2790", Title: "Severance", Year: 2022, Status: "Running",
+		Seasons: map[int][]metadata.Episode{1: {{EpisodeNumber: 1, Title: "Good News About Hell", AirDate: "2022-02-18"}}}}}
+
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	m.metadataClients = map[models.MediaType][]metadata.Client{
+		models.MediaTypeMovie:  {tmdb, trakt},
+		models.MediaTypeTVShow: {tvmaze},
+	}
+
+	tests := []struct {
+		name         string
+		mediaType    models.MediaType
+		provider     string
+		title        string
+		wantProvider string
+		wantID       string
+		wantTMDB     *int
+	}{
+		{"tmdb movie", models.MediaTypeMovie, "tmdb", "The Matrix", "tmdb", "603", func() *int { id := 603; return &id }()},
+		{"trakt movie", models.MediaTypeMovie, "trakt", "Heat", "trakt", "481", nil},
+		{"tvmaze show", models.MediaTypeTVShow, "", "Severance", "tvmaze", "2790", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media, _, err := m.AddMedia(AddMediaOptions{Type: tt.mediaType, Provider: tt.provider, Title: tt.title})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, err := m.mediaRepo.GetByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.MetadataProvider != tt.wantProvider || stored.MetadataID != tt.wantID {
+				t.Errorf("stored %q %q, want %q %q", stored.MetadataProvider, stored.MetadataID, tt.wantProvider, tt.wantID)
+			}
+			if (stored.TMDBId == nil) != (tt.wantTMDB == nil) || (stored.TMDBId != nil && *stored.TMDBId != *tt.wantTMDB) {
+				t.Errorf("stored tmdb_id %v, want %v", stored.TMDBId, tt.wantTMDB)
+			}
+		})
+	}
+
+	// A refresh asks the provider the item came from, by its ID there
+	movies, err := m.mediaRepo.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, media := range movies {
+		if media.Title == "Heat" {
+			if _, _, err := m.RefreshMetadata(media.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(trakt.byID) != 1 || trakt.byID[0] != "481" {
+		t.Errorf("trakt was asked for %v, want [481]", trakt.byID)
+	}
+	if len(tmdb.byID) != 0 {
+		t.Errorf("tmdb was asked for %v, want nothing", tmdb.byID)
+	}
+}
+
+// fakeIndexer returns the same results for every search.
+type fakeIndexer struct {
+	results      []indexers.IndexerResult
+	queries      []string
+	healthChecks int
+}
+
+func (i *fakeIndexer) SearchMovies(query string, tmdbID string, searchMode string) ([]indexers.IndexerResult, error) {
+	i.queries = append(i.queries, query)
+	return append([]indexers.IndexerResult(nil), i.results...), nil
+}
+func (i *fakeIndexer) SearchTVShows(query string, season int, episode int, searchMode string) ([]indexers.IndexerResult, error) {
+	i.queries = append(i.queries, query)
+	return append([]indexers.IndexerResult(nil), i.results...), nil
+}
+func (i *fakeIndexer) HealthCheck() (bool, error)                    { i.healthChecks++; return true, nil }
+func (i *fakeIndexer) Capabilities() (*indexers.Capabilities, error) { return nil, nil }
+
+func TestDryRunSearchAddsNoTorrent(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	client := newFakeTorrentClient()
+	m := newTestManager(t, cfg, client)
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+		Client: &fakeIndexer{results: []indexers.IndexerResult{{
+			Title:       "Alien.1979.1080p.BluRay.x264-GRP",
+			DownloadURL: "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567",
+			Size:        8 << 30,
+			Seeders:     50,
+		}}},
+		Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search"},
+	}}
+	media := createMovie(t, m.mediaRepo, "Alien", 1979)
+	if err := m.mediaRepo.UpdateSettings(media.ID, "720p", "2160p", true); err != nil {
+		t.Fatal(err)
+	}
+
+	grabs, err := m.DryRunSearch(media.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grabs) != 1 || grabs[0].Torrent.Title != "Alien.1979.1080p.BluRay.x264-GRP" {
+		t.Errorf("DryRunSearch() = %+v, want the BluRay release", grabs)
+	}
+	if len(client.added) != 0 {
+		t.Errorf("dry run added %d torrents", len(client.added))
+	}
+	if got, _ := m.mediaRepo.GetByID(media.ID); got.Status != models.StatusPending {
+		t.Errorf("dry run changed the status to %s", got.Status)
+	}
+
+	if _, err := m.DryRunSearch(999); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("DryRunSearch() of a missing media = %v, want ErrNotFound", err)
+	}
+}
+
+// fakeNotifier reports the shows it was told are complete, and the folders it was
+// told are low on space, on channels.
+type fakeNotifier struct {
+	completed chan string
+	lowSpace  chan string
+}
+
+func (n *fakeNotifier) NotifyDownloadStart(media *models.Media, torrentName string)       {}
+func (n *fakeNotifier) NotifyNotEnoughSpace(media *models.Media, torrentName string)      {}
+func (n *fakeNotifier) NotifyDownloadError(media *models.Media, torrentName string)       {}
+func (n *fakeNotifier) NotifyDownloadComplete(media *models.Media, torrentName string)    {}
+func (n *fakeNotifier) NotifyPostProcessComplete(media *models.Media, torrentName string) {}
+func (n *fakeNotifier) NotifyLowDiskSpace(path string, freeMB uint64)                     { n.lowSpace <- path }
+func (n *fakeNotifier) Test() error                                                       { return nil }
+func (n *fakeNotifier) NotifyShowComplete(media *models.Media)                            { n.completed <- media.Title }
+
+func TestEndedShowIsArchivedOnce(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	notifier := &fakeNotifier{completed: make(chan string, 2)}
+	m.notifiers = []notifications.Notifier{notifier}
+	media := createShow(t, m.mediaRepo, "Firefly", 1, "2002-09-20", "2002-09-27")
+	show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.mediaRepo.UpdateTVShowStatus(show.ID, "Ended"); err != nil {
+		t.Fatal(err)
+	}
+
+	// One episode to go: the show is not complete yet
+	hash, name := "aaaa", "Firefly S01E01"
+	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, 1, 1, models.StatusDownloaded, &hash, &name); err != nil {
+		t.Fatal(err)
+	}
+	m.updateShowProgress(media.ID)
+	if got, _ := m.mediaRepo.GetByID(media.ID); got.Status != models.StatusPending {
+		t.Fatalf("status with a pending episode = %s, want pending", got.Status)
+	}
+
+	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, 1, 2, models.StatusDownloaded, &hash, &name); err != nil {
+		t.Fatal(err)
+	}
+	m.updateShowProgress(media.ID)
+	if got, _ := m.mediaRepo.GetByID(media.ID); got.Status != models.StatusArchived {
+		t.Fatalf("status of the complete ended show = %s, want archived", got.Status)
+	}
+	select {
+	case title := <-notifier.completed:
+		if title != "Firefly" {
+			t.Errorf("notified %q, want Firefly", title)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no show complete notification")
+	}
+
+	// Later progress updates leave the archived show alone
+	m.updateShowProgress(media.ID)
+	select {
+	case title := <-notifier.completed:
+		t.Errorf("notified %q again", title)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestValidateSearchModesDuringReload(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.TorrentClient = config.TorrentClientConfig{Type: "transmission", Host: "http://127.0.0.1:1"}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{Client: &fakeIndexer{}, Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "movie-search"}}}
+
+	// Reloads replace the clients while the validation ranges over them
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if err := m.reloadConfig(cfg); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	m.validateIndexerSearchModes()
+	<-done
+
+	if len(m.indexers()) != 0 {
+		t.Errorf("indexers after reloading a config without sources = %v, want none", m.indexers())
+	}
+}
+
+func TestHealthHistorySurvivesRestart(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.TorrentClient.Type = "qbittorrent"
+	m := newTestManager(t, cfg, &fakeTorrentClient{})
+	m.checkHealth()
+	m.checkHealth()
+
+	// A new manager over the same database, as after a restart
+	restarted := newTestManager(t, cfg, &fakeTorrentClient{})
+	restarted.mediaRepo = m.mediaRepo
+	status := restarted.checkHealth()
+	if got := len(status.TorrentClient.History); got != 3 {
+		t.Errorf("got %d checks after the restart, want 3", got)
+	}
+
+	// A new client type starts a new history
+	cfg.TorrentClient.Type = "transmission"
+	status = restarted.checkHealth()
+	if got := len(status.TorrentClient.History); got != 1 {
+		t.Errorf("got %d checks after changing the client type, want 1", got)
+	}
+}
+
+func TestResetRetryFailureIsLogged(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	cfg.TVShows.DownloadFolder = t.TempDir()
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	db := openTestDB(t)
+	// Errors are logged to stderr
+	logs, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = logs
+	m.logger = utils.NewLogger(false, io.Discard)
+	os.Stderr = stderr
+	m.mediaRepo = models.NewMediaRepository(db, m.logger)
+	// Clearing the retry count fails, while the grab itself is stored
+	if _, err := db.Exec(`CREATE TRIGGER fail_reset BEFORE UPDATE OF retry_count ON media
+		WHEN NEW.retry_count = 0 BEGIN SELECT RAISE(ABORT, 'reset refused'); END`); err != nil {
+		t.Fatal(err)
+	}
+	nextRetry := time.Now().Add(time.Hour)
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	show := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+
+	tests := []struct {
+		name  string
+		media *models.Media
+		grab  func() error
+	}{
+		{"movie", movie, func() error {
+			return m.StartDownload(context.Background(), movie.ID, indexers.IndexerResult{Title: "Heat.1995.1080p", DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("1", 40)})
+		}},
+		{"episode", show, func() error {
+			return m.StartEpisodeDownload(context.Background(), show.ID, 1, 1, indexers.IndexerResult{Title: "Severance.S01E01.1080p", DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("2", 40)})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.mediaRepo.ScheduleRetry(tt.media.ID, 2, &nextRetry); err != nil {
+				t.Fatal(err)
+			}
+			logs.Truncate(0)
+			if err := tt.grab(); err != nil {
+				t.Fatalf("grab failed: %v", err)
+			}
+			logged, err := os.ReadFile(logs.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(logged), "reset refused") {
+				t.Errorf("the failed reset was not logged: %s", logged)
+			}
+		})
+	}
+}
+
+// droppingTorrentClient accepts torrents without ever listing them, as qBittorrent does
+// with a duplicate or invalid magnet.
+type droppingTorrentClient struct {
+	*fakeTorrentClient
+}
+
+func (c droppingTorrentClient) GetTorrentStatus(hash string) (torrent.TorrentStatus, error) {
+	return torrent.TorrentStatus{}, os.ErrNotExist
+}
+
+func TestStartDownloadConfirmsWithinRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		dropped      bool // The client accepts the torrent but doesn't list it
+		wantStatus   models.MediaStatus
+		wantTorrents int // Left in the client afterwards
+	}{
+		{"registered", false, models.StatusDownloading, 1},
+		{"dropped by the client", true, models.StatusFailed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Movies.DownloadFolder = t.TempDir()
+			fake := newFakeTorrentClient()
+			var client torrent.TorrentClient = fake
+			if tt.dropped {
+				client = droppingTorrentClient{fake}
+			}
+			m := newTestManager(t, cfg, client)
+			movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+
+			// The request ends long before the client would have been asked again
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err := m.StartDownload(ctx, movie.ID, indexers.IndexerResult{Title: "Heat.1995.1080p", DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("3", 40)})
+			if elapsed := time.Since(start); elapsed >= torrentConfirmDelay {
+				t.Errorf("StartDownload took %v, past the end of the request", elapsed)
+			}
+			if (err != nil) != (tt.wantStatus == models.StatusFailed) {
+				t.Errorf("StartDownload() = %v", err)
+			}
+			media, err := m.mediaRepo.GetByID(movie.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if media.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", media.Status, tt.wantStatus)
+			}
+			// An unconfirmed torrent isn't left downloading in the client
+			if torrents, _ := fake.ListTorrents(); len(torrents) != tt.wantTorrents {
+				t.Errorf("client holds %d torrents, want %d", len(torrents), tt.wantTorrents)
+			}
+		})
+	}
+}
+
+func TestRefreshSkipsOnlyNewSpecials(t *testing.T) {
+	tests := []struct {
+		name            string
+		includeSpecials bool
+		queued          bool // the special is stored pending before the refresh
+		want            models.MediaStatus
+	}{
+		{"new special with ignore_specials", false, false, models.StatusSkipped},
+		{"new special included for the show", true, false, models.StatusPending},
+		{"queued special is kept", false, true, models.StatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tvmaze := &fakeMetadataClient{name: "tvmaze", show: metadata.TVShowResult{Title: "Sherlock", Status: "Ended",
+				Seasons: map[int][]metadata.Episode{0: {{EpisodeNumber: 1, Title: "The Abominable Bride", AirDate: "2016-01-01"}}}}}
+			m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+			m.metadataClients = map[models.MediaType][]metadata.Client{models.MediaTypeTVShow: {tvmaze}}
+			media := createShow(t, m.mediaRepo, "Sherlock", 0)
+			if tt.queued {
+				show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := m.mediaRepo.CreateEpisode(&models.Episode{SeasonID: show.Seasons[0].ID, EpisodeNumber: 1, AirDate: "2016-01-01", Status: models.StatusPending}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := m.mediaRepo.SetIncludeSpecials(media.ID, tt.includeSpecials); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, err := m.RefreshMetadata(media.ID); err != nil {
+				t.Fatal(err)
+			}
+			show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(show.Seasons) != 1 || len(show.Seasons[0].Episodes) != 1 {
+				t.Fatalf("stored seasons %+v, want one special", show.Seasons)
+			}
+			if got := show.Seasons[0].Episodes[0].Status; got != tt.want {
+				t.Errorf("special status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeSpecialsRejectedForMovies(t *testing.T) {
+	tmdb := &fakeMetadataClient{name: "tmdb", movie: metadata.MovieResult{ID: "603", Title: "The Matrix", Year: 1999}}
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	m.metadataClients = map[models.MediaType][]metadata.Client{models.MediaTypeMovie: {tmdb}}
+
+	if _, _, err := m.AddMedia(AddMediaOptions{Type: models.MediaTypeMovie, Provider: "tmdb", Title: "The Matrix", IncludeSpecials: true}); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("AddMedia() with include_specials = %v, want ErrInvalidSetting", err)
+	}
+	media := createMovie(t, m.mediaRepo, "Heat", 1995)
+	include := true
+	if err := m.UpdateMediaSettings(media.ID, "", "", true, nil, nil, nil, &include); !errors.Is(err, ErrInvalidSetting) {
+		t.Errorf("UpdateMediaSettings() with include_specials = %v, want ErrInvalidSetting", err)
+	}
+	include = false
+	if err := m.UpdateMediaSettings(media.ID, "", "", true, nil, nil, nil, &include); err != nil {
+		t.Errorf("UpdateMediaSettings() without specials = %v", err)
+	}
+}
+
+// waitForFiles waits for post-processing running in the background to leave exactly
+// the named files in dir.
+func waitForFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	var got []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		got = nil
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			got = append(got, entry.Name())
+		}
+		if slices.Equal(got, names) {
+			return
+		}
+	}
+	t.Fatalf("%s holds %v, want %v", dir, got, names)
+}
+
+func TestConcurrentEpisodeDownloadsComplete(t *testing.T) {
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	cfg := &config.Config{}
+	cfg.TVShows.DestinationFolder = filepath.Join(root, "tv")
+	cfg.TVShows.MoveMethod = []string{"hardlink"}
+
+	var torrents []torrent.TorrentStatus
+	for _, name := range []string{"Severance.S01E01.1080p.WEB-GRP", "Severance.S01E02.1080p.WEB-GRP"} {
+		if err := os.MkdirAll(filepath.Join(downloads, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(downloads, name, name+".mkv"), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		torrents = append(torrents, torrent.TorrentStatus{
+			Hash: strings.Repeat(strconv.Itoa(len(torrents)+1), 40), Name: name, Progress: 1, IsCompleted: true,
+			DownloadDir: downloads, Files: []string{name + "/" + name + ".mkv"},
+		})
+	}
+	m := newTestManager(t, cfg, newFakeTorrentClient(torrents...))
+	media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-18")
+	for i, status := range torrents {
+		if err := m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, 1, i+1, models.StatusDownloading, &status.Hash, &status.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both torrents finished since the last poll: neither hides the other
+	m.updateDownloadStatus()
+	show, err := m.mediaRepo.GetTVShowByMediaID(media.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, episode := range show.Seasons[0].Episodes {
+		if episode.Status != models.StatusDownloaded {
+			t.Errorf("episode %d is %s, want downloaded", episode.EpisodeNumber, episode.Status)
+		}
+		if episode.TorrentHash == nil || *episode.TorrentHash != torrents[episode.EpisodeNumber-1].Hash {
+			t.Errorf("episode %d tracks torrent %v, want its own", episode.EpisodeNumber, episode.TorrentHash)
+		}
+	}
+	waitForFiles(t, filepath.Join(cfg.TVShows.DestinationFolder, "Severance (2020)", "S01"),
+		"Severance - S01E01 [1080p].mkv", "Severance - S01E02 [1080p].mkv")
+}
+
+func TestSystemStatusReusesIndexerClients(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	jackett := &fakeIndexer{}
+	prowlarr := &fakeIndexer{}
+	jackettSource := config.SourceConfig{Type: "jackett", URL: "http://jackett:9117/api/v2.0/indexers/1337x/results/torznab"}
+	prowlarrSource := config.SourceConfig{Type: "prowlarr", URL: "http://prowlarr:9696"}
+	// The same Jackett source serves movies and shows
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{Client: jackett, Source: jackettSource}, {Client: prowlarr, Source: prowlarrSource}}
+	m.indexerClients[models.MediaTypeTVShow] = []IndexerClientWithMode{{Client: jackett, Source: jackettSource}}
+
+	status := m.checkHealth()
+	if jackett.healthChecks != 1 || prowlarr.healthChecks != 1 {
+		t.Errorf("health checks: jackett %d, prowlarr %d, want one each on the configured clients", jackett.healthChecks, prowlarr.healthChecks)
+	}
+	for url, want := range map[string]string{jackettSource.URL: "1337x", prowlarrSource.URL: "Prowlarr"} {
+		if got := status.IndexerClients[url]; !got.Status || got.Name != want {
+			t.Errorf("status of %s = %+v, want healthy %q", url, got, want)
+		}
+	}
+
+	// Testing a connection looks the client up by the keys used in the status
+	tests := []struct {
+		key     string
+		client  *fakeIndexer
+		wantErr bool
+	}{
+		{jackettSource.URL, jackett, false},
+		{"1337x", jackett, false},
+		{"prowlarr", prowlarr, false},
+		{"jackett", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			var before int
+			if tt.client != nil {
+				before = tt.client.healthChecks
+			}
+			_, err := m.TestIndexerConnection(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TestIndexerConnection(%q) = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if tt.client != nil && tt.client.healthChecks != before+1 {
+				t.Errorf("the configured client was not checked")
+			}
+		})
+	}
+}
+
+func TestEpisodeSummary(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	day := func(days int) string { return time.Now().AddDate(0, 0, days).Format("2006-01-02") }
+
+	// Two aired episodes pending, one downloaded, one skipped and two to come
+	show := createShow(t, m.mediaRepo, "Severance", 2, day(-21), day(-14), day(-7), day(-3), day(10), day(3))
+	hash, name := "aaaa", "Severance S02E01"
+	if err := m.mediaRepo.UpdateEpisodeDownloadInfo(show.ID, 2, 1, models.StatusDownloaded, &hash, &name); err != nil {
+		t.Fatal(err)
+	}
+	for episode, status := range map[int]models.MediaStatus{4: models.StatusSkipped, 5: models.StatusTBA, 6: models.StatusTBA} {
+		if err := m.mediaRepo.UpdateEpisodeDownloadInfo(show.ID, 2, episode, status, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	empty := createShow(t, m.mediaRepo, "Pluribus", 1)
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+
+	all, err := m.GetAllMedia("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[int]models.Media)
+	for _, media := range all {
+		byID[media.ID] = media
+	}
+
+	if media := byID[movie.ID]; media.PendingCount != nil || media.DownloadedCount != nil || media.MetadataIncomplete {
+		t.Errorf("movie has an episode summary: %+v", media)
+	}
+
+	tests := []struct {
+		name           string
+		media          models.Media
+		wantPending    int
+		wantDownloaded int
+		wantNextAir    string
+		wantIncomplete bool
+	}{
+		{"mixed statuses", byID[show.ID], 2, 1, day(3), false},
+		{"no episodes", byID[empty.ID], 0, 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.media.PendingCount == nil || tt.media.DownloadedCount == nil {
+				t.Fatalf("no episode summary: %+v", tt.media)
+			}
+			if *tt.media.PendingCount != tt.wantPending || *tt.media.DownloadedCount != tt.wantDownloaded {
+				t.Errorf("pending %d, downloaded %d, want %d and %d", *tt.media.PendingCount, *tt.media.DownloadedCount, tt.wantPending, tt.wantDownloaded)
+			}
+			nextAir := ""
+			if tt.media.NextAirDate != nil {
+				nextAir = *tt.media.NextAirDate
+			}
+			if nextAir != tt.wantNextAir {
+				t.Errorf("next air date %q, want %q", nextAir, tt.wantNextAir)
+			}
+			if tt.media.MetadataIncomplete != tt.wantIncomplete {
+				t.Errorf("metadata incomplete %v, want %v", tt.media.MetadataIncomplete, tt.wantIncomplete)
+			}
+		})
+	}
+}
+
+func TestTransferStatsAfterPoll(t *testing.T) {
+	movieTorrent := torrent.TorrentStatus{Hash: strings.Repeat("a", 40), Name: "Heat.1995.1080p", Progress: 0.4, DownloadRate: 12 << 20, UploadRate: 1 << 20, ETA: 240, State: torrent.StateDownloading}
+	episodeTorrents := []torrent.TorrentStatus{
+		{Hash: strings.Repeat("b", 40), Name: "Severance.S01E01", Progress: 0.5, DownloadRate: 3 << 20, UploadRate: 100, ETA: 60, State: torrent.StateDownloading},
+		{Hash: strings.Repeat("c", 40), Name: "Severance.S01E02", Progress: 0.1, DownloadRate: 1 << 20, UploadRate: 50, ETA: 900, State: torrent.StateStalled},
+	}
+	client := newFakeTorrentClient(append(episodeTorrents, movieTorrent)...)
+	m := newTestManager(t, &config.Config{}, client)
+
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	if err := m.mediaRepo.UpdateDownloadInfo(movie.ID, models.StatusDownloading, &movieTorrent.Hash, &movieTorrent.Name); err != nil {
+		t.Fatal(err)
+	}
+	show := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-25")
+	for i, status := range episodeTorrents {
+		if err := m.mediaRepo.UpdateEpisodeDownloadInfo(show.ID, 1, i+1, models.StatusDownloading, &status.Hash, &status.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.updateDownloadStatus()
+	all, err := m.GetAllMedia("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]models.TransferStats{
+		movie.ID: {DownloadRate: 12 << 20, UploadRate: 1 << 20, ETA: 240, State: torrent.StateDownloading},
+		// Rates add up over the episodes; the ETA and state are the ones needing attention
+		show.ID: {DownloadRate: 4 << 20, UploadRate: 150, ETA: 900, State: torrent.StateStalled},
+	}
+	for _, media := range all {
+		if media.Transfer == nil || *media.Transfer != want[media.ID] {
+			t.Errorf("%s: transfer %+v, want %+v", media.Title, media.Transfer, want[media.ID])
+		}
+		if media.ID == movie.ID && media.Progress != 0.4 {
+			t.Errorf("%s: progress %v, want 0.4", media.Title, media.Progress)
+		}
+	}
+
+	// A download that is gone has no stats on the next poll
+	client.RemoveTorrent(movieTorrent.Hash)
+	m.updateDownloadStatus()
+	all, err = m.GetAllMedia("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, media := range all {
+		if media.ID == movie.ID && media.Transfer != nil {
+			t.Errorf("%s: transfer %+v after the torrent was removed, want none", media.Title, media.Transfer)
+		}
+	}
+}
+
+// episodeStatuses returns the status of each episode of a show by "SxxEyy".
+func episodeStatuses(t *testing.T, repo *models.MediaRepository, mediaID int) map[string]models.MediaStatus {
+	t.Helper()
+	show, err := repo.GetTVShowByMediaID(mediaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]models.MediaStatus)
+	for _, season := range show.Seasons {
+		for _, episode := range season.Episodes {
+			statuses[fmt.Sprintf("S%02dE%02d", season.SeasonNumber, episode.EpisodeNumber)] = episode.Status
+		}
+	}
+	return statuses
+}
+
+func TestAddMediaMonitorFromNow(t *testing.T) {
+	day := func(days int) string { return time.Now().AddDate(0, 0, days).Format("2006-01-02") }
+	tvmaze := &fakeMetadataClient{name: "tvmaze", show: metadata.TVShowResult{ID: "1", Title: "Slow Horses", Year: 2022, Status: "Running",
+		Seasons: map[int][]metadata.Episode{
+			1: {{EpisodeNumber: 1, AirDate: day(-400)}, {EpisodeNumber: 2, AirDate: day(-393)}},
+			2: {{EpisodeNumber: 1, AirDate: day(-7)}, {EpisodeNumber: 2, AirDate: day(0)}, {EpisodeNumber: 3, AirDate: day(7)}, {EpisodeNumber: 4}},
+		}}}
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	m.metadataClients = map[models.MediaType][]metadata.Client{models.MediaTypeTVShow: {tvmaze}}
+
+	media, _, err := m.AddMedia(AddMediaOptions{Type: models.MediaTypeTVShow, ID: "1", Provider: "tvmaze", Title: "Slow Horses", AutoDownload: true, MonitorMode: config.MonitorFuture})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]models.MediaStatus{
+		"S01E01": models.StatusSkipped,
+		"S01E02": models.StatusSkipped,
+		"S02E01": models.StatusSkipped,
+		"S02E02": models.StatusPending, // Airs today
+		"S02E03": models.StatusTBA,
+		"S02E04": models.StatusPending, // No air date yet
+	}
+	if got := episodeStatuses(t, m.mediaRepo, media.ID); !maps.Equal(got, want) {
+		t.Errorf("episode statuses %v, want %v", got, want)
+	}
+}
+
+func TestAddMediaDefaultMonitorMode(t *testing.T) {
+	day := func(days int) string { return time.Now().AddDate(0, 0, days).Format("2006-01-02") }
+	const (
+		pending = models.StatusPending
+		skipped = models.StatusSkipped
+		tba     = models.StatusTBA
+	)
+
+	tests := []struct {
+		name         string
+		defaultMode  string
+		monitorMode  string
+		startSeason  int
+		startEpisode int
+		want         map[string]models.MediaStatus
+	}{
+		{
+			name: "no default monitors every episode",
+			want: map[string]models.MediaStatus{"S01E01": pending, "S01E02": pending, "S02E01": pending, "S02E02": pending, "S02E03": tba},
+		},
+		{
+			name:        "all",
+			defaultMode: config.MonitorAll,
+			want:        map[string]models.MediaStatus{"S01E01": pending, "S01E02": pending, "S02E01": pending, "S02E02": pending, "S02E03": tba},
+		},
+		{
+			name:        "future",
+			defaultMode: config.MonitorFuture,
+			want:        map[string]models.MediaStatus{"S01E01": skipped, "S01E02": skipped, "S02E01": skipped, "S02E02": pending, "S02E03": tba},
+		},
+		{
+			name:        "latest-season",
+			defaultMode: config.MonitorLatestSeason,
+			want:        map[string]models.MediaStatus{"S01E01": skipped, "S01E02": skipped, "S02E01": pending, "S02E02": pending, "S02E03": tba},
+		},
+		{
+			name:        "the request's mode wins over the default",
+			defaultMode: config.MonitorFuture,
+			monitorMode: config.MonitorAll,
+			want:        map[string]models.MediaStatus{"S01E01": pending, "S01E02": pending, "S02E01": pending, "S02E02": pending, "S02E03": tba},
+		},
+		{
+			name:         "a starting episode replaces the default",
+			defaultMode:  config.MonitorFuture,
+			startSeason:  1,
+			startEpisode: 2,
+			want:         map[string]models.MediaStatus{"S01E01": skipped, "S01E02": pending, "S02E01": pending, "S02E02": pending, "S02E03": tba},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tvmaze := &fakeMetadataClient{name: "tvmaze", show: metadata.TVShowResult{ID: "1", Title: "Slow Horses", Year: 2022, Status: "Running",
+				Seasons: map[int][]metadata.Episode{
+					1: {{EpisodeNumber: 1, AirDate: day(-400)}, {EpisodeNumber: 2, AirDate: day(-393)}},
+					2: {{EpisodeNumber: 1, AirDate: day(-7)}, {EpisodeNumber: 2, AirDate: day(0)}, {EpisodeNumber: 3, AirDate: day(7)}},
+				}}}
+			cfg := &config.Config{}
+			cfg.Automation.DefaultMonitorMode = tt.defaultMode
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+			m.metadataClients = map[models.MediaType][]metadata.Client{models.MediaTypeTVShow: {tvmaze}}
+
+			media, _, err := m.AddMedia(AddMediaOptions{Type: models.MediaTypeTVShow, ID: "1", Provider: "tvmaze", Title: "Slow Horses", AutoDownload: true, StartSeason: tt.startSeason, StartEpisode: tt.startEpisode, MonitorMode: tt.monitorMode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := episodeStatuses(t, m.mediaRepo, media.ID); !maps.Equal(got, tt.want) {
+				t.Errorf("episode statuses %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanLibraryBeforeSearch(t *testing.T) {
+	for _, scan := range []bool{true, false} {
+		t.Run(fmt.Sprintf("scan=%t", scan), func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.TVShows.DestinationFolder = t.TempDir()
+			cfg.Automation.MaxConcurrentDownloads = 2
+			cfg.Automation.ScanLibraryBeforeSearch = scan
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+			indexer := &fakeIndexer{}
+			m.indexerClients[models.MediaTypeTVShow] = []IndexerClientWithMode{{
+				Client: indexer,
+				Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search"},
+			}}
+			media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-25")
+
+			// Copied into the library by hand
+			manual := filepath.Join(cfg.TVShows.DestinationFolder, "Severance (2020)", "S01", "Severance - S01E01 [1080p].mkv")
+			if err := os.MkdirAll(filepath.Dir(manual), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(manual, []byte("video"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			m.searchAndDownloadNextEpisode(media, false)
+
+			want := map[string]models.MediaStatus{"S01E01": models.StatusPending, "S01E02": models.StatusPending}
+			if scan {
+				want["S01E01"] = models.StatusDownloaded
+			}
+			searched := slices.Contains(indexer.queries, "Severance S01E01")
+			if searched == scan {
+				t.Errorf("searched the indexer with %q, want S01E01 searched only without the scan", indexer.queries)
+			}
+			if got := episodeStatuses(t, m.mediaRepo, media.ID); !maps.Equal(got, want) {
+				t.Errorf("episode statuses %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestManualDownloadBySearchResultID(t *testing.T) {
+	const magnet = "magnet:?xt=urn:btih:0123456789abcdef0123456789abcdef01234567"
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	client := newFakeTorrentClient()
+	m := newTestManager(t, cfg, client)
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+		Client: &fakeIndexer{results: []indexers.IndexerResult{{
+			Title:       "Alien.1979.1080p.BluRay.x264-GRP",
+			DownloadURL: magnet,
+			Size:        8 << 30,
+			Seeders:     50,
+		}}},
+		Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search"},
+	}}
+	media := createMovie(t, m.mediaRepo, "Alien", 1979)
+	other := createMovie(t, m.mediaRepo, "Aliens", 1986)
+	if err := m.mediaRepo.UpdateSettings(media.ID, "720p", "2160p", true); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _, err := m.PerformSearch(media.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID == "" {
+		t.Fatalf("PerformSearch() = %+v, want one result with an ID", results)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		mediaID int
+		id      string
+	}{
+		{"unknown id", media.ID, "0000000000000000"},
+		{"another media's result", other.ID, results[0].ID},
+	} {
+		if _, found, err := m.ResolveSearchResult(tt.mediaID, indexers.IndexerResult{ID: tt.id}); found || err != nil {
+			t.Errorf("%s: ResolveSearchResult() found = %t, err = %v, want not found", tt.name, found, err)
+		}
+	}
+
+	resolved, found, err := m.ResolveSearchResult(media.ID, indexers.IndexerResult{ID: results[0].ID})
+	if err != nil || !found {
+		t.Fatalf("ResolveSearchResult() found = %t, err = %v", found, err)
+	}
+	if resolved.DownloadURL != magnet || resolved.Title != "Alien.1979.1080p.BluRay.x264-GRP" {
+		t.Errorf("resolved %+v, want the searched release", resolved)
+	}
+	if err := m.StartDownload(context.Background(), media.ID, resolved); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(client.added, []string{magnet}) {
+		t.Errorf("added %v, want the searched release", client.added)
+	}
+}
+
+func TestSearchSkippedOnLowDiskSpace(t *testing.T) {
+	var freeMB uint64 = 100
+	diskUsage = func(path string) (*disk.UsageStat, error) {
+		return &disk.UsageStat{Path: path, Free: freeMB << 20}, nil
+	}
+	t.Cleanup(func() { diskUsage = disk.Usage })
+
+	cfg := &config.Config{}
+	cfg.Movies.DestinationFolder = t.TempDir()
+	cfg.Automation.MinFreeSpaceMB = 1024
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	notifier := &fakeNotifier{lowSpace: make(chan string, 2)}
+	m.notifiers = []notifications.Notifier{notifier}
+	indexer := &fakeIndexer{}
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+		Client: indexer,
+		Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search"},
+	}}
+	media := createMovie(t, m.mediaRepo, "Alien", 1979)
+
+	// Low on space: no search, and a single notification however often it is checked
+	m.searchAndDownloadMovie(media, false)
+	m.searchAndDownloadMovie(media, false)
+	if len(indexer.queries) != 0 {
+		t.Errorf("searched %q with the destination low on space", indexer.queries)
+	}
+	select {
+	case path := <-notifier.lowSpace:
+		if path != cfg.Movies.DestinationFolder {
+			t.Errorf("notified low space in %s, want %s", path, cfg.Movies.DestinationFolder)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("low disk space not notified")
+	}
+	select {
+	case <-notifier.lowSpace:
+		t.Error("low disk space notified twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Space was freed: searches resume
+	freeMB = 4096
+	m.searchAndDownloadMovie(media, false)
+	if len(indexer.queries) == 0 {
+		t.Error("searches did not resume once space was freed")
+	}
+}
+
+func TestTorrentCompletedStartsPostProcessing(t *testing.T) {
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	name := "Heat.1995.1080p.BluRay-GRP"
+	if err := os.MkdirAll(filepath.Join(downloads, name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(downloads, name, "heat.mkv"), []byte("movie"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status := torrent.TorrentStatus{
+		Hash: strings.Repeat("a", 40), Name: name, Progress: 1, IsCompleted: true,
+		DownloadDir: downloads, Files: []string{name + "/heat.mkv"},
+	}
+	cfg := &config.Config{}
+	cfg.Movies.DestinationFolder = filepath.Join(root, "movies")
+	cfg.Movies.MoveMethod = []string{"hardlink"}
+	m := newTestManager(t, cfg, newFakeTorrentClient(status))
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	if err := m.mediaRepo.UpdateDownloadInfo(movie.ID, models.StatusDownloading, &status.Hash, &status.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := m.TorrentCompleted(strings.Repeat("f", 40)); found || err != nil {
+		t.Errorf("TorrentCompleted() of an unknown hash = %t, %v, want not found", found, err)
+	}
+	// Clients may report the hash in upper case
+	if found, err := m.TorrentCompleted(strings.ToUpper(status.Hash)); !found || err != nil {
+		t.Fatalf("TorrentCompleted() = %t, %v, want found", found, err)
+	}
+	waitForFiles(t, filepath.Join(cfg.Movies.DestinationFolder, "Heat (1995)"), "Heat (1995) [1080p].mkv")
+	if got, _ := m.mediaRepo.GetByID(movie.ID); got.Status != models.StatusDownloaded {
+		t.Errorf("status = %s, want downloaded", got.Status)
+	}
+}
+
+func TestShowProgressWithoutEpisodes(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+
+	// Neither an ended show nor one whose provider returned no seasons is complete
+	seasonless := &models.TVShow{Status: "Ended"}
+	if err := m.mediaRepo.CreateTVShow(seasonless); err != nil {
+		t.Fatal(err)
+	}
+	noSeasons := &models.Media{Type: models.MediaTypeTVShow, Title: "Pluribus", Year: 2025, Language: "en", TVShowID: &seasonless.ID, Status: models.StatusPending, Monitored: true}
+	if err := m.mediaRepo.Create(noSeasons); err != nil {
+		t.Fatal(err)
+	}
+	emptySeason := createShow(t, m.mediaRepo, "Andor", 1)
+
+	for _, media := range []*models.Media{noSeasons, emptySeason} {
+		t.Run(media.Title, func(t *testing.T) {
+			m.updateShowProgress(media.ID)
+			got, err := m.mediaRepo.GetByID(media.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != models.StatusMonitoring || got.Progress != 0 {
+				t.Errorf("status %s, progress %v, want monitoring with no progress", got.Status, got.Progress)
+			}
+		})
+	}
+}
+
+func TestDuplicateTorrentIsFlagged(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	client := newFakeTorrentClient()
+	m := newTestManager(t, cfg, client)
+	release := indexers.IndexerResult{Title: "Heat.1995.1080p", DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("a", 40)}
+	first := createMovie(t, m.mediaRepo, "Heat", 1995)
+	second := createMovie(t, m.mediaRepo, "Heat", 1995)
+
+	if err := m.StartDownload(context.Background(), first.ID, release); err != nil {
+		t.Fatal(err)
+	}
+	err := m.StartDownload(context.Background(), second.ID, release)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("already downloading for 'Heat' (media %d)", first.ID)) {
+		t.Errorf("second grab of the torrent = %v, want it flagged as a duplicate", err)
+	}
+	if len(client.added) != 1 {
+		t.Errorf("the torrent was sent %d times, want once", len(client.added))
+	}
+	if got, _ := m.mediaRepo.GetByID(second.ID); got.Status != models.StatusFailed {
+		t.Errorf("duplicate's status = %s, want failed", got.Status)
+	}
+	if got, _ := m.mediaRepo.GetByID(first.ID); got.Status != models.StatusDownloading {
+		t.Errorf("first media's status = %s, want downloading", got.Status)
+	}
+}
+
+func TestProwlarrCategoriesByMediaType(t *testing.T) {
+	var categories []string
+	prowlarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		categories = r.URL.Query()["categories"]
+		w.Write([]byte(`[]`))
+	}))
+	defer prowlarr.Close()
+
+	tests := []struct {
+		name       string
+		mediaType  models.MediaType
+		configured []int
+		want       []string
+	}{
+		{"anime", models.MediaTypeAnime, nil, []string{"5070"}},
+		{"tv show", models.MediaTypeTVShow, nil, []string{"5000"}},
+		{"configured categories win", models.MediaTypeAnime, []int{5070, 5080}, []string{"5070", "5080"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := config.SourceConfig{Type: "prowlarr", URL: prowlarr.URL, IndexerCategories: tt.configured}
+			client := newIndexerClient(source, tt.mediaType, utils.HTTPClientConfig{Timeout: time.Second}, nil)
+			if _, err := client.SearchTVShows("Frieren", 1, 3, "tvsearch"); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(categories, tt.want) {
+				t.Errorf("categories = %q, want %q", categories, tt.want)
+			}
+		})
+	}
+}
+
+// blockingIndexer holds every movie search until release is closed, counting them.
+type blockingIndexer struct {
+	fakeIndexer
+	searches atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (i *blockingIndexer) SearchMovies(query string, tmdbID string, searchMode string) ([]indexers.IndexerResult, error) {
+	i.searches.Add(1)
+	i.started <- struct{}{}
+	<-i.release
+	return nil, nil
+}
+
+func TestSearchQueueSkipsMediaInFlight(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	indexer := &blockingIndexer{started: make(chan struct{}, 10), release: make(chan struct{})}
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+		Client: indexer,
+		Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search"},
+	}}
+	media := createMovie(t, m.mediaRepo, "Heat", 1995)
+	go m.startSearchQueueWorker()
+	// The worker exits once its pause after the search is over
+	defer close(m.searchQueue)
+
+	if added, err := m.enqueueSearch(*media, false); !added || err != nil {
+		t.Fatalf("enqueueSearch() = %t, %v, want added", added, err)
+	}
+	select {
+	case <-indexer.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the queued search never started")
+	}
+
+	// Every tick while the search runs tries to queue the media again
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := m.enqueueSearch(*media, false); ok {
+				added.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if added.Load() != 0 {
+		t.Errorf("media queued %d more times while being searched", added.Load())
+	}
+
+	close(indexer.release)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		m.queuedMu.Lock()
+		queued := m.queuedMedia[media.ID]
+		m.queuedMu.Unlock()
+		if !queued {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("media still marked as queued after its search")
+		}
+	}
+	if n := indexer.searches.Load(); n != 1 {
+		t.Errorf("%d searches, want 1", n)
+	}
+}
+
+func TestNoMetadataProvider(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	var logs strings.Builder
+	m.logger = utils.NewLogger(false, &logs)
+
+	// Added without enrichment: nothing to look the anime up with
+	media, _, err := m.AddMedia(AddMediaOptions{Type: models.MediaTypeAnime, Title: "Frieren", Year: 2023, AutoDownload: true, MonitorMode: config.MonitorAll})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.mediaRepo.UpdateStatus(media.ID, models.StatusMonitoring); err != nil {
+		t.Fatal(err)
+	}
+	createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+
+	// Runs twice, as the scheduler would, and warns once per run and type
+	m.checkForNewEpisodes()
+	m.checkForNewEpisodes()
+	warning := "No metadata provider for anime; skipping new-episode check"
+	if n := strings.Count(logs.String(), warning); n != 2 {
+		t.Errorf("warning logged %d times, want once per check:\n%s", n, logs.String())
+	}
+	if !strings.Contains(logs.String(), "No metadata provider for tvshow") {
+		t.Errorf("no warning for shows:\n%s", logs.String())
+	}
+
+	if _, found, err := m.RefreshMetadata(media.ID); !found || err == nil || !strings.Contains(err.Error(), "no metadata provider configured for anime") {
+		t.Errorf("RefreshMetadata() = found %t, %v, want a missing provider error", found, err)
+	}
+}
+
+// titleOnlyIndexer only finds releases when searched for an episode's title.
+type titleOnlyIndexer struct {
+	fakeIndexer
+	title string
+}
+
+func (i *titleOnlyIndexer) SearchTVShows(query string, season int, episode int, searchMode string) ([]indexers.IndexerResult, error) {
+	i.queries = append(i.queries, query)
+	if !strings.HasSuffix(query, i.title) {
+		return nil, nil
+	}
+	return append([]indexers.IndexerResult(nil), i.results...), nil
+}
+
+func TestEpisodeTitleFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback bool
+		queries  []string
+		results  int
+	}{
+		{"enabled", true, []string{"Severance S01E01", "Severance 1x01", "Severance Good News About Hell"}, 1},
+		{"disabled", false, []string{"Severance S01E01", "Severance 1x01"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.TVShows.DownloadFolder = t.TempDir()
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+			db := openTestDB(t)
+			m.mediaRepo = models.NewMediaRepository(db, m.logger)
+			media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+			if _, err := db.Exec("UPDATE episodes SET title = 'Good News About Hell'"); err != nil {
+				t.Fatal(err)
+			}
+			indexer := &titleOnlyIndexer{
+				fakeIndexer: fakeIndexer{results: []indexers.IndexerResult{{
+					Title:       "Severance.Good.News.About.Hell.1080p.WEB.h264-GRP",
+					DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("a", 40),
+					Seeders:     20,
+				}}},
+				title: "Good News About Hell",
+			}
+			m.indexerClients[models.MediaTypeTVShow] = []IndexerClientWithMode{{
+				Client: indexer,
+				Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search", EpisodeTitleFallback: tt.fallback},
+			}}
+
+			results, err := m.performSearch(media, 1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(indexer.queries, tt.queries) {
+				t.Errorf("queries = %q, want %q", indexer.queries, tt.queries)
+			}
+			if len(results) != tt.results {
+				t.Fatalf("got %d results, want %d", len(results), tt.results)
+			}
+			if tt.results > 0 && !results[0].EpisodeTitleMatch {
+				t.Errorf("result %s not flagged as matching the episode title", results[0].Title)
+			}
+		})
+	}
+}
+
+// failingIndexer fails its searches while err is set.
+type failingIndexer struct {
+	fakeIndexer
+	err error
+}
+
+func (i *failingIndexer) SearchMovies(query string, tmdbID string, searchMode string) ([]indexers.IndexerResult, error) {
+	i.queries = append(i.queries, query)
+	return nil, i.err
+}
+
+func TestFailingIndexerIsSkipped(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	threshold := 2
+	cfg.Automation.IndexerFailureThreshold = &threshold
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	const url = "http://down.test"
+	indexer := &failingIndexer{err: errors.New("connection refused")}
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+		Client: indexer,
+		Source: config.SourceConfig{URL: url, SearchMode: "search"},
+	}}
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	search := func(wantSearched bool) {
+		t.Helper()
+		before := len(indexer.queries)
+		if _, err := m.performSearch(movie, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		if searched := len(indexer.queries) > before; searched != wantSearched {
+			t.Errorf("indexer searched = %t, want %t", searched, wantSearched)
+		}
+	}
+	tripped := func() bool {
+		status := m.withTrippedIndexers(&SystemStatus{IndexerClients: map[string]ClientStatus{url: {}}})
+		return status.IndexerClients[url].TrippedUntil != nil
+	}
+	expireCooldown := func() {
+		m.indexerFailuresMu.Lock()
+		m.indexerTripped[url] = time.Now().Add(-time.Second)
+		m.indexerFailuresMu.Unlock()
+	}
+
+	// Searched until it fails threshold times in a row, then skipped
+	search(true)
+	if tripped() {
+		t.Error("indexer tripped after one failure")
+	}
+	search(true)
+	if !tripped() {
+		t.Fatal("indexer not tripped after reaching the threshold")
+	}
+	search(false)
+
+	// After the cooldown one search probes it; still failing, it is skipped again
+	expireCooldown()
+	if tripped() {
+		t.Error("indexer still reported as tripped after its cooldown")
+	}
+	search(true)
+	search(false)
+	if !tripped() {
+		t.Error("indexer not tripped again after a failed probe")
+	}
+
+	// A successful probe puts it back into every search
+	expireCooldown()
+	indexer.err = nil
+	search(true)
+	search(true)
+	if tripped() {
+		t.Error("indexer still tripped after a successful search")
+	}
+}
+
+func TestExtraTrackersSkipPrivateSources(t *testing.T) {
+	delay := extraTrackersDelay
+	extraTrackersDelay = 0
+	t.Cleanup(func() { extraTrackersDelay = delay })
+
+	tests := []struct {
+		name    string
+		private bool
+	}{
+		{"public source", false},
+		{"private source", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Movies.DownloadFolder = t.TempDir()
+			cfg.ExtraTrackersList = []string{"udp://tracker.test:1337/announce"}
+			client := newFakeTorrentClient()
+			m := newTestManager(t, cfg, client)
+			m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{{
+				Client: &fakeIndexer{results: []indexers.IndexerResult{{
+					Title:       "Heat.1995.1080p.BluRay.x264-GRP",
+					DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat("4", 40),
+					Seeders:     20,
+				}}},
+				Source: config.SourceConfig{URL: "http://indexer.test", SearchMode: "search", Private: tt.private},
+			}}
+			movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+
+			results, err := m.performSearch(movie, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Private != tt.private {
+				t.Fatalf("performSearch() = %+v, want one result with Private %t", results, tt.private)
+			}
+			if err := m.StartDownload(context.Background(), movie.ID, results[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			// The trackers are added in the background
+			var trackers []string
+			for deadline := time.Now().Add(500 * time.Millisecond); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				client.mu.Lock()
+				trackers = client.trackers[strings.Repeat("4", 40)]
+				client.mu.Unlock()
+				if len(trackers) > 0 {
+					break
+				}
+			}
+			if tt.private && len(trackers) > 0 {
+				t.Errorf("trackers %q added to a private release", trackers)
+			}
+			if !tt.private && !slices.Equal(trackers, cfg.ExtraTrackersList) {
+				t.Errorf("trackers = %q, want %q", trackers, cfg.ExtraTrackersList)
+			}
+		})
+	}
+}
+
+func TestMultiEpisodeGrabMarksRange(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.TVShows.DownloadFolder = t.TempDir()
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-18", "2022-02-25", "2022-03-04")
+	hash := strings.Repeat("5", 40)
+
+	release := indexers.IndexerResult{Title: "Severance.S01E01-E03.1080p.WEB.h264-GRP", DownloadURL: "magnet:?xt=urn:btih:" + hash}
+	if err := m.StartEpisodeDownload(context.Background(), media.ID, 1, 1, release); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]models.MediaStatus{
+		"S01E01": models.StatusDownloading,
+		"S01E02": models.StatusDownloading,
+		"S01E03": models.StatusDownloading,
+		"S01E04": models.StatusPending,
+	}
+	if got := episodeStatuses(t, m.mediaRepo, media.ID); !maps.Equal(got, want) {
+		t.Errorf("episode statuses %v, want %v", got, want)
+	}
+	for episode := 1; episode <= 3; episode++ {
+		ep, err := m.mediaRepo.GetEpisodeByDetails(media.ID, 1, episode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ep.TorrentHash == nil || *ep.TorrentHash != hash {
+			t.Errorf("E%02d torrent hash = %v, want the range's", episode, ep.TorrentHash)
+		}
+	}
+}
+
+func TestRejectedSettingsLeaveConfigUntouched(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Movies.MoveMethod = []string{"hardlink", "copy"}
+	cfg.Automation.QualityPreferences = []string{"1080p", "720p"}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+
+	patch := `{"movies": {"move_method": ["bogus", "copy"]}, "automation": {"quality_preferences": ["2160p", "480p"]}}`
+	if _, err := m.UpdateSettings([]byte(patch)); err == nil {
+		t.Fatal("UpdateSettings() accepted an unknown move method")
+	}
+	if want := []string{"hardlink", "copy"}; !slices.Equal(cfg.Movies.MoveMethod, want) {
+		t.Errorf("movies.move_method = %q, want %q", cfg.Movies.MoveMethod, want)
+	}
+	if want := []string{"1080p", "720p"}; !slices.Equal(cfg.Automation.QualityPreferences, want) {
+		t.Errorf("automation.quality_preferences = %q, want %q", cfg.Automation.QualityPreferences, want)
+	}
+}
+
+func TestSaveConfigRejectsUnbuildableClient(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	const saved = "torrent_client:\n  type: transmission\n  host: 127.0.0.1:9091\n"
+	if err := os.WriteFile("config.yml", []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+
+	err := m.SaveAndReloadConfig("torrent_client:\n  type: bogus\n  host: 127.0.0.1:1\n")
+	if err == nil || !strings.Contains(err.Error(), "unsupported torrent client type") {
+		t.Fatalf("SaveAndReloadConfig() = %v, want the client error", err)
+	}
+	if m.Config() != cfg {
+		t.Error("the running config was replaced")
+	}
+	if data, _ := os.ReadFile("config.yml"); string(data) != saved {
+		t.Errorf("config file = %q, want it unchanged", data)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	never := 0
+	tests := []struct {
+		name       string
+		shows      bool // A failed episode of a show instead of a failed movie
+		maxRetries *int
+		wantRetry  bool
+	}{
+		{"failed movie", false, nil, true},
+		{"failed episode", true, nil, true},
+		{"failed movie with max_retries 0", false, &never, false},
+		{"failed episode with max_retries 0", true, &never, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Automation.DryRun = true
+			cfg.Automation.MaxRetries = tt.maxRetries
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+
+			// Movies are retried by retryFailedDownloads, episodes by processPendingMedia
+			var media *models.Media
+			run := (*Manager).retryFailedDownloads
+			if tt.shows {
+				media = createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+				if err := m.mediaRepo.UpdateStatus(media.ID, models.StatusDownloaded); err != nil {
+					t.Fatal(err)
+				}
+				if err := m.mediaRepo.UpdateEpisodeDownloadInfo(media.ID, 1, 1, models.StatusFailed, nil, nil); err != nil {
+					t.Fatal(err)
+				}
+				run = (*Manager).processPendingMedia
+			} else {
+				media = createMovie(t, m.mediaRepo, "Heat", 1995)
+				if err := m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed); err != nil {
+					t.Fatal(err)
+				}
+			}
+			queued := func() bool {
+				m.queuedMu.Lock()
+				defer m.queuedMu.Unlock()
+				return m.queuedMedia[media.ID]
+			}
+			stored := func() *models.Media {
+				stored, err := m.mediaRepo.GetByID(media.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return stored
+			}
+
+			// The first run only schedules the retry
+			run(m)
+			if queued() {
+				t.Fatal("re-queued before the backoff passed")
+			}
+			if scheduled := stored().NextRetryAt != nil; scheduled != tt.wantRetry {
+				t.Fatalf("retry scheduled = %v, want %v", scheduled, tt.wantRetry)
+			}
+			if !tt.wantRetry {
+				return
+			}
+
+			past := time.Now().Add(-time.Minute)
+			if err := m.mediaRepo.ScheduleRetry(media.ID, 0, &past); err != nil {
+				t.Fatal(err)
+			}
+			run(m)
+			if !queued() {
+				t.Error("not re-queued once the retry was due")
+			}
+			if count := stored().RetryCount; count != 1 {
+				t.Errorf("retry count = %d, want 1", count)
+			}
+		})
+	}
+}
+
+func TestPausedMediaIsSkipped(t *testing.T) {
+	// Each task runs over a monitored and a paused item, movies or shows
+	type task struct {
+		name  string
+		shows bool
+		setup func(t *testing.T, m *Manager, media *models.Media) // Prepares each item
+		run   func(m *Manager)
+		acted func(t *testing.T, m *Manager, logs string, media *models.Media) bool // Whether the task handled an item
+	}
+	queued := func(t *testing.T, m *Manager, logs string, media *models.Media) bool {
+		m.queuedMu.Lock()
+		defer m.queuedMu.Unlock()
+		return m.queuedMedia[media.ID]
+	}
+	logged := func(format string) func(t *testing.T, m *Manager, logs string, media *models.Media) bool {
+		return func(t *testing.T, m *Manager, logs string, media *models.Media) bool {
+			return strings.Contains(logs, fmt.Sprintf(format, media.Title))
+		}
+	}
+	tests := []task{
+		{
+			name:  "processPendingMedia",
+			run:   (*Manager).processPendingMedia,
+			acted: queued,
+		},
+		{
+			name: "retryFailedDownloads",
+			setup: func(t *testing.T, m *Manager, media *models.Media) {
+				if err := m.mediaRepo.UpdateStatus(media.ID, models.StatusFailed); err != nil {
+					t.Fatal(err)
+				}
+			},
+			run: (*Manager).retryFailedDownloads,
+			acted: func(t *testing.T, m *Manager, logs string, media *models.Media) bool {
+				stored, err := m.mediaRepo.GetByID(media.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return stored.NextRetryAt != nil
+			},
+		},
+		{
+			name:  "checkForNewEpisodes",
+			shows: true,
+			run:   (*Manager).checkForNewEpisodes,
+			acted: logged("Updating metadata for show: %s"),
+		},
+		{
+			name:  "matchFeedItems",
+			shows: true,
+			run: func(m *Manager) {
+				m.matchFeedItems(config.SourceConfig{}, []rssItem{
+					{Title: "Severance.S01E01.1080p.WEB.h264-GRP", Link: "magnet:?xt=urn:btih:" + strings.Repeat("6", 40)},
+					{Title: "Pluribus.S01E01.1080p.WEB.h264-GRP", Link: "magnet:?xt=urn:btih:" + strings.Repeat("7", 40)},
+				})
+			},
+			acted: logged("Found match in RSS feed for %s"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Automation.DryRun = true
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+			var logs strings.Builder
+			m.logger = utils.NewLogger(false, &logs)
+			m.metadataClients = map[models.MediaType][]metadata.Client{models.MediaTypeTVShow: {&fakeMetadataClient{name: "tvmaze"}}}
+
+			var monitored, paused *models.Media
+			if tt.shows {
+				monitored = createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18")
+				paused = createShow(t, m.mediaRepo, "Pluribus", 1, "2025-11-07")
+			} else {
+				monitored = createMovie(t, m.mediaRepo, "Heat", 1995)
+				paused = createMovie(t, m.mediaRepo, "Ronin", 1998)
+			}
+			for _, media := range []*models.Media{monitored, paused} {
+				if err := m.mediaRepo.UpdateSettings(media.ID, "720p", "2160p", true); err != nil {
+					t.Fatal(err)
+				}
+				if tt.setup != nil {
+					tt.setup(t, m, media)
+				}
+			}
+			if err := m.SetMonitored(paused.ID, false); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.run(m)
+			if !tt.acted(t, m, logs.String(), monitored) {
+				t.Errorf("%s skipped the monitored %s", tt.name, monitored.Title)
+			}
+			if tt.acted(t, m, logs.String(), paused) {
+				t.Errorf("%s handled the paused %s", tt.name, paused.Title)
+			}
+		})
+	}
+}
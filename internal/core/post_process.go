@@ -2,12 +2,17 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"reel/internal/clients/notifications"
@@ -36,7 +41,9 @@ func NewPostProcessor(cfg *config.Config, logger *utils.Logger, mediaRepo *model
 }
 
 // ProcessDownload is the main entry point for post-processing a completed download.
-func (pp *PostProcessor) ProcessDownload(media models.Media, torrentStatus torrent.TorrentStatus, seasonNumber int, episodeNumber int, downloadPath string) error {
+// With replace, the download replaces a release the user rejected, so files of the same
+// name are overwritten whatever file_renaming.on_collision says.
+func (pp *PostProcessor) ProcessDownload(media models.Media, torrentStatus torrent.TorrentStatus, seasonNumber int, episodeNumber int, downloadPath string, replace bool) error {
 	pp.logger.Info("Starting post-processing for:", media.Title)
 
 	destinationPath := pp.createDestinationFolder(&media, seasonNumber)
@@ -46,18 +53,44 @@ func (pp *PostProcessor) ProcessDownload(media models.Media, torrentStatus torre
 		return err
 	}
 
-	mediaFiles := pp.identifyMediaFiles(downloadPath, torrentStatus.Files)
+	torrentFiles := torrentStatus.Files
+	if len(torrentFiles) == 0 {
+		// Some clients report no files (or their status couldn't be parsed) even though
+		// the download is on disk
+		pp.logger.Warn("Torrent client reported no files for", torrentStatus.Name, "- looking for them on disk")
+		torrentFiles = pp.filesOnDisk(downloadPath, torrentStatus.Name)
+	}
+
+	mediaFiles := pp.identifyMediaFiles(downloadPath, torrentFiles)
+	if torrentStatus.Progress < 1.0 {
+		// Completed by its state: files that were unselected in the client never arrive
+		mediaFiles = pp.existingFiles(mediaFiles)
+	}
 	if len(mediaFiles) == 0 {
 		err := fmt.Errorf("no media files identified for: %s", media.Title)
 		pp.logger.Error(err.Error())
 		return err
 	}
 
-	if err := pp.processFilesWithFallback(&media, mediaFiles, destinationPath); err != nil {
+	onCollision := pp.config.FileRenaming.OnCollision
+	if replace {
+		onCollision = config.CollisionOverwrite
+	}
+
+	placed, err := pp.processFilesWithFallback(&media, mediaFiles, destinationPath, onCollision)
+	if err != nil {
 		return err
 	}
+	if len(placed) == 0 {
+		err := fmt.Errorf("every file of %s already exists in %s, nothing was placed", torrentStatus.Name, destinationPath)
+		pp.logger.Error(err.Error())
+		return err
+	}
+	if skipped := len(mediaFiles) - len(placed); skipped > 0 {
+		pp.logger.Warn(fmt.Sprintf("%d of %d files of %s were left out, as files of the same name are in the library", skipped, len(mediaFiles), torrentStatus.Name))
+	}
 
-	pp.renameFiles(&media, destinationPath, seasonNumber, episodeNumber, torrentStatus.Name, mediaFiles)
+	pp.renameFiles(&media, destinationPath, seasonNumber, episodeNumber, torrentStatus.Name, placed, onCollision)
 
 	pp.notifyPostProcessCompleted(&media, torrentStatus.Name)
 
@@ -85,9 +118,8 @@ func (pp *PostProcessor) createDestinationFolder(media *models.Media, seasonNumb
 	mediaFolderName := fmt.Sprintf("%s (%d)", safeTitle, media.Year)
 	fullPath := filepath.Join(baseDestPath, mediaFolderName)
 
-	if (media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime) && seasonNumber > 0 {
-		seasonFolderName := fmt.Sprintf("S%02d", seasonNumber)
-		fullPath = filepath.Join(fullPath, seasonFolderName)
+	if media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime {
+		fullPath = filepath.Join(fullPath, pp.config.SeasonFolder(string(media.Type), seasonNumber))
 	}
 
 	err := os.MkdirAll(fullPath, os.ModePerm)
@@ -116,8 +148,59 @@ func (pp *PostProcessor) identifyMediaFiles(downloadPath string, torrentFiles []
 	return files
 }
 
+// filesOnDisk lists the files of a torrent from its download folder, relative to
+// downloadPath like the client's own file list: the torrent's folder is walked, or a
+// single-file torrent is returned as is. Without a name nothing is scanned, since
+// downloadPath is shared with other downloads.
+func (pp *PostProcessor) filesOnDisk(downloadPath, torrentName string) []string {
+	if torrentName == "" {
+		return nil
+	}
+	root := filepath.Join(downloadPath, torrentName)
+	info, err := os.Stat(root)
+	if err != nil {
+		pp.logger.Warn("Could not find the download on disk:", err)
+		return nil
+	}
+	if !info.IsDir() {
+		return []string{torrentName}
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			if rel, relErr := filepath.Rel(downloadPath, path); relErr == nil {
+				files = append(files, rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		pp.logger.Warn("Could not scan the download folder:", err)
+	}
+	return files
+}
+
+// existingFiles drops the files that aren't on disk.
+func (pp *PostProcessor) existingFiles(files []string) []string {
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			pp.logger.Info("Skipping file that was not downloaded:", file)
+			continue
+		}
+		existing = append(existing, file)
+	}
+	return existing
+}
+
 // processFilesWithFallback attempts to process files using a sequential list of methods.
-func (pp *PostProcessor) processFilesWithFallback(media *models.Media, files []string, destination string) error {
+// It returns where each file ended up, by its source path; files left out because of
+// a collision (see collisionPath) are missing.
+func (pp *PostProcessor) processFilesWithFallback(media *models.Media, files []string, destination, onCollision string) (map[string]string, error) {
 	var moveMethods []string
 	switch media.Type {
 	case models.MediaTypeMovie:
@@ -129,26 +212,39 @@ func (pp *PostProcessor) processFilesWithFallback(media *models.Media, files []s
 	}
 
 	if len(moveMethods) == 0 {
-		return fmt.Errorf("no move_method defined for media type: %s", media.Type)
+		return nil, fmt.Errorf("no move_method defined for media type: %s", media.Type)
 	}
 
+	placed := make(map[string]string)
 	for _, file := range files {
 		if !waitForFile(file, 30*time.Second) {
-			return fmt.Errorf("source file did not appear in time: %s", file)
+			return nil, fmt.Errorf("source file did not appear in time: %s", file)
+		}
+
+		newPath, err := pp.collisionPath(filepath.Join(destination, filepath.Base(file)), file, onCollision)
+		if err != nil {
+			return nil, err
+		}
+		if newPath == "" {
+			continue
+		}
+		if isSameFile(file, newPath) {
+			// Linked by an earlier run of the post-processing
+			placed[file] = newPath
+			continue
 		}
 
 		var lastErr error
 		success := false
 		for _, method := range moveMethods {
-			newPath := filepath.Join(destination, filepath.Base(file))
 			pp.logger.Info(fmt.Sprintf("Attempting to '%s' file: %s", method, file))
 
 			var err error
 			switch method {
 			case "hardlink":
-				err = os.Link(file, newPath)
+				err = replaceWithLink(linkFile, file, newPath)
 			case "symlink":
-				err = os.Symlink(file, newPath)
+				err = replaceWithLink(symlinkFile, file, newPath)
 			case "move":
 				err = os.Rename(file, newPath)
 			case "copy":
@@ -159,21 +255,111 @@ func (pp *PostProcessor) processFilesWithFallback(media *models.Media, files []s
 
 			if err == nil {
 				pp.logger.Info(fmt.Sprintf("Successfully processed file with method: '%s'", method))
+				placed[file] = newPath
 				success = true
 				break // Success, move to the next file
 			}
 			lastErr = err
-			pp.logger.Warn(fmt.Sprintf("Method '%s' failed for file '%s': %v. Trying next method.", method, file, err))
+			switch {
+			case errors.Is(err, syscall.EXDEV):
+				pp.logger.Warn(fmt.Sprintf("Method '%s' failed for file '%s': download and destination are on different filesystems; %s impossible. Trying next method.", method, file, method))
+			case errors.Is(err, fs.ErrPermission):
+				pp.logger.Error(fmt.Sprintf("Method '%s' failed for file '%s': permission denied, check that Reel can write to '%s': %v. Trying next method.", method, file, destination, err))
+			default:
+				pp.logger.Warn(fmt.Sprintf("Method '%s' failed for file '%s': %v. Trying next method.", method, file, err))
+			}
 		}
 
 		if !success {
 			pp.logger.Error(fmt.Sprintf("All processing methods failed for file '%s'. Last error: %v", file, lastErr))
-			return fmt.Errorf("failed to process file '%s' after all fallbacks", file)
+			if errors.Is(lastErr, fs.ErrPermission) {
+				return nil, fmt.Errorf("failed to process file '%s' after all fallbacks: permission denied: %w", file, lastErr)
+			}
+			return nil, fmt.Errorf("failed to process file '%s' after all fallbacks: %w", file, lastErr)
+		}
+	}
+	return placed, nil
+}
+
+// collisionPath returns the path the file at source is placed at when path is taken,
+// by the onCollision policy (see file_renaming.on_collision): path itself to overwrite
+// it, a free "name (2).ext" to rename with a suffix, or "" to skip the file. A free
+// path, or one that already is source (a link placed by an earlier run), is returned as
+// it is. An overwritten file is left in place until the new one replaces it (see
+// replaceWithLink).
+func (pp *PostProcessor) collisionPath(path, source, onCollision string) (string, error) {
+	if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) || isSameFile(source, path) {
+		return path, nil
+	}
+	switch onCollision {
+	case config.CollisionOverwrite:
+		pp.logger.Warn("Overwriting existing file:", path)
+		return path, nil
+	case config.CollisionRenameWithSuffix:
+		ext := filepath.Ext(path)
+		base := strings.TrimSuffix(path, ext)
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+			if _, err := os.Lstat(candidate); errors.Is(err, fs.ErrNotExist) || isSameFile(source, candidate) {
+				pp.logger.Warn("File already exists:", path, "- using", filepath.Base(candidate))
+				return candidate, nil
+			}
 		}
+	default:
+		pp.logger.Warn("File already exists, leaving it in place:", path)
+		return "", nil
+	}
+}
+
+// isSymlink reports whether path is a symbolic link.
+func isSymlink(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// isSameFile reports whether two paths are the same file, e.g. a hardlink or a symlink
+// to a download that was already placed.
+func isSameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	return err == nil && os.SameFile(infoA, infoB)
+}
+
+// linkFile hardlinks dst to src; tests replace it to fail like a cross-device link.
+var linkFile = os.Link
+
+// symlinkFile links dst to src. The target is made absolute, so the link keeps pointing
+// at the download wherever the library folder is.
+// replaceWithLink links dst to src with link (linkFile or symlinkFile), which fail on
+// an existing dst. Such a file is only replaced once the link is in place: the link is
+// made under a temporary name and renamed over it, so a failed link keeps the old file.
+func replaceWithLink(link func(src, dst string) error, src, dst string) error {
+	if _, err := os.Lstat(dst); errors.Is(err, fs.ErrNotExist) {
+		return link(src, dst)
+	}
+	tempPath := dst + ".part"
+	os.Remove(tempPath) // Left over by an interrupted run
+	if err := link(src, tempPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, dst); err != nil {
+		os.Remove(tempPath)
+		return err
 	}
 	return nil
 }
 
+func symlinkFile(src, dst string) error {
+	target, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	return os.Symlink(target, dst)
+}
+
 // copyFileAndRemoveOriginal performs a manual copy and then deletes the source.
 func (pp *PostProcessor) copyFileAndRemoveOriginal(src, dst string) error {
 	sourceFile, err := os.Open(src)
@@ -182,14 +368,27 @@ func (pp *PostProcessor) copyFileAndRemoveOriginal(src, dst string) error {
 	}
 	defer sourceFile.Close()
 
-	destinationFile, err := os.Create(dst)
+	// Copy under a temporary name and rename it once complete, so media servers and
+	// the streaming endpoints never see a partially copied file. Renaming within the
+	// destination folder is atomic.
+	tempPath := dst + ".part"
+	destinationFile, err := os.Create(tempPath)
 	if err != nil {
 		return err
 	}
-	defer destinationFile.Close()
 
 	_, err = io.Copy(destinationFile, sourceFile)
+	if err == nil {
+		err = destinationFile.Sync()
+	}
+	if closeErr := destinationFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, dst)
+	}
 	if err != nil {
+		os.Remove(tempPath)
 		return err
 	}
 
@@ -217,51 +416,65 @@ func waitForFile(filePath string, timeout time.Duration) bool {
 	}
 }
 
+// parseQualityFromTorrentName returns the resolution of a release name for the renamed
+// files, or its source when it gives no resolution.
 func (pp *PostProcessor) parseQualityFromTorrentName(torrentName string) string {
-	lowerName := strings.ToLower(torrentName)
-	// Check for resolutions first
-	for _, res := range SUPPORTED_RESOLUTIONS {
-		if strings.Contains(lowerName, res) {
-			return res
-		}
+	release := utils.ParseReleaseName(torrentName)
+	if release.Resolution != "" {
+		return release.Resolution
 	}
-	// Fallback to other quality indicators
-	if strings.Contains(lowerName, "web-dl") || strings.Contains(lowerName, "webdl") {
-		return "WEB-DL"
+	if release.Source != "" {
+		return release.Source
 	}
-	if strings.Contains(lowerName, "bluray") {
-		return "BluRay"
-	}
-	if strings.Contains(lowerName, "webrip") {
-		return "WEBRip"
-	}
-	if strings.Contains(lowerName, "bdrip") {
-		return "BDRip"
-	}
-	if strings.Contains(lowerName, "brrip") {
-		return "BRRip"
-	}
-	if strings.Contains(lowerName, "hdtv") {
-		return "HDTV"
-	}
-	if strings.Contains(lowerName, "dvdrip") {
-		return "DVDRip"
-	}
-	if strings.Contains(lowerName, "xvid") {
+	if release.Codec == "XviD" {
 		return "Xvid" // Not really a quality, but it's quite common
 	}
 	return "Unknown"
 }
 
-// renameFiles renames the moved/linked files to a clean, standardized format.
-func (pp *PostProcessor) renameFiles(media *models.Media, destination string, season, episode int, torrentName string, filesToRename []string) {
+// episodeNumberFromFilename returns the episode number of an "S01E02" or "1x02" style
+// file name, or 0 if it has none.
+func episodeNumberFromFilename(name string) int {
+	match := episodeTagRegex.FindStringSubmatch(name)
+	if match == nil {
+		return 0
+	}
+	number := match[1]
+	if number == "" {
+		number = match[2]
+	}
+	n, _ := strconv.Atoi(number)
+	return n
+}
+
+// renameFiles renames the moved/linked files to a clean, standardized format. placed
+// maps the download's files to where processFilesWithFallback put them.
+func (pp *PostProcessor) renameFiles(media *models.Media, destination string, season, episode int, torrentName string, placed map[string]string, onCollision string) {
 	quality := pp.parseQualityFromTorrentName(torrentName)
 
-	for _, oldPath := range filesToRename {
-		// We need to construct the path of the file *after* it has been moved/symlinked
-		movedPath := filepath.Join(destination, filepath.Base(oldPath))
+	for _, oldPath := range slices.Sorted(maps.Keys(placed)) {
+		// The path of the file *after* it has been moved/symlinked
+		movedPath := placed[oldPath]
 		ext := filepath.Ext(movedPath)
 
+		fileEpisode := episode
+		if episode == 0 && media.Type != models.MediaTypeMovie {
+			// Season pack: each file carries its own episode number
+			fileEpisode = episodeNumberFromFilename(filepath.Base(oldPath))
+			if fileEpisode == 0 {
+				pp.logger.Warn("Keeping original name, no episode number found in:", filepath.Base(oldPath))
+				continue
+			}
+		}
+
+		episodeLabel := fmt.Sprintf("%02d", fileEpisode)
+		if media.Type != models.MediaTypeMovie {
+			// A single file holding several episodes keeps its range rather than being split
+			if _, first, last, ok := episodeRange(filepath.Base(oldPath)); ok {
+				episodeLabel = fmt.Sprintf("%02d-E%02d", first, last)
+			}
+		}
+
 		var newName string
 		var template string
 		switch media.Type {
@@ -278,23 +491,45 @@ func (pp *PostProcessor) renameFiles(media *models.Media, destination string, se
 			if media.Type == models.MediaTypeMovie {
 				newName = fmt.Sprintf("%s (%d) [%s]%s", media.Title, media.Year, quality, ext)
 			} else {
-				newName = fmt.Sprintf("%s - S%02dE%02d [%s]%s", media.Title, season, episode, quality, ext)
+				newName = fmt.Sprintf("%s - S%02dE%s [%s]%s", media.Title, season, episodeLabel, quality, ext)
 			}
 		} else {
 			r := strings.NewReplacer(
 				"{title}", media.Title,
 				"{year}", strconv.Itoa(media.Year),
 				"{season}", fmt.Sprintf("%02d", season),
-				"{episode}", fmt.Sprintf("%02d", episode),
+				"{episode}", episodeLabel,
 				"{quality}", quality,
 			)
 			newName = r.Replace(template) + ext
 		}
 
 		newPath := filepath.Join(destination, newName)
+		newPath, err := pp.collisionPath(newPath, movedPath, onCollision)
+		if err != nil || newPath == "" {
+			pp.logger.Warn("Keeping original name:", movedPath)
+			continue
+		}
+		if newPath == movedPath {
+			continue
+		}
+		if isSameFile(movedPath, newPath) {
+			// Renamed by an earlier run: drop the second link, unless it is the file a
+			// renamed symlink points at
+			pp.logger.Debug("Already renamed by an earlier run:", newPath)
+			if isSymlink(movedPath) || !isSymlink(newPath) {
+				os.Remove(movedPath)
+			}
+			continue
+		}
 
 		// Check if the moved file actually exists before trying to rename it
-		if _, err := os.Stat(movedPath); err == nil {
+		// Lstat, so a symlink into the download folder is renamed itself. Rename never
+		// follows links, which leaves the still-seeding file where the client expects it.
+		if info, err := os.Lstat(movedPath); err == nil {
+			if info.Mode()&os.ModeSymlink != 0 {
+				pp.logger.Debug("Renaming symlink, its target stays in place:", movedPath)
+			}
 			err := os.Rename(movedPath, newPath)
 			if err != nil {
 				pp.logger.Error("Failed to rename file:", err)
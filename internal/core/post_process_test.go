@@ -0,0 +1,515 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"reel/internal/clients/torrent"
+	"reel/internal/config"
+	"reel/internal/database/models"
+	"reel/internal/utils"
+)
+
+func TestProcessDownloadCollisions(t *testing.T) {
+	const renamed = "Heat (1995) [1080p].mkv"
+
+	tests := []struct {
+		name        string
+		onCollision string
+		replace     bool
+		existing    string            // Name of the file already in the library
+		wantErr     bool              // Nothing could be placed
+		want        map[string]string // Library files and their contents afterwards
+	}{
+		{
+			name:     "skip keeps the existing file",
+			existing: renamed,
+			want:     map[string]string{renamed: "old", "heat.mkv": "new"},
+		},
+		{
+			name:        "overwrite replaces it",
+			onCollision: config.CollisionOverwrite,
+			existing:    renamed,
+			want:        map[string]string{renamed: "new"},
+		},
+		{
+			name:        "rename-with-suffix adds a number",
+			onCollision: config.CollisionRenameWithSuffix,
+			existing:    renamed,
+			want:        map[string]string{renamed: "old", "Heat (1995) [1080p] (2).mkv": "new"},
+		},
+		{
+			name:        "a replacement overwrites despite skip",
+			onCollision: config.CollisionSkip,
+			replace:     true,
+			existing:    renamed,
+			want:        map[string]string{renamed: "new"},
+		},
+		{
+			name:     "skipping every file is an error",
+			existing: "heat.mkv",
+			wantErr:  true,
+			want:     map[string]string{"heat.mkv": "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			downloads := filepath.Join(root, "downloads")
+			library := filepath.Join(root, "movies", "Heat (1995)")
+			if err := os.MkdirAll(filepath.Join(downloads, "Heat.1995.1080p.BluRay-GRP"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(library, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(downloads, "Heat.1995.1080p.BluRay-GRP", "heat.mkv"), []byte("new"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(library, tt.existing), []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{}
+			cfg.Movies.DestinationFolder = filepath.Join(root, "movies")
+			cfg.Movies.MoveMethod = []string{"hardlink"}
+			cfg.FileRenaming.OnCollision = tt.onCollision
+			pp := NewPostProcessor(cfg, utils.NewLogger(false, io.Discard), nil, nil)
+
+			media := models.Media{ID: 1, Type: models.MediaTypeMovie, Title: "Heat", Year: 1995}
+			status := torrent.TorrentStatus{Name: "Heat.1995.1080p.BluRay-GRP", Progress: 1, Files: []string{"Heat.1995.1080p.BluRay-GRP/heat.mkv"}}
+			err := pp.ProcessDownload(media, status, 0, 0, downloads, tt.replace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessDownload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			entries, err := os.ReadDir(library)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string)
+			for _, entry := range entries {
+				content, err := os.ReadFile(filepath.Join(library, entry.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[entry.Name()] = string(content)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("library holds %v, want %v", got, tt.want)
+			}
+			for name, content := range tt.want {
+				if got[name] != content {
+					t.Errorf("library holds %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestReplacesRelease(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-25")
+
+	if m.replacesRelease(media.ID, 1, 1) {
+		t.Error("a first download replaces nothing")
+	}
+	if err := m.mediaRepo.AddReleaseHistory(&models.ReleaseHistoryEntry{MediaID: media.ID, SeasonNumber: 1, EpisodeNumber: 1, Event: models.ReleaseBlacklisted, ReleaseTitle: "Severance.S01E01.720p-BAD"}); err != nil {
+		t.Fatal(err)
+	}
+	if !m.replacesRelease(media.ID, 1, 1) {
+		t.Error("the download after a re-download does not replace the rejected release")
+	}
+	if m.replacesRelease(media.ID, 1, 2) {
+		t.Error("another episode's re-download counts as a replacement")
+	}
+}
+
+func TestHardlinkFallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		linkErr     error
+		moveMethods []string
+		wantErr     string // Empty when the file is placed
+		wantLog     string
+	}{
+		{
+			name:        "cross-device link falls back to copy",
+			linkErr:     syscall.EXDEV,
+			moveMethods: []string{"hardlink", "copy"},
+			wantLog:     "download and destination are on different filesystems; hardlink impossible",
+		},
+		{
+			name:        "cross-device link without a fallback",
+			linkErr:     syscall.EXDEV,
+			moveMethods: []string{"hardlink"},
+			wantErr:     "invalid cross-device link",
+		},
+		{
+			name:        "permission denied is reported as such",
+			linkErr:     syscall.EACCES,
+			moveMethods: []string{"hardlink"},
+			wantErr:     "permission denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linkFile = func(oldname, newname string) error {
+				return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: tt.linkErr}
+			}
+			t.Cleanup(func() { linkFile = os.Link })
+
+			root := t.TempDir()
+			source := filepath.Join(root, "downloads", "heat.mkv")
+			library := filepath.Join(root, "movies")
+			for _, dir := range []string{filepath.Dir(source), library} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(source, []byte("movie"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{}
+			cfg.Movies.MoveMethod = tt.moveMethods
+			var logs strings.Builder
+			pp := NewPostProcessor(cfg, utils.NewLogger(false, &logs), nil, nil)
+			media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995}
+
+			placed, err := pp.processFilesWithFallback(media, []string{source}, library, config.CollisionSkip)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content, err := os.ReadFile(placed[source]); err != nil || string(content) != "movie" {
+				t.Errorf("placed file %q holds %q (%v), want the copy", placed[source], content, err)
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("log lacks %q:\n%s", tt.wantLog, logs.String())
+			}
+		})
+	}
+}
+
+func TestOverwriteKeepsFileUntilReplaced(t *testing.T) {
+	tests := []struct {
+		name        string
+		linkErr     error
+		moveMethods []string
+		want        string // Library file's content afterwards
+	}{
+		{"hardlink replaces it", nil, []string{"hardlink"}, "new"},
+		{"symlink replaces it", nil, []string{"symlink"}, "new"},
+		{"move replaces it", nil, []string{"move"}, "new"},
+		{"copy replaces it", nil, []string{"copy"}, "new"},
+		{"a failed link keeps it", syscall.EXDEV, []string{"hardlink"}, "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.linkErr != nil {
+				linkFile = func(oldname, newname string) error {
+					return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: tt.linkErr}
+				}
+				t.Cleanup(func() { linkFile = os.Link })
+			}
+			root := t.TempDir()
+			source := filepath.Join(root, "downloads", "heat.mkv")
+			library := filepath.Join(root, "movies")
+			existing := filepath.Join(library, "heat.mkv")
+			for _, dir := range []string{filepath.Dir(source), library} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(source, []byte("new"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{}
+			cfg.Movies.MoveMethod = tt.moveMethods
+			pp := NewPostProcessor(cfg, utils.NewLogger(false, io.Discard), nil, nil)
+			media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995}
+
+			_, err := pp.processFilesWithFallback(media, []string{source}, library, config.CollisionOverwrite)
+			if (err != nil) != (tt.want == "old") {
+				t.Fatalf("processFilesWithFallback() = %v", err)
+			}
+			if content, err := os.ReadFile(existing); err != nil || string(content) != tt.want {
+				t.Errorf("library file holds %q (%v), want %q", content, err, tt.want)
+			}
+			if _, err := os.Lstat(existing + ".part"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("temporary file left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestSymlinkImportKeepsSeedingSource(t *testing.T) {
+	root := t.TempDir()
+	downloads := filepath.Join(root, "downloads")
+	source := filepath.Join(downloads, "Heat.1995.1080p.BluRay-GRP", "heat.mkv")
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source, []byte("seeding"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Movies.DestinationFolder = filepath.Join(root, "movies")
+	cfg.Movies.MoveMethod = []string{"symlink"}
+	pp := NewPostProcessor(cfg, utils.NewLogger(false, io.Discard), nil, nil)
+
+	media := models.Media{ID: 1, Type: models.MediaTypeMovie, Title: "Heat", Year: 1995}
+	status := torrent.TorrentStatus{Name: "Heat.1995.1080p.BluRay-GRP", Progress: 1, Files: []string{"Heat.1995.1080p.BluRay-GRP/heat.mkv"}}
+	if err := pp.ProcessDownload(media, status, 0, 0, downloads, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if content, err := os.ReadFile(source); err != nil || string(content) != "seeding" {
+		t.Fatalf("seeding source holds %q (%v), want it untouched", content, err)
+	}
+	library := filepath.Join(root, "movies", "Heat (1995)")
+	entries, err := os.ReadDir(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "Heat (1995) [1080p].mkv" {
+		t.Fatalf("library holds %v, want only the renamed link", entries)
+	}
+	target, err := os.Readlink(filepath.Join(library, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("renamed file is not a symlink: %v", err)
+	}
+	if target != source {
+		t.Errorf("link points at %s, want the seeding source %s", target, source)
+	}
+}
+
+func TestCopyShowsOnlyTempFileUntilDone(t *testing.T) {
+	root := t.TempDir()
+	// A pipe as the source holds the copy open until the writer closes it
+	source := filepath.Join(root, "heat.mkv")
+	if err := syscall.Mkfifo(source, 0644); err != nil {
+		t.Skip("named pipes unsupported:", err)
+	}
+	destination := filepath.Join(root, "Heat (1995).mkv")
+	pp := NewPostProcessor(&config.Config{}, utils.NewLogger(false, io.Discard), nil, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- pp.copyFileAndRemoveOriginal(source, destination) }()
+
+	writer, err := os.OpenFile(source, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.WriteString("first half "); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForFile(destination+".part", 5*time.Second) {
+		t.Fatal("copy never started")
+	}
+	if _, err := os.Stat(destination); !os.IsNotExist(err) {
+		t.Errorf("final file exists mid-copy (stat error %v)", err)
+	}
+
+	writer.WriteString("second half")
+	writer.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(destination); err != nil || string(content) != "first half second half" {
+		t.Errorf("final file holds %q (%v), want the whole copy", content, err)
+	}
+	if _, err := os.Stat(destination + ".part"); !os.IsNotExist(err) {
+		t.Error("temporary file left behind")
+	}
+}
+
+func TestAnimeFolderLayout(t *testing.T) {
+	tests := []struct {
+		layout     string
+		wantFolder string // Relative to the show folder
+	}{
+		{"", "S01"},
+		{config.FolderLayoutSeasons, "S01"},
+		{config.FolderLayoutFlat, ""},
+	}
+	for _, tt := range tests {
+		t.Run("layout="+tt.layout, func(t *testing.T) {
+			root := t.TempDir()
+			downloads := filepath.Join(root, "downloads")
+			name := "[SubsPlease] Frieren - 03 (1080p).mkv"
+			if err := os.MkdirAll(downloads, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(downloads, name), []byte("episode"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{}
+			cfg.Anime.DestinationFolder = filepath.Join(root, "anime")
+			cfg.Anime.MoveMethod = []string{"hardlink"}
+			cfg.Anime.FolderLayout = tt.layout
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+
+			show := &models.TVShow{Status: "Running"}
+			if err := m.mediaRepo.CreateTVShow(show); err != nil {
+				t.Fatal(err)
+			}
+			media := models.Media{Type: models.MediaTypeAnime, Title: "Frieren", Year: 2023, Language: "ja", TVShowID: &show.ID, Status: models.StatusMonitoring}
+			if err := m.mediaRepo.Create(&media); err != nil {
+				t.Fatal(err)
+			}
+
+			status := torrent.TorrentStatus{Name: name, Progress: 1, Files: []string{name}}
+			if err := m.postProcessor.ProcessDownload(media, status, 1, 3, downloads, false); err != nil {
+				t.Fatal(err)
+			}
+			folder := filepath.Join(cfg.Anime.DestinationFolder, "Frieren (2023)", tt.wantFolder)
+			entries, err := os.ReadDir(folder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].IsDir() {
+				t.Fatalf("%s holds %v, want only the episode", folder, entries)
+			}
+
+			path, err := m.GetMediaFilePath(media.ID, 1, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(folder, entries[0].Name()); path != want {
+				t.Errorf("GetMediaFilePath() = %s, want %s", path, want)
+			}
+		})
+	}
+}
+
+func TestProcessDownloadWithoutFileList(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // Download files, relative to the download folder
+		want  []string          // Library files afterwards
+	}{
+		{
+			name: "folder torrent",
+			files: map[string]string{
+				"Heat.1995.1080p.BluRay-GRP/heat.mkv":         "movie",
+				"Heat.1995.1080p.BluRay-GRP/Subs/heat.en.srt": "subtitle",
+				"Heat.1995.1080p.BluRay-GRP/heat.nfo":         "info",
+				"Other.Movie.2001.1080p/other.mkv":            "other download",
+			},
+			want: []string{"Heat (1995) [1080p].mkv", "Heat (1995) [1080p].srt"},
+		},
+		{
+			name:  "single-file torrent",
+			files: map[string]string{"Heat.1995.1080p.BluRay-GRP.mkv": "movie", "other.mkv": "other download"},
+			want:  []string{"Heat (1995) [1080p].mkv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			downloads := filepath.Join(root, "downloads")
+			torrentName := ""
+			for name, content := range tt.files {
+				path := filepath.Join(downloads, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if top := strings.Split(name, "/")[0]; strings.HasPrefix(top, "Heat.") {
+					torrentName = top
+				}
+			}
+
+			cfg := &config.Config{}
+			cfg.Movies.DestinationFolder = filepath.Join(root, "movies")
+			cfg.Movies.MoveMethod = []string{"hardlink"}
+			pp := NewPostProcessor(cfg, utils.NewLogger(false, io.Discard), nil, nil)
+
+			media := models.Media{ID: 1, Type: models.MediaTypeMovie, Title: "Heat", Year: 1995}
+			// The client reported no files
+			status := torrent.TorrentStatus{Name: torrentName, Progress: 1}
+			if err := pp.ProcessDownload(media, status, 0, 0, downloads, false); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(filepath.Join(cfg.Movies.DestinationFolder, "Heat (1995)"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("library holds %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiEpisodeFileKeepsRange(t *testing.T) {
+	tests := []struct {
+		file      string
+		wantRange string
+	}{
+		{"Severance.S01E01-E03.1080p.WEB.h264-GRP.mkv", "S01E01-E03"},
+		{"Severance.S01E01E02.1080p.WEB.h264-GRP.mkv", "S01E01-E02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			root := t.TempDir()
+			downloads := filepath.Join(root, "downloads")
+			if err := os.MkdirAll(downloads, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(downloads, tt.file), []byte("episodes"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{}
+			cfg.TVShows.DestinationFolder = filepath.Join(root, "tv")
+			cfg.TVShows.MoveMethod = []string{"hardlink"}
+			m := newTestManager(t, cfg, newFakeTorrentClient())
+			media := createShow(t, m.mediaRepo, "Severance", 1, "2022-02-18", "2022-02-18", "2022-02-25")
+
+			status := torrent.TorrentStatus{Name: tt.file, Progress: 1, Files: []string{tt.file}}
+			if err := m.postProcessor.ProcessDownload(*media, status, 1, 1, downloads, false); err != nil {
+				t.Fatal(err)
+			}
+			// The file is placed once, named after its whole range
+			var placed []string
+			filepath.WalkDir(cfg.TVShows.DestinationFolder, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					placed = append(placed, d.Name())
+				}
+				return nil
+			})
+			if len(placed) != 1 || !strings.Contains(placed[0], tt.wantRange) {
+				t.Errorf("library holds %q, want one file named with %s", placed, tt.wantRange)
+			}
+		})
+	}
+}
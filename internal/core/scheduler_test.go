@@ -0,0 +1,81 @@
+package core
+
+import (
+	"reel/internal/config"
+	"testing"
+	"time"
+)
+
+func TestSpreadWindow(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Automation.SearchSpreadMinutes = 120
+	cfg.Automation.Schedules = map[string]string{config.TaskNewEpisodes: "0 2 * * *"}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+
+	// Capped to the 30 minute search interval
+	if got := m.spreadWindow(config.TaskPendingSearch); got != pendingMediaInterval {
+		t.Errorf("pending search window = %v, want %v", got, pendingMediaInterval)
+	}
+	// Scheduled for 2am: every check starts then
+	if got := m.spreadWindow(config.TaskNewEpisodes); got != 0 {
+		t.Errorf("cron-scheduled window = %v, want 0", got)
+	}
+	cfg.Automation.SearchSpreadMinutes = 0
+	if got := m.spreadWindow(config.TaskPendingSearch); got != 0 {
+		t.Errorf("window without search_spread_minutes = %v, want 0", got)
+	}
+}
+
+func TestScheduleTasksReplacesEntries(t *testing.T) {
+	cfg := &config.Config{}
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	m.scheduleTasks()
+	tasks := len(m.scheduler.Entries())
+	if tasks != len(config.ScheduledTasks) {
+		t.Fatalf("%d entries scheduled, want %d", tasks, len(config.ScheduledTasks))
+	}
+
+	m.config = &config.Config{}
+	m.config.Automation.Schedules = map[string]string{config.TaskPendingSearch: "0 2 * * *"}
+	m.scheduleTasks()
+	entries := m.scheduler.Entries()
+	if len(entries) != tasks {
+		t.Fatalf("%d entries after rescheduling, want %d", len(entries), tasks)
+	}
+	// The pending search now runs at 2am instead of every 30 minutes
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local)
+	found := false
+	for _, entry := range entries {
+		if next := entry.Schedule.Next(now); next.Equal(time.Date(2026, 1, 11, 2, 0, 0, 0, time.Local)) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("no entry runs at the new 2am schedule")
+	}
+}
+
+func TestSpreadOffset(t *testing.T) {
+	const window = 2 * time.Hour
+	offsets := make(map[time.Duration]int)
+	for id := 1; id <= 50; id++ {
+		offset := spreadOffset(id, window)
+		if offset < 0 || offset >= window {
+			t.Fatalf("offset of media %d = %v, want within [0, %v)", id, offset, window)
+		}
+		if other, ok := offsets[offset]; ok {
+			t.Errorf("media %d and %d share the offset %v", other, id, offset)
+		}
+		offsets[offset] = id
+		if again := spreadOffset(id, window); again != offset {
+			t.Errorf("offset of media %d changed from %v to %v", id, offset, again)
+		}
+	}
+	// Consecutive IDs, the usual case, land far apart
+	if gap := (spreadOffset(2, window) - spreadOffset(1, window)).Abs(); gap < window/10 {
+		t.Errorf("media 1 and 2 are only %v apart", gap)
+	}
+	if got := spreadOffset(7, 0); got != 0 {
+		t.Errorf("offset without a window = %v, want 0", got)
+	}
+}
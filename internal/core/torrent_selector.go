@@ -2,10 +2,14 @@ package core
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"reel/internal/clients/indexers"
 	"reel/internal/config"
@@ -17,17 +21,46 @@ import (
 type FilterStats struct {
 	InitialCount   int
 	RejectPatterns int
+	Blacklisted    int
 	EpisodeNumber  int
 	SeriesName     int
+	MovieYear      int
 	Quality        int
 	MinSeeders     int
+	MinPeers       int
+	ReleaseAge     int
 	FinalCount     int
+	Rejected       []RejectedResult
 }
 
+// RejectedResult is a torrent dropped by one of the filters, along with the reason.
+type RejectedResult struct {
+	indexers.IndexerResult
+	RejectReason string
+}
+
+// indexerPriorityWeight is the score bonus per level of a source's priority; one level
+// is worth as much as ten seeders.
+const indexerPriorityWeight = 10
+
 type TorrentSelector struct {
-	config       *config.Config
-	logger       *utils.Logger
-	filterLogger *log.Logger // New detailed logger
+	// The config is replaced by SetConfig when it is reloaded; read it with currentConfig
+	configMu sync.RWMutex
+	config   *config.Config
+	logger   *utils.Logger
+
+	// Detailed logging to filter.log, nil while it is off; switchable at runtime
+	filterMu     sync.Mutex
+	filterLogger *log.Logger
+	filterFile   io.Closer
+}
+
+// SelectionFacts are the stored facts about the wanted movie or episode that filtering
+// needs besides the media item. The caller looks them up, so the selector itself never
+// touches the database.
+type SelectionFacts struct {
+	Blacklisted map[string]bool // Lower-cased titles of the media item's blacklisted releases
+	AirDate     time.Time       // Air date of a date-based show's episode, zero otherwise
 }
 
 func NewTorrentSelector(cfg *config.Config, logger *utils.Logger) *TorrentSelector {
@@ -36,91 +69,268 @@ func NewTorrentSelector(cfg *config.Config, logger *utils.Logger) *TorrentSelect
 		logger: logger,
 	}
 
-	// This is the effective "single line" to control detailed logging.
-	// If the config value is not "detail", the filterLogger will be nil.
-	if cfg.App.FilterLogLevel == "detail" {
-		filterLogger, err := utils.NewFilterLogger(cfg.App.DataPath)
-		if err != nil {
-			logger.Error("Could not create filter.log:", err)
-		} else {
-			ts.filterLogger = filterLogger
-			ts.filterLogger.Println("--- New Filter Session Started ---")
-		}
+	// Detailed logging is only on when the config value is "detail"
+	if err := ts.SetFilterLogging(cfg.App.FilterLogLevel == "detail"); err != nil {
+		logger.Error("Could not create filter.log:", err)
 	}
 
 	return ts
 }
 
-// logReject logs a rejected torrent to filter.log if the logger is enabled.
-func (ts *TorrentSelector) logReject(reason string, result indexers.IndexerResult) {
+// SetConfig makes the selector use a reloaded config.
+func (ts *TorrentSelector) SetConfig(cfg *config.Config) {
+	ts.configMu.Lock()
+	defer ts.configMu.Unlock()
+	ts.config = cfg
+}
+
+// currentConfig returns the config in use.
+func (ts *TorrentSelector) currentConfig() *config.Config {
+	ts.configMu.RLock()
+	defer ts.configMu.RUnlock()
+	return ts.config
+}
+
+// SetFilterLogging turns detailed logging to filter.log on, opening the file, or off,
+// closing it.
+func (ts *TorrentSelector) SetFilterLogging(enabled bool) error {
+	ts.filterMu.Lock()
+	defer ts.filterMu.Unlock()
+
+	if enabled == (ts.filterLogger != nil) {
+		return nil
+	}
+	if !enabled {
+		ts.filterLogger.Println("--- Filter Session Ended ---")
+		err := ts.filterFile.Close()
+		ts.filterLogger, ts.filterFile = nil, nil
+		return err
+	}
+
+	filterLogger, file, err := utils.NewFilterLogger(ts.currentConfig().App.DataPath)
+	if err != nil {
+		return err
+	}
+	ts.filterLogger, ts.filterFile = filterLogger, file
+	ts.filterLogger.Println("--- New Filter Session Started ---")
+	return nil
+}
+
+// FilterLogging reports whether detailed logging to filter.log is on.
+func (ts *TorrentSelector) FilterLogging() bool {
+	ts.filterMu.Lock()
+	defer ts.filterMu.Unlock()
+	return ts.filterLogger != nil
+}
+
+// filterLog writes a line to filter.log if detailed logging is on.
+func (ts *TorrentSelector) filterLog(format string, args ...interface{}) {
+	ts.filterMu.Lock()
+	defer ts.filterMu.Unlock()
 	if ts.filterLogger != nil {
-		ts.filterLogger.Printf("REJECT: [%s] | %s", reason, result.Title)
+		ts.filterLogger.Printf(format, args...)
 	}
 }
 
+// logReject records a rejected torrent in the stats and logs it to filter.log if the logger is enabled.
+func (ts *TorrentSelector) logReject(reason string, result indexers.IndexerResult, stats *FilterStats) {
+	stats.Rejected = append(stats.Rejected, RejectedResult{IndexerResult: result, RejectReason: reason})
+	ts.filterLog("REJECT: [%s] | %s", reason, result.Title)
+}
+
 // logPass logs a passed torrent to filter.log if the logger is enabled.
 func (ts *TorrentSelector) logPass(result indexers.IndexerResult) {
-	if ts.filterLogger != nil {
-		ts.filterLogger.Printf("PASS: [Score: %d] %s", result.Score, result.Title)
+	ts.filterLog("PASS: [Score: %d] %s", result.Score, result.Title)
+}
+
+// qualityProfile returns the named profile assigned to the media, or nil when none is
+// set or the name is not in the config (the legacy min/max quality is used then).
+func (ts *TorrentSelector) qualityProfile(media *models.Media) *config.QualityProfile {
+	if media.QualityProfile == "" {
+		return nil
+	}
+	profile, ok := ts.currentConfig().QualityProfiles[media.QualityProfile]
+	if !ok {
+		ts.logger.Warn("Unknown quality profile", media.QualityProfile, "for", media.Title, "- using min/max quality")
+		return nil
 	}
+	return &profile
 }
 
-// getResolutionRank finds the resolution in a title and returns its numerical rank.
-func getResolutionRank(title string) int {
-	lowerTitle := strings.ToLower(title)
-	// Iterate from highest to lowest to catch the best quality first
-	for _, res := range SUPPORTED_RESOLUTIONS {
-		synonyms := RESOLUTION_SYNONYMS[res]
-		for _, synonym := range synonyms {
-			if strings.Contains(lowerTitle, strings.ToLower(synonym)) {
-				return RESOLUTION_RANK[res]
+// matchTexts are the texts of a result that reject patterns and preferred words are
+// tested against: the title, its release group on its own (so a pattern like "^YIFY$"
+// can name a group exactly) and, with automation.match_description, the description.
+func (ts *TorrentSelector) matchTexts(r indexers.IndexerResult) []string {
+	texts := []string{r.Title}
+	if group := utils.ParseReleaseName(r.Title).Group; group != "" {
+		texts = append(texts, group)
+	}
+	if ts.currentConfig().Automation.MatchDescription && r.Description != "" {
+		texts = append(texts, r.Description)
+	}
+	return texts
+}
+
+// profileScore is the bonus a release earns from a quality profile: 10 points per
+// preferred word found in any of its texts (see matchTexts), plus a bonus for the
+// resolution's position in the preferred order. The title comes first in texts, and
+// only the title is searched for the resolution.
+func profileScore(texts []string, profile *config.QualityProfile) int {
+	if profile == nil || len(texts) == 0 {
+		return 0
+	}
+
+	score := 0
+	lowerTexts := make([]string, len(texts))
+	for i, text := range texts {
+		lowerTexts[i] = strings.ToLower(text)
+	}
+	for _, word := range profile.PreferredWords {
+		if word == "" {
+			continue
+		}
+		for _, text := range lowerTexts {
+			if strings.Contains(text, strings.ToLower(word)) {
+				score += 10
+				break
 			}
 		}
 	}
+
+	resolution := utils.ParseReleaseName(texts[0]).ImpliedResolution()
+	if resolution == "" {
+		return score
+	}
+	// Entries may use a synonym ("4k"), so they are parsed like a release name
+	for i, res := range profile.PreferredOrder {
+		if utils.ParseReleaseName(res).Resolution == resolution {
+			return score + (len(profile.PreferredOrder)-i)*10
+		}
+	}
+	return score
+}
+
+// originScore is the bonus of a release's origin (INTERNAL, SCENE, P2P) for its position
+// in automation.preferred_origins, 10 points per place like a profile's preferred order.
+func originScore(title string, preferredOrigins []string) int {
+	origin := utils.ParseReleaseName(title).Origin
+	if origin == "" {
+		return 0
+	}
+	for i, preferred := range preferredOrigins {
+		if strings.EqualFold(preferred, origin) {
+			return (len(preferredOrigins) - i) * 10
+		}
+	}
+	return 0
+}
+
+// preferPropers puts every PROPER or REPACK just above the best-scored original it fixes
+// (see utils.ReleaseBaseName), so a fixed release wins over the flawed one even with
+// fewer seeders.
+func preferPropers(results []indexers.IndexerResult) {
+	bases := make([]string, len(results))
+	bestOriginal := make(map[string]int)
+	for i, r := range results {
+		bases[i] = utils.ReleaseBaseName(r.Title)
+		if utils.ParseReleaseName(r.Title).Proper {
+			continue
+		}
+		if score, ok := bestOriginal[bases[i]]; !ok || r.Score > score {
+			bestOriginal[bases[i]] = r.Score
+		}
+	}
+	for i, r := range results {
+		if score, ok := bestOriginal[bases[i]]; ok && utils.ParseReleaseName(r.Title).Proper && r.Score <= score {
+			results[i].Score = score + 1
+		}
+	}
+}
+
+// getResolutionRank finds the resolution in a title, or the one its source implies (see
+// utils.ReleaseInfo.ImpliedResolution), and returns its numerical rank.
+func getResolutionRank(title string) int {
+	if rank, ok := RESOLUTION_RANK[utils.ParseReleaseName(title).ImpliedResolution()]; ok {
+		return rank
+	}
 	// Return a low rank if no specific resolution is found, which will be filtered out.
 	return -1
 }
 
 // FilterAndScoreTorrents applies all filtering and scoring logic and returns a sorted list of results.
-func (ts *TorrentSelector) FilterAndScoreTorrents(media *models.Media, results []indexers.IndexerResult, season, episode int, searchTerms []string) []indexers.IndexerResult {
+func (ts *TorrentSelector) FilterAndScoreTorrents(media *models.Media, results []indexers.IndexerResult, season, episode int, searchTerms []string, facts SelectionFacts) []indexers.IndexerResult {
+	filtered, _ := ts.FilterAndScoreTorrentsWithRejects(media, results, season, episode, searchTerms, facts)
+	return filtered
+}
+
+// FilterAndScoreTorrentsWithRejects is FilterAndScoreTorrents, but also returns the
+// dropped results with the reason each one was rejected.
+func (ts *TorrentSelector) FilterAndScoreTorrentsWithRejects(media *models.Media, results []indexers.IndexerResult, season, episode int, searchTerms []string, facts SelectionFacts) ([]indexers.IndexerResult, []RejectedResult) {
 	stats := &FilterStats{InitialCount: len(results)}
 
+	// Date-based shows name their releases after the day the episode aired
+	airDate := facts.AirDate
+
 	// Create a query string for logging purposes
 	query := media.Title
 	if media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime {
-		if season > 0 && episode > 0 {
+		if !airDate.IsZero() {
+			query = fmt.Sprintf("%s %s", media.Title, airDate.Format("2006-01-02"))
+		} else if season > 0 && episode > 0 {
 			query = fmt.Sprintf("%s S%02dE%02d", media.Title, season, episode)
 		}
 	} else if media.Type == models.MediaTypeMovie {
 		query = fmt.Sprintf("%s (%d)", media.Title, media.Year)
 	}
 
-	if ts.filterLogger != nil {
-		ts.filterLogger.Printf("--- Filtering for: %s ---", query)
-	}
+	ts.filterLog("--- Filtering for: %s ---", query)
 
 	// Step 1: Filter out torrents matching reject patterns
 	results = ts.filterByRejectPatterns(results, stats)
+	results = ts.filterBlacklisted(results, facts.Blacklisted, stats)
 
 	// Step 2: For TV shows, filter by episode number and series name
 	if (media.Type == models.MediaTypeTVShow || media.Type == models.MediaTypeAnime) && season > 0 && episode > 0 {
-		results = ts.filterByEpisodeNumber(results, season, episode, stats)
+		results = ts.filterByEpisodeNumber(results, season, episode, airDate, stats)
 		results = ts.filterBySeriesName(results, searchTerms, stats)
 	}
 
-	// Step 3: Filter by quality (resolution)
-	results = ts.filterByQuality(results, media.MinQuality, media.MaxQuality, stats)
+	// Step 2b: For movies, require the release year (or an explicit id) so a remake or
+	// same-named film from another year isn't picked
+	if media.Type == models.MediaTypeMovie {
+		results = ts.filterByMovieYear(results, media, stats)
+	}
+
+	// Step 3: Filter by quality (resolution), using the media's profile when it has one
+	profile := ts.qualityProfile(media)
+	minQuality, maxQuality := media.MinQuality, media.MaxQuality
+	if profile != nil {
+		minQuality, maxQuality = profile.MinQuality, profile.MaxQuality
+	}
+	results = ts.filterByQuality(results, minQuality, maxQuality, stats)
 
-	// Step 4: Filter by minimum seeders
+	// Step 4: Filter by minimum seeders, and by the whole swarm so a torrent that
+	// momentarily reports a seeder but has nobody else on it is skipped
 	results = ts.filterByMinSeeders(results, stats)
+	results = ts.filterByMinPeers(results, stats)
 
-	// Step 5: Calculate scores and sort the results
+	// Step 4b: Leave releases that are too fresh for a later search, so a proper or
+	// a better encode has a chance to show up
+	results = ts.filterByReleaseAge(results, ts.currentConfig().MinReleaseAge(string(media.Type)), stats)
+
+	// Step 5: Calculate scores and sort the results, breaking ties by indexer priority
+	preferredOrigins := ts.currentConfig().Automation.PreferredOrigins
 	for i := range results {
-		results[i].Score = getQualityScore(results[i].Title) + results[i].Seeders
+		results[i].Score = getQualityScore(results[i].Title) + results[i].Seeders + profileScore(ts.matchTexts(results[i]), profile) +
+			results[i].Priority*indexerPriorityWeight + originScore(results[i].Title, preferredOrigins)
 	}
+	preferPropers(results)
 
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Priority > results[j].Priority
 	})
 
 	// Log passed torrents
@@ -131,7 +341,7 @@ func (ts *TorrentSelector) FilterAndScoreTorrents(media *models.Media, results [
 	stats.FinalCount = len(results)
 	ts.logFilterStats(query, stats)
 
-	return results
+	return results, stats.Rejected
 }
 
 // logFilterStats formats and logs the final filtering statistics.
@@ -142,18 +352,30 @@ func (ts *TorrentSelector) logFilterStats(query string, stats *FilterStats) {
 	if stats.RejectPatterns > 0 {
 		droppedReasons = append(droppedReasons, fmt.Sprintf("%d rejectFilter", stats.RejectPatterns))
 	}
+	if stats.Blacklisted > 0 {
+		droppedReasons = append(droppedReasons, fmt.Sprintf("%d blacklistFilter", stats.Blacklisted))
+	}
 	if stats.EpisodeNumber > 0 {
 		droppedReasons = append(droppedReasons, fmt.Sprintf("%d numberFilter", stats.EpisodeNumber))
 	}
 	if stats.SeriesName > 0 {
 		droppedReasons = append(droppedReasons, fmt.Sprintf("%d nameFilter", stats.SeriesName))
 	}
+	if stats.MovieYear > 0 {
+		droppedReasons = append(droppedReasons, fmt.Sprintf("%d yearFilter", stats.MovieYear))
+	}
 	if stats.Quality > 0 {
 		droppedReasons = append(droppedReasons, fmt.Sprintf("%d qualityFilter", stats.Quality))
 	}
 	if stats.MinSeeders > 0 {
 		droppedReasons = append(droppedReasons, fmt.Sprintf("%d seederFilter", stats.MinSeeders))
 	}
+	if stats.MinPeers > 0 {
+		droppedReasons = append(droppedReasons, fmt.Sprintf("%d peerFilter", stats.MinPeers))
+	}
+	if stats.ReleaseAge > 0 {
+		droppedReasons = append(droppedReasons, fmt.Sprintf("%d ageFilter", stats.ReleaseAge))
+	}
 
 	if stats.InitialCount > 0 {
 		logMessage := fmt.Sprintf("Filtering %d result(s) for '%s': %d drop (%s), %d pass",
@@ -168,8 +390,8 @@ func (ts *TorrentSelector) logFilterStats(query string, stats *FilterStats) {
 }
 
 // SelectBestTorrent filters and selects the best torrent based on various criteria
-func (ts *TorrentSelector) SelectBestTorrent(media *models.Media, results []indexers.IndexerResult, season, episode int, searchTerms []string) *indexers.IndexerResult {
-	filteredAndScored := ts.FilterAndScoreTorrents(media, results, season, episode, searchTerms)
+func (ts *TorrentSelector) SelectBestTorrent(media *models.Media, results []indexers.IndexerResult, season, episode int, searchTerms []string, facts SelectionFacts) *indexers.IndexerResult {
+	filteredAndScored := ts.FilterAndScoreTorrents(media, results, season, episode, searchTerms, facts)
 
 	if len(filteredAndScored) == 0 {
 		return nil
@@ -181,36 +403,65 @@ func (ts *TorrentSelector) SelectBestTorrent(media *models.Media, results []inde
 	return &bestTorrent
 }
 
-// filterByRejectPatterns removes torrents that match any of the reject regex patterns
+// filterBlacklisted drops the releases blacklisted for the media item, e.g. a grab that
+// turned out to be the wrong cut.
+func (ts *TorrentSelector) filterBlacklisted(results []indexers.IndexerResult, blacklisted map[string]bool, stats *FilterStats) []indexers.IndexerResult {
+	if len(blacklisted) == 0 {
+		return results
+	}
+
+	var filtered []indexers.IndexerResult
+	for _, r := range results {
+		if blacklisted[strings.ToLower(r.Title)] {
+			stats.Blacklisted++
+			ts.logReject("Blacklisted release", r, stats)
+		} else {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// filterByRejectPatterns removes torrents whose title, release group or (optionally)
+// description matches any of the reject regex patterns.
 func (ts *TorrentSelector) filterByRejectPatterns(results []indexers.IndexerResult, stats *FilterStats) []indexers.IndexerResult {
+	var patterns []*regexp.Regexp
+	for _, rejectPattern := range ts.currentConfig().Automation.RejectCommon {
+		regex, err := regexp.Compile("(?i)" + rejectPattern)
+		if err != nil {
+			ts.logger.Error("Invalid regex pattern:", rejectPattern, "Error:", err)
+			continue
+		}
+		patterns = append(patterns, regex)
+	}
+
 	var filtered []indexers.IndexerResult
 	for _, r := range results {
 		rejected := false
 		var matchedPattern string
-		for _, rejectPattern := range ts.config.Automation.RejectCommon {
-			regex, err := regexp.Compile("(?i)" + rejectPattern)
-			if err != nil {
-				ts.logger.Error("Invalid regex pattern:", rejectPattern, "Error:", err)
-				continue
-			}
-			if regex.MatchString(r.Title) {
-				rejected = true
-				matchedPattern = rejectPattern
-				break
+	match:
+		for _, regex := range patterns {
+			for _, text := range ts.matchTexts(r) {
+				if regex.MatchString(text) {
+					rejected = true
+					matchedPattern = strings.TrimPrefix(regex.String(), "(?i)")
+					break match
+				}
 			}
 		}
 		if !rejected {
 			filtered = append(filtered, r)
 		} else {
 			stats.RejectPatterns++
-			ts.logReject(fmt.Sprintf("Matches reject pattern '%s'", matchedPattern), r)
+			ts.logReject(fmt.Sprintf("Matches reject pattern '%s'", matchedPattern), r, stats)
 		}
 	}
 	return filtered
 }
 
-// filterByEpisodeNumber filters torrents to only include those with the correct episode number
-func (ts *TorrentSelector) filterByEpisodeNumber(results []indexers.IndexerResult, season, episode int, stats *FilterStats) []indexers.IndexerResult {
+// filterByEpisodeNumber filters torrents to only include those with the correct episode number.
+// For date-based shows airDate is the episode's air date, and dated releases must match it.
+func (ts *TorrentSelector) filterByEpisodeNumber(results []indexers.IndexerResult, season, episode int, airDate time.Time, stats *FilterStats) []indexers.IndexerResult {
 	var filtered []indexers.IndexerResult
 
 	// --- Standard SxxExx patterns ---
@@ -234,6 +485,19 @@ func (ts *TorrentSelector) filterByEpisodeNumber(results []indexers.IndexerResul
 	}
 
 	for _, r := range results {
+		// 0. A dated release ("Show 2024.01.15") of a date-based show is the episode aired that day
+		if !airDate.IsZero() {
+			if titleDate, ok := airDateFromTitle(r.Title); ok {
+				if titleDate.Equal(airDate) {
+					filtered = append(filtered, r)
+				} else {
+					stats.EpisodeNumber++
+					ts.logReject("Air date mismatch", r, stats)
+				}
+				continue
+			}
+		}
+
 		matched := false
 
 		// 1. Try standard patterns first
@@ -244,7 +508,14 @@ func (ts *TorrentSelector) filterByEpisodeNumber(results []indexers.IndexerResul
 			}
 		}
 
-		// 2. If no standard match, and it's season 1, try absolute number patterns
+		// 2. Multi-episode releases ("S01E01-E03") hold every episode in their range
+		if !matched {
+			if rangeSeason, first, last, ok := episodeRange(r.Title); ok && rangeSeason == season && episode >= first && episode <= last {
+				matched = true
+			}
+		}
+
+		// 3. If no standard match, and it's season 1, try absolute number patterns
 		if !matched && season == 1 {
 			for _, pattern := range absolutePatterns {
 				if pattern.MatchString(r.Title) {
@@ -257,16 +528,170 @@ func (ts *TorrentSelector) filterByEpisodeNumber(results []indexers.IndexerResul
 			}
 		}
 
+		// 4. Season packs from a "season" mode search hold the episode too
+		if !matched && r.SeasonPack && isSeasonPack(r.Title, season) {
+			matched = true
+		}
+
+		// 5. So do releases named after the episode's title, see episodeTitleResults
+		if !matched && r.EpisodeTitleMatch {
+			matched = true
+		}
+
 		if matched {
 			filtered = append(filtered, r)
 		} else {
 			stats.EpisodeNumber++
-			ts.logReject("Episode mismatch", r)
+			ts.logReject("Episode mismatch", r, stats)
+		}
+	}
+	return filtered
+}
+
+// episodeTagRegex matches an "S01E02" or "1x02" episode tag, capturing the episode number.
+var episodeTagRegex = regexp.MustCompile(`(?i)s\d{1,2}e(\d{1,3})|(?:^|\D)\d{1,2}x(\d{2,3})(?:\D|$)`)
+
+// episodeRangeRegex matches a multi-episode tag such as "S01E01-E03", "S01E01-03" or
+// "S01E01E02E03", capturing the season, the first episode and the rest of the tag.
+var episodeRangeRegex = regexp.MustCompile(`(?i)s(\d{1,2})e(\d{1,3})((?:[-_. ]?e\d{1,3})+|-\d{1,3})(?:[^a-z0-9]|$)`)
+
+var digitsRegex = regexp.MustCompile(`\d+`)
+
+// episodeRange returns the season and the first and last episodes of a release or file
+// name covering several episodes, e.g. "Show S01E01-E03" or "Show S01E01E02E03".
+func episodeRange(title string) (season, first, last int, ok bool) {
+	match := episodeRangeRegex.FindStringSubmatch(title)
+	if match == nil {
+		return 0, 0, 0, false
+	}
+	season, _ = strconv.Atoi(match[1])
+	first, _ = strconv.Atoi(match[2])
+	last = first
+	for _, number := range digitsRegex.FindAllString(match[3], -1) {
+		if n, _ := strconv.Atoi(number); n > last {
+			last = n
+		}
+	}
+	return season, first, last, last > first
+}
+
+// airDateRegex matches an air date in a release title, year first: "2024.01.15",
+// "2024-01-15", "2024 01 15" or "2024_01_15".
+var airDateRegex = regexp.MustCompile(`(?:^|\D)((?:19|20)\d{2})[ ._-](\d{2})[ ._-](\d{2})(?:\D|$)`)
+
+// airDateFromTitle returns the air date named in a release title, as used by daily shows.
+func airDateFromTitle(title string) (time.Time, bool) {
+	match := airDateRegex.FindStringSubmatch(title)
+	if match == nil {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006-01-02", match[1]+"-"+match[2]+"-"+match[3])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// episodeTitleQueryWords caps the words of an episode title searched for; the first
+// words are enough to find a release, and long queries tend to match nothing.
+const episodeTitleQueryWords = 6
+
+var (
+	episodeTitleSeparators = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	// placeholderEpisodeTitle matches the titles providers give episodes they know
+	// nothing about, such as "Episode 5" or "TBA"
+	placeholderEpisodeTitle = regexp.MustCompile(`(?i)^(?:(?:episode|chapter|ep)\s*\d*|\d+|tba|tbd)$`)
+)
+
+// episodeTitleQuery returns an episode title as a search query: punctuation becomes
+// spaces and only the first words are kept. Titles too short or generic to search for
+// give "".
+func episodeTitleQuery(title string) string {
+	title = strings.NewReplacer("'", "", "’", "").Replace(title)
+	words := strings.Fields(episodeTitleSeparators.ReplaceAllString(title, " "))
+	if len(words) > episodeTitleQueryWords {
+		words = words[:episodeTitleQueryWords]
+	}
+	query := strings.Join(words, " ")
+	if len([]rune(query)) < 4 || placeholderEpisodeTitle.MatchString(query) {
+		return ""
+	}
+	return query
+}
+
+// episodeTitleResults flags the results of an episode title search that name the
+// episode's title, so the episode filter takes them for the episode. Results with an
+// episode number are left to the usual filter.
+func episodeTitleResults(results []indexers.IndexerResult, titleQuery string) []indexers.IndexerResult {
+	for i := range results {
+		if !episodeTagRegex.MatchString(results[i].Title) && utils.TitleMatches(results[i].Title, []string{titleQuery}) {
+			results[i].EpisodeTitleMatch = true
+		}
+	}
+	return results
+}
+
+// isSeasonPack reports whether a release title names the whole season ("Show S01",
+// "Show Season 1 Complete") rather than a single episode of it.
+func isSeasonPack(title string, season int) bool {
+	if episodeTagRegex.MatchString(title) {
+		return false
+	}
+	seasonPattern := regexp.MustCompile(fmt.Sprintf(`(?i)(?:^|[^a-z0-9])(?:s0*%d|season[ ._-]*0*%d)(?:\D|$)`, season, season))
+	return seasonPattern.MatchString(title)
+}
+
+var (
+	yearRegex   = regexp.MustCompile(`(?:^|\D)((?:19|20)\d{2})(?:\D|$)`)
+	tmdbIDRegex = regexp.MustCompile(`(?i)tmdb(?:id)?[-_=: ]?(\d+)`)
+)
+
+// filterByMovieYear keeps torrents whose title carries the movie's year (within one year,
+// to tolerate regional release dates) or its IMDb/TMDB id.
+func (ts *TorrentSelector) filterByMovieYear(results []indexers.IndexerResult, media *models.Media, stats *FilterStats) []indexers.IndexerResult {
+	if media.Year == 0 {
+		return results
+	}
+
+	var filtered []indexers.IndexerResult
+	for _, r := range results {
+		if movieTitleMatchesYearOrID(r.Title, media) {
+			filtered = append(filtered, r)
+		} else {
+			stats.MovieYear++
+			ts.logReject(fmt.Sprintf("Year %d (±1) or movie id not found in title", media.Year), r, stats)
 		}
 	}
 	return filtered
 }
 
+func movieTitleMatchesYearOrID(title string, media *models.Media) bool {
+	if media.IMDBId != "" && strings.Contains(strings.ToLower(title), strings.ToLower(media.IMDBId)) {
+		return true
+	}
+	if media.TMDBId != nil {
+		for _, match := range tmdbIDRegex.FindAllStringSubmatch(title, -1) {
+			if id, err := strconv.Atoi(match[1]); err == nil && id == *media.TMDBId {
+				return true
+			}
+		}
+	}
+
+	// Scan from the end of each year so a shared separator ("1984.2021") is not consumed
+	for i := 0; i < len(title); {
+		loc := yearRegex.FindStringSubmatchIndex(title[i:])
+		if loc == nil {
+			break
+		}
+		year, _ := strconv.Atoi(title[i+loc[2] : i+loc[3]])
+		if year >= media.Year-1 && year <= media.Year+1 {
+			return true
+		}
+		i += loc[3]
+	}
+	return false
+}
+
 // filterByQuality filters torrents by resolution quality
 func (ts *TorrentSelector) filterByQuality(results []indexers.IndexerResult, minQuality, maxQuality string, stats *FilterStats) []indexers.IndexerResult {
 	minRank := RESOLUTION_RANK[minQuality]
@@ -279,7 +704,7 @@ func (ts *TorrentSelector) filterByQuality(results []indexers.IndexerResult, min
 			filtered = append(filtered, r)
 		} else {
 			stats.Quality++
-			ts.logReject(fmt.Sprintf("Quality rank %d is outside range [%d, %d]", rank, minRank, maxRank), r)
+			ts.logReject(fmt.Sprintf("Quality rank %d is outside range [%d, %d]", rank, minRank, maxRank), r, stats)
 		}
 	}
 	return filtered
@@ -287,142 +712,78 @@ func (ts *TorrentSelector) filterByQuality(results []indexers.IndexerResult, min
 
 // filterByMinSeeders filters torrents by minimum number of seeders
 func (ts *TorrentSelector) filterByMinSeeders(results []indexers.IndexerResult, stats *FilterStats) []indexers.IndexerResult {
+	minSeeders := ts.currentConfig().Automation.MinSeeders
 	var filtered []indexers.IndexerResult
 	for _, r := range results {
-		if r.Seeders >= ts.config.Automation.MinSeeders {
+		if r.Seeders >= minSeeders {
 			filtered = append(filtered, r)
 		} else {
 			stats.MinSeeders++
-			ts.logReject(fmt.Sprintf("Not enough seeders (%d < %d)", r.Seeders, ts.config.Automation.MinSeeders), r)
+			ts.logReject(fmt.Sprintf("Not enough seeders (%d < %d)", r.Seeders, minSeeders), r, stats)
 		}
 	}
 	return filtered
 }
 
-// This function splits camelCase words
-func (ts *TorrentSelector) splitCamelCase(word string) []string {
-	// Regular expression to find camelCase boundaries
-	camelCaseRegex := regexp.MustCompile(`([a-z])([A-Z])`)
-
-	// Insert spaces before uppercase letters that follow lowercase letters
-	spaced := camelCaseRegex.ReplaceAllString(word, "$1 $2")
-
-	// Split by spaces and return non-empty parts
-	parts := strings.Fields(spaced)
-
-	// If we got multiple parts, return them. Otherwise return the original word
-	if len(parts) > 1 {
-		return parts
+// filterByMinPeers removes torrents whose seeders plus leechers are below min_peers
+func (ts *TorrentSelector) filterByMinPeers(results []indexers.IndexerResult, stats *FilterStats) []indexers.IndexerResult {
+	minPeers := ts.currentConfig().Automation.MinPeers
+	if minPeers <= 0 {
+		return results
 	}
-	return []string{word}
-}
 
-// Enhanced extractMeaningfulWords with punctuation removal and camelCase support
-func (ts *TorrentSelector) extractMeaningfulWords(title string) []string {
-	stopWords := map[string]bool{
-		"the": true, "a": true, "an": true, "and": true, "or": true, "but": true,
-		"in": true, "on": true, "at": true, "to": true, "for": true, "of": true,
-		"with": true, "by": true, "from": true, "up": true, "about": true, "into": true,
+	var filtered []indexers.IndexerResult
+	for _, r := range results {
+		if r.Seeders+r.Leechers >= minPeers {
+			filtered = append(filtered, r)
+		} else {
+			stats.MinPeers++
+			ts.logReject(fmt.Sprintf("Not enough peers (%d seeders + %d leechers < %d)", r.Seeders, r.Leechers, minPeers), r, stats)
+		}
 	}
+	return filtered
+}
 
-	// Step 1: Remove dots, commas, semicolons and other punctuation (but keep spaces and alphanumeric)
-	// This converts "Dr. Stone" -> "Dr Stone" and "Steins;Gate" -> "SteinsGate"
-	cleanTitle := regexp.MustCompile(`[^\w\s]`).ReplaceAllString(title, "")
-
-	// Step 2: Split by spaces to get individual words
-	words := regexp.MustCompile(`\s+`).Split(strings.TrimSpace(cleanTitle), -1)
-	var meaningfulWords []string
-
-	for _, word := range words {
-		word = strings.TrimSpace(word)
-		if len(word) > 1 {
-			// Step 3: Check if this word contains camelCase and split if needed
-			camelParts := ts.splitCamelCase(word)
-
-			// Add the original word if it's not a stop word
-			if !stopWords[strings.ToLower(word)] {
-				meaningfulWords = append(meaningfulWords, word)
-			}
-
-			// If camelCase was split, also add the individual parts
-			if len(camelParts) > 1 {
-				for _, part := range camelParts {
-					part = strings.TrimSpace(part)
-					if len(part) > 1 && !stopWords[strings.ToLower(part)] {
-						meaningfulWords = append(meaningfulWords, part)
-					}
-				}
-			}
-		}
+// filterByReleaseAge drops torrents published less than minAge ago. Results without a
+// publish date are kept.
+func (ts *TorrentSelector) filterByReleaseAge(results []indexers.IndexerResult, minAge time.Duration, stats *FilterStats) []indexers.IndexerResult {
+	if minAge <= 0 {
+		return results
 	}
 
-	// Remove duplicates
-	seen := make(map[string]bool)
-	var unique []string
-	for _, word := range meaningfulWords {
-		lowerWord := strings.ToLower(word)
-		if !seen[lowerWord] {
-			seen[lowerWord] = true
-			unique = append(unique, word)
+	var filtered []indexers.IndexerResult
+	for _, r := range results {
+		age := time.Since(r.PublishDate)
+		if r.PublishDate.IsZero() || age >= minAge {
+			filtered = append(filtered, r)
+		} else {
+			stats.ReleaseAge++
+			ts.logReject(fmt.Sprintf("Too recent (published %s ago, waiting %s)", age.Round(time.Minute), minAge), r, stats)
 		}
 	}
-
-	return unique
+	return filtered
 }
 
-// Enhanced filterBySeriesName with flexible matching
+// filterBySeriesName keeps torrents whose title matches one of the search terms
 func (ts *TorrentSelector) filterBySeriesName(results []indexers.IndexerResult, searchTerms []string, stats *FilterStats) []indexers.IndexerResult {
-	var filtered []indexers.IndexerResult
-	var allMeaningfulWords []string
+	hasTerm := false
 	for _, term := range searchTerms {
-		allMeaningfulWords = append(allMeaningfulWords, ts.extractMeaningfulWords(term)...)
+		if utils.NormalizeTitle(term) != "" {
+			hasTerm = true
+			break
+		}
 	}
-
-	if len(allMeaningfulWords) == 0 {
+	if !hasTerm {
 		return results
 	}
 
+	var filtered []indexers.IndexerResult
 	for _, r := range results {
-		titleLower := strings.ToLower(r.Title)
-		matchFound := false
-
-		for _, term := range searchTerms {
-			// Strategy 1: All words must be found individually
-			meaningfulWords := ts.extractMeaningfulWords(term)
-			allWordsFound := true
-			for _, word := range meaningfulWords {
-				if !strings.Contains(titleLower, strings.ToLower(word)) {
-					allWordsFound = false
-					break
-				}
-			}
-			if allWordsFound {
-				matchFound = true
-				break
-			}
-
-			// Strategy 2: Try original series title as-is (for exact matches)
-			if strings.Contains(titleLower, strings.ToLower(term)) {
-				matchFound = true
-				break
-			}
-
-			// Strategy 3: Try camelCase variations
-			camelParts := ts.splitCamelCase(term)
-			if len(camelParts) > 1 {
-				spacedVersion := strings.ToLower(strings.Join(camelParts, " "))
-				if strings.Contains(titleLower, spacedVersion) {
-					matchFound = true
-					break
-				}
-			}
-		}
-
-		if matchFound {
+		if utils.TitleMatches(r.Title, searchTerms) {
 			filtered = append(filtered, r)
 		} else {
 			stats.SeriesName++
-			ts.logReject(fmt.Sprintf("Series name not found in title using terms: %s", strings.Join(searchTerms, ", ")), r)
+			ts.logReject(fmt.Sprintf("Series name not found in title using terms: %s", strings.Join(searchTerms, ", ")), r, stats)
 		}
 	}
 	return filtered
@@ -0,0 +1,582 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"reel/internal/clients/indexers"
+	"reel/internal/config"
+	"reel/internal/database/models"
+	"reel/internal/utils"
+)
+
+func newTestSelector() *TorrentSelector {
+	return NewTorrentSelector(&config.Config{}, utils.NewLogger(false, io.Discard))
+}
+
+func resultTitles(results []indexers.IndexerResult) []string {
+	var titles []string
+	for _, r := range results {
+		titles = append(titles, r.Title)
+	}
+	return titles
+}
+
+// The selector works on the facts it is given, without a database behind it.
+func TestFilterAndScoreTorrentsFacts(t *testing.T) {
+	movie := &models.Media{ID: 1, Type: models.MediaTypeMovie, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+	show := &models.Media{ID: 2, Type: models.MediaTypeTVShow, Title: "The Daily Show", MinQuality: "720p", MaxQuality: "2160p", DateBased: true}
+
+	tests := []struct {
+		name     string
+		media    *models.Media
+		season   int
+		episode  int
+		results  []string
+		facts    SelectionFacts
+		expected []string
+	}{
+		{
+			name:     "no facts keeps everything",
+			media:    movie,
+			results:  []string{"Heat.1995.1080p.BluRay-GRP", "Heat.1995.1080p.WEB-DL-OTHER"},
+			expected: []string{"Heat.1995.1080p.BluRay-GRP", "Heat.1995.1080p.WEB-DL-OTHER"},
+		},
+		{
+			name:     "blacklisted release is dropped whatever its case",
+			media:    movie,
+			results:  []string{"Heat.1995.1080p.BluRay-GRP", "Heat.1995.1080p.WEB-DL-OTHER"},
+			facts:    SelectionFacts{Blacklisted: map[string]bool{"heat.1995.1080p.bluray-grp": true}},
+			expected: []string{"Heat.1995.1080p.WEB-DL-OTHER"},
+		},
+		{
+			name:     "date-based episode matches its air date",
+			media:    show,
+			season:   2024,
+			episode:  10,
+			results:  []string{"The.Daily.Show.2024.03.14.1080p.WEB", "The.Daily.Show.2024.03.15.1080p.WEB"},
+			facts:    SelectionFacts{AirDate: time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+			expected: []string{"The.Daily.Show.2024.03.14.1080p.WEB"},
+		},
+		{
+			name:     "date-based episode without an air date falls back to SxxExx",
+			media:    show,
+			season:   2024,
+			episode:  10,
+			results:  []string{"The.Daily.Show.2024.03.14.1080p.WEB", "The.Daily.Show.S2024E10.1080p.WEB"},
+			expected: []string{"The.Daily.Show.S2024E10.1080p.WEB"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []indexers.IndexerResult
+			for _, title := range tt.results {
+				results = append(results, indexers.IndexerResult{Title: title, Seeders: 10})
+			}
+			filtered, _ := newTestSelector().FilterAndScoreTorrentsWithRejects(tt.media, results, tt.season, tt.episode, []string{tt.media.Title}, tt.facts)
+			got := resultTitles(filtered)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectionFacts(t *testing.T) {
+	m := newTestManager(t, &config.Config{}, newFakeTorrentClient())
+	media := createShow(t, m.mediaRepo, "The Daily Show", 2024, "2024-03-14")
+	media.DateBased = true
+
+	if err := m.mediaRepo.AddReleaseHistory(&models.ReleaseHistoryEntry{MediaID: media.ID, SeasonNumber: 2024, EpisodeNumber: 1, Event: models.ReleaseBlacklisted, ReleaseTitle: "The.Daily.Show.2024.03.14.720p-BAD"}); err != nil {
+		t.Fatal(err)
+	}
+
+	facts := m.selectionFacts(media, 2024, 1)
+	if !facts.Blacklisted["the.daily.show.2024.03.14.720p-bad"] {
+		t.Errorf("expected the blacklisted release, got %v", facts.Blacklisted)
+	}
+	if want := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC); !facts.AirDate.Equal(want) {
+		t.Errorf("expected air date %v, got %v", want, facts.AirDate)
+	}
+
+	// Shows named by episode number don't need the air date
+	media.DateBased = false
+	if facts := m.selectionFacts(media, 2024, 1); !facts.AirDate.IsZero() {
+		t.Errorf("expected no air date, got %v", facts.AirDate)
+	}
+}
+
+func TestGetQualityScore(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected int
+	}{
+		{"Movie.2021.2160p.UHD.BluRay.REMUX.HEVC.DTS-HD.MA.5.1-GRP", 8 + 10 + 3 + 3},
+		{"Movie.2021.1080p.WEB-DL.DDP5.1.H.264-GRP", 5 + 7 + 1 + 2},
+		{"Movie.2021.1080p.BluRay.x264.DTS-GRP", 5 + 8 + 2 + 2},
+		{"Movie.2021.PROPER.1080p.WEBRip.x265-GRP", 5 + 5 + 3 + PROPER_SCORE},
+		{"Movie.2021.IMAX.2160p.WEB.DV.HDR10.Atmos-GRP", 2 + 8 + 6 + 3 + 2 + 3},
+		{"Show.S01E01.HDTV.XviD-GRP", 4},
+		// "HD" inside "HDTV" or "HDR" is no 720p tag
+		{"Show.S01E01.HDR.WEB-GRP", 2 + 6},
+		{"Movie.2021", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := getQualityScore(tt.title); got != tt.expected {
+				t.Errorf("getQualityScore(%q) = %d, want %d", tt.title, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProfileScore(t *testing.T) {
+	profile := &config.QualityProfile{PreferredOrder: []string{"4k", "1080p", "720p"}, PreferredWords: []string{"remux"}}
+
+	tests := []struct {
+		title    string
+		expected int
+	}{
+		{"Movie.2021.2160p.BluRay.REMUX-GRP", 10 + 30},
+		{"Movie.2021.UHD.WEB-GRP", 30},
+		{"Movie.2021.1080p.WEB-GRP", 20},
+		// The resolution HDTV implies
+		{"Show.S01E01.HDTV-GRP", 10},
+		{"Movie.2021.480p.DVDRip-GRP", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := profileScore([]string{tt.title}, profile); got != tt.expected {
+				t.Errorf("profileScore(%q) = %d, want %d", tt.title, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterByMovieYear(t *testing.T) {
+	tmdbID := 841
+	results := []string{
+		"Dune.1984.1080p.BluRay.x264-GRP",
+		"Dune.2021.1080p.BluRay.x264-GRP",
+		"Dune.1985.720p.BluRay.x264-EU",
+		"Dune.Part.Two.2024.1080p.WEB-DL-GRP",
+		"Dune.1080p.BluRay.tt0087182-GRP",
+		"Dune.1080p.BluRay.tmdb-841-GRP",
+		"Dune.1080p.BluRay-NOYEAR",
+	}
+
+	tests := []struct {
+		name     string
+		media    *models.Media
+		expected []string
+	}{
+		{
+			name:  "1984 within a year, or by id",
+			media: &models.Media{Title: "Dune", Year: 1984, IMDBId: "tt0087182", TMDBId: &tmdbID},
+			expected: []string{"Dune.1984.1080p.BluRay.x264-GRP", "Dune.1985.720p.BluRay.x264-EU",
+				"Dune.1080p.BluRay.tt0087182-GRP", "Dune.1080p.BluRay.tmdb-841-GRP"},
+		},
+		{
+			name:     "2021 drops the 1984 film",
+			media:    &models.Media{Title: "Dune", Year: 2021},
+			expected: []string{"Dune.2021.1080p.BluRay.x264-GRP"},
+		},
+		{
+			name:     "unknown year keeps everything",
+			media:    &models.Media{Title: "Dune"},
+			expected: results,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.media.Type = models.MediaTypeMovie
+			tt.media.MinQuality, tt.media.MaxQuality = "720p", "2160p"
+			var indexerResults []indexers.IndexerResult
+			for _, title := range results {
+				indexerResults = append(indexerResults, indexers.IndexerResult{Title: title})
+			}
+			stats := &FilterStats{}
+			got := resultTitles(newTestSelector().filterByMovieYear(indexerResults, tt.media, stats))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("kept %v, want %v", got, tt.expected)
+			}
+			if dropped := len(results) - len(tt.expected); stats.MovieYear != dropped || len(stats.Rejected) != dropped {
+				t.Errorf("stats count %d drops and %d rejects, want %d", stats.MovieYear, len(stats.Rejected), dropped)
+			}
+		})
+	}
+
+	// The whole selection picks the right film, even with fewer seeders
+	media := &models.Media{Type: models.MediaTypeMovie, Title: "Dune", Year: 1984, MinQuality: "720p", MaxQuality: "2160p"}
+	best := newTestSelector().SelectBestTorrent(media, []indexers.IndexerResult{
+		{Title: "Dune.2021.1080p.BluRay.x264-GRP", Seeders: 500},
+		{Title: "Dune.1984.1080p.BluRay.x264-GRP", Seeders: 20},
+	}, 0, 0, []string{"Dune"}, SelectionFacts{})
+	if best == nil || best.Title != "Dune.1984.1080p.BluRay.x264-GRP" {
+		t.Errorf("SelectBestTorrent() = %v, want the 1984 release", best)
+	}
+}
+
+func TestMinReleaseAge(t *testing.T) {
+	none, threeHours := 0, 180
+	cfg := &config.Config{}
+	cfg.Automation.MinReleaseAgeMinutes = 60
+	cfg.TVShows.MinReleaseAge = &none
+	cfg.Anime.MinReleaseAge = &threeHours
+	selector := NewTorrentSelector(cfg, utils.NewLogger(false, io.Discard))
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		mediaType models.MediaType
+		published time.Time
+		deferred  bool
+	}{
+		{"fresh movie release", models.MediaTypeMovie, now.Add(-10 * time.Minute), true},
+		{"movie release past the wait", models.MediaTypeMovie, now.Add(-2 * time.Hour), false},
+		{"movie release without a date", models.MediaTypeMovie, time.Time{}, false},
+		{"show section without a wait", models.MediaTypeTVShow, now.Add(-time.Minute), false},
+		{"anime section waits longer", models.MediaTypeAnime, now.Add(-2 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media := &models.Media{Type: tt.mediaType, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+			results := []indexers.IndexerResult{{Title: "Heat.1995.1080p.BluRay-GRP", Seeders: 10, PublishDate: tt.published}}
+			filtered, rejected := selector.FilterAndScoreTorrentsWithRejects(media, results, 0, 0, []string{"Heat"}, SelectionFacts{})
+			if deferred := len(filtered) == 0; deferred != tt.deferred {
+				t.Fatalf("deferred = %v, want %v (rejects %v)", deferred, tt.deferred, rejected)
+			}
+			if tt.deferred && (len(rejected) != 1 || !strings.HasPrefix(rejected[0].RejectReason, "Too recent")) {
+				t.Errorf("rejects = %v, want the release held back as too recent", rejected)
+			}
+		})
+	}
+}
+
+func TestIndexerPriority(t *testing.T) {
+	media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+	const title = "Heat.1995.1080p.BluRay.x264-GRP"
+
+	tests := []struct {
+		name     string
+		results  []indexers.IndexerResult
+		expected string
+	}{
+		{
+			name: "preferred indexer wins with a few seeders less",
+			results: []indexers.IndexerResult{
+				{Title: title, Indexer: "public", Seeders: 25},
+				{Title: title, Indexer: "trusted", Seeders: 20, Priority: 1},
+			},
+			expected: "trusted",
+		},
+		{
+			name: "many more seeders outweigh one priority level",
+			results: []indexers.IndexerResult{
+				{Title: title, Indexer: "public", Seeders: 40},
+				{Title: title, Indexer: "trusted", Seeders: 20, Priority: 1},
+			},
+			expected: "public",
+		},
+		{
+			name: "a tie goes to the higher priority",
+			results: []indexers.IndexerResult{
+				{Title: title, Indexer: "public", Seeders: 30},
+				{Title: title, Indexer: "trusted", Seeders: 20, Priority: 1},
+			},
+			expected: "trusted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best := newTestSelector().SelectBestTorrent(media, tt.results, 0, 0, []string{"Heat"}, SelectionFacts{})
+			if best == nil || best.Indexer != tt.expected {
+				t.Errorf("SelectBestTorrent() = %+v, want the %s result", best, tt.expected)
+			}
+		})
+	}
+
+	// A search carries each source's priority over to its results
+	cfg := &config.Config{}
+	cfg.Movies.DownloadFolder = t.TempDir()
+	m := newTestManager(t, cfg, newFakeTorrentClient())
+	release := func(seeders int, hash string) []indexers.IndexerResult {
+		return []indexers.IndexerResult{{Title: title, Seeders: seeders, DownloadURL: "magnet:?xt=urn:btih:" + strings.Repeat(hash, 40)}}
+	}
+	m.indexerClients[models.MediaTypeMovie] = []IndexerClientWithMode{
+		{Client: &fakeIndexer{results: release(25, "a")}, Source: config.SourceConfig{URL: "http://public.test", Name: "public", SearchMode: "search"}},
+		{Client: &fakeIndexer{results: release(20, "b")}, Source: config.SourceConfig{URL: "http://trusted.test", Name: "trusted", SearchMode: "search", Priority: 1}},
+	}
+	movie := createMovie(t, m.mediaRepo, "Heat", 1995)
+	if err := m.mediaRepo.UpdateSettings(movie.ID, "720p", "2160p", true); err != nil {
+		t.Fatal(err)
+	}
+	grabs, err := m.DryRunSearch(movie.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grabs) != 1 || grabs[0].Torrent.Indexer != "trusted" {
+		t.Errorf("DryRunSearch() = %+v, want the trusted indexer's release", grabs)
+	}
+}
+
+func TestPreferPropers(t *testing.T) {
+	media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+
+	tests := []struct {
+		name     string
+		results  []indexers.IndexerResult
+		expected string
+	}{
+		{
+			name: "proper wins at equal resolution and seeders",
+			results: []indexers.IndexerResult{
+				{Title: "Heat.1995.1080p.BluRay.x264-GRP", Seeders: 20},
+				{Title: "Heat.1995.PROPER.1080p.BluRay.x264-GRP", Seeders: 20},
+			},
+			expected: "Heat.1995.PROPER.1080p.BluRay.x264-GRP",
+		},
+		{
+			name: "repack wins with fewer seeders",
+			results: []indexers.IndexerResult{
+				{Title: "Heat.1995.1080p.BluRay.x264-GRP", Seeders: 80},
+				{Title: "Heat.1995.1080p.BluRay.x264.REPACK-GRP", Seeders: 5},
+			},
+			expected: "Heat.1995.1080p.BluRay.x264.REPACK-GRP",
+		},
+		{
+			name: "proper of another release gets no bonus",
+			results: []indexers.IndexerResult{
+				{Title: "Heat.1995.1080p.BluRay.x264-GRP", Seeders: 80},
+				{Title: "Heat.1995.PROPER.1080p.WEB-DL.x264-GRP", Seeders: 5},
+			},
+			expected: "Heat.1995.1080p.BluRay.x264-GRP",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best := newTestSelector().SelectBestTorrent(media, tt.results, 0, 0, []string{"Heat"}, SelectionFacts{})
+			if best == nil || best.Title != tt.expected {
+				t.Errorf("SelectBestTorrent() = %+v, want %s", best, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPreferredOrigins(t *testing.T) {
+	media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+	results := []indexers.IndexerResult{
+		{Title: "Heat.1995.1080p.BluRay.x264-SCENEGRP", Seeders: 20},
+		{Title: "Heat.1995.iNTERNAL.1080p.BluRay.x264-GRP", Seeders: 20},
+		{Title: "Heat.1995.1080p.BluRay.x264.P2P-OTHER", Seeders: 20},
+	}
+
+	tests := []struct {
+		name     string
+		origins  []string
+		expected string
+	}{
+		{"internal first", []string{"internal", "p2p"}, "Heat.1995.iNTERNAL.1080p.BluRay.x264-GRP"},
+		{"p2p first", []string{"P2P", "internal"}, "Heat.1995.1080p.BluRay.x264.P2P-OTHER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Automation.PreferredOrigins = tt.origins
+			selector := NewTorrentSelector(cfg, utils.NewLogger(false, io.Discard))
+			best := selector.SelectBestTorrent(media, slices.Clone(results), 0, 0, []string{"Heat"}, SelectionFacts{})
+			if best == nil || best.Title != tt.expected {
+				t.Errorf("SelectBestTorrent() = %+v, want %s", best, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMinPeers(t *testing.T) {
+	media := &models.Media{Type: models.MediaTypeMovie, Title: "Heat", Year: 1995, MinQuality: "720p", MaxQuality: "2160p"}
+	results := []indexers.IndexerResult{
+		{Title: "Heat.1995.1080p.BluRay-LIVELY", Seeders: 3, Leechers: 12},
+		{Title: "Heat.1995.1080p.BluRay-LONELY", Seeders: 1, Leechers: 0},
+		{Title: "Heat.1995.1080p.BluRay-SEEDED", Seeders: 5, Leechers: 0},
+	}
+
+	tests := []struct {
+		name       string
+		minSeeders int
+		minPeers   int
+		expected   []string
+		peerDrops  int
+	}{
+		{"off", 0, 0, []string{"Heat.1995.1080p.BluRay-LIVELY", "Heat.1995.1080p.BluRay-LONELY", "Heat.1995.1080p.BluRay-SEEDED"}, 0},
+		{"seeders and leechers add up", 0, 5, []string{"Heat.1995.1080p.BluRay-LIVELY", "Heat.1995.1080p.BluRay-SEEDED"}, 1},
+		{"a lone seeder is not a swarm", 1, 2, []string{"Heat.1995.1080p.BluRay-LIVELY", "Heat.1995.1080p.BluRay-SEEDED"}, 1},
+		{"with min_seeders", 4, 5, []string{"Heat.1995.1080p.BluRay-SEEDED"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Automation.MinSeeders = tt.minSeeders
+			cfg.Automation.MinPeers = tt.minPeers
+			selector := NewTorrentSelector(cfg, utils.NewLogger(false, io.Discard))
+
+			stats := &FilterStats{}
+			got := resultTitles(selector.filterByMinPeers(selector.filterByMinSeeders(append([]indexers.IndexerResult(nil), results...), stats), stats))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("kept %v, want %v", got, tt.expected)
+			}
+			if stats.MinPeers != tt.peerDrops {
+				t.Errorf("peer filter dropped %d, want %d", stats.MinPeers, tt.peerDrops)
+			}
+
+			filtered := selector.FilterAndScoreTorrents(media, append([]indexers.IndexerResult(nil), results...), 0, 0, []string{"Heat"}, SelectionFacts{})
+			if len(filtered) != len(tt.expected) {
+				t.Errorf("FilterAndScoreTorrents() kept %v, want %v", resultTitles(filtered), tt.expected)
+			}
+		})
+	}
+}
+
+func TestRejectPatternsMatchGroupAndDescription(t *testing.T) {
+	results := []indexers.IndexerResult{
+		{Title: "Heat.1995.1080p.BluRay.x264-YIFY"},
+		{Title: "Heat.1995.1080p.BluRay.x264-YIFYX"},
+		{Title: "Heat.1995.1080p.BluRay.x264-GRP", Description: "Hardcoded Korean subs"},
+		{Title: "Heat.1995.1080p.WEB-DL-CLEAN", Description: "Clean WEB-DL"},
+	}
+
+	tests := []struct {
+		name             string
+		matchDescription bool
+		expected         []string
+	}{
+		{"title and group only", false, []string{"Heat.1995.1080p.BluRay.x264-YIFYX", "Heat.1995.1080p.BluRay.x264-GRP", "Heat.1995.1080p.WEB-DL-CLEAN"}},
+		{"with match_description", true, []string{"Heat.1995.1080p.BluRay.x264-YIFYX", "Heat.1995.1080p.WEB-DL-CLEAN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Automation.RejectCommon = []string{"^YIFY$", "hardcoded"}
+			cfg.Automation.MatchDescription = tt.matchDescription
+			selector := NewTorrentSelector(cfg, utils.NewLogger(false, io.Discard))
+
+			stats := &FilterStats{}
+			got := resultTitles(selector.filterByRejectPatterns(append([]indexers.IndexerResult(nil), results...), stats))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("kept %v, want %v", got, tt.expected)
+			}
+			if stats.RejectPatterns != len(results)-len(tt.expected) {
+				t.Errorf("reject patterns dropped %d, want %d", stats.RejectPatterns, len(results)-len(tt.expected))
+			}
+		})
+	}
+}
+
+func TestPreferredWordsMatchDescription(t *testing.T) {
+	profile := &config.QualityProfile{PreferredWords: []string{"atmos"}}
+	result := indexers.IndexerResult{Title: "Heat.1995.1080p.BluRay.x264-GRP", Description: "Dolby Atmos track"}
+
+	for _, matchDescription := range []bool{false, true} {
+		cfg := &config.Config{}
+		cfg.Automation.MatchDescription = matchDescription
+		selector := NewTorrentSelector(cfg, utils.NewLogger(false, io.Discard))
+		want := 0
+		if matchDescription {
+			want = 10
+		}
+		if got := profileScore(selector.matchTexts(result), profile); got != want {
+			t.Errorf("match_description=%t: profile score %d, want %d", matchDescription, got, want)
+		}
+	}
+}
+
+func TestEpisodeTitleQuery(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Good News About Hell", "Good News About Hell"},
+		{"Who's Afraid of the Big Bad Wolf?", "Whos Afraid of the Big Bad"},
+		{"Half Loop...", "Half Loop"},
+		{"Dear: Ms. Cobel!", "Dear Ms Cobel"},
+		{"Episode 5", ""},
+		{"TBA", ""},
+		{"42", ""},
+		{"?!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := episodeTitleQuery(tt.title); got != tt.want {
+				t.Errorf("episodeTitleQuery(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEpisodeRange(t *testing.T) {
+	tests := []struct {
+		title       string
+		season      int
+		first, last int
+		ok          bool
+	}{
+		{"Severance.S01E01-E03.1080p.WEB.h264-GRP", 1, 1, 3, true},
+		{"Severance S01E01-03 1080p", 1, 1, 3, true},
+		{"Severance.S01E01E02.1080p.WEB.h264-GRP", 1, 1, 2, true},
+		{"Severance.S02E05.E06.720p.HDTV", 2, 5, 6, true},
+		{"severance s01e07e08e09.mkv", 1, 7, 9, true},
+		{"Severance.S01E04.1080p.WEB.h264-GRP", 0, 0, 0, false},
+		{"Severance.S01E04-1080p", 0, 0, 0, false},
+		{"Severance.S01", 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			season, first, last, ok := episodeRange(tt.title)
+			if ok != tt.ok || (ok && (season != tt.season || first != tt.first || last != tt.last)) {
+				t.Errorf("episodeRange() = S%d E%d-E%d %t, want S%d E%d-E%d %t", season, first, last, ok, tt.season, tt.first, tt.last, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMultiEpisodeReleasesMatchEpisode(t *testing.T) {
+	show := &models.Media{Type: models.MediaTypeTVShow, Title: "Severance", MinQuality: "720p", MaxQuality: "2160p"}
+	results := []indexers.IndexerResult{
+		{Title: "Severance.S01E01-E03.1080p.WEB.h264-GRP", Seeders: 10},
+		{Title: "Severance.S01E01E02.1080p.WEB.h264-GRP", Seeders: 10},
+		{Title: "Severance.S01E04.1080p.WEB.h264-GRP", Seeders: 10},
+	}
+
+	tests := []struct {
+		episode int
+		want    []string
+	}{
+		{2, []string{"Severance.S01E01-E03.1080p.WEB.h264-GRP", "Severance.S01E01E02.1080p.WEB.h264-GRP"}},
+		{3, []string{"Severance.S01E01-E03.1080p.WEB.h264-GRP"}},
+		{4, []string{"Severance.S01E04.1080p.WEB.h264-GRP"}},
+		{5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("E%02d", tt.episode), func(t *testing.T) {
+			filtered := newTestSelector().FilterAndScoreTorrents(show, slices.Clone(results), 1, tt.episode, []string{"Severance"}, SelectionFacts{})
+			got := resultTitles(filtered)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilterAndScoreTorrents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+-- SQLite does not allow non-constant defaults on ADD COLUMN, so the timestamps
+-- are added as nullable columns and backfilled for existing rows.
+ALTER TABLE episodes ADD COLUMN created_at DATETIME;
+ALTER TABLE episodes ADD COLUMN updated_at DATETIME;
+ALTER TABLE episodes ADD COLUMN downloaded_at DATETIME;
+
+UPDATE episodes SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE episodes SET updated_at = CURRENT_TIMESTAMP WHERE updated_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_episodes_season_id ON episodes(season_id);
+CREATE INDEX IF NOT EXISTS idx_episodes_torrent_hash ON episodes(torrent_hash) WHERE torrent_hash IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE media ADD COLUMN retry_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN next_retry_at DATETIME;
@@ -0,0 +1 @@
+ALTER TABLE media ADD COLUMN monitored BOOLEAN NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+ALTER TABLE media ADD COLUMN quality_profile TEXT;
@@ -0,0 +1,93 @@
+-- Anime had no uniqueness constraint, unlike movies (tmdb_id) and TV shows (title, year).
+-- Existing duplicates are merged into the oldest entry with the same title and year so
+-- the index can be created: their search terms, seasons and episodes move over to the
+-- kept entry, and an episode both entries have keeps the downloaded copy.
+CREATE TEMP TABLE anime_duplicates AS
+SELECT m.id AS duplicate_id, k.keep_id, m.tv_show_id AS duplicate_show_id
+FROM media m
+JOIN (SELECT title, year, MIN(id) AS keep_id FROM media WHERE type = 'anime' GROUP BY title, year) k
+    ON m.title = k.title AND m.year = k.year
+WHERE m.type = 'anime' AND m.id <> k.keep_id;
+
+INSERT INTO anime_search_terms (media_id, term)
+SELECT DISTINCT d.keep_id, t.term
+FROM anime_search_terms t
+JOIN anime_duplicates d ON t.media_id = d.duplicate_id
+WHERE NOT EXISTS (SELECT 1 FROM anime_search_terms s WHERE s.media_id = d.keep_id AND s.term = t.term);
+
+-- A kept entry without a show adopts the show of its oldest duplicate
+UPDATE media SET tv_show_id = (
+    SELECT d.duplicate_show_id FROM anime_duplicates d
+    WHERE d.keep_id = media.id AND d.duplicate_show_id IS NOT NULL
+    ORDER BY d.duplicate_id LIMIT 1
+)
+WHERE tv_show_id IS NULL AND id IN (SELECT keep_id FROM anime_duplicates);
+
+-- The shows of the other duplicates are merged into the kept entry's show
+CREATE TEMP TABLE anime_show_merges AS
+SELECT DISTINCT d.duplicate_show_id AS from_show, m.tv_show_id AS to_show
+FROM anime_duplicates d
+JOIN media m ON m.id = d.keep_id
+WHERE d.duplicate_show_id IS NOT NULL AND d.duplicate_show_id <> m.tv_show_id;
+
+-- Seasons the kept show doesn't have move over (the oldest one when several duplicates have it)
+CREATE TEMP TABLE anime_season_moves AS
+SELECT MIN(s.id) AS season_id, sm.to_show
+FROM seasons s
+JOIN anime_show_merges sm ON s.show_id = sm.from_show
+WHERE NOT EXISTS (SELECT 1 FROM seasons t WHERE t.show_id = sm.to_show AND t.season_number = s.season_number)
+GROUP BY sm.to_show, s.season_number;
+
+UPDATE seasons SET show_id = (SELECT to_show FROM anime_season_moves WHERE season_id = seasons.id)
+WHERE id IN (SELECT season_id FROM anime_season_moves);
+
+-- The remaining seasons are merged into the kept show's season with the same number
+CREATE TEMP TABLE anime_season_merges AS
+SELECT s.id AS from_season, t.id AS to_season
+FROM seasons s
+JOIN anime_show_merges sm ON s.show_id = sm.from_show
+JOIN seasons t ON t.show_id = sm.to_show AND t.season_number = s.season_number;
+
+CREATE TEMP TABLE anime_episode_moves AS
+SELECT MIN(e.id) AS episode_id, sm.to_season
+FROM episodes e
+JOIN anime_season_merges sm ON e.season_id = sm.from_season
+WHERE NOT EXISTS (SELECT 1 FROM episodes t WHERE t.season_id = sm.to_season AND t.episode_number = e.episode_number)
+GROUP BY sm.to_season, e.episode_number;
+
+UPDATE episodes SET season_id = (SELECT to_season FROM anime_episode_moves WHERE episode_id = episodes.id)
+WHERE id IN (SELECT episode_id FROM anime_episode_moves);
+
+-- Episodes both shows have keep the duplicate's download when only the duplicate has one
+UPDATE episodes SET
+    status = d.status,
+    torrent_hash = d.torrent_hash,
+    torrent_name = d.torrent_name,
+    progress = d.progress,
+    completed_at = d.completed_at,
+    downloaded_at = d.downloaded_at,
+    updated_at = CURRENT_TIMESTAMP
+FROM (
+    SELECT t.id AS to_episode, e.status, e.torrent_hash, e.torrent_name, e.progress, e.completed_at, e.downloaded_at
+    FROM episodes e
+    JOIN anime_season_merges sm ON e.season_id = sm.from_season
+    JOIN episodes t ON t.season_id = sm.to_season AND t.episode_number = e.episode_number
+    WHERE e.status = 'downloaded' AND t.status <> 'downloaded'
+) AS d
+WHERE episodes.id = d.to_episode;
+
+DELETE FROM anime_search_terms WHERE media_id IN (SELECT duplicate_id FROM anime_duplicates);
+DELETE FROM media WHERE id IN (SELECT duplicate_id FROM anime_duplicates);
+DELETE FROM episodes WHERE season_id IN (SELECT s.id FROM seasons s JOIN anime_show_merges sm ON s.show_id = sm.from_show);
+DELETE FROM seasons WHERE show_id IN (SELECT from_show FROM anime_show_merges);
+DELETE FROM tv_shows WHERE id IN (SELECT from_show FROM anime_show_merges);
+
+DROP TABLE anime_duplicates;
+DROP TABLE anime_show_merges;
+DROP TABLE anime_season_moves;
+DROP TABLE anime_season_merges;
+DROP TABLE anime_episode_moves;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_anime_title_year
+ON media(title, year, type)
+WHERE type = 'anime';
@@ -0,0 +1,4 @@
+ALTER TABLE media ADD COLUMN date_based BOOLEAN NOT NULL DEFAULT 0;
+
+-- Episodes of date-based shows are looked up by the day they aired
+CREATE INDEX IF NOT EXISTS idx_episodes_air_date ON episodes(season_id, air_date);
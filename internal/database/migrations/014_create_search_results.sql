@@ -0,0 +1,12 @@
+-- Manual search results, kept for a while so a result can be downloaded by its ID
+-- (even after a restart) instead of the client posting the whole result back.
+CREATE TABLE IF NOT EXISTS search_results (
+    media_id INTEGER NOT NULL,
+    result_id TEXT NOT NULL,
+    result TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (media_id, result_id),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_results_created_at ON search_results(created_at);
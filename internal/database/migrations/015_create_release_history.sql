@@ -0,0 +1,16 @@
+-- Releases grabbed for a movie or an episode (season and episode are 0 for movies),
+-- and releases blacklisted so they are never picked again for that media item.
+CREATE TABLE IF NOT EXISTS release_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    media_id INTEGER NOT NULL,
+    season_number INTEGER NOT NULL DEFAULT 0,
+    episode_number INTEGER NOT NULL DEFAULT 0,
+    event TEXT NOT NULL,
+    release_title TEXT NOT NULL,
+    indexer TEXT,
+    torrent_hash TEXT,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_release_history_episode ON release_history(media_id, season_number, episode_number);
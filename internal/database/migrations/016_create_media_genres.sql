@@ -0,0 +1,9 @@
+-- Genres of media items, from their metadata provider, normalized (see metadata.NormalizeGenres).
+CREATE TABLE IF NOT EXISTS media_genres (
+    media_id INTEGER NOT NULL,
+    genre TEXT NOT NULL,
+    PRIMARY KEY (media_id, genre),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_genres_genre ON media_genres(genre COLLATE NOCASE);
@@ -0,0 +1,2 @@
+-- Shows that download their specials (season 0) although automation.ignore_specials is on
+ALTER TABLE media ADD COLUMN include_specials BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- The normalized title (utils.NormalizeTitle) lets duplicate checks use an index instead
+-- of comparing every title of the library. Existing rows are filled in at startup, see
+-- MediaRepository.BackfillNormalizedTitles.
+ALTER TABLE media ADD COLUMN normalized_title TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_media_type_normalized_title ON media(type, normalized_title);
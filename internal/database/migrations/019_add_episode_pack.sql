@@ -0,0 +1,4 @@
+-- Episodes grabbed with a release holding several episodes (a season pack or a range
+-- such as E01-E03) are post-processed once for the whole torrent, even when the release
+-- was grabbed for a single missing episode.
+ALTER TABLE episodes ADD COLUMN pack BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- The metadata provider a media item was added from and its ID there. tmdb_id only ever
+-- holds a TMDB ID, other providers' IDs (TVmaze, Trakt, AniList) live here. Items added
+-- before keep their tmdb_id and are looked up by title until refreshed from a provider.
+ALTER TABLE media ADD COLUMN metadata_provider TEXT;
+ALTER TABLE media ADD COLUMN metadata_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_media_type_metadata_id ON media(type, metadata_provider, metadata_id);
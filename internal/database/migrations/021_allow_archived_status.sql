@@ -0,0 +1,83 @@
+-- Add 'archived' (ended shows with every episode downloaded) to the CHECK constraint for
+-- the status column in the media table, which means rebuilding it. Migrations run in a
+-- transaction, where foreign keys cannot be turned off: dropping the old table deletes
+-- the rows that reference it, so those are set aside and put back afterwards.
+
+-- 1. Create the new 'media' table with the updated CHECK constraint
+CREATE TABLE media_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL CHECK(type IN ('movie', 'tvshow', 'anime')),
+    imdb_id TEXT,
+    tmdb_id INTEGER,
+    title TEXT NOT NULL,
+    year INTEGER,
+    language TEXT NOT NULL DEFAULT 'en',
+    min_quality TEXT NOT NULL DEFAULT '720p',
+    max_quality TEXT NOT NULL DEFAULT '1080p',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'searching', 'downloading', 'downloaded', 'failed', 'skipped', 'monitoring', 'tba', 'archived')),
+    torrent_hash TEXT,
+    torrent_name TEXT,
+    download_path TEXT,
+    progress REAL DEFAULT 0.0,
+    added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME,
+    overview TEXT,
+    poster_url TEXT,
+    rating REAL,
+    auto_download BOOLEAN DEFAULT TRUE,
+    tv_show_id INTEGER REFERENCES tv_shows(id),
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    next_retry_at DATETIME,
+    monitored BOOLEAN NOT NULL DEFAULT 1,
+    quality_profile TEXT,
+    date_based BOOLEAN NOT NULL DEFAULT 0,
+    include_specials BOOLEAN NOT NULL DEFAULT 0,
+    normalized_title TEXT,
+    metadata_provider TEXT,
+    metadata_id TEXT
+);
+
+-- 2. Copy the data from the old table to the new table
+INSERT INTO media_new (id, type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality, status, torrent_hash, torrent_name, download_path, progress, added_at, completed_at, overview, poster_url, rating, auto_download, tv_show_id, retry_count, next_retry_at, monitored, quality_profile, date_based, include_specials, normalized_title, metadata_provider, metadata_id)
+SELECT id, type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality, status, torrent_hash, torrent_name, download_path, progress, added_at, completed_at, overview, poster_url, rating, auto_download, tv_show_id, retry_count, next_retry_at, monitored, quality_profile, date_based, include_specials, normalized_title, metadata_provider, metadata_id
+FROM media;
+
+-- 3. Set aside the rows referencing media, which dropping it cascades to
+CREATE TEMP TABLE anime_search_terms_backup AS SELECT * FROM anime_search_terms;
+CREATE TEMP TABLE search_results_backup AS SELECT * FROM search_results;
+CREATE TEMP TABLE release_history_backup AS SELECT * FROM release_history;
+CREATE TEMP TABLE media_genres_backup AS SELECT * FROM media_genres;
+
+-- 4. Replace the old table
+DROP TABLE media;
+ALTER TABLE media_new RENAME TO media;
+
+-- 5. Put the referencing rows back
+INSERT INTO anime_search_terms SELECT * FROM anime_search_terms_backup;
+INSERT INTO search_results SELECT * FROM search_results_backup;
+INSERT INTO release_history SELECT * FROM release_history_backup;
+INSERT INTO media_genres SELECT * FROM media_genres_backup;
+DROP TABLE anime_search_terms_backup;
+DROP TABLE search_results_backup;
+DROP TABLE release_history_backup;
+DROP TABLE media_genres_backup;
+
+-- 6. Recreate all indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_movie_tmdb
+ON media(tmdb_id, type)
+WHERE type = 'movie' AND tmdb_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_tvshow_title_year
+ON media(title, year, type)
+WHERE type = 'tvshow';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_anime_title_year
+ON media(title, year, type)
+WHERE type = 'anime';
+
+CREATE INDEX IF NOT EXISTS idx_media_tv_show_id ON media(tv_show_id) WHERE tv_show_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_media_status ON media(status);
+CREATE INDEX IF NOT EXISTS idx_media_type ON media(type);
+CREATE INDEX IF NOT EXISTS idx_media_added_at ON media(added_at);
+CREATE INDEX IF NOT EXISTS idx_media_type_normalized_title ON media(type, normalized_title);
+CREATE INDEX IF NOT EXISTS idx_media_type_metadata_id ON media(type, metadata_provider, metadata_id);
@@ -0,0 +1,10 @@
+-- Past health checks of the download clients and indexers, by client key (see
+-- Manager.checkHealth), so the status page keeps their history across restarts.
+CREATE TABLE IF NOT EXISTS health_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client TEXT NOT NULL,
+    checked_at DATETIME NOT NULL,
+    status BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_health_checks_client ON health_checks(client, checked_at);
@@ -2,11 +2,17 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"reel/internal/utils"
+	"strings"
 	"time"
 )
 
+// ErrNotFound is wrapped by the errors of lookups whose media item or episode doesn't
+// exist, e.g. "episode S01E02 not found".
+var ErrNotFound = errors.New("not found")
+
 type MediaType string
 
 const (
@@ -31,27 +37,78 @@ const (
 )
 
 type Media struct {
-	ID           int         `json:"id" db:"id"`
-	Type         MediaType   `json:"type" db:"type"`
-	IMDBId       string      `json:"imdb_id,omitempty" db:"imdb_id"`
-	TMDBId       *int        `json:"tmdb_id,omitempty" db:"tmdb_id"`
-	TVShowID     *int        `json:"tv_show_id,omitempty" db:"tv_show_id"`
-	Title        string      `json:"title" db:"title"`
-	Year         int         `json:"year" db:"year"`
-	Language     string      `json:"language" db:"language"`
-	MinQuality   string      `json:"min_quality" db:"min_quality"`
-	MaxQuality   string      `json:"max_quality" db:"max_quality"`
-	Status       MediaStatus `json:"status" db:"status"`
-	TorrentHash  *string     `json:"torrent_hash,omitempty" db:"torrent_hash"`
-	TorrentName  *string     `json:"torrent_name,omitempty" db:"torrent_name"`
-	DownloadPath *string     `json:"download_path,omitempty" db:"download_path"`
-	Progress     float64     `json:"progress" db:"progress"`
-	AddedAt      time.Time   `json:"added_at" db:"added_at"`
-	CompletedAt  *time.Time  `json:"completed_at,omitempty" db:"completed_at"`
-	Overview     *string     `json:"overview,omitempty" db:"overview"`
-	PosterURL    *string     `json:"poster_url,omitempty" db:"poster_url"`
-	Rating       *float64    `json:"rating,omitempty" db:"rating"`
-	AutoDownload bool        `json:"auto_download" db:"auto_download"`
+	ID             int         `json:"id" db:"id"`
+	Type           MediaType   `json:"type" db:"type"`
+	IMDBId         string      `json:"imdb_id,omitempty" db:"imdb_id"`
+	TMDBId         *int        `json:"tmdb_id,omitempty" db:"tmdb_id"`
+	TVShowID       *int        `json:"tv_show_id,omitempty" db:"tv_show_id"`
+	Title          string      `json:"title" db:"title"`
+	Year           int         `json:"year" db:"year"`
+	Language       string      `json:"language" db:"language"`
+	MinQuality     string      `json:"min_quality" db:"min_quality"`
+	MaxQuality     string      `json:"max_quality" db:"max_quality"`
+	QualityProfile string      `json:"quality_profile,omitempty" db:"quality_profile"`
+	Status         MediaStatus `json:"status" db:"status"`
+	TorrentHash    *string     `json:"torrent_hash,omitempty" db:"torrent_hash"`
+	TorrentName    *string     `json:"torrent_name,omitempty" db:"torrent_name"`
+	DownloadPath   *string     `json:"download_path,omitempty" db:"download_path"`
+	Progress       float64     `json:"progress" db:"progress"`
+	AddedAt        time.Time   `json:"added_at" db:"added_at"`
+	CompletedAt    *time.Time  `json:"completed_at,omitempty" db:"completed_at"`
+	Overview       *string     `json:"overview,omitempty" db:"overview"`
+	PosterURL      *string     `json:"poster_url,omitempty" db:"poster_url"`
+	Rating         *float64    `json:"rating,omitempty" db:"rating"`
+	AutoDownload   bool        `json:"auto_download" db:"auto_download"`
+	Monitored      bool        `json:"monitored" db:"monitored"`
+	DateBased      bool        `json:"date_based" db:"date_based"` // Releases are named by air date ("Show 2024.01.15")
+	RetryCount     int         `json:"retry_count" db:"retry_count"`
+	NextRetryAt    *time.Time  `json:"next_retry_at,omitempty" db:"next_retry_at"`
+	Genres         []string    `json:"genres,omitempty"` // From the media_genres table, filled in by GetAllMedia and AddMedia
+
+	// Episode summary of TV shows and anime, computed on read (see GetEpisodeSummaries)
+	PendingCount    *int    `json:"pending_count,omitempty"`
+	DownloadedCount *int    `json:"downloaded_count,omitempty"`
+	NextAirDate     *string `json:"next_air_date,omitempty"`
+	// MetadataIncomplete marks a show without any episode, e.g. when the metadata provider
+	// returned none yet; such a show stays monitored so its metadata is refreshed
+	MetadataIncomplete bool `json:"metadata_incomplete,omitempty"`
+
+	// Live transfer figures of an active download, from the last status poll
+	Transfer *TransferStats `json:"transfer,omitempty"`
+
+	// Specials (season 0) are downloaded although automation.ignore_specials is on
+	IncludeSpecials bool `json:"include_specials" db:"include_specials"`
+
+	// The metadata provider the item was added from ("tmdb", "tvmaze", ...) and its ID
+	// there; TMDBId is only set for TMDB
+	MetadataProvider string `json:"metadata_provider,omitempty" db:"metadata_provider"`
+	MetadataID       string `json:"metadata_id,omitempty" db:"metadata_id"`
+}
+
+// TransferStats are the transfer rates (bytes/s), ETA (seconds, 0 when unknown) and
+// client state of a download. For shows they cover all of the show's downloading
+// episodes, with the state of the one that most needs attention (e.g. "error").
+type TransferStats struct {
+	DownloadRate int64  `json:"download_rate"`
+	UploadRate   int64  `json:"upload_rate"`
+	ETA          int    `json:"eta,omitempty"`
+	State        string `json:"state,omitempty"`
+}
+
+// ProgressUpdate is the download progress of a movie (MediaID) or an episode (EpisodeID),
+// see MediaRepository.UpdateProgressBatch.
+type ProgressUpdate struct {
+	MediaID   int
+	EpisodeID int
+	Progress  float64
+}
+
+// EpisodeSummary counts a show's episodes by status and holds the next air date
+// of an episode that hasn't been downloaded yet.
+type EpisodeSummary struct {
+	PendingCount    int
+	DownloadedCount int
+	NextAirDate     *string
 }
 
 type TVShow struct {
@@ -79,6 +136,43 @@ type Episode struct {
 	TorrentName   *string     `json:"torrent_name,omitempty" db:"torrent_name"`
 	Progress      float64     `json:"progress,omitempty" db:"progress"`
 	CompletedAt   *time.Time  `json:"completed_at,omitempty" db:"completed_at"`
+	DownloadedAt  *time.Time  `json:"downloaded_at,omitempty" db:"downloaded_at"` // When the torrent was sent to the client
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
+
+	// The torrent holds several episodes (a season pack or an episode range)
+	Pack bool `json:"pack,omitempty" db:"pack"`
+}
+
+// Release history events.
+const (
+	ReleaseGrabbed     = "grabbed"     // Sent to the download client
+	ReleaseBlacklisted = "blacklisted" // Never to be picked again for the media item
+)
+
+// ReleaseHistoryEntry is a release grabbed or blacklisted for a movie or an episode
+// (season and episode are 0 for movies).
+type ReleaseHistoryEntry struct {
+	ID            int       `json:"id"`
+	MediaID       int       `json:"media_id"`
+	SeasonNumber  int       `json:"season_number"`
+	EpisodeNumber int       `json:"episode_number"`
+	Event         string    `json:"event"`
+	ReleaseTitle  string    `json:"release_title"`
+	Indexer       string    `json:"indexer,omitempty"`
+	TorrentHash   string    `json:"torrent_hash,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RecentItem is a recently added media item, or a recently downloaded movie or episode.
+type RecentItem struct {
+	MediaID   int       `json:"media_id"`
+	Type      MediaType `json:"type"`
+	Title     string    `json:"title"`
+	PosterURL *string   `json:"poster_url,omitempty"`
+	Season    *int      `json:"season,omitempty"`
+	Episode   *int      `json:"episode,omitempty"`
+	Date      time.Time `json:"date"`
 }
 
 type AnimeSearchTerm struct {
@@ -99,15 +193,19 @@ func NewMediaRepository(db *sql.DB, logger *utils.Logger) *MediaRepository {
 func (r *MediaRepository) Create(media *Media) error {
 	query := `
         INSERT INTO media (type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality, 
-                        status, overview, poster_url, rating, auto_download, tv_show_id)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                        status, overview, poster_url, rating, auto_download, tv_show_id, monitored, quality_profile, date_based,
+                        include_specials, normalized_title, metadata_provider, metadata_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
 	r.Logger.Debug(fmt.Sprintf("Creating media - Title: %s, Type: %s, TMDB ID: %v, TV Show ID: %v",
 		media.Title, media.Type, media.TMDBId, media.TVShowID))
 
 	result, err := r.db.Exec(query, media.Type, media.IMDBId, media.TMDBId, media.Title,
 		media.Year, media.Language, media.MinQuality, media.MaxQuality, media.Status,
-		media.Overview, media.PosterURL, media.Rating, media.AutoDownload, media.TVShowID)
+		media.Overview, media.PosterURL, media.Rating, media.AutoDownload, media.TVShowID, media.Monitored,
+		sql.NullString{String: media.QualityProfile, Valid: media.QualityProfile != ""}, media.DateBased, media.IncludeSpecials,
+		utils.NormalizeTitle(media.Title), sql.NullString{String: media.MetadataProvider, Valid: media.MetadataProvider != ""},
+		sql.NullString{String: media.MetadataID, Valid: media.MetadataID != ""})
 
 	if err != nil {
 		r.Logger.Error(fmt.Sprintf("Insert failed: %v\n", err))
@@ -127,22 +225,32 @@ func (r *MediaRepository) Create(media *Media) error {
 	return nil
 }
 
+// mediaColumns lists the media columns in the order expected by scanMedia.
+const mediaColumns = `m.id, m.type, m.imdb_id, m.tmdb_id, m.title, m.year, m.language, m.min_quality, m.max_quality,
+	m.status, m.torrent_hash, m.torrent_name, m.download_path, m.progress, m.added_at, m.completed_at,
+	m.overview, m.poster_url, m.rating, m.auto_download, m.tv_show_id, m.retry_count, m.next_retry_at, m.monitored, m.quality_profile, m.date_based,
+	m.include_specials, m.metadata_provider, m.metadata_id`
+
 func scanMedia(row interface {
 	Scan(dest ...interface{}) error
 }) (*Media, error) {
 	var m Media
 	var tmdbID, tvShowID sql.NullInt64
-	var imdbID, torrentHash, torrentName, downloadPath, overview, posterURL sql.NullString
-	var completedAt sql.NullTime
+	var imdbID, torrentHash, torrentName, downloadPath, overview, posterURL, qualityProfile sql.NullString
+	var metadataProvider, metadataID sql.NullString
+	var completedAt, nextRetryAt sql.NullTime
 	var rating sql.NullFloat64
 
 	err := row.Scan(&m.ID, &m.Type, &imdbID, &tmdbID, &m.Title, &m.Year, &m.Language,
 		&m.MinQuality, &m.MaxQuality, &m.Status, &torrentHash, &torrentName,
 		&downloadPath, &m.Progress, &m.AddedAt, &completedAt,
-		&overview, &posterURL, &rating, &m.AutoDownload, &tvShowID)
+		&overview, &posterURL, &rating, &m.AutoDownload, &tvShowID, &m.RetryCount, &nextRetryAt, &m.Monitored, &qualityProfile, &m.DateBased, &m.IncludeSpecials,
+		&metadataProvider, &metadataID)
 	if err != nil {
 		return nil, err
 	}
+	m.MetadataProvider = metadataProvider.String
+	m.MetadataID = metadataID.String
 
 	if imdbID.Valid {
 		m.IMDBId = imdbID.String
@@ -176,16 +284,20 @@ func scanMedia(row interface {
 	if rating.Valid {
 		m.Rating = &rating.Float64
 	}
+	if nextRetryAt.Valid {
+		m.NextRetryAt = &nextRetryAt.Time
+	}
+	if qualityProfile.Valid {
+		m.QualityProfile = qualityProfile.String
+	}
 
 	return &m, nil
 }
 
 func (r *MediaRepository) GetByID(id int) (*Media, error) {
 	query := `
-        SELECT id, type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality,
-			status, torrent_hash, torrent_name, download_path, progress, added_at, completed_at,
-			overview, poster_url, rating, auto_download, tv_show_id
-        FROM media WHERE id = ?
+        SELECT ` + mediaColumns + `
+        FROM media m WHERE m.id = ?
     `
 	row := r.db.QueryRow(query, id)
 	media, err := scanMedia(row)
@@ -200,10 +312,8 @@ func (r *MediaRepository) GetByID(id int) (*Media, error) {
 
 func (r *MediaRepository) GetAll() ([]Media, error) {
 	query := `
-        SELECT id, type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality,
-			status, torrent_hash, torrent_name, download_path, progress, added_at, completed_at,
-			overview, poster_url, rating, auto_download, tv_show_id
-        FROM media ORDER BY added_at DESC
+        SELECT ` + mediaColumns + `
+        FROM media m ORDER BY m.added_at DESC
     `
 
 	r.Logger.Debug(fmt.Sprintf("Executing GetAll query: %s\n", query))
@@ -245,10 +355,8 @@ func (r *MediaRepository) GetAll() ([]Media, error) {
 
 func (r *MediaRepository) GetByStatus(status MediaStatus) ([]Media, error) {
 	query := `
-        SELECT id, type, imdb_id, tmdb_id, title, year, language, min_quality, max_quality,
-			status, torrent_hash, torrent_name, download_path, progress, added_at, completed_at,
-			overview, poster_url, rating, auto_download, tv_show_id
-        FROM media WHERE status = ? ORDER BY added_at DESC
+        SELECT ` + mediaColumns + `
+        FROM media m WHERE m.status = ? ORDER BY m.added_at DESC
     `
 	rows, err := r.db.Query(query, status)
 	if err != nil {
@@ -267,6 +375,70 @@ func (r *MediaRepository) GetByStatus(status MediaStatus) ([]Media, error) {
 	return mediaList, nil
 }
 
+// GetByGenre returns the media items of a genre, matched case-insensitively.
+func (r *MediaRepository) GetByGenre(genre string) ([]Media, error) {
+	query := `
+        SELECT ` + mediaColumns + `
+        FROM media m
+        WHERE m.id IN (SELECT media_id FROM media_genres WHERE genre = ? COLLATE NOCASE)
+        ORDER BY m.added_at DESC
+    `
+	rows, err := r.db.Query(query, genre)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mediaList []Media
+	for rows.Next() {
+		media, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		mediaList = append(mediaList, *media)
+	}
+	return mediaList, rows.Err()
+}
+
+// SetGenres replaces the genres of a media item.
+func (r *MediaRepository) SetGenres(mediaID int, genres []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM media_genres WHERE media_id = ?", mediaID); err != nil {
+		return err
+	}
+	for _, genre := range genres {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO media_genres (media_id, genre) VALUES (?, ?)", mediaID, genre); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAllGenres returns the genres of every media item that has some, by media ID.
+func (r *MediaRepository) GetAllGenres() (map[int][]string, error) {
+	rows, err := r.db.Query("SELECT media_id, genre FROM media_genres ORDER BY media_id, genre")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := make(map[int][]string)
+	for rows.Next() {
+		var mediaID int
+		var genre string
+		if err := rows.Scan(&mediaID, &genre); err != nil {
+			return nil, err
+		}
+		genres[mediaID] = append(genres[mediaID], genre)
+	}
+	return genres, rows.Err()
+}
+
 func (r *MediaRepository) UpdateStatus(id int, status MediaStatus) error {
 	query := `UPDATE media SET status = ? WHERE id = ?`
 	_, err := r.db.Exec(query, status, id)
@@ -285,6 +457,123 @@ func (r *MediaRepository) UpdateProgress(id int, status MediaStatus, progress fl
 	return err
 }
 
+// UpdateProgressBatch stores the progress of many downloads in a single transaction.
+// Only progress is written, and only to items still downloading: the batch is flushed
+// at the end of a status poll, after which a pause, delete or completion made in the
+// meantime must win.
+func (r *MediaRepository) UpdateProgressBatch(updates []ProgressUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, update := range updates {
+		if update.EpisodeID != 0 {
+			_, err = tx.Exec(`UPDATE episodes SET progress = ?, updated_at = ? WHERE id = ? AND status = ?`, update.Progress, now, update.EpisodeID, StatusDownloading)
+		} else {
+			_, err = tx.Exec(`UPDATE media SET progress = ? WHERE id = ? AND status = ?`, update.Progress, update.MediaID, StatusDownloading)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ScheduleRetry stores how many automatic retries have been made and when the next one may run.
+// A nil nextRetryAt means no retry is currently scheduled.
+func (r *MediaRepository) ScheduleRetry(id int, retryCount int, nextRetryAt *time.Time) error {
+	query := `UPDATE media SET retry_count = ?, next_retry_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, retryCount, nextRetryAt, id)
+	return err
+}
+
+// ResetRetry clears the retry state after a successful grab or a manual retry.
+func (r *MediaRepository) ResetRetry(id int) error {
+	return r.ScheduleRetry(id, 0, nil)
+}
+
+// FindExisting returns the media item of the given type that is clearly the same title
+// as the one described, or nil: the same ID at the same metadata provider (or the same
+// tmdb_id, for TMDB), or the same normalized title (any of titles) and year. A missing
+// year on either side matches any year.
+func (r *MediaRepository) FindExisting(mediaType MediaType, provider, metadataID string, titles []string, year int) (*Media, error) {
+	if provider != "" && metadataID != "" {
+		var id int
+		err := r.db.QueryRow(`SELECT id FROM media
+            WHERE type = ? AND ((metadata_provider = ? AND metadata_id = ?) OR (? = 'tmdb' AND tmdb_id = ?))
+            ORDER BY id LIMIT 1`, mediaType, provider, metadataID, provider, metadataID).Scan(&id)
+		if err == nil {
+			return r.GetByID(id)
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	var normalized []interface{}
+	for _, title := range titles {
+		if n := utils.NormalizeTitle(title); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT id FROM media
+        WHERE type = ? AND normalized_title IN (?%s)
+          AND (? = 0 OR year IS NULL OR year = 0 OR year = ?)
+        ORDER BY id LIMIT 1`, strings.Repeat(", ?", len(normalized)-1))
+	args := append([]interface{}{mediaType}, normalized...)
+	args = append(args, year, year)
+
+	var id int
+	err := r.db.QueryRow(query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// BackfillNormalizedTitles fills in the normalized title of media added before the
+// column existed, so FindExisting finds them.
+func (r *MediaRepository) BackfillNormalizedTitles() error {
+	rows, err := r.db.Query("SELECT id, title FROM media WHERE normalized_title IS NULL")
+	if err != nil {
+		return err
+	}
+	titles := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var title string
+		if err := rows.Scan(&id, &title); err != nil {
+			rows.Close()
+			return err
+		}
+		titles[id] = title
+	}
+	rows.Close() // release the connection before the updates
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, title := range titles {
+		if _, err := r.db.Exec("UPDATE media SET normalized_title = ? WHERE id = ?", utils.NormalizeTitle(title), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *MediaRepository) Delete(id int) error {
 	_, err := r.db.Exec("DELETE FROM media WHERE id = ?", id)
 	return err
@@ -302,6 +591,12 @@ func (r *MediaRepository) CreateTVShow(show *TVShow) error {
 	return nil
 }
 
+// UpdateTVShowStatus stores the show's canonical status from the metadata provider (e.g. "Running", "Ended").
+func (r *MediaRepository) UpdateTVShowStatus(showID int, status string) error {
+	_, err := r.db.Exec("UPDATE tv_shows SET status = ? WHERE id = ?", status, showID)
+	return err
+}
+
 func (r *MediaRepository) CreateSeason(season *Season) error {
 	res, err := r.db.Exec("INSERT INTO seasons (show_id, season_number) VALUES (?, ?)", season.ShowID, season.SeasonNumber)
 	if err != nil {
@@ -313,16 +608,59 @@ func (r *MediaRepository) CreateSeason(season *Season) error {
 }
 
 func (r *MediaRepository) CreateEpisode(episode *Episode) error {
-	res, err := r.db.Exec("INSERT INTO episodes (season_id, episode_number, title, air_date, status) VALUES (?, ?, ?, ?, ?)",
-		episode.SeasonID, episode.EpisodeNumber, episode.Title, episode.AirDate, episode.Status)
+	now := time.Now()
+	res, err := r.db.Exec("INSERT INTO episodes (season_id, episode_number, title, air_date, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		episode.SeasonID, episode.EpisodeNumber, episode.Title, episode.AirDate, episode.Status, now, now)
 	if err != nil {
 		return err
 	}
 	id, _ := res.LastInsertId()
 	episode.ID = int(id)
+	episode.CreatedAt = now
+	episode.UpdatedAt = now
 	return nil
 }
 
+// episodeColumns lists the episode columns in the order expected by scanEpisode.
+const episodeColumns = `e.id, e.season_id, e.episode_number, e.title, e.air_date, e.status,
+	e.torrent_hash, e.torrent_name, e.progress, e.completed_at, e.downloaded_at, e.created_at, e.updated_at, e.pack`
+
+func scanEpisode(row interface {
+	Scan(dest ...interface{}) error
+}) (*Episode, error) {
+	var e Episode
+	var airDate, torrentHash, torrentName sql.NullString
+	var completedAt, downloadedAt, createdAt, updatedAt sql.NullTime
+
+	err := row.Scan(&e.ID, &e.SeasonID, &e.EpisodeNumber, &e.Title, &airDate, &e.Status,
+		&torrentHash, &torrentName, &e.Progress, &completedAt, &downloadedAt, &createdAt, &updatedAt, &e.Pack)
+	if err != nil {
+		return nil, err
+	}
+
+	e.AirDate = airDate.String
+	if torrentHash.Valid {
+		e.TorrentHash = &torrentHash.String
+	}
+	if torrentName.Valid {
+		e.TorrentName = &torrentName.String
+	}
+	if completedAt.Valid {
+		e.CompletedAt = &completedAt.Time
+	}
+	if downloadedAt.Valid {
+		e.DownloadedAt = &downloadedAt.Time
+	}
+	if createdAt.Valid {
+		e.CreatedAt = createdAt.Time
+	}
+	if updatedAt.Valid {
+		e.UpdatedAt = updatedAt.Time
+	}
+
+	return &e, nil
+}
+
 func (r *MediaRepository) GetTVShowByMediaID(mediaID int) (*TVShow, error) {
 	var show TVShow
 	// First, get the tv_show_id from the media table
@@ -376,19 +714,18 @@ func (r *MediaRepository) GetTVShowByMediaID(mediaID int) (*TVShow, error) {
 		}
 
 		// Get episodes for this season
-		episodeRows, err := r.db.Query("SELECT id, episode_number, title, air_date, status FROM episodes WHERE season_id = ? ORDER BY episode_number", season.ID)
+		episodeRows, err := r.db.Query("SELECT "+episodeColumns+" FROM episodes e WHERE e.season_id = ? ORDER BY e.episode_number", season.ID)
 		if err != nil {
 			return nil, err
 		}
 
 		for episodeRows.Next() {
-			var e Episode
-			e.SeasonID = season.ID
-			if err := episodeRows.Scan(&e.ID, &e.EpisodeNumber, &e.Title, &e.AirDate, &e.Status); err != nil {
+			e, err := scanEpisode(episodeRows)
+			if err != nil {
 				episodeRows.Close()
 				return nil, err
 			}
-			season.Episodes = append(season.Episodes, e)
+			season.Episodes = append(season.Episodes, *e)
 		}
 		episodeRows.Close()
 
@@ -419,13 +756,26 @@ func (r *MediaRepository) UpdateEpisodeDownloadInfo(mediaID int, seasonNumber in
 		return fmt.Errorf("season not found: %w", err)
 	}
 
-	// --- MODIFIED SECTION ---
 	// Update the specific episode with its own status, hash, and name.
+	// The grab time is only stamped when a torrent is handed to the client,
+	// and the completion time only when the episode finishes. The pack flag
+	// belongs to the torrent, so it is cleared when the torrent changes.
+	now := time.Now()
+	var downloadedAt, completedAt interface{}
+	if status == StatusDownloading {
+		downloadedAt = now
+	}
+	if status == StatusDownloaded {
+		completedAt = now
+	}
 	_, err = r.db.Exec(`
 		UPDATE episodes 
-		SET status = ?, torrent_hash = ?, torrent_name = ?
+		SET status = ?, torrent_hash = ?, torrent_name = ?, updated_at = ?,
+			downloaded_at = COALESCE(?, downloaded_at),
+			completed_at = COALESCE(?, completed_at),
+			pack = CASE WHEN torrent_hash IS ? THEN pack ELSE 0 END
 		WHERE season_id = ? AND episode_number = ?`,
-		status, hash, torrentName, seasonID, episodeNumber)
+		status, hash, torrentName, now, downloadedAt, completedAt, hash, seasonID, episodeNumber)
 
 	if err != nil {
 		return fmt.Errorf("failed to update episode download info: %w", err)
@@ -447,11 +797,21 @@ func (r *MediaRepository) UpdateEpisodeDownloadInfo(mediaID int, seasonNumber in
 	return nil
 }
 
+// MarkTorrentPack flags the episodes downloading with the torrent as sharing it, so
+// the torrent is post-processed as a whole once it completes.
+func (r *MediaRepository) MarkTorrentPack(hash string) error {
+	_, err := r.db.Exec("UPDATE episodes SET pack = 1 WHERE torrent_hash = ? AND status = ?", hash, StatusDownloading)
+	return err
+}
+
 // GetEpisodeByDetails gets a specific episode by media ID, season, and episode number
 func (r *MediaRepository) GetEpisodeByDetails(mediaID int, seasonNumber int, episodeNumber int) (*Episode, error) {
 	// First get the TV show ID from media
 	var tvShowID sql.NullInt64
 	err := r.db.QueryRow("SELECT tv_show_id FROM media WHERE id = ?", mediaID).Scan(&tvShowID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("media %w", ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get TV show ID: %w", err)
 	}
@@ -461,25 +821,54 @@ func (r *MediaRepository) GetEpisodeByDetails(mediaID int, seasonNumber int, epi
 	}
 
 	// Get the episode
-	var episode Episode
 	query := `
-		SELECT e.id, e.season_id, e.episode_number, e.title, e.air_date, e.status
+		SELECT ` + episodeColumns + `
 		FROM episodes e
 		JOIN seasons s ON e.season_id = s.id
 		WHERE s.show_id = ? AND s.season_number = ? AND e.episode_number = ?`
 
-	err = r.db.QueryRow(query, tvShowID.Int64, seasonNumber, episodeNumber).Scan(
-		&episode.ID, &episode.SeasonID, &episode.EpisodeNumber,
-		&episode.Title, &episode.AirDate, &episode.Status)
-
+	episode, err := scanEpisode(r.db.QueryRow(query, tvShowID.Int64, seasonNumber, episodeNumber))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("episode S%02dE%02d not found", seasonNumber, episodeNumber)
+			return nil, fmt.Errorf("episode S%02dE%02d %w", seasonNumber, episodeNumber, ErrNotFound)
 		}
 		return nil, err
 	}
 
-	return &episode, nil
+	return episode, nil
+}
+
+// GetEpisodeByAirDate returns the episode of a show that aired on the given day
+// (YYYY-MM-DD) and its season number, or nil if no episode aired that day.
+func (r *MediaRepository) GetEpisodeByAirDate(mediaID int, airDate string) (*Episode, int, error) {
+	var seasonNumber, episodeNumber int
+	err := r.db.QueryRow(`
+		SELECT s.season_number, e.episode_number
+		FROM episodes e
+		JOIN seasons s ON e.season_id = s.id
+		JOIN media m ON m.tv_show_id = s.show_id
+		WHERE m.id = ? AND e.air_date = ?
+		ORDER BY s.season_number, e.episode_number
+		LIMIT 1`, mediaID, airDate).Scan(&seasonNumber, &episodeNumber)
+	if err == sql.ErrNoRows {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	episode, err := r.GetEpisodeByDetails(mediaID, seasonNumber, episodeNumber)
+	if err != nil {
+		return nil, 0, err
+	}
+	return episode, seasonNumber, nil
+}
+
+// UpdateMetadata stores refreshed descriptive metadata. Nil values keep what is stored.
+func (r *MediaRepository) UpdateMetadata(id int, overview, posterURL *string, rating *float64) error {
+	query := `UPDATE media SET overview = COALESCE(?, overview), poster_url = COALESCE(?, poster_url), rating = COALESCE(?, rating) WHERE id = ?`
+	_, err := r.db.Exec(query, overview, posterURL, rating, id)
+	return err
 }
 
 // UpdateSettings updates the quality and auto-download status for a media item.
@@ -489,6 +878,32 @@ func (r *MediaRepository) UpdateSettings(id int, minQuality, maxQuality string,
 	return err
 }
 
+// SetQualityProfile assigns a named quality profile to a media item; "" clears it.
+func (r *MediaRepository) SetQualityProfile(id int, profile string) error {
+	_, err := r.db.Exec(`UPDATE media SET quality_profile = ? WHERE id = ?`,
+		sql.NullString{String: profile, Valid: profile != ""}, id)
+	return err
+}
+
+// SetMonitored pauses (false) or resumes (true) automatic searching for a media item.
+func (r *MediaRepository) SetMonitored(id int, monitored bool) error {
+	_, err := r.db.Exec(`UPDATE media SET monitored = ? WHERE id = ?`, monitored, id)
+	return err
+}
+
+// SetIncludeSpecials marks whether a show downloads its specials (season 0) although
+// automation.ignore_specials is on.
+func (r *MediaRepository) SetIncludeSpecials(id int, includeSpecials bool) error {
+	_, err := r.db.Exec(`UPDATE media SET include_specials = ? WHERE id = ?`, includeSpecials, id)
+	return err
+}
+
+// SetDateBased marks whether a show's releases are named by air date instead of SxxExx.
+func (r *MediaRepository) SetDateBased(id int, dateBased bool) error {
+	_, err := r.db.Exec(`UPDATE media SET date_based = ? WHERE id = ?`, dateBased, id)
+	return err
+}
+
 func (r *MediaRepository) AddAnimeSearchTerm(mediaID int, term string) (*AnimeSearchTerm, error) {
 	query := `INSERT INTO anime_search_terms (media_id, term) VALUES (?, ?)`
 	res, err := r.db.Exec(query, mediaID, term)
@@ -527,7 +942,7 @@ func (r *MediaRepository) DeleteAnimeSearchTerm(id int) error {
 // GetDownloadingEpisodesForShow retrieves all episodes for a given show that are currently downloading.
 func (r *MediaRepository) GetDownloadingEpisodesForShow(tvShowID int) ([]Episode, error) {
 	query := `
-		SELECT e.id, e.season_id, e.episode_number, e.title, e.air_date, e.status, e.torrent_hash
+		SELECT ` + episodeColumns + `
 		FROM episodes e
 		JOIN seasons s ON e.season_id = s.id
 		WHERE s.show_id = ? AND e.status = ? AND e.torrent_hash IS NOT NULL
@@ -540,33 +955,199 @@ func (r *MediaRepository) GetDownloadingEpisodesForShow(tvShowID int) ([]Episode
 
 	var episodes []Episode
 	for rows.Next() {
-		var ep Episode
-		var torrentHash sql.NullString
-		// Note: We are not scanning torrent_name, progress, or completed_at here as they aren't needed for this specific function's purpose.
-		if err := rows.Scan(&ep.ID, &ep.SeasonID, &ep.EpisodeNumber, &ep.Title, &ep.AirDate, &ep.Status, &torrentHash); err != nil {
+		ep, err := scanEpisode(rows)
+		if err != nil {
 			return nil, err
 		}
-		if torrentHash.Valid {
-			ep.TorrentHash = &torrentHash.String
-		}
-		episodes = append(episodes, ep)
+		episodes = append(episodes, *ep)
 	}
 	return episodes, nil
 }
 
+// GetEpisodeSummaries returns the episode summary of every show, keyed by TV show ID.
+func (r *MediaRepository) GetEpisodeSummaries() (map[int]EpisodeSummary, error) {
+	query := `
+		SELECT s.show_id,
+			SUM(CASE WHEN e.status = ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN e.status = ? THEN 1 ELSE 0 END),
+			MIN(CASE WHEN e.status != ? AND e.air_date >= date('now') THEN e.air_date END)
+		FROM episodes e
+		JOIN seasons s ON e.season_id = s.id
+		GROUP BY s.show_id
+	`
+	rows, err := r.db.Query(query, StatusPending, StatusDownloaded, StatusDownloaded)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make(map[int]EpisodeSummary)
+	for rows.Next() {
+		var showID int
+		var summary EpisodeSummary
+		var nextAirDate sql.NullString
+		if err := rows.Scan(&showID, &summary.PendingCount, &summary.DownloadedCount, &nextAirDate); err != nil {
+			return nil, err
+		}
+		if nextAirDate.Valid {
+			summary.NextAirDate = &nextAirDate.String
+		}
+		summaries[showID] = summary
+	}
+	return summaries, rows.Err()
+}
+
+// GetByTorrentHash returns the media item actively downloading (or post-processing) the
+// torrent with the given hash, as a movie or through one of its episodes, or nil if none
+// is. Hashes are compared case-insensitively.
+func (r *MediaRepository) GetByTorrentHash(hash string) (*Media, error) {
+	query := `
+		SELECT ` + mediaColumns + `
+		FROM media m
+		WHERE (m.torrent_hash = ? COLLATE NOCASE AND m.status IN (?, ?))
+			OR m.tv_show_id IN (
+				SELECT s.show_id FROM episodes e JOIN seasons s ON e.season_id = s.id
+				WHERE e.torrent_hash = ? COLLATE NOCASE AND e.status IN (?, ?))
+		ORDER BY m.id LIMIT 1
+	`
+	row := r.db.QueryRow(query, hash, StatusDownloading, StatusPostProcessing, hash, StatusDownloading, StatusPostProcessing)
+	media, err := scanMedia(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return media, err
+}
+
+// IsTorrentDownloading reports whether a movie or an episode is downloading the torrent
+// with the given hash, compared case-insensitively.
+func (r *MediaRepository) IsTorrentDownloading(hash string) (bool, error) {
+	var downloading bool
+	err := r.db.QueryRow(`SELECT
+		EXISTS (SELECT 1 FROM media WHERE torrent_hash = ? COLLATE NOCASE AND status = ?)
+		OR EXISTS (SELECT 1 FROM episodes WHERE torrent_hash = ? COLLATE NOCASE AND status = ?)`,
+		hash, StatusDownloading, hash, StatusDownloading).Scan(&downloading)
+	return downloading, err
+}
+
+// GetTorrentNames returns the torrent name of every movie and episode that has one.
+func (r *MediaRepository) GetTorrentNames() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT torrent_name FROM media WHERE torrent_name IS NOT NULL AND torrent_name != ''
+		UNION
+		SELECT torrent_name FROM episodes WHERE torrent_name IS NOT NULL AND torrent_name != ''
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// GetRecentlyAdded returns the most recently added media items, newest first.
+func (r *MediaRepository) GetRecentlyAdded(limit int) ([]RecentItem, error) {
+	rows, err := r.db.Query(`
+		SELECT id, type, title, poster_url, added_at
+		FROM media ORDER BY added_at DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []RecentItem
+	for rows.Next() {
+		var item RecentItem
+		var posterURL sql.NullString
+		if err := rows.Scan(&item.MediaID, &item.Type, &item.Title, &posterURL, &item.Date); err != nil {
+			return nil, err
+		}
+		if posterURL.Valid {
+			item.PosterURL = &posterURL.String
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetRecentlyDownloaded returns the most recently completed movies and episodes, newest first.
+func (r *MediaRepository) GetRecentlyDownloaded(limit int) ([]RecentItem, error) {
+	rows, err := r.db.Query(`
+		SELECT id, type, title, poster_url, season_number, episode_number, completed_at FROM (
+			SELECT m.id, m.type, m.title, m.poster_url, NULL AS season_number, NULL AS episode_number, m.completed_at
+			FROM media m
+			WHERE m.tv_show_id IS NULL AND m.completed_at IS NOT NULL
+			UNION ALL
+			SELECT m.id, m.type, m.title, m.poster_url, s.season_number, e.episode_number, e.completed_at
+			FROM episodes e
+			JOIN seasons s ON e.season_id = s.id
+			JOIN media m ON m.tv_show_id = s.show_id
+			WHERE e.completed_at IS NOT NULL
+		)
+		ORDER BY completed_at DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []RecentItem
+	for rows.Next() {
+		var item RecentItem
+		var posterURL sql.NullString
+		var season, episode sql.NullInt64
+		var completedAt sql.NullTime
+		if err := rows.Scan(&item.MediaID, &item.Type, &item.Title, &posterURL, &season, &episode, &completedAt); err != nil {
+			return nil, err
+		}
+		if posterURL.Valid {
+			item.PosterURL = &posterURL.String
+		}
+		if season.Valid && episode.Valid {
+			seasonNumber, episodeNumber := int(season.Int64), int(episode.Int64)
+			item.Season, item.Episode = &seasonNumber, &episodeNumber
+		}
+		item.Date = completedAt.Time
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// UpdateEpisodeProgress records the download progress of a single episode by its ID.
+func (r *MediaRepository) UpdateEpisodeProgress(episodeID int, progress float64) error {
+	_, err := r.db.Exec(`UPDATE episodes SET progress = ?, updated_at = ? WHERE id = ?`, progress, time.Now(), episodeID)
+	return err
+}
+
 // GetSeriesWithFailedEpisodes finds all series that contain at least one failed episode.
 func (r *MediaRepository) GetSeriesWithFailedEpisodes() ([]Media, error) {
+	return r.getSeriesWithEpisodeStatus(StatusFailed)
+}
+
+// GetSeriesWithDownloadingEpisodes finds all series that have at least one episode
+// with an active torrent, regardless of the parent media's own status.
+func (r *MediaRepository) GetSeriesWithDownloadingEpisodes() ([]Media, error) {
+	return r.getSeriesWithEpisodeStatus(StatusDownloading)
+}
+
+func (r *MediaRepository) getSeriesWithEpisodeStatus(status MediaStatus) ([]Media, error) {
 	query := `
-		SELECT DISTINCT m.id, m.type, m.imdb_id, m.tmdb_id, m.title, m.year, m.language, m.min_quality, m.max_quality,
-			m.status, m.torrent_hash, m.torrent_name, m.download_path, m.progress, m.added_at, m.completed_at,
-			m.overview, m.poster_url, m.rating, m.auto_download, m.tv_show_id
+		SELECT DISTINCT ` + mediaColumns + `
 		FROM media m
 		JOIN tv_shows ts ON m.tv_show_id = ts.id
 		JOIN seasons s ON ts.id = s.show_id
 		JOIN episodes e ON s.id = e.season_id
 		WHERE e.status = ?
 	`
-	rows, err := r.db.Query(query, StatusFailed)
+	rows, err := r.db.Query(query, status)
 	if err != nil {
 		return nil, err
 	}
@@ -582,3 +1163,171 @@ func (r *MediaRepository) GetSeriesWithFailedEpisodes() ([]Media, error) {
 	}
 	return mediaList, nil
 }
+
+// --- Manual Search Results ---
+
+// SaveSearchResults stores the JSON-encoded manual search results of a media item,
+// keyed by result ID, replacing earlier copies of the same results. Results older
+// than maxAge, of any media item, are dropped.
+func (r *MediaRepository) SaveSearchResults(mediaID int, results map[string][]byte, maxAge time.Duration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec("DELETE FROM search_results WHERE created_at < ?", now.Add(-maxAge)); err != nil {
+		return err
+	}
+	for resultID, result := range results {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO search_results (media_id, result_id, result, created_at)
+			VALUES (?, ?, ?, ?)`, mediaID, resultID, string(result), now)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSearchResult returns a stored manual search result, or nil if there is none
+// newer than maxAge.
+func (r *MediaRepository) GetSearchResult(mediaID int, resultID string, maxAge time.Duration) ([]byte, error) {
+	var result string
+	err := r.db.QueryRow(`SELECT result FROM search_results
+		WHERE media_id = ? AND result_id = ? AND created_at >= ?`,
+		mediaID, resultID, time.Now().Add(-maxAge)).Scan(&result)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(result), nil
+}
+
+// --- Release History ---
+
+// AddReleaseHistory records a release event for a movie or an episode.
+func (r *MediaRepository) AddReleaseHistory(entry *ReleaseHistoryEntry) error {
+	entry.CreatedAt = time.Now()
+	result, err := r.db.Exec(`INSERT INTO release_history
+		(media_id, season_number, episode_number, event, release_title, indexer, torrent_hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.MediaID, entry.SeasonNumber, entry.EpisodeNumber, entry.Event, entry.ReleaseTitle,
+		entry.Indexer, entry.TorrentHash, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+// GetReleaseHistory returns the release events of a movie (season and episode 0) or
+// an episode, newest first.
+func (r *MediaRepository) GetReleaseHistory(mediaID, seasonNumber, episodeNumber int) ([]ReleaseHistoryEntry, error) {
+	rows, err := r.db.Query(`SELECT id, media_id, season_number, episode_number, event, release_title,
+			COALESCE(indexer, ''), COALESCE(torrent_hash, ''), created_at
+		FROM release_history
+		WHERE media_id = ? AND season_number = ? AND episode_number = ?
+		ORDER BY created_at DESC, id DESC`, mediaID, seasonNumber, episodeNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ReleaseHistoryEntry{}
+	for rows.Next() {
+		var entry ReleaseHistoryEntry
+		if err := rows.Scan(&entry.ID, &entry.MediaID, &entry.SeasonNumber, &entry.EpisodeNumber, &entry.Event,
+			&entry.ReleaseTitle, &entry.Indexer, &entry.TorrentHash, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetBlacklistedReleases returns the lowercased titles of the releases blacklisted for
+// a media item, in any of its episodes.
+func (r *MediaRepository) GetBlacklistedReleases(mediaID int) (map[string]bool, error) {
+	rows, err := r.db.Query("SELECT release_title FROM release_history WHERE media_id = ? AND event = ?",
+		mediaID, ReleaseBlacklisted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := make(map[string]bool)
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles[strings.ToLower(title)] = true
+	}
+	return titles, rows.Err()
+}
+
+// --- Health Checks ---
+
+// HealthCheck is the outcome of one health check of a download client or indexer.
+type HealthCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Status    bool      `json:"status"`
+}
+
+// AddHealthChecks stores one round of health checks, by client key. Only the latest
+// keep checks of each client are kept, and clients missing from the round are dropped.
+func (r *MediaRepository) AddHealthChecks(checks map[string]bool, checkedAt time.Time, keep int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	clients := make([]interface{}, 0, len(checks))
+	for client, status := range checks {
+		if _, err := tx.Exec("INSERT INTO health_checks (client, checked_at, status) VALUES (?, ?, ?)", client, checkedAt, status); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM health_checks WHERE client = ? AND id NOT IN (
+				SELECT id FROM health_checks WHERE client = ? ORDER BY checked_at DESC, id DESC LIMIT ?)`,
+			client, client, keep); err != nil {
+			return err
+		}
+		clients = append(clients, client)
+	}
+	query := "DELETE FROM health_checks"
+	if len(clients) > 0 {
+		query += " WHERE client NOT IN (?" + strings.Repeat(", ?", len(clients)-1) + ")"
+	}
+	if _, err := tx.Exec(query, clients...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetHealthHistory returns the stored health checks by client key, oldest first.
+func (r *MediaRepository) GetHealthHistory() (map[string][]HealthCheck, error) {
+	rows, err := r.db.Query("SELECT client, checked_at, status FROM health_checks ORDER BY checked_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make(map[string][]HealthCheck)
+	for rows.Next() {
+		var client string
+		var check HealthCheck
+		if err := rows.Scan(&client, &check.CheckedAt, &check.Status); err != nil {
+			return nil, err
+		}
+		history[client] = append(history[client], check)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,320 @@
+package models
+
+import (
+	"io"
+	"path/filepath"
+	"reel/internal/database"
+	"reel/internal/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRepo(t *testing.T) *MediaRepository {
+	t.Helper()
+	logger := utils.NewLogger(false, io.Discard)
+	db, err := database.NewSQLite(filepath.Join(t.TempDir(), "reel.db"), database.PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := database.RunMigrations(db, logger); err != nil {
+		t.Fatal(err)
+	}
+	return NewMediaRepository(db, logger)
+}
+
+func createMedia(t *testing.T, repo *MediaRepository, media *Media) *Media {
+	t.Helper()
+	if media.Status == "" {
+		media.Status = StatusPending
+	}
+	if media.Language == "" {
+		media.Language = "en"
+	}
+	if err := repo.Create(media); err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+func TestFindExisting(t *testing.T) {
+	repo := newTestRepo(t)
+	tmdbID := 603
+	// Added before the metadata columns existed
+	matrix := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "The Matrix", Year: 1999, TMDBId: &tmdbID})
+	show := createMedia(t, repo, &Media{Type: MediaTypeTVShow, Title: "Law & Order: SVU", Year: 1999, MetadataProvider: "tvmaze", MetadataID: "9"})
+	undated := createMedia(t, repo, &Media{Type: MediaTypeAnime, Title: "Mushishi"})
+
+	tests := []struct {
+		name       string
+		mediaType  MediaType
+		provider   string
+		metadataID string
+		titles     []string
+		year       int
+		want       int
+	}{
+		{"same tmdb id", MediaTypeMovie, "tmdb", "603", []string{"Matrix"}, 2000, matrix.ID},
+		{"same id at another provider", MediaTypeMovie, "trakt", "603", []string{"Matrix"}, 2000, 0},
+		{"same provider id", MediaTypeTVShow, "tvmaze", "9", []string{"SVU"}, 2001, show.ID},
+		{"provider id of another provider", MediaTypeTVShow, "trakt", "9", []string{"SVU"}, 2001, 0},
+		{"normalized title and year", MediaTypeMovie, "tmdb", "604", []string{"the matrix!"}, 1999, matrix.ID},
+		{"title with another year", MediaTypeMovie, "", "", []string{"The Matrix"}, 2003, 0},
+		{"unknown year matches", MediaTypeMovie, "", "", []string{"The Matrix"}, 0, matrix.ID},
+		{"any of the titles", MediaTypeTVShow, "", "", []string{"SVU", "Law and Order SVU"}, 1999, show.ID},
+		{"other type", MediaTypeAnime, "", "", []string{"The Matrix"}, 1999, 0},
+		{"stored without year", MediaTypeAnime, "", "", []string{"Mushi-shi"}, 2005, 0},
+		{"stored without year, same title", MediaTypeAnime, "", "", []string{"mushishi"}, 2005, undated.ID},
+		{"no titles", MediaTypeMovie, "", "", nil, 1999, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindExisting(tt.mediaType, tt.provider, tt.metadataID, tt.titles, tt.year)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotID := 0
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.want {
+				t.Errorf("FindExisting() = %d, want %d", gotID, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataIDRoundTrip(t *testing.T) {
+	repo := newTestRepo(t)
+	created := createMedia(t, repo, &Media{Type: MediaTypeAnime, Title: "Frieren", Year: 2023, MetadataProvider: "anilist", MetadataID: "154587"})
+	got, err := repo.GetByID(created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MetadataProvider != "anilist" || got.MetadataID != "154587" || got.TMDBId != nil {
+		t.Errorf("stored metadata = %q %q (tmdb %v), want anilist 154587 and no tmdb id", got.MetadataProvider, got.MetadataID, got.TMDBId)
+	}
+}
+
+func TestBackfillNormalizedTitles(t *testing.T) {
+	repo := newTestRepo(t)
+	if _, err := repo.db.Exec("INSERT INTO media (type, title, year) VALUES ('movie', 'Amélie', 2001)"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.FindExisting(MediaTypeMovie, "", "", []string{"Amelie"}, 2001); got != nil {
+		t.Fatalf("found %d before the backfill", got.ID)
+	}
+	if err := repo.BackfillNormalizedTitles(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.FindExisting(MediaTypeMovie, "", "", []string{"Amelie"}, 2001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Title != "Amélie" {
+		t.Errorf("FindExisting() after backfill = %v, want Amélie", got)
+	}
+}
+
+// createShow adds a show with one season of the given number of pending episodes.
+func createShow(t *testing.T, repo *MediaRepository, title string, seasonNumber, episodes int) *Media {
+	t.Helper()
+	show := &TVShow{Status: "Running"}
+	if err := repo.CreateTVShow(show); err != nil {
+		t.Fatal(err)
+	}
+	season := &Season{ShowID: show.ID, SeasonNumber: seasonNumber}
+	if err := repo.CreateSeason(season); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= episodes; i++ {
+		if err := repo.CreateEpisode(&Episode{SeasonID: season.ID, EpisodeNumber: i, Title: title, Status: StatusPending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return createMedia(t, repo, &Media{Type: MediaTypeTVShow, Title: title, Year: 2020, TVShowID: &show.ID, Status: StatusMonitoring})
+}
+
+func TestMarkTorrentPack(t *testing.T) {
+	repo := newTestRepo(t)
+	media := createShow(t, repo, "Severance", 1, 3)
+
+	packHash, packName := "aaaa", "Severance S01 1080p"
+	if err := repo.UpdateEpisodeDownloadInfo(media.ID, 1, 2, StatusDownloading, &packHash, &packName); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkTorrentPack(packHash); err != nil {
+		t.Fatal(err)
+	}
+	episode, err := repo.GetEpisodeByDetails(media.ID, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !episode.Pack {
+		t.Fatal("episode grabbed with a pack is not flagged")
+	}
+	if other, _ := repo.GetEpisodeByDetails(media.ID, 1, 1); other.Pack {
+		t.Error("episode without a torrent is flagged")
+	}
+
+	// Completing keeps the flag, another torrent clears it
+	if err := repo.UpdateEpisodeDownloadInfo(media.ID, 1, 2, StatusDownloaded, &packHash, &packName); err != nil {
+		t.Fatal(err)
+	}
+	if episode, _ = repo.GetEpisodeByDetails(media.ID, 1, 2); !episode.Pack {
+		t.Error("flag cleared on completion")
+	}
+	singleHash, singleName := "bbbb", "Severance S01E02 1080p"
+	if err := repo.UpdateEpisodeDownloadInfo(media.ID, 1, 2, StatusDownloading, &singleHash, &singleName); err != nil {
+		t.Fatal(err)
+	}
+	if episode, _ = repo.GetEpisodeByDetails(media.ID, 1, 2); episode.Pack {
+		t.Error("flag kept for a new torrent")
+	}
+}
+
+func TestUpdateProgressBatch(t *testing.T) {
+	repo := newTestRepo(t)
+	downloading := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Heat", Year: 1995, Status: StatusDownloading})
+	paused := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Ronin", Year: 1998, Status: StatusDownloading})
+	show := createShow(t, repo, "Andor", 1, 2)
+	hash, name := "cccc", "Andor S01E01"
+	if err := repo.UpdateEpisodeDownloadInfo(show.ID, 1, 1, StatusDownloading, &hash, &name); err != nil {
+		t.Fatal(err)
+	}
+	episode, err := repo.GetEpisodeByDetails(show.ID, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := repo.GetEpisodeByDetails(show.ID, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Paused by the user while the poll was running
+	if err := repo.UpdateStatus(paused.ID, StatusPending); err != nil {
+		t.Fatal(err)
+	}
+
+	err = repo.UpdateProgressBatch([]ProgressUpdate{
+		{MediaID: downloading.ID, Progress: 0.5},
+		{MediaID: paused.ID, Progress: 0.6},
+		{EpisodeID: episode.ID, Progress: 0.7},
+		{EpisodeID: pending.ID, Progress: 0.8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := repo.GetByID(downloading.ID); got.Progress != 0.5 || got.Status != StatusDownloading {
+		t.Errorf("downloading movie: progress %v, status %s", got.Progress, got.Status)
+	}
+	if got, _ := repo.GetByID(paused.ID); got.Progress != 0 || got.Status != StatusPending {
+		t.Errorf("paused movie overwritten: progress %v, status %s", got.Progress, got.Status)
+	}
+	if got, _ := repo.GetEpisodeByDetails(show.ID, 1, 1); got.Progress != 0.7 {
+		t.Errorf("downloading episode progress = %v, want 0.7", got.Progress)
+	}
+	if got, _ := repo.GetEpisodeByDetails(show.ID, 1, 2); got.Progress != 0 {
+		t.Errorf("pending episode progress = %v, want 0", got.Progress)
+	}
+}
+
+func TestUpdateProgressBatchIsOneTransaction(t *testing.T) {
+	repo := newTestRepo(t)
+	first := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Heat", Year: 1995, Status: StatusDownloading})
+	second := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Ronin", Year: 1998, Status: StatusDownloading})
+
+	// Make the second write fail: the first must not be stored either
+	_, err := repo.db.Exec(`CREATE TRIGGER fail_progress BEFORE UPDATE OF progress ON media
+		WHEN NEW.id = ` + strconv.Itoa(second.ID) + ` BEGIN SELECT RAISE(ABORT, 'disk full'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.UpdateProgressBatch([]ProgressUpdate{{MediaID: first.ID, Progress: 0.5}, {MediaID: second.ID, Progress: 0.5}})
+	if err == nil {
+		t.Fatal("UpdateProgressBatch() succeeded despite a failing write")
+	}
+	if got, _ := repo.GetByID(first.ID); got.Progress != 0 {
+		t.Errorf("first write kept after the batch failed: progress %v", got.Progress)
+	}
+}
+
+func TestHealthHistory(t *testing.T) {
+	repo := newTestRepo(t)
+	start := time.Now().Truncate(time.Second)
+	for i := range 4 {
+		checks := map[string]bool{"torrent::qbittorrent": i%2 == 0}
+		if i < 2 {
+			checks["indexer:http://old:"] = true
+		}
+		if err := repo.AddHealthChecks(checks, start.Add(time.Duration(i)*time.Minute), 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := repo.GetHealthHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := history["indexer:http://old:"]; found {
+		t.Error("kept the history of a client missing from the latest round")
+	}
+	torrent := history["torrent::qbittorrent"]
+	if len(torrent) != 3 {
+		t.Fatalf("got %d checks, want the latest 3", len(torrent))
+	}
+	for i, check := range torrent {
+		want := i + 1
+		if !check.CheckedAt.Equal(start.Add(time.Duration(want)*time.Minute)) || check.Status != (want%2 == 0) {
+			t.Errorf("check %d = %+v, want round %d, oldest first", i, check, want)
+		}
+	}
+}
+
+func TestGetByTorrentHash(t *testing.T) {
+	repo := newTestRepo(t)
+	movie := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Heat", Year: 1995})
+	done := createMedia(t, repo, &Media{Type: MediaTypeMovie, Title: "Alien", Year: 1979})
+	show := createShow(t, repo, "Severance", 1, 2)
+	movieHash, doneHash, episodeHash := strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 40)
+	name := "release"
+	if err := repo.UpdateDownloadInfo(movie.ID, StatusDownloading, &movieHash, &name); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateDownloadInfo(done.ID, StatusDownloaded, &doneHash, &name); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateEpisodeDownloadInfo(show.ID, 1, 2, StatusDownloading, &episodeHash, &name); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want int // 0 when no media is found
+	}{
+		{"downloading movie", movieHash, movie.ID},
+		{"hash in upper case", strings.ToUpper(movieHash), movie.ID},
+		{"downloading episode", episodeHash, show.ID},
+		{"finished download", doneHash, 0},
+		{"unknown hash", strings.Repeat("d", 40), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			media, err := repo.GetByTorrentHash(tt.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := 0
+			if media != nil {
+				got = media.ID
+			}
+			if got != tt.want {
+				t.Errorf("GetByTorrentHash() = media %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"reel/internal/utils"
 	"sort"
 	"strings"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -17,7 +18,39 @@ import (
 //go:embed migrations/*.sql
 var migrationFiles embed.FS
 
-func NewSQLite(dbPath string) (*sql.DB, error) {
+// PoolConfig sizes the connection pool of the database. Zero values keep the
+// driver's defaults, see defaultPools.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// defaultPools holds the pool defaults of each driver. SQLite only supports one
+// writer, so more connections just wait on each other.
+var defaultPools = map[string]PoolConfig{
+	"sqlite": {MaxOpenConns: 1, MaxIdleConns: 1},
+}
+
+// applyPool configures the pool of db, filling the unset values of pool from the
+// driver's defaults.
+func applyPool(db *sql.DB, driver string, pool PoolConfig) {
+	defaults := defaultPools[driver]
+	if pool.MaxOpenConns == 0 {
+		pool.MaxOpenConns = defaults.MaxOpenConns
+	}
+	if pool.MaxIdleConns == 0 {
+		pool.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if pool.ConnMaxLifetime == 0 {
+		pool.ConnMaxLifetime = defaults.ConnMaxLifetime
+	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+}
+
+func NewSQLite(dbPath string, pool PoolConfig) (*sql.DB, error) {
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
@@ -28,9 +61,7 @@ func NewSQLite(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Configure SQLite
-	db.SetMaxOpenConns(1) // SQLite only supports one writer
-	db.SetMaxIdleConns(1)
+	applyPool(db, "sqlite", pool)
 
 	return db, nil
 }
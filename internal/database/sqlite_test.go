@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"reel/internal/utils"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := NewSQLite(filepath.Join(t.TempDir(), "reel.db"), PoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// applyMigrationsBefore runs and records the migrations older than version, so
+// RunMigrations only applies version and the ones after it.
+func applyMigrationsBefore(t *testing.T, db *sql.DB, version string) {
+	t.Helper()
+	if _, err := db.Exec("CREATE TABLE schema_migrations (version TEXT PRIMARY KEY, applied_at DATETIME DEFAULT CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		name = strings.TrimSuffix(name, ".sql")
+		if name >= version {
+			break
+		}
+		content, err := fs.ReadFile(migrationFiles, "migrations/"+name+".sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(content)); err != nil {
+			t.Fatalf("migration %s: %v", name, err)
+		}
+		if _, err := db.Exec("INSERT INTO schema_migrations (version) VALUES (?)", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnimeUniqueMigrationMergesDuplicates(t *testing.T) {
+	db := openTestDB(t)
+	applyMigrationsBefore(t, db, "012_add_media_anime_unique")
+
+	// Two shows for the same anime: the kept one has S01E01 pending, the duplicate
+	// has it downloaded plus S01E02 and a second season.
+	setup := []string{
+		`INSERT INTO tv_shows (id, status) VALUES (1, 'Running'), (2, 'Running')`,
+		`INSERT INTO seasons (id, show_id, season_number) VALUES (10, 1, 1), (20, 2, 1), (21, 2, 2)`,
+		`INSERT INTO episodes (id, season_id, episode_number, title, status) VALUES
+			(100, 10, 1, 'One', 'pending'),
+			(200, 20, 1, 'One', 'downloaded'),
+			(201, 20, 2, 'Two', 'pending'),
+			(210, 21, 1, 'Three', 'pending')`,
+		`INSERT INTO media (id, type, title, year, tv_show_id) VALUES
+			(1, 'anime', 'Frieren', 2023, 1),
+			(2, 'anime', 'Frieren', 2023, 2),
+			(3, 'anime', 'Other', 2023, NULL)`,
+		`INSERT INTO anime_search_terms (media_id, term) VALUES (1, 'Sousou no Frieren'), (2, 'Sousou no Frieren'), (2, 'Frieren Beyond')`,
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := RunMigrations(db, utils.NewLogger(false, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(query string, args ...interface{}) int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count("SELECT COUNT(*) FROM media WHERE type = 'anime'"); n != 2 {
+		t.Errorf("anime entries = %d, want 2", n)
+	}
+	if n := count("SELECT COUNT(*) FROM anime_search_terms WHERE media_id = 1"); n != 2 {
+		t.Errorf("search terms of kept entry = %d, want 2", n)
+	}
+	if n := count("SELECT COUNT(*) FROM anime_search_terms WHERE media_id = 2"); n != 0 {
+		t.Errorf("search terms of removed entry = %d, want 0", n)
+	}
+	if n := count("SELECT COUNT(*) FROM tv_shows"); n != 1 {
+		t.Errorf("tv shows = %d, want 1", n)
+	}
+	if n := count("SELECT COUNT(*) FROM seasons WHERE show_id = 1"); n != 2 {
+		t.Errorf("seasons of kept show = %d, want 2", n)
+	}
+	if n := count("SELECT COUNT(*) FROM episodes e JOIN seasons s ON e.season_id = s.id WHERE s.show_id = 1"); n != 3 {
+		t.Errorf("episodes of kept show = %d, want 3", n)
+	}
+	if n := count("SELECT COUNT(*) FROM episodes WHERE season_id NOT IN (SELECT id FROM seasons)"); n != 0 {
+		t.Errorf("orphaned episodes = %d, want 0", n)
+	}
+	var status string
+	if err := db.QueryRow("SELECT status FROM episodes WHERE id = 100").Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != "downloaded" {
+		t.Errorf("merged S01E01 status = %q, want downloaded", status)
+	}
+}
+
+func TestAnimeUniqueMigrationAdoptsDuplicateShow(t *testing.T) {
+	db := openTestDB(t)
+	applyMigrationsBefore(t, db, "012_add_media_anime_unique")
+
+	setup := []string{
+		`INSERT INTO tv_shows (id, status) VALUES (2, 'Ended')`,
+		`INSERT INTO seasons (id, show_id, season_number) VALUES (20, 2, 1)`,
+		`INSERT INTO episodes (id, season_id, episode_number, title, status) VALUES (200, 20, 1, 'One', 'downloaded')`,
+		`INSERT INTO media (id, type, title, year, tv_show_id) VALUES (1, 'anime', 'Mushishi', 2005, NULL), (2, 'anime', 'Mushishi', 2005, 2)`,
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := RunMigrations(db, utils.NewLogger(false, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+
+	var showID sql.NullInt64
+	if err := db.QueryRow("SELECT tv_show_id FROM media WHERE id = 1").Scan(&showID); err != nil {
+		t.Fatal(err)
+	}
+	if !showID.Valid || showID.Int64 != 2 {
+		t.Errorf("kept entry show = %v, want 2", showID)
+	}
+	var episodes int
+	if err := db.QueryRow("SELECT COUNT(*) FROM episodes WHERE season_id = 20").Scan(&episodes); err != nil {
+		t.Fatal(err)
+	}
+	if episodes != 1 {
+		t.Errorf("episodes of adopted show = %d, want 1", episodes)
+	}
+}
+
+func TestArchivedStatusMigrationKeepsReferences(t *testing.T) {
+	db := openTestDB(t)
+	applyMigrationsBefore(t, db, "021_allow_archived_status")
+
+	setup := []string{
+		`INSERT INTO media (id, type, title, year, status, monitored, metadata_provider, metadata_id) VALUES
+			(1, 'tvshow', 'Firefly', 2002, 'downloaded', 0, 'tvmaze', '180')`,
+		`INSERT INTO anime_search_terms (media_id, term) VALUES (1, 'Firefly 2002')`,
+		`INSERT INTO search_results (media_id, result_id, result, created_at) VALUES (1, 'r1', '{}', '2026-01-01')`,
+		`INSERT INTO release_history (media_id, event, release_title, created_at) VALUES (1, 'grabbed', 'Firefly.S01E01', '2026-01-01')`,
+		`INSERT INTO media_genres (media_id, genre) VALUES (1, 'Western')`,
+	}
+	for _, stmt := range setup {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := RunMigrations(db, utils.NewLogger(false, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec("UPDATE media SET status = 'archived' WHERE id = 1"); err != nil {
+		t.Fatalf("archiving after the migration: %v", err)
+	}
+	var monitored bool
+	var provider, metadataID string
+	if err := db.QueryRow("SELECT monitored, metadata_provider, metadata_id FROM media WHERE id = 1").Scan(&monitored, &provider, &metadataID); err != nil {
+		t.Fatal(err)
+	}
+	if monitored || provider != "tvmaze" || metadataID != "180" {
+		t.Errorf("copied row = monitored %t, %s %s; want false, tvmaze 180", monitored, provider, metadataID)
+	}
+	for _, table := range []string{"anime_search_terms", "search_results", "release_history", "media_genres"} {
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE media_id = 1").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("%s rows = %d, want 1", table, n)
+		}
+	}
+
+	// The foreign keys point at the new table
+	if _, err := db.Exec("DELETE FROM media WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	var left int
+	if err := db.QueryRow("SELECT COUNT(*) FROM media_genres").Scan(&left); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Errorf("%d genres left after deleting the media, want 0", left)
+	}
+}
+
+func TestNewSQLitePool(t *testing.T) {
+	tests := []struct {
+		name string
+		pool PoolConfig
+		want int
+	}{
+		{"default", PoolConfig{}, 1},
+		{"configured", PoolConfig{MaxOpenConns: 4}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewSQLite(filepath.Join(t.TempDir(), "reel.db"), tt.pool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if got := db.Stats().MaxOpenConnections; got != tt.want {
+				t.Errorf("MaxOpenConnections = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
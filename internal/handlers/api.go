@@ -2,17 +2,22 @@ package handlers
 
 import (
 	"bufio"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"reel/internal/clients/indexers"
+	"reel/internal/clients/metadata"
 	"reel/internal/config"
 	"reel/internal/core"
 	"reel/internal/database/models"
@@ -70,10 +75,185 @@ func (h *APIHandler) Login(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"token": token})
 }
 
+// checkWebhookToken validates the shared secret of an inbound webhook, read from the
+// X-Webhook-Token header, the Authorization header (with or without "Bearer ") or the
+// token query parameter, and writes the error response when it fails. The token is
+// read from the live config, so a new one applies as soon as the config is saved.
+func (h *APIHandler) checkWebhookToken(w http.ResponseWriter, r *http.Request) bool {
+	secret := h.manager.Config().App.WebhookToken
+	if secret == "" {
+		respondError(w, http.StatusNotFound, "Webhooks are disabled, set app.webhook_token to enable them")
+		return false
+	}
+
+	token := r.Header.Get("X-Webhook-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		respondError(w, http.StatusUnauthorized, "Invalid webhook token")
+		return false
+	}
+	return true
+}
+
+// TorrentCompleteHook lets a torrent client report a finished download, so that it is
+// post-processed right away instead of on the next status poll. The torrent hash is read
+// from the hash parameter or a JSON body.
+func (h *APIHandler) TorrentCompleteHook(w http.ResponseWriter, r *http.Request) {
+	if !h.checkWebhookToken(w, r) {
+		return
+	}
+
+	hash := r.FormValue("hash")
+	if hash == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var req struct {
+			Hash string `json:"hash"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		hash = req.Hash
+	}
+	hash = strings.TrimSpace(hash)
+	if hash == "" {
+		respondError(w, http.StatusBadRequest, "Torrent hash is required")
+		return
+	}
+
+	found, err := h.manager.TorrentCompleted(hash)
+	if err != nil {
+		h.logger.Error("Failed to look up completed torrent:", err)
+		respondError(w, http.StatusInternalServerError, "Failed to look up torrent")
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "No download in progress for this torrent")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, map[string]string{"status": "processing"})
+}
+
+// Overseerr and Jellyseerr notifications that mean a request should be fulfilled
+var approvedRequestNotifications = map[string]bool{
+	"MEDIA_APPROVED":      true,
+	"MEDIA_AUTO_APPROVED": true,
+}
+
+// Overseerr's subject is the requested title with its year, e.g. "Dune (2021)"
+var requestSubjectRegex = regexp.MustCompile(`^(.+?)\s+\((\d{4})\)$`)
+
+// RequestHook adds the media approved in Overseerr or Jellyseerr, from the payload of
+// their webhook notification agent. Movies are looked up by TMDB ID, shows by title and
+// year, starting at the lowest requested season. Other notifications are acknowledged
+// and ignored.
+func (h *APIHandler) RequestHook(w http.ResponseWriter, r *http.Request) {
+	if !h.checkWebhookToken(w, r) {
+		return
+	}
+
+	var req struct {
+		NotificationType string `json:"notification_type"`
+		Subject          string `json:"subject"`
+		Media            *struct {
+			MediaType string      `json:"media_type"`
+			TMDBID    json.Number `json:"tmdbId"`
+		} `json:"media"`
+		Extra []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"extra"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !approvedRequestNotifications[req.NotificationType] {
+		h.logger.Debug("Ignoring request webhook notification:", req.NotificationType)
+		respondJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+		return
+	}
+	if req.Media == nil {
+		respondError(w, http.StatusBadRequest, "Request has no media")
+		return
+	}
+
+	title, year := strings.TrimSpace(req.Subject), 0
+	if match := requestSubjectRegex.FindStringSubmatch(title); match != nil {
+		title = match[1]
+		year, _ = strconv.Atoi(match[2])
+	}
+
+	var mediaType models.MediaType
+	var id, provider string
+	startSeason := 0
+	switch req.Media.MediaType {
+	case "movie":
+		mediaType = models.MediaTypeMovie
+		id, provider = req.Media.TMDBID.String(), "tmdb"
+		if id == "" && title == "" {
+			respondError(w, http.StatusBadRequest, "Movie request needs a tmdbId or a subject")
+			return
+		}
+	case "tv":
+		mediaType = models.MediaTypeTVShow
+		if title == "" {
+			respondError(w, http.StatusBadRequest, "TV request needs a subject")
+			return
+		}
+		for _, extra := range req.Extra {
+			if extra.Name != "Requested Seasons" {
+				continue
+			}
+			for _, field := range strings.Split(extra.Value, ",") {
+				season, err := strconv.Atoi(strings.TrimSpace(field))
+				if err != nil {
+					respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid requested season '%s'", field))
+					return
+				}
+				if startSeason == 0 || season < startSeason {
+					startSeason = season
+				}
+			}
+		}
+	default:
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported media type '%s'", req.Media.MediaType))
+		return
+	}
+
+	h.logger.Info("Request webhook: adding", mediaType, title, year)
+	media, existing, err := h.manager.AddMedia(core.AddMediaOptions{
+		Type:         mediaType,
+		ID:           id,
+		Provider:     provider,
+		Title:        title,
+		Year:         year,
+		AutoDownload: true,
+		StartSeason:  startSeason,
+	})
+	if err != nil {
+		h.logger.Error("Failed to add requested media - Title:", title, "Error:", err)
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := "added"
+	if existing {
+		status = "exists"
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"status": status, "media": media})
+}
+
 // Get all media
 func (h *APIHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
 
-	media, err := h.manager.GetAllMedia()
+	media, err := h.manager.GetAllMedia(strings.TrimSpace(r.URL.Query().Get("genre")))
 	if err != nil {
 		h.logger.Error("CRITICAL: Failed to fetch media from manager:", err)
 		respondError(w, http.StatusInternalServerError, "Failed to fetch media")
@@ -94,16 +274,23 @@ func (h *APIHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
 // Add new media
 func (h *APIHandler) AddMedia(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Type         string `json:"type"`
-		Title        string `json:"title"`
-		Year         int    `json:"year"`
-		ID           string `json:"id"`
-		Language     string `json:"language"`
-		MinQuality   string `json:"min_quality"`
-		MaxQuality   string `json:"max_quality"`
-		AutoDownload bool   `json:"auto_download"`
-		StartSeason  int    `json:"start_season"`
-		StartEpisode int    `json:"start_episode"`
+		Type           string `json:"type"`
+		Title          string `json:"title"`
+		Year           int    `json:"year"`
+		ID             string `json:"id"`
+		Provider       string `json:"provider"`
+		URL            string `json:"url"`
+		Language       string `json:"language"`
+		MinQuality     string `json:"min_quality"`
+		MaxQuality     string `json:"max_quality"`
+		Profile        string `json:"quality_profile"`
+		AutoDownload   bool   `json:"auto_download"`
+		DateBased      bool   `json:"date_based"`
+		Specials       bool   `json:"include_specials"`
+		StartSeason    int    `json:"start_season"`
+		StartEpisode   int    `json:"start_episode"`
+		MonitorFromNow bool   `json:"monitor_from_now"`
+		MonitorMode    string `json:"monitor_mode"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -115,6 +302,36 @@ func (h *APIHandler) AddMedia(w http.ResponseWriter, r *http.Request) {
 	// Log the request for debugging
 	h.logger.Info("Adding media request:", req.Type, req.Title, req.Year)
 
+	// A metadata link stands for the provider and ID of the title it points to
+	if req.URL != "" {
+		link, err := metadata.ParseLink(req.URL)
+		if err == nil && link == nil {
+			err = fmt.Errorf("'%s' is not a TMDB, IMDb, Trakt or AniList link", req.URL)
+		}
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		result, err := h.manager.ResolveMetadataLink(link, req.Type)
+		if err != nil {
+			h.logger.Error("Metadata link lookup failed:", err)
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		switch resolved := result.(type) {
+		case *metadata.MovieResult:
+			req.ID, req.Provider = resolved.ID, resolved.Provider
+			if req.Title == "" {
+				req.Title, req.Year = resolved.Title, resolved.Year
+			}
+		case *metadata.TVShowResult:
+			req.ID, req.Provider = resolved.ID, resolved.Provider
+			if req.Title == "" {
+				req.Title, req.Year = resolved.Title, resolved.Year
+			}
+		}
+	}
+
 	// Validate required fields
 	if req.Type == "" || req.Title == "" {
 		h.logger.Error("Missing required fields - Type:", req.Type, "Title:", req.Title)
@@ -123,12 +340,30 @@ func (h *APIHandler) AddMedia(w http.ResponseWriter, r *http.Request) {
 	}
 
 	mediaType := models.MediaType(req.Type)
+	if req.MonitorFromNow {
+		req.MonitorMode = config.MonitorFuture
+	}
 
 	// Add detailed logging before the database operation
 	h.logger.Info("Creating media with type:", mediaType, "title:", req.Title)
 
-	media, err := h.manager.AddMedia(mediaType, req.ID, req.Title, req.Year,
-		req.Language, req.MinQuality, req.MaxQuality, req.AutoDownload, req.StartSeason, req.StartEpisode)
+	media, existing, err := h.manager.AddMedia(core.AddMediaOptions{
+		Type:            mediaType,
+		ID:              req.ID,
+		Provider:        req.Provider,
+		Title:           req.Title,
+		Year:            req.Year,
+		Language:        req.Language,
+		MinQuality:      req.MinQuality,
+		MaxQuality:      req.MaxQuality,
+		QualityProfile:  req.Profile,
+		AutoDownload:    req.AutoDownload,
+		DateBased:       req.DateBased,
+		IncludeSpecials: req.Specials,
+		StartSeason:     req.StartSeason,
+		StartEpisode:    req.StartEpisode,
+		MonitorMode:     req.MonitorMode,
+	})
 
 	if err != nil {
 		// Log the full error details
@@ -145,6 +380,11 @@ func (h *APIHandler) AddMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if existing {
+		respondJSON(w, http.StatusOK, media)
+		return
+	}
+
 	h.logger.Info("Successfully added media:", media.Title, "ID:", media.ID)
 	respondJSON(w, http.StatusCreated, media)
 }
@@ -193,6 +433,23 @@ func (h *APIHandler) SearchMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A pasted link gives just the title it points to, to confirm before adding it
+	link, err := metadata.ParseLink(query)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if link != nil {
+		result, err := h.manager.ResolveMetadataLink(link, mediaType)
+		if err != nil {
+			h.logger.Error("Metadata link lookup failed:", err)
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, []interface{}{result})
+		return
+	}
+
 	results, err := h.manager.SearchMetadata(query, mediaType)
 	if err != nil {
 		h.logger.Error("Metadata search failed:", err)
@@ -213,6 +470,36 @@ func (h *APIHandler) GetSystemStatus(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, status)
 }
 
+// RefreshSystemStatus rechecks the torrent client and indexers right away.
+func (h *APIHandler) RefreshSystemStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.manager.RefreshSystemStatus()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to refresh system status")
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
+
+// Liveness probe: the process is up and the database answers
+func (h *APIHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	if err := h.manager.CheckDatabase(); err != nil {
+		h.logger.Error("Health check failed, database unreachable:", err)
+		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "unhealthy", "database": false})
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "database": true})
+}
+
+// Readiness probe: the torrent client and at least one indexer are reachable
+func (h *APIHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	status := h.manager.CheckReadiness()
+	code := http.StatusOK
+	if !status.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	respondJSON(w, code, status)
+}
+
 // Test connections
 func (h *APIHandler) TestIndexer(w http.ResponseWriter, r *http.Request) {
 	indexerKey := r.URL.Query().Get("indexer")
@@ -250,6 +537,42 @@ func (h *APIHandler) ClearFailed(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// SearchPending queues all pending and monitored media for an immediate search.
+func (h *APIHandler) SearchPending(w http.ResponseWriter, r *http.Request) {
+	queued, err := h.manager.SearchAllPending()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to queue pending media")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]int{"queued": queued})
+}
+
+// GetRecent lists recently added media or recently downloaded movies and episodes
+func (h *APIHandler) GetRecent(w http.ResponseWriter, r *http.Request) {
+	kind := r.URL.Query().Get("type")
+	if kind == "" {
+		kind = "downloaded"
+	}
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+	}
+
+	items, err := h.manager.GetRecent(kind, limit)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if items == nil {
+		items = []models.RecentItem{}
+	}
+	respondJSON(w, http.StatusOK, items)
+}
+
 func (h *APIHandler) ManualSearch(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
@@ -258,13 +581,72 @@ func (h *APIHandler) ManualSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := h.manager.PerformSearch(id)
+	// A dry run shows what the automatic search would grab, without downloading it
+	if r.URL.Query().Get("dry_run") == "true" {
+		grabs, err := h.manager.DryRunSearch(id)
+		if err != nil {
+			respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{"grabs": grabs})
+		return
+	}
+
+	results, rejected, err := h.manager.PerformSearch(id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
-	respondJSON(w, http.StatusOK, results)
+	respondSearchResults(w, r, results, rejected)
+}
+
+// respondSearchResults writes the manual search results. With ?include_rejected=true the
+// response is an object that also lists the dropped results and why they were rejected.
+func respondSearchResults(w http.ResponseWriter, r *http.Request, results []indexers.IndexerResult, rejected []core.RejectedResult) {
+	for i := range results {
+		release := utils.ParseReleaseName(results[i].Title)
+		results[i].Release = &release
+	}
+	for i := range rejected {
+		release := utils.ParseReleaseName(rejected[i].Title)
+		rejected[i].Release = &release
+	}
+
+	if r.URL.Query().Get("include_rejected") != "true" {
+		respondJSON(w, http.StatusOK, results)
+		return
+	}
+
+	if results == nil {
+		results = []indexers.IndexerResult{}
+	}
+	if rejected == nil {
+		rejected = []core.RejectedResult{}
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"results":  results,
+		"rejected": rejected,
+	})
+}
+
+// resolveSearchResult replaces a download request that only carries a search result ID
+// with the stored result, writing an error response if that fails.
+func (h *APIHandler) resolveSearchResult(w http.ResponseWriter, mediaID int, req indexers.IndexerResult) (indexers.IndexerResult, bool) {
+	if req.ID == "" && req.DownloadURL == "" {
+		respondError(w, http.StatusBadRequest, "Either ID or DownloadURL is required")
+		return req, false
+	}
+	result, found, err := h.manager.ResolveSearchResult(mediaID, req)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return req, false
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "Search result not found or expired, search again")
+		return req, false
+	}
+	return result, true
 }
 
 func (h *APIHandler) ManualDownload(w http.ResponseWriter, r *http.Request) {
@@ -280,8 +662,12 @@ func (h *APIHandler) ManualDownload(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	req, ok := h.resolveSearchResult(w, id, req)
+	if !ok {
+		return
+	}
 
-	if err := h.manager.StartDownload(id, req); err != nil {
+	if err := h.manager.StartDownload(r.Context(), id, req); err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -289,6 +675,66 @@ func (h *APIHandler) ManualDownload(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// maxTorrentFileSize bounds the .torrent uploads accepted by AddTorrent.
+const maxTorrentFileSize = 10 << 20
+
+// AddTorrent downloads a release the user found themselves, sent either as a multipart
+// form (a "torrent" file or a "magnet" field, plus "season" and "episode" for shows) or
+// as a JSON body with the same fields minus the file.
+func (h *APIHandler) AddTorrent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+
+	var req struct {
+		Magnet  string `json:"magnet"`
+		Season  int    `json:"season"`
+		Episode int    `json:"episode"`
+	}
+	var torrentFile []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxTorrentFileSize); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid form data")
+			return
+		}
+		req.Magnet = r.FormValue("magnet")
+		for field, target := range map[string]*int{"season": &req.Season, "episode": &req.Episode} {
+			if value := r.FormValue(field); value != "" {
+				if *target, err = strconv.Atoi(value); err != nil {
+					respondError(w, http.StatusBadRequest, "Invalid "+field+" number")
+					return
+				}
+			}
+		}
+		if file, _, err := r.FormFile("torrent"); err == nil {
+			defer file.Close()
+			if torrentFile, err = ioutil.ReadAll(io.LimitReader(file, maxTorrentFileSize)); err != nil {
+				respondError(w, http.StatusBadRequest, "Failed to read torrent file")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := core.ManualTorrentResult(req.Magnet, torrentFile)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.manager.AddManualTorrent(r.Context(), id, req.Season, req.Episode, result); err != nil {
+		h.logger.Error("Manual torrent download failed:", err)
+		respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "download started", "title": result.Title})
+}
+
 func (h *APIHandler) GetTVShowDetails(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
@@ -336,15 +782,37 @@ func (h *APIHandler) EpisodeSearch(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info(fmt.Sprintf("Manual episode search requested for media %d S%02dE%02d", mediaID, season, episode))
 
-	results, err := h.manager.PerformEpisodeSearch(mediaID, season, episode)
+	results, rejected, err := h.manager.PerformEpisodeSearch(mediaID, season, episode)
 	if err != nil {
 		h.logger.Error("Episode search failed:", err)
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 
 	h.logger.Info(fmt.Sprintf("Episode search completed: found %d results", len(results)))
-	respondJSON(w, http.StatusOK, results)
+	respondSearchResults(w, r, results, rejected)
+}
+
+// SeasonDownload searches for and downloads every missing episode of a season
+func (h *APIHandler) SeasonDownload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	mediaID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+	season, err := strconv.Atoi(vars["season"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid season number")
+		return
+	}
+
+	summary, err := h.manager.DownloadSeason(mediaID, season)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, summary)
 }
 
 // Manual download for a specific episode
@@ -376,11 +844,15 @@ func (h *APIHandler) EpisodeDownload(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	req, ok := h.resolveSearchResult(w, mediaID, req)
+	if !ok {
+		return
+	}
 
 	h.logger.Info(fmt.Sprintf("Manual episode download requested for media %d S%02dE%02d: %s",
 		mediaID, season, episode, req.Title))
 
-	if err := h.manager.StartEpisodeDownload(mediaID, season, episode, req); err != nil {
+	if err := h.manager.StartEpisodeDownload(r.Context(), mediaID, season, episode, req); err != nil {
 		h.logger.Error("Episode download failed:", err)
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -442,6 +914,70 @@ func (h *APIHandler) GetEpisodeDetails(w http.ResponseWriter, r *http.Request) {
 	respondError(w, http.StatusNotFound, "Episode not found")
 }
 
+// EpisodeHistory lists the releases grabbed and blacklisted for an episode.
+func (h *APIHandler) EpisodeHistory(w http.ResponseWriter, r *http.Request) {
+	mediaID, season, episode, ok := episodeVars(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.manager.GetEpisodeHistory(mediaID, season, episode)
+	if err != nil {
+		respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, history)
+}
+
+// EpisodeRedownload blacklists the episode's current release and searches for it again,
+// returning the new results like the episode search.
+func (h *APIHandler) EpisodeRedownload(w http.ResponseWriter, r *http.Request) {
+	mediaID, season, episode, ok := episodeVars(w, r)
+	if !ok {
+		return
+	}
+
+	results, rejected, err := h.manager.RedownloadEpisode(mediaID, season, episode)
+	if err != nil {
+		status := errorStatus(err, http.StatusInternalServerError)
+		if errors.Is(err, core.ErrNoReleaseToReplace) {
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+	respondSearchResults(w, r, results, rejected)
+}
+
+// episodeVars parses the media ID, season and episode of an episode route, writing an
+// error response if one is invalid.
+func episodeVars(w http.ResponseWriter, r *http.Request) (mediaID, season, episode int, ok bool) {
+	vars := mux.Vars(r)
+	var err error
+	if mediaID, err = strconv.Atoi(vars["id"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return 0, 0, 0, false
+	}
+	if season, err = strconv.Atoi(vars["season"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid season number")
+		return 0, 0, 0, false
+	}
+	if episode, err = strconv.Atoi(vars["episode"]); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid episode number")
+		return 0, 0, 0, false
+	}
+	return mediaID, season, episode, true
+}
+
+// errorStatus is the response status of an error from the manager: 404 when the media
+// item or episode doesn't exist, otherwise status.
+func errorStatus(err error, status int) int {
+	if errors.Is(err, models.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return status
+}
+
 // StreamVideo handles serving the video file for playback.
 func (h *APIHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -464,6 +1000,70 @@ func (h *APIHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, filePath)
 }
 
+// GetPoster serves a media item's poster from the local cache when app.proxy_images is
+// enabled, so browsers never contact the image CDNs, and redirects to it otherwise.
+func (h *APIHandler) GetPoster(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	mediaID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+
+	posterURL, path, found, err := h.manager.GetPoster(mediaID)
+	if errors.Is(err, core.ErrPosterUnavailable) {
+		h.logger.Error("Failed to get poster for media", mediaID, ":", err)
+		respondError(w, http.StatusBadGateway, "Failed to get poster")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get poster for media", mediaID, ":", err)
+		respondError(w, http.StatusInternalServerError, "Failed to get media")
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "Media has no poster")
+		return
+	}
+	if path == "" {
+		http.Redirect(w, r, posterURL, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=604800")
+	http.ServeFile(w, r, path)
+}
+
+// RefreshMetadata fetches a media item's metadata right away and returns the updated item.
+func (h *APIHandler) RefreshMetadata(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	mediaID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+
+	media, found, err := h.manager.RefreshMetadata(mediaID)
+	if errors.Is(err, core.ErrRefreshInProgress) {
+		respondError(w, http.StatusConflict, "A metadata refresh of this item is already running")
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to refresh metadata for media", mediaID, ":", err)
+		status := http.StatusBadGateway // The metadata provider failed
+		if !found {
+			status = http.StatusInternalServerError
+		}
+		respondError(w, status, "Failed to refresh metadata: "+err.Error())
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "Media not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, media)
+}
+
 // GetSubtitles handles finding, converting, and serving the subtitle file.
 func (h *APIHandler) GetSubtitles(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -519,7 +1119,6 @@ func (h *APIHandler) GetSubtitles(w http.ResponseWriter, r *http.Request) {
 	// Set headers for proper caching and content type
 	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
 	w.Header().Set("Cache-Control", "public, max-age=3600") // Cache for 1 hour
-	w.Header().Set("Access-Control-Allow-Origin", "*")      // Allow CORS for subtitles
 
 	// Serve the converted content
 	http.ServeContent(w, r, "subtitles.vtt", time.Now(), vttContent)
@@ -560,9 +1159,13 @@ func (h *APIHandler) UpdateMediaSettings(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req struct {
-		MinQuality   string `json:"min_quality"`
-		MaxQuality   string `json:"max_quality"`
-		AutoDownload bool   `json:"auto_download"`
+		MinQuality   string  `json:"min_quality"`
+		MaxQuality   string  `json:"max_quality"`
+		AutoDownload bool    `json:"auto_download"`
+		Monitored    *bool   `json:"monitored"`
+		Profile      *string `json:"quality_profile"`
+		DateBased    *bool   `json:"date_based"`
+		Specials     *bool   `json:"include_specials"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -570,8 +1173,12 @@ func (h *APIHandler) UpdateMediaSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.manager.UpdateMediaSettings(id, req.MinQuality, req.MaxQuality, req.AutoDownload); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to update settings")
+	if err := h.manager.UpdateMediaSettings(id, req.MinQuality, req.MaxQuality, req.AutoDownload, req.Monitored, req.Profile, req.DateBased, req.Specials); err != nil {
+		if errors.Is(err, core.ErrInvalidSetting) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, errorStatus(err, http.StatusInternalServerError), "Failed to update settings")
 		return
 	}
 
@@ -589,6 +1196,59 @@ func (h *APIHandler) GetConfig(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(configContent))
 }
 
+// GetQualityProfiles lists the configured quality profiles for the add-media form.
+func (h *APIHandler) GetQualityProfiles(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.manager.GetQualityProfiles())
+}
+
+// GetSettings returns the editable settings as JSON.
+func (h *APIHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, h.manager.GetSettings())
+}
+
+// UpdateSettings applies a partial settings update and returns the resulting settings.
+func (h *APIHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Could not read request body")
+		return
+	}
+
+	settings, err := h.manager.UpdateSettings(body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Failed to update settings: %v", err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+// PauseMedia stops automatic searching for a media item without deleting it.
+func (h *APIHandler) PauseMedia(w http.ResponseWriter, r *http.Request) {
+	h.setMonitored(w, r, false)
+}
+
+// ResumeMedia re-enables automatic searching for a paused media item.
+func (h *APIHandler) ResumeMedia(w http.ResponseWriter, r *http.Request) {
+	h.setMonitored(w, r, true)
+}
+
+func (h *APIHandler) setMonitored(w http.ResponseWriter, r *http.Request, monitored bool) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid media ID")
+		return
+	}
+
+	if err := h.manager.SetMonitored(id, monitored); err != nil {
+		respondError(w, errorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]bool{"monitored": monitored})
+}
+
 func (h *APIHandler) GetAnimeSearchTerms(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
@@ -676,6 +1336,53 @@ func (h *APIHandler) SaveConfig(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"status": "Configuration saved and reloaded successfully"})
 }
 
+// Default and largest number of lines returned by GetFilterLog
+const (
+	defaultFilterLogLines = 200
+	maxFilterLogLines     = 5000
+)
+
+// GetFilterLog returns the last lines of filter.log (?tail=N) and the current filter log level.
+func (h *APIHandler) GetFilterLog(w http.ResponseWriter, r *http.Request) {
+	tail := defaultFilterLogLines
+	if value := r.URL.Query().Get("tail"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "tail must be a positive number")
+			return
+		}
+		tail = min(n, maxFilterLogLines)
+	}
+
+	lines, err := h.manager.GetFilterLog(tail)
+	if err != nil {
+		h.logger.Error("Failed to read filter.log:", err)
+		respondError(w, http.StatusInternalServerError, "Failed to read filter log")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"level": h.manager.FilterLogLevel(),
+		"lines": lines,
+	})
+}
+
+// SetFilterLogLevel turns detailed filter logging on ("detail") or off ("none") at runtime.
+func (h *APIHandler) SetFilterLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.manager.SetFilterLogLevel(req.Level); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"level": h.manager.FilterLogLevel()})
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
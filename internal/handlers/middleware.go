@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Methods and headers allowed in cross-origin requests when the config lists none.
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type"}
+)
+
+// corsPreflightMaxAge is how long, in seconds, browsers may cache a preflight answer.
+const corsPreflightMaxAge = "600"
+
+type contextKey string
+
+const requestIDKey contextKey = "request_id"
+
+// maxRequestIDLength caps request IDs taken from the X-Request-ID header.
+const maxRequestIDLength = 64
+
+// unloggedPaths are long-lived requests (video streams, the log websocket) whose
+// duration says nothing about the API's speed.
+var unloggedPaths = []string{"/api/v1/stream/video/", "/api/v1/logs/ws"}
+
+// RequestID returns the ID the logging middleware assigned to the request, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// loggingMiddleware logs the method, path, status and duration of every request at
+// debug level. Each request gets an ID, taken from its X-Request-ID header when set,
+// which is stored in the request context and echoed in the response.
+func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range unloggedPaths {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestIDKey, requestID)))
+
+		s.logger.WithFields(map[string]interface{}{
+			"request_id":  requestID,
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      recorder.status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}).Debug("HTTP request")
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// corsMiddleware adds the CORS headers for requests from the origins in app.cors and
+// answers their preflight requests. Requests from other origins get no CORS headers,
+// so browsers keep them same-origin only. With credentials allowed, the origin is
+// echoed back instead of "*", as browsers require.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cors := s.manager.Config().App.CORS // Follows config reloads
+		origin := r.Header.Get("Origin")
+		allowAny, allowed := false, false
+		for _, allowedOrigin := range cors.AllowedOrigins {
+			if allowedOrigin == "*" {
+				allowAny = true
+			}
+			if allowedOrigin == "*" || strings.EqualFold(allowedOrigin, origin) {
+				allowed = true
+			}
+		}
+		if origin == "" || !allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		if allowAny && !cors.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		}
+		if cors.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		header.Set("Access-Control-Expose-Headers", "X-Request-ID")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			methods, headers := cors.AllowedMethods, cors.AllowedHeaders
+			if len(methods) == 0 {
+				methods = defaultCORSMethods
+			}
+			if len(headers) == 0 {
+				headers = defaultCORSHeaders
+			}
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			header.Set("Access-Control-Max-Age", corsPreflightMaxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
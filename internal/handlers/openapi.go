@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the hand-maintained OpenAPI 3 description of the API. Keep it in sync
+// with the routes in server.go (TestOpenAPIDocumentsEveryRoute checks it covers them)
+// and docs/api_endpoints.md.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI serves the OpenAPI description of the API.
+func (h *APIHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
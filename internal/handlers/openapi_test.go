@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reel/internal/config"
+	"reel/internal/utils"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// routeVariable matches the pattern of a mux path variable, e.g. the ":[0-9]+" of
+// "{id:[0-9]+}", which OpenAPI paths leave out.
+var routeVariable = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	server := &Server{config: &config.Config{}, logger: utils.NewLogger(false, io.Discard)}
+	routes := make(map[string]bool)
+	err := server.routes().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(template, "/api/v1/") || route.GetHandler() == nil {
+			return nil
+		}
+		path := routeVariable.ReplaceAllString(strings.TrimPrefix(template, "/api/v1"), "{$1}")
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{http.MethodGet} // The log websocket
+		}
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue // CORS preflights
+			}
+			routes[method+" "+path] = true
+			if _, documented := spec.Paths[path][strings.ToLower(method)]; !documented {
+				t.Errorf("%s %s is not in openapi.json", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if !routes[strings.ToUpper(method)+" "+path] {
+				t.Errorf("openapi.json describes %s %s, which no route serves", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
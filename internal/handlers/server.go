@@ -31,14 +31,45 @@ func NewServer(cfg *config.Config, manager *core.Manager, logger *utils.Logger)
 }
 
 func (s *Server) Start() error {
+	s.httpServer = &http.Server{
+		Addr:         fmt.Sprintf(":%d", s.config.App.Port),
+		Handler:      s.routes(),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	s.logger.Info("Starting server on port", s.config.App.Port)
+	return s.httpServer.ListenAndServe()
+}
+
+// routes returns the router of every endpoint. The API routes are described in
+// openapi.json, which a test checks against them.
+func (s *Server) routes() *mux.Router {
 	router := mux.NewRouter()
+	router.Use(s.loggingMiddleware)
+
+	// Health probes for container orchestration (unauthenticated)
+	router.HandleFunc("/healthz", s.apiHandler.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", s.apiHandler.Readyz).Methods("GET")
 
 	// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(s.corsMiddleware)
+	// Answers CORS preflights, which match no route's method otherwise
+	api.PathPrefix("/").Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
 
 	// Auth
 	api.HandleFunc("/login", s.apiHandler.Login).Methods("POST")
 
+	// Machine-readable description of the API
+	api.HandleFunc("/openapi.json", s.apiHandler.OpenAPI).Methods("GET")
+
+	// Inbound webhooks, authenticated with app.webhook_token
+	api.HandleFunc("/hooks/torrent-complete", s.apiHandler.TorrentCompleteHook).Methods("POST")
+	api.HandleFunc("/hooks/request", s.apiHandler.RequestHook).Methods("POST")
+
 	// Protected routes (add auth middleware in production)
 	protected := api.PathPrefix("").Subrouter()
 	// protected.Use(s.authMiddleware) // Implement JWT middleware
@@ -49,17 +80,28 @@ func (s *Server) Start() error {
 	protected.HandleFunc("/media/{id}/retry", s.apiHandler.RetryMedia).Methods("POST")
 	protected.HandleFunc("/media/{id}/search", s.apiHandler.ManualSearch).Methods("GET")
 	protected.HandleFunc("/media/{id}/download", s.apiHandler.ManualDownload).Methods("POST")
+	protected.HandleFunc("/media/{id}/add-torrent", s.apiHandler.AddTorrent).Methods("POST")
 	protected.HandleFunc("/media/{id}/tv-details", s.apiHandler.GetTVShowDetails).Methods("GET")
+	protected.HandleFunc("/media/{id}/poster", s.apiHandler.GetPoster).Methods("GET")
+	protected.HandleFunc("/media/{id}/refresh", s.apiHandler.RefreshMetadata).Methods("POST")
 	protected.HandleFunc("/media/{id}/settings", s.apiHandler.UpdateMediaSettings).Methods("POST") // <-- NEW ROUTE
+	protected.HandleFunc("/media/{id}/pause", s.apiHandler.PauseMedia).Methods("POST")
+	protected.HandleFunc("/media/{id}/resume", s.apiHandler.ResumeMedia).Methods("POST")
 	protected.HandleFunc("/media/clear-failed", s.apiHandler.ClearFailed).Methods("POST")
+	protected.HandleFunc("/search/pending", s.apiHandler.SearchPending).Methods("POST")
+	protected.HandleFunc("/recent", s.apiHandler.GetRecent).Methods("GET")
 	protected.HandleFunc("/search-metadata", s.apiHandler.SearchMetadata).Methods("GET")
 	protected.HandleFunc("/status", s.apiHandler.GetSystemStatus).Methods("GET")
+	protected.HandleFunc("/status/refresh", s.apiHandler.RefreshSystemStatus).Methods("POST")
 	protected.HandleFunc("/test/indexer", s.apiHandler.TestIndexer).Methods("GET")
 	protected.HandleFunc("/test/torrent", s.apiHandler.TestTorrent).Methods("GET")
 	// Episode-specific routes
+	protected.HandleFunc("/media/{id}/season/{season}/download", s.apiHandler.SeasonDownload).Methods("POST")
 	protected.HandleFunc("/media/{id}/season/{season}/episode/{episode}/search", s.apiHandler.EpisodeSearch).Methods("GET")
 	protected.HandleFunc("/media/{id}/season/{season}/episode/{episode}/download", s.apiHandler.EpisodeDownload).Methods("POST")
 	protected.HandleFunc("/media/{id}/season/{season}/episode/{episode}/details", s.apiHandler.GetEpisodeDetails).Methods("GET")
+	protected.HandleFunc("/media/{id}/season/{season}/episode/{episode}/history", s.apiHandler.EpisodeHistory).Methods("GET")
+	protected.HandleFunc("/media/{id}/season/{season}/episode/{episode}/redownload", s.apiHandler.EpisodeRedownload).Methods("POST")
 
 	// Streaming routes
 	protected.HandleFunc("/stream/video/{id}", s.apiHandler.StreamVideo).Methods("GET")
@@ -72,12 +114,19 @@ func (s *Server) Start() error {
 	// Config endpoint
 	protected.HandleFunc("/config", s.apiHandler.GetConfig).Methods("GET")
 	protected.HandleFunc("/config", s.apiHandler.SaveConfig).Methods("POST")
+	protected.HandleFunc("/quality-profiles", s.apiHandler.GetQualityProfiles).Methods("GET")
+	protected.HandleFunc("/settings", s.apiHandler.GetSettings).Methods("GET")
+	protected.HandleFunc("/settings", s.apiHandler.UpdateSettings).Methods("PATCH")
 
 	// Anime search term routes
 	protected.HandleFunc("/media/{id}/anime-search-terms", s.apiHandler.GetAnimeSearchTerms).Methods("GET")
 	protected.HandleFunc("/media/{id}/anime-search-terms", s.apiHandler.AddAnimeSearchTerm).Methods("POST")
 	protected.HandleFunc("/media/anime-search-terms/{term_id}", s.apiHandler.DeleteAnimeSearchTerm).Methods("DELETE")
 
+	// Filter log
+	protected.HandleFunc("/logs/filter", s.apiHandler.GetFilterLog).Methods("GET")
+	protected.HandleFunc("/logs/filter/level", s.apiHandler.SetFilterLogLevel).Methods("POST")
+
 	// Calendar route
 	protected.HandleFunc("/calendar", s.apiHandler.GetCalendar).Methods("GET")
 
@@ -89,15 +138,7 @@ func (s *Server) Start() error {
 	// Add the WebSocket route for logs
 	api.HandleFunc("/logs/ws", s.handleLogsWebsocket)
 
-	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf(":%d", s.config.App.Port),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-	}
-
-	s.logger.Info("Starting server on port", s.config.App.Port)
-	return s.httpServer.ListenAndServe()
+	return router
 }
 
 func (s *Server) Stop(ctx context.Context) error {
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent is sent to indexers and metadata providers when app.user_agent is not set.
+const DefaultUserAgent = "Reel/1.0 (+https://github.com/pixelotes/reel)"
+
+// Rate-limited (429) requests are retried up to rateLimitRetries times, waiting
+// for the server's Retry-After (or rateLimitBackoff, doubled on every attempt).
+// Longer waits than maxRetryAfter are not worth blocking a search for.
+const (
+	rateLimitRetries = 2
+	rateLimitBackoff = time.Second
+	maxRetryAfter    = 30 * time.Second
+)
+
+// Connection pool of the shared transports, see sharedTransport. Indexers and metadata
+// providers are called in bursts, so more idle connections per host are kept than the
+// Go default of 2.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 10
+	idleConnTimeout     = 90 * time.Second
+)
+
+// transports are the shared transports by proxy URL, "" for direct connections.
+var (
+	transportsMu sync.Mutex
+	transports   = make(map[string]*http.Transport)
+)
+
+// ParseProxyURL checks a proxy URL: http://, https:// or socks5:// (socks5h:// to
+// resolve names through the proxy), with an optional user:password. "" gives nil.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+	proxy, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s' (use http, https, socks5 or socks5h)", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("proxy URL '%s' has no host", proxy.Redacted())
+	}
+	return proxy, nil
+}
+
+// sharedTransport returns the transport of every client going through proxy (nil for a
+// direct connection, which still honors the HTTP_PROXY environment variables), so
+// they share one pool of kept-alive connections.
+func sharedTransport(proxy *url.URL) *http.Transport {
+	key := ""
+	if proxy != nil {
+		key = proxy.String()
+	}
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport
+	}
+	// The default transport's dialer keeps connections alive and it speaks HTTP/2
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	transports[key] = transport
+	return transport
+}
+
+// HTTPClientConfig holds the settings of a client created by NewHTTPClient.
+type HTTPClientConfig struct {
+	Timeout time.Duration     // 0 means none
+	Headers map[string]string // User-Agent, cookies, tokens...
+	Proxy   *url.URL          // nil connects directly, see ParseProxyURL
+}
+
+// NewHTTPClient returns an HTTP client that adds the configured headers to every
+// request that does not set them itself, sends its requests through the configured
+// proxy, if any, and retries requests the server rejects with 429 Too Many Requests.
+// Clients going through the same proxy share their connections.
+func NewHTTPClient(cfg HTTPClientConfig) *http.Client {
+	return newHTTPClient(cfg.Timeout, cfg.Headers, sharedTransport(cfg.Proxy))
+}
+
+// NewLocalHTTPClient returns an HTTP client for services on the user's own network,
+// such as download clients and FlareSolverr, which are never reached through a proxy.
+// Like NewHTTPClient's, it shares its connections and retries 429s.
+func NewLocalHTTPClient(timeout time.Duration) *http.Client {
+	return newHTTPClient(timeout, nil, sharedTransport(nil))
+}
+
+func newHTTPClient(timeout time.Duration, headers map[string]string, base http.RoundTripper) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &retryTransport{
+			base: &headerTransport{base: base, headers: headers},
+		},
+	}
+}
+
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := rateLimitBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= rateLimitRetries {
+			return resp, err
+		}
+		// A request with a body can only be sent again if it can be rewound.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			wait = backoff
+		}
+		if wait > maxRetryAfter {
+			return resp, nil
+		}
+		if deadline, hasDeadline := req.Context().Deadline(); hasDeadline && time.Until(deadline) < wait {
+			return resp, nil
+		}
+		backoff *= 2
+
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
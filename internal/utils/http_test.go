@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "direct")
+	}))
+	defer target.Close()
+
+	// A stub HTTP proxy, which receives the absolute URL of each request
+	var proxied []string
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		io.WriteString(w, "proxied")
+	}))
+	defer stub.Close()
+	stubURL, err := url.Parse(stub.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		proxy *url.URL
+		want  string
+	}{
+		{"direct", nil, "direct"},
+		{"through the proxy", stubURL, "proxied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxied = nil
+			client := NewHTTPClient(HTTPClientConfig{Proxy: tt.proxy})
+			resp, err := client.Get(target.URL + "/api?t=search")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != tt.want {
+				t.Fatalf("got %q, want %q", body, tt.want)
+			}
+			if tt.proxy != nil && (len(proxied) != 1 || proxied[0] != target.URL+"/api?t=search") {
+				t.Errorf("proxy received %v, want the request to %s", proxied, target.URL)
+			}
+		})
+	}
+}
+
+func TestRateLimitRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		limited      int    // Requests answered with 429 before a 200
+		retryAfter   string // Retry-After of the 429s
+		body         string // Request body, resent on every attempt
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "429 then 200", limited: 1, retryAfter: "0", wantStatus: http.StatusOK, wantAttempts: 2},
+		{name: "body is resent", limited: 2, retryAfter: "0", body: "hash=abc", wantStatus: http.StatusOK, wantAttempts: 3},
+		{name: "gives up after the retries", limited: 10, retryAfter: "0", wantStatus: http.StatusTooManyRequests, wantAttempts: rateLimitRetries + 1},
+		{name: "too long a wait is not waited for", limited: 1, retryAfter: "3600", wantStatus: http.StatusTooManyRequests, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if body, _ := io.ReadAll(r.Body); string(body) != tt.body {
+					t.Errorf("attempt %d sent body %q, want %q", attempts, body, tt.body)
+				}
+				if attempts <= tt.limited {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				io.WriteString(w, "ok")
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(HTTPClientConfig{Timeout: 5 * time.Second})
+			var resp *http.Response
+			var err error
+			if tt.body != "" {
+				resp, err = client.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader(tt.body))
+			} else {
+				resp, err = client.Get(server.URL)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("%d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true}, // In the past
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := retryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %v, %t, want %v, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package utils
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,13 +21,17 @@ type Logger struct {
 	err   *log.Logger
 	fatal *log.Logger
 	out   io.Writer
+
+	// fields are attached to every entry written by this logger.
+	fields map[string]interface{}
 }
 
 // LogEntry defines the structure for a JSON log entry.
 type LogEntry struct {
-	Timestamp string `json:"timestamp"`
-	Level     string `json:"level"`
-	Message   string `json:"message"`
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
 // NewLogger creates a new logger instance for structured JSON logging.
@@ -45,12 +50,33 @@ func NewLogger(debug bool, out io.Writer) *Logger {
 	}
 }
 
+// WithFields returns a sub-logger that adds the given fields to every entry it writes.
+// Fields are merged with any fields already set on l; the new values win on conflict.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	sub := *l
+	sub.fields = merged
+	return &sub
+}
+
+// WithField is a shorthand for WithFields with a single key.
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
 // writeJSONLog creates and writes a JSON log entry.
 func (l *Logger) writeJSONLog(logger *log.Logger, level string, v ...interface{}) {
 	entry := LogEntry{
 		Timestamp: time.Now().Format(time.RFC3339),
 		Level:     level,
 		Message:   formatMessage(v...),
+		Fields:    l.fields,
 	}
 	jsonData, err := json.Marshal(entry)
 	if err != nil {
@@ -85,10 +111,14 @@ func formatInterface(val interface{}) string {
 	}
 }
 
+// FilterLogName is the file name of the filter log in the data path.
+const FilterLogName = "filter.log"
+
 // NewFilterLogger creates a dedicated logger that writes to filter.log with a size limit.
-func NewFilterLogger(dataPath string) (*log.Logger, error) {
+// The returned file must be closed once the logger is no longer used.
+func NewFilterLogger(dataPath string) (*log.Logger, io.Closer, error) {
 	const maxLogSize = 5 * 1024 * 1024 // 5 MB
-	logFilePath := filepath.Join(dataPath, "filter.log")
+	logFilePath := filepath.Join(dataPath, FilterLogName)
 
 	fileInfo, err := os.Stat(logFilePath)
 	openFlags := os.O_APPEND | os.O_CREATE | os.O_WRONLY
@@ -101,10 +131,30 @@ func NewFilterLogger(dataPath string) (*log.Logger, error) {
 
 	file, err := os.OpenFile(logFilePath, openFlags, 0666)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return log.New(file, "", log.LstdFlags), nil
+	return log.New(file, "", log.LstdFlags), file, nil
+}
+
+// TailFile returns the last n lines of a file.
+func TailFile(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
 }
 
 // Debug logs a debug message.
@@ -4,17 +4,94 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
 )
 
+// ParseTorrentFile checks that data is a valid .torrent file and returns the
+// torrent's name and total size.
+func ParseTorrentFile(data []byte) (name string, size int64, err error) {
+	mi, err := metainfo.Load(bytes.NewReader(data))
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid torrent file: %w", err)
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid torrent info: %w", err)
+	}
+	return info.BestName(), info.TotalLength(), nil
+}
+
+// ParseMagnet checks that uri is a valid magnet link and returns its display
+// name, which is empty when the link has no "dn" parameter.
+func ParseMagnet(uri string) (string, error) {
+	magnet, err := metainfo.ParseMagnetUri(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid magnet link: %w", err)
+	}
+	return magnet.DisplayName, nil
+}
+
+// InfoHash returns the lowercase hex info hash of a .torrent file or, without one, of a
+// magnet link, so a release can be identified before it is sent to the download client.
+// It returns "" when neither gives one, e.g. for an HTTP download URL.
+func InfoHash(downloadURL string, torrentFile []byte) string {
+	if len(torrentFile) > 0 {
+		mi, err := metainfo.Load(bytes.NewReader(torrentFile))
+		if err != nil {
+			return ""
+		}
+		return mi.HashInfoBytes().HexString()
+	}
+	if strings.HasPrefix(downloadURL, "magnet:") {
+		if magnet, err := metainfo.ParseMagnetUri(downloadURL); err == nil {
+			return magnet.InfoHash.HexString()
+		}
+	}
+	return ""
+}
+
+// magnetTempDir is the directory under the data path that holds the per-conversion
+// directories of ConvertMagnetToTorrent.
+const magnetTempDir = "magnet-tmp"
+
 // ConvertMagnetToTorrent fetches torrent metadata from a magnet link with a specified timeout.
-func ConvertMagnetToTorrent(magnetURI string, timeout time.Duration, dataPath string, logger *Logger) ([]byte, error) {
+// The torrent client works in its own directory under dataPath, which is removed along
+// with any piece or metadata files once the conversion ends. HTTP trackers and web
+// seeds are reached through proxy, if set.
+func ConvertMagnetToTorrent(magnetURI string, timeout time.Duration, dataPath string, proxy *url.URL, logger *Logger) ([]byte, error) {
+	tempRoot := filepath.Join(dataPath, magnetTempDir)
+	if err := os.MkdirAll(tempRoot, 0755); err != nil {
+		return nil, fmt.Errorf("error creating magnet temp directory: %w", err)
+	}
+	tempDir, err := os.MkdirTemp(tempRoot, "convert-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating magnet temp directory: %w", err)
+	}
+	// Deferred first so it runs after the client is closed and its files are released
+	defer func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			logger.Warn("Failed to remove magnet temp directory", tempDir+":", err)
+		}
+	}()
+
 	cfg := torrent.NewDefaultClientConfig()
 	cfg.NoUpload = true // We are only interested in metadata
 	cfg.DisablePEX = true
-	cfg.DataDir = dataPath
+	cfg.DataDir = tempDir
+	cfg.ListenPort = 0 // Any free port, so that conversions can run side by side
+	// HTTP trackers and web seeds can go through the proxy; DHT, UDP trackers and the
+	// peers themselves are reached directly
+	if proxy != nil {
+		cfg.HTTPProxy = http.ProxyURL(proxy)
+	}
 
 	client, err := torrent.NewClient(cfg)
 	if err != nil {
@@ -28,6 +105,7 @@ func ConvertMagnetToTorrent(magnetURI string, timeout time.Duration, dataPath st
 		logger.Error("Error adding magnet:", err)
 		return nil, fmt.Errorf("error adding magnet: %w", err)
 	}
+	defer t.Drop()
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// seedMagnet starts a local client seeding a small file and returns a magnet link
+// that points at it, so metadata can be fetched without the network.
+func seedMagnet(t *testing.T) string {
+	t.Helper()
+	seedDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(seedDir, "Heat.1995.1080p.mkv"), []byte(strings.Repeat("movie", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info := metainfo.Info{PieceLength: 16 << 10}
+	if err := info.BuildFromFilePath(filepath.Join(seedDir, "Heat.1995.1080p.mkv")); err != nil {
+		t.Fatal(err)
+	}
+	mi := metainfo.MetaInfo{}
+	var err error
+	if mi.InfoBytes, err = bencode.Marshal(info); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := torrent.NewDefaultClientConfig()
+	cfg.DataDir = seedDir
+	cfg.Seed = true
+	cfg.NoDHT = true
+	cfg.ListenPort = 0
+	seeder, err := torrent.NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { seeder.Close() })
+	if _, err := seeder.AddTorrent(&mi); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := mi.HashInfoBytes()
+	return fmt.Sprintf("%s&x.pe=127.0.0.1:%d", mi.Magnet(&hash, &info).String(), seeder.LocalPort())
+}
+
+func TestConvertMagnetToTorrentCleansUp(t *testing.T) {
+	logger := NewLogger(false, io.Discard)
+	tests := []struct {
+		name    string
+		magnet  func(t *testing.T) string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "metadata fetched", magnet: seedMagnet, timeout: 30 * time.Second},
+		{
+			name:    "timed out",
+			magnet:  func(t *testing.T) string { return "magnet:?xt=urn:btih:" + strings.Repeat("1", 40) },
+			timeout: 100 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataPath := t.TempDir()
+			data, err := ConvertMagnetToTorrent(tt.magnet(t), tt.timeout, dataPath, nil, logger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertMagnetToTorrent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if name, _, err := ParseTorrentFile(data); err != nil || name != "Heat.1995.1080p.mkv" {
+					t.Errorf("converted torrent name = %q (%v), want the seeded file", name, err)
+				}
+			}
+			entries, err := os.ReadDir(filepath.Join(dataPath, magnetTempDir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("magnet temp directory holds %d entries after the conversion, want none", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ReleaseInfo is what a release name tells about its quality, see ParseReleaseName.
+type ReleaseInfo struct {
+	Resolution string   `json:"resolution,omitempty"` // "2160p", "1080p", ...; only when the name gives one
+	Source     string   `json:"source,omitempty"`     // "BluRay", "WEB-DL", "HDTV", ...
+	Codec      string   `json:"codec,omitempty"`      // "H.265", "H.264", "AV1", ...
+	Audio      []string `json:"audio,omitempty"`      // "DTS-HD", "Atmos", "DD+", ...
+	HDR        []string `json:"hdr,omitempty"`        // "HDR", "HDR10", "HDR10+", "DV", "HLG"
+	Group      string   `json:"group,omitempty"`
+	Origin     string   `json:"origin,omitempty"`  // "INTERNAL", "SCENE" or "P2P", when the name says
+	Proper     bool     `json:"proper,omitempty"`  // A PROPER or REPACK, fixing an earlier release
+	Edition    []string `json:"edition,omitempty"` // "IMAX", "Extended", "Uncut", "Director's Cut"
+}
+
+// releaseTag maps the words of a release name to the tag they stand for. Lists of them
+// are ordered best first, where only one tag is kept.
+type releaseTag struct {
+	name  string
+	words []string
+}
+
+var (
+	releaseResolutions = []releaseTag{
+		{"4320p", []string{"4320p", "8k"}},
+		{"2160p", []string{"2160p", "4k", "uhd"}},
+		{"1440p", []string{"1440p", "2k"}},
+		{"1080p", []string{"1080p", "1080i", "fhd"}},
+		{"720p", []string{"720p", "hd"}},
+		{"480p", []string{"480p", "576p", "sd", "msd", "ntsc", "pal"}},
+		{"360p", []string{"360p"}},
+	}
+	releaseSources = []releaseTag{
+		{"Remux", []string{"remux", "bdremux"}},
+		{"BluRay", []string{"bluray", "bd"}},
+		{"BDRip", []string{"bdrip"}},
+		{"BRRip", []string{"brrip"}},
+		{"WEB-DL", []string{"webdl"}},
+		{"WEBRip", []string{"webrip"}},
+		{"WEB", []string{"web"}},
+		{"HDTV", []string{"hdtv", "pdtv"}},
+		{"HDRip", []string{"hdrip"}},
+		{"DVDRip", []string{"dvdrip"}},
+		{"DVD", []string{"dvd", "dvd5", "dvd9"}},
+		{"TC", []string{"tc", "telecine"}},
+		{"TS", []string{"ts", "telesync", "hdts"}},
+		{"CAM", []string{"cam", "camrip", "hdcam"}},
+	}
+	releaseCodecs = []releaseTag{
+		{"AV1", []string{"av1"}},
+		{"H.265", []string{"x265", "h265", "hevc"}},
+		{"H.264", []string{"x264", "h264", "avc"}},
+		{"VP9", []string{"vp9"}},
+		{"XviD", []string{"xvid"}},
+		{"DivX", []string{"divx"}},
+	}
+	releaseAudio = []releaseTag{
+		{"Atmos", []string{"atmos"}},
+		{"TrueHD", []string{"truehd"}},
+		{"DTS-X", []string{"dtsx"}},
+		{"DTS-HD", []string{"dtshd"}},
+		{"DTS", []string{"dts"}},
+		{"DD+", []string{"ddp", "eac3"}},
+		{"DD", []string{"dd", "ac3"}},
+		{"FLAC", []string{"flac"}},
+		{"LPCM", []string{"lpcm", "pcm"}},
+		{"AAC", []string{"aac"}},
+		{"Opus", []string{"opus"}},
+		{"MP3", []string{"mp3"}},
+	}
+	releaseHDR = []releaseTag{
+		{"DV", []string{"dv", "dovi", "dolbyvision"}},
+		{"HDR10+", []string{"hdr10plus"}},
+		{"HDR10", []string{"hdr10"}},
+		{"HDR", []string{"hdr"}},
+		{"HLG", []string{"hlg"}},
+	}
+	releaseEditions = []releaseTag{
+		{"IMAX", []string{"imax"}},
+		{"Extended", []string{"extended"}},
+		{"Uncut", []string{"uncut"}},
+		{"Director's Cut", []string{"directors", "dircut"}},
+	}
+	releaseOrigins = []releaseTag{
+		{"INTERNAL", []string{"internal"}},
+		{"SCENE", []string{"scene"}},
+		{"P2P", []string{"p2p"}},
+	}
+	// properWords mark a release that replaces a flawed one of the same name.
+	properWords = []string{"proper", "repack", "rerip"}
+
+	// impliedResolutions are the resolutions of sources and codecs that hardly come in
+	// any other, for names that don't give one.
+	impliedResolutions = map[string]string{"HDTV": "720p", "XviD": "720p", "DVDRip": "480p"}
+
+	// releaseTagSpellings joins the tags that are spelled with a separator, so they stay
+	// one word ("DTS-HD" is not the "HD" resolution).
+	releaseTagSpellings = strings.NewReplacer(
+		"web-dl", "webdl", "web.dl", "webdl", "web-rip", "webrip", "blu-ray", "bluray",
+		"dts-hd", "dtshd", "dts-x", "dtsx", "dts:x", "dtsx", "true-hd", "truehd",
+		"h.264", "h264", "h.265", "h265", "hdr10+", "hdr10plus", "dd+", "ddp", "e-ac3", "eac3",
+		"dolby.vision", "dolbyvision", "dolby vision", "dolbyvision",
+	)
+	releaseWordSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+	trailingDigits        = regexp.MustCompile(`[0-9]+$`)
+
+	// releaseGroupPatterns find the group of a release name: a trailing "-GROUP" (before
+	// any file extension or bracketed tag), or a leading "[Group]" as anime releases use.
+	releaseGroupPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`-([A-Za-z0-9]+)(?:\.[a-z0-9]{2,4})?(?:\s*\[[^\]]*\])*\s*$`),
+		regexp.MustCompile(`^\[([^\]]+)\]`),
+	}
+	// notReleaseGroups are title endings that look like a group but are part of a tag.
+	notReleaseGroups = map[string]bool{"dl": true, "rip": true}
+)
+
+// ParseReleaseName reads the quality tags of a release name, e.g.
+// "Movie.2021.2160p.UHD.BluRay.x265.HDR10.DTS-HD.MA.5.1-GROUP". Tags are matched as
+// whole words, so the title itself rarely passes for one. Tags the name doesn't have
+// are left empty.
+func ParseReleaseName(title string) ReleaseInfo {
+	words := make(map[string]bool)
+	// Audio tags carry their channels ("DDP5.1", "AAC2.0")
+	audioWords := make(map[string]bool)
+	for _, word := range releaseWordSeparators.Split(releaseTagSpellings.Replace(strings.ToLower(title)), -1) {
+		if word == "" {
+			continue
+		}
+		words[word] = true
+		audioWords[word] = true
+		if trimmed := trailingDigits.ReplaceAllString(word, ""); trimmed != "" {
+			audioWords[trimmed] = true
+		}
+	}
+
+	proper := false
+	for _, word := range properWords {
+		proper = proper || words[word]
+	}
+
+	return ReleaseInfo{
+		Resolution: bestReleaseTag(releaseResolutions, words),
+		Source:     bestReleaseTag(releaseSources, words),
+		Codec:      bestReleaseTag(releaseCodecs, words),
+		Audio:      allReleaseTags(releaseAudio, audioWords),
+		HDR:        allReleaseTags(releaseHDR, words),
+		Group:      releaseGroup(title),
+		Origin:     bestReleaseTag(releaseOrigins, words),
+		Proper:     proper,
+		Edition:    allReleaseTags(releaseEditions, words),
+	}
+}
+
+// Resolutions returns the resolutions a release name can have, lowest first.
+func Resolutions() []string {
+	resolutions := make([]string, len(releaseResolutions))
+	for i, tag := range releaseResolutions {
+		resolutions[len(releaseResolutions)-1-i] = tag.name
+	}
+	return resolutions
+}
+
+// Tags returns all the tags of the release, for scoring them.
+func (r ReleaseInfo) Tags() []string {
+	var tags []string
+	for _, tag := range []string{r.Resolution, r.Source, r.Codec, r.Origin} {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	tags = append(tags, r.Audio...)
+	tags = append(tags, r.HDR...)
+	return append(tags, r.Edition...)
+}
+
+// ReleaseBaseName is the release name without its proper tags and group, in lowercase
+// words: a PROPER or REPACK has the same base name as the release it fixes, even when
+// another group made it.
+func ReleaseBaseName(title string) string {
+	info := ParseReleaseName(title)
+	var base []string
+	for _, word := range releaseWordSeparators.Split(strings.ToLower(title), -1) {
+		if word == "" || word == "real" || word == strings.ToLower(info.Group) || slices.Contains(properWords, word) {
+			continue
+		}
+		base = append(base, word)
+	}
+	return strings.Join(base, " ")
+}
+
+// ImpliedResolution is the release's resolution, or the one its source or codec
+// implies when the name doesn't give one (an HDTV or XviD release is taken for 720p).
+func (r ReleaseInfo) ImpliedResolution() string {
+	if r.Resolution != "" {
+		return r.Resolution
+	}
+	if resolution, ok := impliedResolutions[r.Source]; ok {
+		return resolution
+	}
+	return impliedResolutions[r.Codec]
+}
+
+// bestReleaseTag returns the first tag of tags found in words, or "".
+func bestReleaseTag(tags []releaseTag, words map[string]bool) string {
+	for _, tag := range tags {
+		for _, word := range tag.words {
+			if words[word] {
+				return tag.name
+			}
+		}
+	}
+	return ""
+}
+
+// allReleaseTags returns every tag of tags found in words, in the order of tags.
+func allReleaseTags(tags []releaseTag, words map[string]bool) []string {
+	var found []string
+	for _, tag := range tags {
+		for _, word := range tag.words {
+			if words[word] {
+				found = append(found, tag.name)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// releaseGroup returns the release group of a title, or "" when it has none.
+func releaseGroup(title string) string {
+	title = strings.TrimSpace(title)
+	for _, pattern := range releaseGroupPatterns {
+		if match := pattern.FindStringSubmatch(title); match != nil && !notReleaseGroups[strings.ToLower(match[1])] {
+			return match[1]
+		}
+	}
+	return ""
+}
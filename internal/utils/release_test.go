@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReleaseName(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected ReleaseInfo
+	}{
+		{
+			title: "Dune.Part.Two.2024.2160p.UHD.BluRay.REMUX.HEVC.DV.HDR10.TrueHD.Atmos.7.1-FraMeSToR",
+			expected: ReleaseInfo{Resolution: "2160p", Source: "Remux", Codec: "H.265", Audio: []string{"Atmos", "TrueHD"},
+				HDR: []string{"DV", "HDR10"}, Group: "FraMeSToR"},
+		},
+		{
+			title:    "The.Bear.S03E01.1080p.WEB-DL.DDP5.1.H.264-NTb",
+			expected: ReleaseInfo{Resolution: "1080p", Source: "WEB-DL", Codec: "H.264", Audio: []string{"DD+"}, Group: "NTb"},
+		},
+		{
+			title:    "Oppenheimer.2023.IMAX.1080p.BluRay.x264.DTS-HD.MA.5.1-GRP",
+			expected: ReleaseInfo{Resolution: "1080p", Source: "BluRay", Codec: "H.264", Audio: []string{"DTS-HD"}, Group: "GRP", Edition: []string{"IMAX"}},
+		},
+		{
+			title:    "[SubsPlease] Frieren - 12 (1080p) [A1B2C3D4].mkv",
+			expected: ReleaseInfo{Resolution: "1080p", Group: "SubsPlease"},
+		},
+		{
+			title:    "Show.S02E05.PROPER.720p.HDTV.x264-KILLERS",
+			expected: ReleaseInfo{Resolution: "720p", Source: "HDTV", Codec: "H.264", Group: "KILLERS", Proper: true},
+		},
+		{
+			title:    "Movie.2010.iNTERNAL.Extended.Cut.1080p.WEBRip.AAC2.0.x265-GRP",
+			expected: ReleaseInfo{Resolution: "1080p", Source: "WEBRip", Codec: "H.265", Audio: []string{"AAC"}, Group: "GRP", Origin: "INTERNAL", Edition: []string{"Extended"}},
+		},
+		{
+			title:    "Old.Movie.1999.DVDRip.XviD-GRP",
+			expected: ReleaseInfo{Source: "DVDRip", Codec: "XviD", Group: "GRP"},
+		},
+		{
+			// "HD" inside other tags is no resolution
+			title:    "Movie.2022.HDR.WEB-DL-GRP",
+			expected: ReleaseInfo{Source: "WEB-DL", HDR: []string{"HDR"}, Group: "GRP"},
+		},
+		{
+			title:    "Movie 2021",
+			expected: ReleaseInfo{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := ParseReleaseName(tt.title); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseReleaseName(%q) =\n%+v\nwant\n%+v", tt.title, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImpliedResolution(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Show.S01E01.1080p.HDTV-GRP", "1080p"},
+		{"Show.S01E01.HDTV.x264-GRP", "720p"},
+		{"Movie.2005.XviD-GRP", "720p"},
+		{"Movie.2005.DVDRip-GRP", "480p"},
+		{"Movie.2005.WEB-GRP", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParseReleaseName(tt.title).ImpliedResolution(); got != tt.expected {
+			t.Errorf("ImpliedResolution(%q) = %q, want %q", tt.title, got, tt.expected)
+		}
+	}
+}
+
+func TestResolutions(t *testing.T) {
+	expected := []string{"360p", "480p", "720p", "1080p", "1440p", "2160p", "4320p"}
+	if got := Resolutions(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Resolutions() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	camelCaseRegex   = regexp.MustCompile(`([a-z])([A-Z])`)
+	apostropheRegex  = regexp.MustCompile(`['’` + "`" + `]`)
+	nonAlphanumRegex = regexp.MustCompile(`[^a-z0-9]+`)
+	titleStopWords   = map[string]bool{
+		"the": true, "a": true, "an": true, "and": true, "or": true, "but": true,
+		"in": true, "on": true, "at": true, "to": true, "for": true, "of": true,
+		"with": true, "by": true, "from": true, "up": true, "about": true, "into": true,
+	}
+	// Single-letter numerals (i, v, x) are left alone; they are too often initials or separators.
+	romanNumerals = map[string]string{
+		"ii": "2", "iii": "3", "iv": "4", "vi": "6", "vii": "7", "viii": "8", "ix": "9",
+		"xi": "11", "xii": "12", "xiii": "13", "xiv": "14", "xv": "15",
+	}
+)
+
+// NormalizeTitle reduces a title to lowercase ASCII words separated by single spaces:
+// accents are folded ("Pokémon" -> "pokemon"), apostrophes dropped ("Marvel's" -> "marvels"),
+// "&" becomes "and", camelCase is split, other punctuation becomes a separator, roman
+// numerals become numbers and leading zeros are trimmed ("Part II" / "Part 02" -> "part 2").
+func NormalizeTitle(title string) string {
+	var folded strings.Builder
+	for _, r := range norm.NFD.String(title) {
+		if !unicode.Is(unicode.Mn, r) {
+			folded.WriteRune(r)
+		}
+	}
+
+	s := camelCaseRegex.ReplaceAllString(folded.String(), "$1 $2")
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "&", " and ")
+	s = apostropheRegex.ReplaceAllString(s, "")
+	s = nonAlphanumRegex.ReplaceAllString(s, " ")
+
+	words := strings.Fields(s)
+	for i, word := range words {
+		if number, ok := romanNumerals[word]; ok {
+			words[i] = number
+		} else if n, err := strconv.Atoi(word); err == nil {
+			words[i] = strconv.Itoa(n)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// TitleMatches reports whether the candidate (e.g. a torrent name) contains any of the
+// search terms. A term matches when all its meaningful words appear as words in the
+// normalized candidate, or when the term with spaces removed appears in the candidate
+// with spaces removed ("Steins;Gate" vs "SteinsGate").
+func TitleMatches(candidate string, terms []string) bool {
+	normalizedCandidate := NormalizeTitle(candidate)
+	candidateWords := make(map[string]bool)
+	for _, word := range strings.Fields(normalizedCandidate) {
+		candidateWords[word] = true
+	}
+	compactCandidate := strings.ReplaceAll(normalizedCandidate, " ", "")
+
+	for _, term := range terms {
+		words := titleMeaningfulWords(term)
+		if len(words) == 0 {
+			continue
+		}
+
+		allWordsFound := true
+		for _, word := range words {
+			if !candidateWords[word] {
+				allWordsFound = false
+				break
+			}
+		}
+		if allWordsFound {
+			return true
+		}
+
+		if strings.Contains(compactCandidate, strings.ReplaceAll(NormalizeTitle(term), " ", "")) {
+			return true
+		}
+	}
+	return false
+}
+
+// titleMeaningfulWords returns the normalized words of a title without stop words and
+// single letters (digits are kept). If nothing is left, all normalized words are returned.
+func titleMeaningfulWords(title string) []string {
+	words := strings.Fields(NormalizeTitle(title))
+	var meaningful []string
+	for _, word := range words {
+		_, err := strconv.Atoi(word)
+		if (len(word) > 1 || err == nil) && !titleStopWords[word] {
+			meaningful = append(meaningful, word)
+		}
+	}
+	if len(meaningful) == 0 {
+		return words
+	}
+	return meaningful
+}
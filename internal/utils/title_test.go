@@ -0,0 +1,55 @@
+package utils
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Pokémon", "pokemon"},
+		{"Marvel's Agents of S.H.I.E.L.D.", "marvels agents of s h i e l d"},
+		{"Marvel’s Daredevil", "marvels daredevil"},
+		{"Law & Order", "law and order"},
+		{"Amélie", "amelie"},
+		{"Rocky II", "rocky 2"},
+		{"Star Wars: Episode IV", "star wars episode 4"},
+		{"Part 02", "part 2"},
+		{"BoJackHorseman", "bo jack horseman"},
+		{"  Steins;Gate  ", "steins gate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := NormalizeTitle(tt.title); got != tt.want {
+				t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleMatches(t *testing.T) {
+	tests := []struct {
+		candidate string
+		terms     []string
+		want      bool
+	}{
+		{"Pokemon.S01E01.1080p.WEB-GRP", []string{"Pokémon"}, true},
+		{"Pokémon.Horizons.S01E01.1080p", []string{"Pokemon Horizons"}, true},
+		{"Marvels.Daredevil.S01E01.720p", []string{"Marvel's Daredevil"}, true},
+		{"Marvel's.Daredevil.S01E01.720p", []string{"Marvels Daredevil"}, true},
+		{"Law.and.Order.S20E01.1080p", []string{"Law & Order"}, true},
+		{"Steins.Gate.0.S01E01", []string{"SteinsGate"}, true},
+		{"Rocky.2.1979.1080p", []string{"Rocky II"}, true},
+		{"The.Office.US.S01E01", []string{"Office"}, true},
+		{"Daredevil.Born.Again.S01E01", []string{"Marvel's Daredevil"}, false},
+		{"Pokemon.S01E01", []string{"Digimon", "Yu-Gi-Oh!"}, false},
+		{"Pokemon.S01E01", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.candidate, func(t *testing.T) {
+			if got := TitleMatches(tt.candidate, tt.terms); got != tt.want {
+				t.Errorf("TitleMatches(%q, %q) = %v, want %v", tt.candidate, tt.terms, got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"reel/internal/config"
 	"reel/internal/core"
@@ -27,6 +28,11 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	// Everything Reel writes at runtime lives under the data path
+	if err := cfg.PrepareDataPath(); err != nil {
+		log.Fatal("Failed to prepare data path:", err)
+	}
+
 	// Initialize logger to write to both file and console
 	logFile, err := os.OpenFile(filepath.Join(cfg.App.DataPath, "app.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -38,7 +44,11 @@ func main() {
 	logger := utils.NewLogger(cfg.App.Debug, multiWriter)
 
 	// Initialize database
-	db, err := database.NewSQLite(cfg.Database.Path)
+	db, err := database.NewSQLite(cfg.DatabasePath(), database.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(cfg.Database.ConnMaxLifetimeMinutes) * time.Minute,
+	})
 	if err != nil {
 		logger.Fatal("Failed to initialize database:", err)
 	}